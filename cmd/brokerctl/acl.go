@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"queue-service/internal/delivery/grpc/pb"
+)
+
+var (
+	aclResourceNames = map[string]pb.AclResourceType{
+		"topic":   pb.AclResourceType_ACL_RESOURCE_TOPIC,
+		"group":   pb.AclResourceType_ACL_RESOURCE_GROUP,
+		"cluster": pb.AclResourceType_ACL_RESOURCE_CLUSTER,
+	}
+	aclOperationNames = map[string]pb.AclOperation{
+		"publish": pb.AclOperation_ACL_OPERATION_PUBLISH,
+		"consume": pb.AclOperation_ACL_OPERATION_CONSUME,
+		"admin":   pb.AclOperation_ACL_OPERATION_ADMIN,
+	}
+)
+
+func (c *cli) acl(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "add":
+		return c.aclAdd(ctx, args[1:])
+	case "list":
+		return c.aclList(ctx, args[1:])
+	case "delete":
+		return c.aclDelete(ctx, args[1:])
+	}
+	return fmt.Errorf("%w: unknown acl command %q", errUsage, args[0])
+}
+
+func (c *cli) aclAdd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("acl add", flag.ContinueOnError)
+	principal := fs.String("principal", "", `client name, "*" or "anonymous" (required)`)
+	resource := fs.String("resource", "", "topic:PATTERN, group:PATTERN or cluster (required)")
+	ops := fs.String("ops", "", "comma-separated publish, consume, admin (required)")
+	allNamespaces := fs.Bool("all-namespaces", false, "grant in every namespace; by default only in the namespace of the request")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	if *principal == "" || *resource == "" || *ops == "" {
+		return fmt.Errorf("%w: -principal, -resource and -ops are required", errUsage)
+	}
+	kind, pattern, _ := strings.Cut(*resource, ":")
+	resourceType, ok := aclResourceNames[kind]
+	if !ok {
+		return fmt.Errorf("%w: -resource must start with topic:, group: or be cluster, got %q", errUsage, *resource)
+	}
+	rule := &pb.AclRule{Principal: *principal, ResourceType: resourceType, Pattern: pattern}
+	if *allNamespaces {
+		rule.Namespace = "*"
+	}
+	for _, name := range strings.Split(*ops, ",") {
+		op, ok := aclOperationNames[strings.TrimSpace(name)]
+		if !ok {
+			return fmt.Errorf("%w: unknown operation %q", errUsage, name)
+		}
+		rule.Operations = append(rule.Operations, op)
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	resp, err := c.broker.CreateAclRule(ctx, &pb.CreateAclRuleRequest{Rule: rule})
+	if err != nil {
+		return err
+	}
+	return c.print(resp.Rule, aclHeader, [][]string{aclRow(resp.Rule)})
+}
+
+func (c *cli) aclList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("acl list", flag.ContinueOnError)
+	principal := fs.String("principal", "", "only rules of this client")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	resp, err := c.broker.ListAclRules(ctx, &pb.ListAclRulesRequest{Principal: *principal})
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(resp.Rules))
+	for _, r := range resp.Rules {
+		rows = append(rows, aclRow(r))
+	}
+	return c.print(resp.Rules, aclHeader, rows)
+}
+
+func (c *cli) aclDelete(ctx context.Context, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("acl delete", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	if _, err := c.broker.DeleteAclRule(ctx, &pb.DeleteAclRuleRequest{Id: pos[0]}); err != nil {
+		return err
+	}
+	return c.print(map[string]string{"deleted": pos[0]}, []string{"DELETED"}, [][]string{{pos[0]}})
+}
+
+var aclHeader = []string{"RULE", "PRINCIPAL", "RESOURCE", "NAMESPACE", "PATTERN", "OPERATIONS"}
+
+func aclRow(r *pb.AclRule) []string {
+	var resource string
+	for name, t := range aclResourceNames {
+		if t == r.ResourceType {
+			resource = name
+		}
+	}
+	ops := make([]string, 0, len(r.Operations))
+	for _, op := range r.Operations {
+		for name, o := range aclOperationNames {
+			if o == op {
+				ops = append(ops, name)
+			}
+		}
+	}
+	ns := r.Namespace
+	if ns == "" {
+		ns = "-"
+	}
+	return []string{r.Id, r.Principal, resource, ns, r.Pattern, strings.Join(ops, ",")}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+)
+
+// authOptions — учётные данные, которые brokerctl передаёт брокеру.
+type authOptions struct {
+	apiKey string
+	token  string
+}
+
+type perRPCMetadata map[string]string
+
+func (m perRPCMetadata) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return m, nil
+}
+
+func (perRPCMetadata) RequireTransportSecurity() bool { return false }
+
+// dialOptions возвращает параметр с ключом или токеном; без них — ничего.
+func (o authOptions) dialOptions() ([]grpc.DialOption, error) {
+	switch {
+	case o.apiKey != "" && o.token != "":
+		return nil, errors.New("-api-key and -token are mutually exclusive")
+	case o.apiKey != "":
+		return []grpc.DialOption{grpc.WithPerRPCCredentials(perRPCMetadata{"x-api-key": o.apiKey})}, nil
+	case o.token != "":
+		return []grpc.DialOption{grpc.WithPerRPCCredentials(perRPCMetadata{"authorization": "Bearer " + o.token})}, nil
+	}
+	return nil, nil
+}
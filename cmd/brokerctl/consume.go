@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"queue-service/internal/delivery/grpc/pb"
+)
+
+var messageHeader = []string{"OFFSET", "QUEUE", "KEY", "HEADERS", "DELIVERY", "PAYLOAD"}
+
+// consume выполняет один Consume; в режиме tail опрашивает подписку, пока
+// не прерван (Ctrl+C), и печатает сообщения по мере поступления.
+func (c *cli) consume(ctx context.Context, args []string, tail bool) error {
+	name := "consume"
+	if tail {
+		name = "tail"
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	maxMessages := fs.Int("max", 10, "max messages per request")
+	ack := fs.Bool("ack", false, "acknowledge at-least-once messages after printing")
+	interval := fs.Duration("interval", 500*time.Millisecond, "tail: pause between empty polls")
+	pos, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	subID := pos[0]
+
+	if !tail {
+		msgs, err := c.poll(ctx, subID, *maxMessages)
+		if err != nil {
+			return err
+		}
+		if err := c.print(msgs, messageHeader, messageRows(msgs)); err != nil {
+			return err
+		}
+		if *ack {
+			return c.ackAll(ctx, subID, msgs)
+		}
+		return nil
+	}
+
+	if c.format == "table" {
+		if err := c.print(nil, messageHeader, nil); err != nil {
+			return err
+		}
+	}
+	for {
+		msgs, err := c.poll(ctx, subID, *maxMessages)
+		if err != nil {
+			if expired(ctx) {
+				return nil
+			}
+			return err
+		}
+		if err := c.printStream(msgs); err != nil {
+			return err
+		}
+		if *ack {
+			if err := c.ackAll(ctx, subID, msgs); err != nil {
+				return err
+			}
+		}
+		if len(msgs) > 0 {
+			continue
+		}
+		select {
+		case <-time.After(*interval):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// expired сообщает, что ctx отменён или его срок истёк. Сервер может ответить
+// DeadlineExceeded раньше, чем сработает таймер самого ctx.
+func expired(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	deadline, ok := ctx.Deadline()
+	return ok && !time.Now().Before(deadline)
+}
+
+func (c *cli) poll(ctx context.Context, subID string, maxMessages int) ([]*pb.Message, error) {
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	resp, err := c.broker.Consume(ctx, &pb.ConsumeRequest{SubscriptionId: subID, MaxMessages: int32(maxMessages)})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Messages, nil
+}
+
+func (c *cli) ackAll(ctx context.Context, subID string, msgs []*pb.Message) error {
+	for _, m := range msgs {
+		if m.DeliveryId == "" {
+			continue
+		}
+		actx, cancel := c.call(ctx)
+		_, err := c.broker.Ack(actx, &pb.AckRequest{SubscriptionId: subID, DeliveryId: m.DeliveryId})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("ack offset %d: %w", m.Offset, err)
+		}
+	}
+	return nil
+}
+
+// printStream печатает сообщения tail без заголовка; JSON — по объекту в строке.
+func (c *cli) printStream(msgs []*pb.Message) error {
+	if c.format == "json" {
+		enc := json.NewEncoder(c.out)
+		for _, m := range msgs {
+			if err := enc.Encode(m); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	for _, row := range messageRows(msgs) {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func messageRows(msgs []*pb.Message) [][]string {
+	rows := make([][]string, 0, len(msgs))
+	for _, m := range msgs {
+		rows = append(rows, []string{
+			strconv.FormatInt(m.Offset, 10),
+			m.QueueId,
+			orDash(m.Key),
+			formatHeaders(m.Headers),
+			orDash(m.DeliveryId),
+			printable(m.Payload),
+		})
+	}
+	return rows
+}
@@ -0,0 +1,195 @@
+// brokerctl — консольная утилита администрирования брокера поверх gRPC API.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"queue-service/internal/delivery/grpc/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const usage = `Usage: brokerctl [flags] <command> [args]
+
+Commands:
+  topic create <name> [-retention N]
+  topic list
+  topic describe <name>
+  topic delete <name> [-force]
+  queue create <topic> <queue>
+  queue list <topic>
+  queue delete <topic> <queue> [-force]
+  queue purge <topic> <queue>
+  subscription create <topic> -group G [-queue Q] [-guarantee at-most-once|at-least-once]
+                      [-start earliest|latest|RFC3339-TIME]
+  subscription list [-topic T]
+  subscription describe <subscription-id>
+  subscription seek <subscription-id> -to earliest|latest|OFFSET|RFC3339-TIME
+  subscription delete <subscription-id>
+  message browse <topic> <queue> [-from N] [-limit N] [-key K] [-H k=v]... [-grep S]
+  message get <topic> <queue> <message-id>
+  memory
+  acl add -principal P -resource topic:PATTERN|group:PATTERN|cluster -ops publish,consume,admin [-all-namespaces]
+  acl list [-principal P]
+  acl delete <rule-id>
+  publish <topic> [-queue Q] [-key K] [-H k=v]... [-file F] [-lines]
+  consume <subscription-id> [-max N] [-ack]
+  tail <subscription-id> [-max N] [-ack] [-interval D]
+
+Flags:
+`
+
+// errUsage — неверные аргументы; main печатает справку и выходит с кодом 2.
+var errUsage = errors.New("usage")
+
+type cli struct {
+	broker  pb.BrokerClient
+	in      io.Reader
+	out     io.Writer
+	format  string
+	timeout time.Duration
+}
+
+func main() {
+	global := flag.NewFlagSet("brokerctl", flag.ContinueOnError)
+	global.Usage = func() {
+		fmt.Fprint(global.Output(), usage)
+		global.PrintDefaults()
+	}
+	addr := global.String("addr", envOr("BROKER_ADDR", "localhost:50051"), "broker gRPC address (env BROKER_ADDR)")
+	format := global.String("o", "table", "output format: table or json")
+	timeout := global.Duration("timeout", 10*time.Second, "per-request timeout")
+	var tlsFlags tlsOptions
+	global.BoolVar(&tlsFlags.enabled, "tls", false, "connect over TLS (implied by -tls-ca and -tls-cert)")
+	global.StringVar(&tlsFlags.caFile, "tls-ca", os.Getenv("BROKER_TLS_CA"), "PEM file with the broker CA; default system roots (env BROKER_TLS_CA)")
+	global.StringVar(&tlsFlags.certFile, "tls-cert", os.Getenv("BROKER_TLS_CERT"), "client certificate for mTLS (env BROKER_TLS_CERT)")
+	global.StringVar(&tlsFlags.keyFile, "tls-key", os.Getenv("BROKER_TLS_KEY"), "client private key for mTLS (env BROKER_TLS_KEY)")
+	global.StringVar(&tlsFlags.serverName, "tls-server-name", "", "expected broker certificate name; default host of -addr")
+	var authFlags authOptions
+	global.StringVar(&authFlags.apiKey, "api-key", os.Getenv("BROKER_API_KEY"), "API key sent as x-api-key (env BROKER_API_KEY)")
+	global.StringVar(&authFlags.token, "token", os.Getenv("BROKER_TOKEN"), "JWT sent as authorization: Bearer (env BROKER_TOKEN)")
+	namespace := global.String("namespace", os.Getenv("BROKER_NAMESPACE"), "namespace sent as x-namespace; default is chosen by the broker (env BROKER_NAMESPACE)")
+	if err := global.Parse(os.Args[1:]); err != nil {
+		os.Exit(2)
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "brokerctl: unknown output format %q\n", *format)
+		os.Exit(2)
+	}
+
+	creds, err := tlsFlags.credentials()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "brokerctl: %v\n", err)
+		os.Exit(2)
+	}
+	dialOpts, err := authFlags.dialOptions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "brokerctl: %v\n", err)
+		os.Exit(2)
+	}
+	if *namespace != "" {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(perRPCMetadata{"x-namespace": *namespace}))
+	}
+	conn, err := grpc.NewClient(*addr, append(dialOpts, grpc.WithTransportCredentials(creds))...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "brokerctl: %v\n", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c := &cli{
+		broker:  pb.NewBrokerClient(conn),
+		in:      os.Stdin,
+		out:     os.Stdout,
+		format:  *format,
+		timeout: *timeout,
+	}
+	if err := c.run(ctx, global.Args()); err != nil {
+		if errors.Is(err, errUsage) {
+			global.Usage()
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "brokerctl: %s\n", errorText(err))
+		os.Exit(1)
+	}
+}
+
+func (c *cli) run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "topic":
+		return c.topic(ctx, args[1:])
+	case "queue":
+		return c.queue(ctx, args[1:])
+	case "subscription":
+		return c.subscription(ctx, args[1:])
+	case "message":
+		return c.message(ctx, args[1:])
+	case "memory":
+		return c.memory(ctx, args[1:])
+	case "acl":
+		return c.acl(ctx, args[1:])
+	case "publish":
+		return c.publish(ctx, args[1:])
+	case "consume":
+		return c.consume(ctx, args[1:], false)
+	case "tail":
+		return c.consume(ctx, args[1:], true)
+	}
+	return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+}
+
+// call ограничивает один запрос к брокеру таймаутом -timeout.
+func (c *cli) call(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// parseFlags разбирает флаги подкоманды; флаги допускаются и после
+// позиционных аргументов. Возвращает позиционные аргументы.
+func parseFlags(fs *flag.FlagSet, args []string, positional int) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		rest = append(rest, args[0])
+		args = args[1:]
+	}
+	if len(rest) != positional {
+		return nil, fmt.Errorf("%w: %s expects %d argument(s)", errUsage, fs.Name(), positional)
+	}
+	return rest, nil
+}
+
+func errorText(err error) string {
+	if st, ok := status.FromError(err); ok {
+		return fmt.Sprintf("%s: %s", st.Code(), st.Message())
+	}
+	return err.Error()
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	deliverygrpc "queue-service/internal/delivery/grpc"
+	"queue-service/internal/delivery/grpc/pb"
+	"queue-service/internal/repository/memory"
+	"queue-service/internal/usecase"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestCLI(t *testing.T) (*cli, *bytes.Buffer) {
+	t.Helper()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	subs := memory.NewSubscriptionRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	topicUC := usecase.NewTopicUseCase(topics, queues, msgs, subs, pending)
+	pub := usecase.NewPublishUseCase(topics, queues, msgs, 1024*1024, usecase.MemoryBudget{}, nil)
+	subUC := usecase.NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := usecase.NewConsumeUseCase(subs, msgs, pending, nil)
+	messageUC := usecase.NewMessageUseCase(topics, queues, msgs)
+	tenants, _ := usecase.NewTenantUseCase(topics, subs, usecase.TenantPolicy{})
+	quotas, _ := usecase.NewQuotaUseCase(topics, subs, usecase.QuotaPolicy{})
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterBrokerServer(srv, deliverygrpc.NewBrokerHandler(topicUC, pub, subUC, consumeUC, messageUC,
+		usecase.NewACLUseCase(memory.NewACLRepository(), usecase.ACLPolicy{}), tenants, quotas))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	out := &bytes.Buffer{}
+	return &cli{broker: pb.NewBrokerClient(conn), in: strings.NewReader(""), out: out, format: "table", timeout: time.Second}, out
+}
+
+func (c *cli) mustRun(t *testing.T, args ...string) {
+	t.Helper()
+	if err := c.run(context.Background(), args); err != nil {
+		t.Fatalf("%v: %v", args, err)
+	}
+}
+
+func TestCLI_topicAndQueue(t *testing.T) {
+	c, out := newTestCLI(t)
+
+	c.mustRun(t, "topic", "create", "orders", "-retention", "500")
+	c.mustRun(t, "queue", "create", "orders", "eu")
+	out.Reset()
+	c.mustRun(t, "topic", "describe", "orders")
+	if got := out.String(); !strings.Contains(got, "orders  500        0") || !strings.Contains(got, "eu") {
+		t.Errorf("describe table:\n%s", got)
+	}
+
+	c.format = "json"
+	out.Reset()
+	c.mustRun(t, "topic", "describe", "orders")
+	var desc topicDescription
+	if err := json.Unmarshal(out.Bytes(), &desc); err != nil {
+		t.Fatalf("describe json: %v\n%s", err, out.String())
+	}
+	if desc.RetentionMessages != 500 || len(desc.Queues) != 2 {
+		t.Errorf("describe: %+v", desc)
+	}
+
+	err := c.run(context.Background(), []string{"topic", "describe", "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("describe missing: want NotFound, got %v", err)
+	}
+	if err := c.run(context.Background(), []string{"topic", "create"}); !errors.Is(err, errUsage) {
+		t.Errorf("missing name: want errUsage, got %v", err)
+	}
+}
+
+func TestCLI_publishConsume(t *testing.T) {
+	c, out := newTestCLI(t)
+	c.mustRun(t, "topic", "create", "orders")
+	c.format = "json"
+	out.Reset()
+	c.mustRun(t, "subscription", "create", "orders", "-group", "g1", "-guarantee", "at-least-once")
+	var sub pb.SubscribeResponse
+	if err := json.Unmarshal(out.Bytes(), &sub); err != nil || sub.SubscriptionId == "" {
+		t.Fatalf("subscription json: %v\n%s", err, out.String())
+	}
+
+	c.in = strings.NewReader("first\nsecond\r\n\nthird\n")
+	c.mustRun(t, "publish", "orders", "-lines", "-key", "k1", "-H", "source=cli", "-H", "v=2")
+
+	c.format = "table"
+	out.Reset()
+	c.mustRun(t, "consume", sub.SubscriptionId, "-max", "2", "-ack")
+	got := out.String()
+	if !strings.Contains(got, "first") || !strings.Contains(got, "second") || strings.Contains(got, "third") {
+		t.Errorf("consume:\n%s", got)
+	}
+	if !strings.Contains(got, "source=cli,v=2") {
+		t.Errorf("consume headers:\n%s", got)
+	}
+
+	// Подтверждённые сообщения не доставляются повторно.
+	c.format = "json"
+	out.Reset()
+	c.mustRun(t, "consume", sub.SubscriptionId)
+	var msgs []*pb.Message
+	if err := json.Unmarshal(out.Bytes(), &msgs); err != nil {
+		t.Fatalf("consume json: %v\n%s", err, out.String())
+	}
+	if len(msgs) != 1 || string(msgs[0].Payload) != "third" {
+		t.Errorf("want only third, got %+v", msgs)
+	}
+}
+
+func TestCLI_tail(t *testing.T) {
+	c, out := newTestCLI(t)
+	c.mustRun(t, "topic", "create", "orders")
+	c.mustRun(t, "subscription", "create", "orders", "-group", "g1")
+	c.in = strings.NewReader("a\nb\n")
+	c.mustRun(t, "publish", "orders", "-lines")
+
+	var sub string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.HasPrefix(line, "sub-") {
+			sub = strings.Fields(line)[0]
+		}
+	}
+	c.format = "json"
+	out.Reset()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := c.run(ctx, []string{"tail", sub, "-interval", "10ms"}); err != nil {
+		t.Fatalf("tail: %v", err)
+	}
+	if lines := strings.Count(out.String(), "\n"); lines != 2 {
+		t.Errorf("want 2 json lines, got %d:\n%s", lines, out.String())
+	}
+}
+
+func TestCLI_topicDelete(t *testing.T) {
+	c, _ := newTestCLI(t)
+	c.mustRun(t, "topic", "create", "orders")
+	c.mustRun(t, "subscription", "create", "orders", "-group", "g1")
+
+	err := c.run(context.Background(), []string{"topic", "delete", "orders"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("delete with subscriptions: want FailedPrecondition, got %v", err)
+	}
+	c.mustRun(t, "topic", "delete", "orders", "-force")
+	err = c.run(context.Background(), []string{"topic", "describe", "orders"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("describe after delete: want NotFound, got %v", err)
+	}
+}
+
+func TestCLI_queuePurgeDelete_subscriptionDelete(t *testing.T) {
+	c, out := newTestCLI(t)
+	c.mustRun(t, "topic", "create", "orders")
+	c.mustRun(t, "queue", "create", "orders", "1")
+	c.in = strings.NewReader("a\nb\n")
+	c.mustRun(t, "publish", "orders", "-queue", "1", "-lines")
+
+	c.format = "json"
+	out.Reset()
+	c.mustRun(t, "queue", "purge", "orders", "1")
+	var purged pb.PurgeQueueResponse
+	if err := json.Unmarshal(out.Bytes(), &purged); err != nil || purged.LogStartOffset != 2 {
+		t.Fatalf("purge: %v %s", err, out.String())
+	}
+
+	out.Reset()
+	c.mustRun(t, "subscription", "create", "orders", "-queue", "1", "-group", "g1")
+	var sub pb.SubscribeResponse
+	_ = json.Unmarshal(out.Bytes(), &sub)
+	c.mustRun(t, "subscription", "delete", sub.SubscriptionId)
+	c.mustRun(t, "queue", "delete", "orders", "1")
+	err := c.run(context.Background(), []string{"queue", "purge", "orders", "1"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("purge deleted queue: want NotFound, got %v", err)
+	}
+}
+
+func TestCLI_subscriptionListDescribe(t *testing.T) {
+	c, out := newTestCLI(t)
+	c.mustRun(t, "topic", "create", "orders")
+	c.in = strings.NewReader("a\nb\nc\n")
+	c.mustRun(t, "publish", "orders", "-lines")
+
+	c.format = "json"
+	out.Reset()
+	c.mustRun(t, "subscription", "create", "orders", "-group", "g1", "-guarantee", "at-least-once")
+	var sub pb.SubscribeResponse
+	_ = json.Unmarshal(out.Bytes(), &sub)
+	c.mustRun(t, "consume", sub.SubscriptionId, "-max", "1")
+
+	out.Reset()
+	c.mustRun(t, "subscription", "describe", sub.SubscriptionId)
+	var info pb.SubscriptionInfo
+	if err := json.Unmarshal(out.Bytes(), &info); err != nil {
+		t.Fatalf("describe: %v %s", err, out.String())
+	}
+	if info.HighWatermark != 3 || info.Lag != 3 || info.PendingCount != 1 {
+		t.Errorf("describe: %+v", &info)
+	}
+
+	c.format = "table"
+	out.Reset()
+	c.mustRun(t, "subscription", "list", "-topic", "orders")
+	if !strings.Contains(out.String(), "LAG") || !strings.Contains(out.String(), sub.SubscriptionId) {
+		t.Errorf("list output:\n%s", out.String())
+	}
+}
+
+func TestCLI_subscriptionSeek(t *testing.T) {
+	c, out := newTestCLI(t)
+	c.mustRun(t, "topic", "create", "orders")
+	c.in = strings.NewReader("a\nb\nc\n")
+	c.mustRun(t, "publish", "orders", "-lines")
+
+	c.format = "json"
+	out.Reset()
+	c.mustRun(t, "subscription", "create", "orders", "-group", "g1")
+	var sub pb.SubscribeResponse
+	_ = json.Unmarshal(out.Bytes(), &sub)
+
+	for to, want := range map[string]int64{"latest": 3, "1": 1, "earliest": 0, "2000-01-01T00:00:00Z": 0} {
+		out.Reset()
+		c.mustRun(t, "subscription", "seek", sub.SubscriptionId, "-to", to)
+		var resp pb.SeekSubscriptionResponse
+		if err := json.Unmarshal(out.Bytes(), &resp); err != nil || resp.Offset != want {
+			t.Errorf("seek -to %s: %v %s", to, err, out.String())
+		}
+	}
+	if err := c.run(context.Background(), []string{"subscription", "seek", sub.SubscriptionId, "-to", "soon"}); !errors.Is(err, errUsage) {
+		t.Errorf("want usage error, got %v", err)
+	}
+
+	out.Reset()
+	c.mustRun(t, "subscription", "create", "orders", "-group", "g2", "-start", "latest")
+	var tail pb.SubscribeResponse
+	if err := json.Unmarshal(out.Bytes(), &tail); err != nil || tail.Offset != 3 {
+		t.Errorf("create -start latest: %v %s", err, out.String())
+	}
+	if err := c.run(context.Background(), []string{"subscription", "create", "orders", "-group", "g3", "-start", "5"}); !errors.Is(err, errUsage) {
+		t.Errorf("-start with offset: want usage error, got %v", err)
+	}
+}
+
+func TestCLI_messageBrowseGet(t *testing.T) {
+	c, out := newTestCLI(t)
+	c.mustRun(t, "topic", "create", "orders")
+	c.in = strings.NewReader("paid 1\nnew 2\npaid 3\n")
+	c.mustRun(t, "publish", "orders", "-queue", "0", "-lines")
+
+	c.format = "json"
+	out.Reset()
+	c.mustRun(t, "message", "browse", "orders", "0", "-grep", "paid", "-limit", "1")
+	var resp pb.BrowseMessagesResponse
+	if err := json.Unmarshal(out.Bytes(), &resp); err != nil || len(resp.Messages) != 1 || resp.NextOffset != 1 {
+		t.Fatalf("browse: %v %s", err, out.String())
+	}
+
+	out.Reset()
+	c.mustRun(t, "message", "get", "orders", "0", resp.Messages[0].Id)
+	var m pb.Message
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil || string(m.Payload) != "paid 1" {
+		t.Errorf("get: %v %s", err, out.String())
+	}
+
+	err := c.run(context.Background(), []string{"message", "get", "orders", "0", "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("get missing: want NotFound, got %v", err)
+	}
+}
+
+func TestCLI_memory(t *testing.T) {
+	c, out := newTestCLI(t)
+	c.mustRun(t, "topic", "create", "orders")
+	c.in = strings.NewReader("hello")
+	c.mustRun(t, "publish", "orders")
+
+	out.Reset()
+	c.mustRun(t, "memory")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "*") || !strings.Contains(lines[1], "reject") ||
+		!strings.HasPrefix(lines[2], "orders") {
+		t.Errorf("memory table:\n%s", out.String())
+	}
+}
+
+func TestCLI_acl(t *testing.T) {
+	c, out := newTestCLI(t)
+	c.mustRun(t, "acl", "add", "-principal", "orders", "-resource", "topic:orders.*", "-ops", "publish,consume")
+	c.mustRun(t, "acl", "add", "-principal", "ops", "-resource", "cluster", "-ops", "admin")
+
+	out.Reset()
+	c.mustRun(t, "acl", "list", "-principal", "orders")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], "topic") || !strings.Contains(lines[1], "orders.*") ||
+		!strings.Contains(lines[1], "publish,consume") {
+		t.Fatalf("acl list:\n%s", out.String())
+	}
+	id := strings.Fields(lines[1])[0]
+	c.mustRun(t, "acl", "delete", id)
+
+	out.Reset()
+	c.mustRun(t, "acl", "list")
+	if strings.Contains(out.String(), id) || !strings.Contains(out.String(), "cluster") {
+		t.Errorf("acl list after delete:\n%s", out.String())
+	}
+
+	for _, args := range [][]string{
+		{"acl", "add", "-principal", "a", "-resource", "queue:x", "-ops", "publish"},
+		{"acl", "add", "-principal", "a", "-resource", "topic:x", "-ops", "write"},
+		{"acl", "add", "-resource", "topic:x", "-ops", "publish"},
+	} {
+		if err := c.run(context.Background(), args); !errors.Is(err, errUsage) {
+			t.Errorf("%v: want usage error, got %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strconv"
+
+	"queue-service/internal/delivery/grpc/pb"
+)
+
+// memory выводит память, занятую сообщениями: первой строкой — всего по
+// брокеру (топик "*") с политикой переполнения, затем по топикам.
+func (c *cli) memory(ctx context.Context, args []string) error {
+	if _, err := parseFlags(flag.NewFlagSet("memory", flag.ContinueOnError), args, 0); err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	resp, err := c.broker.GetMemoryUsage(ctx, &pb.GetMemoryUsageRequest{})
+	if err != nil {
+		return err
+	}
+	rows := [][]string{{"*", strconv.FormatInt(resp.UsedBytes, 10), formatLimit(resp.LimitBytes), resp.OverflowPolicy}}
+	for _, t := range resp.Topics {
+		rows = append(rows, []string{t.TopicName, strconv.FormatInt(t.UsedBytes, 10), formatLimit(t.LimitBytes), "-"})
+	}
+	return c.print(resp, []string{"TOPIC", "USED", "LIMIT", "POLICY"}, rows)
+}
+
+func formatLimit(n int64) string {
+	if n <= 0 {
+		return "-"
+	}
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"queue-service/internal/delivery/grpc/pb"
+)
+
+func (c *cli) message(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "browse":
+		return c.messageBrowse(ctx, args[1:])
+	case "get":
+		return c.messageGet(ctx, args[1:])
+	}
+	return fmt.Errorf("%w: unknown message command %q", errUsage, args[0])
+}
+
+func (c *cli) messageBrowse(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("message browse", flag.ContinueOnError)
+	from := fs.Int64("from", 0, "first offset to look at")
+	limit := fs.Int("limit", 20, "max messages to return")
+	key := fs.String("key", "", "only messages with this key")
+	grep := fs.String("grep", "", "only messages whose payload contains this string")
+	headers := headerFlags{}
+	fs.Var(headers, "H", "only messages with header key=value (repeatable)")
+	pos, err := parseFlags(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	resp, err := c.broker.BrowseMessages(ctx, &pb.BrowseMessagesRequest{
+		TopicName:  pos[0],
+		QueueId:    pos[1],
+		FromOffset: *from,
+		Limit:      int32(*limit),
+		Filter:     &pb.MessageFilter{Key: *key, Headers: headers, PayloadContains: []byte(*grep)},
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.print(resp, messageHeader, messageRows(resp.Messages)); err != nil {
+		return err
+	}
+	if c.format == "table" {
+		fmt.Fprintf(c.out, "next offset: %d\n", resp.NextOffset)
+	}
+	return nil
+}
+
+func (c *cli) messageGet(ctx context.Context, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("message get", flag.ContinueOnError), args, 3)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	resp, err := c.broker.GetMessage(ctx, &pb.GetMessageRequest{TopicName: pos[0], QueueId: pos[1], MessageId: pos[2]})
+	if err != nil {
+		return err
+	}
+	return c.print(resp.Message, messageHeader, messageRows([]*pb.Message{resp.Message}))
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// print выводит v как JSON (nil-срез — как []) либо таблицу с заголовком header и строками rows.
+func (c *cli) print(v any, header []string, rows [][]string) error {
+	if c.format == "json" {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice && rv.IsNil() {
+			v = []any{}
+		}
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// printable показывает payload как текст, а бинарные данные — их размером.
+func printable(b []byte) string {
+	if !utf8.Valid(b) {
+		return fmt.Sprintf("<%d bytes>", len(b))
+	}
+	return strings.NewReplacer("\n", `\n`, "\t", `\t`).Replace(string(b))
+}
+
+func formatHeaders(h map[string]string) string {
+	if len(h) == 0 {
+		return "-"
+	}
+	parts := make([]string, 0, len(h))
+	for k, v := range h {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
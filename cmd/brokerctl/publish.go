@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"queue-service/internal/delivery/grpc/pb"
+
+	"google.golang.org/grpc/codes"
+)
+
+// headerFlags собирает повторяющиеся -H key=value.
+type headerFlags map[string]string
+
+func (h headerFlags) String() string { return formatHeaders(h) }
+
+func (h headerFlags) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("header must be key=value, got %q", s)
+	}
+	h[k] = v
+	return nil
+}
+
+func (c *cli) publish(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("publish", flag.ContinueOnError)
+	queue := fs.String("queue", "", "queue id (server default if empty)")
+	key := fs.String("key", "", "message key")
+	file := fs.String("file", "", "read payload from file instead of stdin")
+	lines := fs.Bool("lines", false, "publish every input line as a separate message")
+	headers := headerFlags{}
+	fs.Var(headers, "H", "message header key=value (repeatable)")
+	pos, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	var in io.Reader = c.in
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	payloads := [][]byte{data}
+	if *lines {
+		payloads = payloads[:0]
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			line = bytes.TrimSuffix(line, []byte("\r"))
+			if len(line) > 0 {
+				payloads = append(payloads, line)
+			}
+		}
+	}
+	req := &pb.PublishBatchRequest{Messages: make([]*pb.PublishRequest, len(payloads))}
+	for i, p := range payloads {
+		req.Messages[i] = &pb.PublishRequest{
+			TopicName: pos[0],
+			QueueId:   *queue,
+			Payload:   p,
+			Key:       *key,
+			Headers:   headers,
+		}
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	resp, err := c.broker.PublishBatch(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	rows := make([][]string, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		if r.Code != int32(codes.OK) {
+			failed++
+			rows = append(rows, []string{"-", "-", codes.Code(r.Code).String() + ": " + r.Error})
+			continue
+		}
+		rows = append(rows, []string{r.MessageId, strconv.FormatInt(r.Offset, 10), "ok"})
+	}
+	if err := c.print(resp.Results, []string{"MESSAGE", "OFFSET", "STATUS"}, rows); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d messages failed", failed, len(resp.Results))
+	}
+	return nil
+}
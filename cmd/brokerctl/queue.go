@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"queue-service/internal/delivery/grpc/pb"
+)
+
+func (c *cli) queue(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "create":
+		return c.queueCreate(ctx, args[1:])
+	case "list":
+		return c.queueList(ctx, args[1:])
+	case "delete":
+		return c.queueDelete(ctx, args[1:])
+	case "purge":
+		return c.queuePurge(ctx, args[1:])
+	}
+	return fmt.Errorf("%w: unknown queue command %q", errUsage, args[0])
+}
+
+func (c *cli) queueCreate(ctx context.Context, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("queue create", flag.ContinueOnError), args, 2)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	resp, err := c.broker.CreateQueue(ctx, &pb.CreateQueueRequest{TopicName: pos[0], QueueId: pos[1]})
+	if err != nil {
+		return err
+	}
+	return c.print(resp, []string{"TOPIC", "QUEUE"}, [][]string{{resp.TopicName, resp.QueueId}})
+}
+
+func (c *cli) queueList(ctx context.Context, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("queue list", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	resp, err := c.broker.ListQueues(ctx, &pb.ListQueuesRequest{TopicName: pos[0]})
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(resp.Queues))
+	for _, q := range resp.Queues {
+		rows = append(rows, []string{q.TopicName, q.QueueId})
+	}
+	return c.print(resp.Queues, []string{"TOPIC", "QUEUE"}, rows)
+}
+
+func (c *cli) queueDelete(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("queue delete", flag.ContinueOnError)
+	force := fs.Bool("force", false, "also delete subscriptions of the queue")
+	pos, err := parseFlags(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	_, err = c.broker.DeleteQueue(ctx, &pb.DeleteQueueRequest{TopicName: pos[0], QueueId: pos[1], Force: *force})
+	if err != nil {
+		return err
+	}
+	return c.print(&pb.QueueInfo{TopicName: pos[0], QueueId: pos[1]}, []string{"DELETED TOPIC", "QUEUE"}, [][]string{pos})
+}
+
+func (c *cli) queuePurge(ctx context.Context, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("queue purge", flag.ContinueOnError), args, 2)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	resp, err := c.broker.PurgeQueue(ctx, &pb.PurgeQueueRequest{TopicName: pos[0], QueueId: pos[1]})
+	if err != nil {
+		return err
+	}
+	return c.print(resp, []string{"TOPIC", "QUEUE", "LOG START"}, [][]string{
+		{pos[0], pos[1], strconv.FormatInt(resp.LogStartOffset, 10)},
+	})
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"queue-service/internal/delivery/grpc/pb"
+)
+
+func (c *cli) subscription(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "create":
+		return c.subscriptionCreate(ctx, args[1:])
+	case "list":
+		return c.subscriptionList(ctx, args[1:])
+	case "describe":
+		return c.subscriptionDescribe(ctx, args[1:])
+	case "seek":
+		return c.subscriptionSeek(ctx, args[1:])
+	case "delete":
+		return c.subscriptionDelete(ctx, args[1:])
+	}
+	return fmt.Errorf("%w: unknown subscription command %q", errUsage, args[0])
+}
+
+func (c *cli) subscriptionCreate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("subscription create", flag.ContinueOnError)
+	queue := fs.String("queue", "0", "queue id")
+	group := fs.String("group", "", "consumer group (required)")
+	guarantee := fs.String("guarantee", "at-most-once", "at-most-once or at-least-once")
+	start := fs.String("start", "earliest", "earliest, latest or an RFC 3339 time")
+	pos, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if *group == "" {
+		return fmt.Errorf("%w: -group is required", errUsage)
+	}
+	dg, err := parseGuarantee(*guarantee)
+	if err != nil {
+		return err
+	}
+	seek, err := parseSeek(*start)
+	if err != nil || seek.Position == pb.SeekPosition_OFFSET {
+		return fmt.Errorf("%w: -start must be earliest, latest or an RFC 3339 time, got %q", errUsage, *start)
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	resp, err := c.broker.Subscribe(ctx, &pb.SubscribeRequest{
+		TopicName:            pos[0],
+		QueueId:              *queue,
+		ConsumerGroup:        *group,
+		DeliveryGuarantee:    dg,
+		StartPosition:        seek.Position,
+		StartTimestampUnixMs: seek.TimestampUnixMs,
+	})
+	if err != nil {
+		return err
+	}
+	return c.print(resp, []string{"SUBSCRIPTION", "TOPIC", "QUEUE", "GROUP", "OFFSET"}, [][]string{
+		{resp.SubscriptionId, resp.TopicName, resp.QueueId, resp.ConsumerGroup, strconv.FormatInt(resp.Offset, 10)},
+	})
+}
+
+func (c *cli) subscriptionList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("subscription list", flag.ContinueOnError)
+	topic := fs.String("topic", "", "only subscriptions of this topic")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	resp, err := c.broker.ListSubscriptions(ctx, &pb.ListSubscriptionsRequest{TopicName: *topic})
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(resp.Subscriptions))
+	for _, s := range resp.Subscriptions {
+		rows = append(rows, subscriptionRow(s))
+	}
+	return c.print(resp.Subscriptions, subscriptionHeader, rows)
+}
+
+func (c *cli) subscriptionDescribe(ctx context.Context, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("subscription describe", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	resp, err := c.broker.DescribeSubscription(ctx, &pb.DescribeSubscriptionRequest{SubscriptionId: pos[0]})
+	if err != nil {
+		return err
+	}
+	return c.print(resp.Subscription, subscriptionHeader, [][]string{subscriptionRow(resp.Subscription)})
+}
+
+var subscriptionHeader = []string{"SUBSCRIPTION", "TOPIC", "QUEUE", "GROUP", "OFFSET", "HIGH WATERMARK", "LAG", "PENDING", "OLDEST PENDING", "LAST CONSUMED"}
+
+func subscriptionRow(s *pb.SubscriptionInfo) []string {
+	oldest, last := "", ""
+	if s.PendingCount > 0 {
+		oldest = (time.Duration(s.OldestPendingAgeMs) * time.Millisecond).String()
+	}
+	if s.LastConsumedAtUnixMs != 0 {
+		last = time.UnixMilli(s.LastConsumedAtUnixMs).Format(time.RFC3339)
+	}
+	return []string{
+		s.SubscriptionId, s.TopicName, s.QueueId, s.ConsumerGroup,
+		strconv.FormatInt(s.CommittedOffset, 10),
+		strconv.FormatInt(s.HighWatermark, 10),
+		strconv.FormatInt(s.Lag, 10),
+		strconv.Itoa(int(s.PendingCount)),
+		orDash(oldest), orDash(last),
+	}
+}
+
+func (c *cli) subscriptionSeek(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("subscription seek", flag.ContinueOnError)
+	to := fs.String("to", "", "earliest, latest, an offset or an RFC 3339 time (required)")
+	pos, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	req, err := parseSeek(*to)
+	if err != nil {
+		return err
+	}
+	req.SubscriptionId = pos[0]
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	resp, err := c.broker.SeekSubscription(ctx, req)
+	if err != nil {
+		return err
+	}
+	return c.print(resp, []string{"SUBSCRIPTION", "OFFSET"}, [][]string{
+		{pos[0], strconv.FormatInt(resp.Offset, 10)},
+	})
+}
+
+func parseSeek(s string) (*pb.SeekSubscriptionRequest, error) {
+	switch s {
+	case "earliest":
+		return &pb.SeekSubscriptionRequest{Position: pb.SeekPosition_EARLIEST}, nil
+	case "latest":
+		return &pb.SeekSubscriptionRequest{Position: pb.SeekPosition_LATEST}, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return &pb.SeekSubscriptionRequest{Position: pb.SeekPosition_OFFSET, Offset: n}, nil
+	}
+	if ts, err := time.Parse(time.RFC3339, s); err == nil {
+		return &pb.SeekSubscriptionRequest{Position: pb.SeekPosition_TIMESTAMP, TimestampUnixMs: ts.UnixMilli()}, nil
+	}
+	return nil, fmt.Errorf("%w: -to must be earliest, latest, an offset or an RFC 3339 time, got %q", errUsage, s)
+}
+
+func (c *cli) subscriptionDelete(ctx context.Context, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("subscription delete", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	if _, err := c.broker.Unsubscribe(ctx, &pb.UnsubscribeRequest{SubscriptionId: pos[0]}); err != nil {
+		return err
+	}
+	return c.print(map[string]string{"deleted": pos[0]}, []string{"DELETED"}, [][]string{{pos[0]}})
+}
+
+func parseGuarantee(s string) (pb.DeliveryGuarantee, error) {
+	switch s {
+	case "at-most-once":
+		return pb.DeliveryGuarantee_AT_MOST_ONCE, nil
+	case "at-least-once":
+		return pb.DeliveryGuarantee_AT_LEAST_ONCE, nil
+	}
+	return 0, fmt.Errorf("%w: unknown delivery guarantee %q", errUsage, s)
+}
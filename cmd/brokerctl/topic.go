@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"queue-service/internal/delivery/grpc/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (c *cli) topic(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "create":
+		return c.topicCreate(ctx, args[1:])
+	case "list":
+		return c.topicList(ctx, args[1:])
+	case "describe":
+		return c.topicDescribe(ctx, args[1:])
+	case "delete":
+		return c.topicDelete(ctx, args[1:])
+	}
+	return fmt.Errorf("%w: unknown topic command %q", errUsage, args[0])
+}
+
+func (c *cli) topicCreate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("topic create", flag.ContinueOnError)
+	retention := fs.Int("retention", 0, "max messages per queue (server default if 0)")
+	pos, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	resp, err := c.broker.CreateTopic(ctx, &pb.CreateTopicRequest{Name: pos[0], RetentionMessages: int32(*retention)})
+	if err != nil {
+		return err
+	}
+	return c.print(resp, []string{"TOPIC", "RETENTION"}, [][]string{
+		{resp.Name, strconv.Itoa(int(resp.RetentionMessages))},
+	})
+}
+
+func (c *cli) topicList(ctx context.Context, args []string) error {
+	if _, err := parseFlags(flag.NewFlagSet("topic list", flag.ContinueOnError), args, 0); err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	resp, err := c.broker.ListTopics(ctx, &pb.ListTopicsRequest{})
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(resp.Topics))
+	for _, t := range resp.Topics {
+		rows = append(rows, []string{t.Name, strconv.Itoa(int(t.RetentionMessages))})
+	}
+	return c.print(resp.Topics, []string{"TOPIC", "RETENTION"}, rows)
+}
+
+type topicDescription struct {
+	Name              string   `json:"name"`
+	RetentionMessages int32    `json:"retention_messages"`
+	Queues            []string `json:"queues"`
+}
+
+func (c *cli) topicDescribe(ctx context.Context, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("topic describe", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	name := pos[0]
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	topics, err := c.broker.ListTopics(ctx, &pb.ListTopicsRequest{})
+	if err != nil {
+		return err
+	}
+	var desc *topicDescription
+	for _, t := range topics.Topics {
+		if t.Name == name {
+			desc = &topicDescription{Name: t.Name, RetentionMessages: t.RetentionMessages}
+			break
+		}
+	}
+	if desc == nil {
+		return status.Errorf(codes.NotFound, "topic %q not found", name)
+	}
+
+	queues, err := c.broker.ListQueues(ctx, &pb.ListQueuesRequest{TopicName: name})
+	if err != nil {
+		return err
+	}
+	desc.Queues = make([]string, 0, len(queues.Queues))
+	rows := make([][]string, 0, len(queues.Queues))
+	for _, q := range queues.Queues {
+		desc.Queues = append(desc.Queues, q.QueueId)
+		rows = append(rows, []string{desc.Name, strconv.Itoa(int(desc.RetentionMessages)), q.QueueId})
+	}
+	return c.print(desc, []string{"TOPIC", "RETENTION", "QUEUE"}, rows)
+}
+
+func (c *cli) topicDelete(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("topic delete", flag.ContinueOnError)
+	force := fs.Bool("force", false, "also delete subscriptions of the topic")
+	pos, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.call(ctx)
+	defer cancel()
+	if _, err := c.broker.DeleteTopic(ctx, &pb.DeleteTopicRequest{Name: pos[0], Force: *force}); err != nil {
+		return err
+	}
+	return c.print(map[string]string{"deleted": pos[0]}, []string{"DELETED"}, [][]string{{pos[0]}})
+}
@@ -1,20 +1,33 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"queue-service/internal/auth"
 	deliverygrpc "queue-service/internal/delivery/grpc"
 	"queue-service/internal/delivery/grpc/pb"
+	"queue-service/internal/delivery/kafka"
+	"queue-service/internal/delivery/stomp"
+	"queue-service/internal/domain"
+	"queue-service/internal/health"
+	"queue-service/internal/logging"
+	"queue-service/internal/metrics"
 	"queue-service/internal/repository/memory"
+	"queue-service/internal/tracing"
 	"queue-service/internal/usecase"
 	"queue-service/pkg/config"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -23,12 +36,30 @@ func main() {
 	if len(os.Args) > 1 {
 		cfgPath = os.Args[1]
 	}
-	log.SetFlags(log.LstdFlags)
 	cfg, err := config.Load(cfgPath)
 	if err != nil {
-		log.Fatalf("load config: %v", err)
+		fatal("load config", err)
+	}
+	logLevel := new(slog.LevelVar)
+	logger, err := logging.New(os.Stderr, logging.Config{Level: cfg.Logging.Level, Format: cfg.Logging.Format, LevelVar: logLevel})
+	if err != nil {
+		fatal("config: logging", err)
+	}
+	// Через slog.Default пишут use case, слушатели STOMP и Kafka и пакет log.
+	slog.SetDefault(logger)
+	slog.Info("config loaded", "grpc_port", cfg.Server.GRPCPort, "log_level", cfg.Logging.Level)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		File:        cfg.Tracing.File,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		fatal("config: tracing", err)
 	}
-	log.Printf("config loaded: grpc_port=%d", cfg.Server.GRPCPort)
 
 	// Repositories
 	topicRepo := memory.NewTopicRepository()
@@ -37,35 +68,304 @@ func main() {
 	subRepo := memory.NewSubscriptionRepository()
 	pendingRepo := memory.NewPendingDeliveryRepository()
 
+	budget, err := memoryBudget(cfg)
+	if err != nil {
+		fatal("config", err)
+	}
+
+	brokerMetrics := metrics.New()
+
 	// Use cases
-	topicUC := usecase.NewTopicUseCase(topicRepo, queueRepo)
-	publishUC := usecase.NewPublishUseCase(topicRepo, queueRepo, msgRepo, cfg.Broker.MaxMessageSize)
-	subscribeUC := usecase.NewSubscriptionUseCase(subRepo, topicRepo, queueRepo, cfg.Broker.AckTimeoutSeconds)
-	consumeUC := usecase.NewConsumeUseCase(subRepo, msgRepo, pendingRepo)
+	topicUC := usecase.NewTopicUseCase(topicRepo, queueRepo, msgRepo, subRepo, pendingRepo)
+	publishUC := usecase.NewPublishUseCase(topicRepo, queueRepo, msgRepo, cfg.Broker.MaxMessageSize, budget, brokerMetrics)
+	subscribeUC := usecase.NewSubscriptionUseCase(subRepo, topicRepo, queueRepo, msgRepo, pendingRepo, cfg.Broker.AckTimeoutSeconds)
+	consumeUC := usecase.NewConsumeUseCase(subRepo, msgRepo, pendingRepo, brokerMetrics)
+	messageUC := usecase.NewMessageUseCase(topicRepo, queueRepo, msgRepo)
+	brokerMetrics.RegisterState(topicUC, messageUC, subscribeUC)
+
+	// Повторная доставка по истечении ack timeout не ждёт опроса потребителей.
+	redelivery := usecase.NewRedeliveryScheduler(pendingRepo, time.Second)
+	topicUC.SetRedelivery(redelivery)
+	subscribeUC.SetRedelivery(redelivery)
+	redeliveryCtx, stopRedelivery := context.WithCancel(context.Background())
+	go redelivery.Run(redeliveryCtx)
+
+	authn, err := newAuthenticator(cfg.Auth)
+	if err != nil {
+		fatal("config: auth", err)
+	}
+
+	aclUC, err := newACLUseCase(cfg.ACL)
+	if err != nil {
+		fatal("config: acl", err)
+	}
+
+	tenantUC, err := newTenantUseCase(cfg.Tenancy, topicRepo, subRepo)
+	if err != nil {
+		fatal("config: tenancy", err)
+	}
+
+	quotaUC, err := newQuotaUseCase(cfg.Quotas, topicRepo, subRepo)
+	if err != nil {
+		fatal("config: quotas", err)
+	}
 
 	// gRPC handler and server
-	handler := deliverygrpc.NewBrokerHandler(topicUC, publishUC, subscribeUC, consumeUC)
-	srv := grpc.NewServer(
-		deliverygrpc.LoggingUnaryInterceptor(),
+	handler := deliverygrpc.NewBrokerHandler(topicUC, publishUC, subscribeUC, consumeUC, messageUC, aclUC, tenantUC, quotaUC)
+	// Метрики — сразу после трассировки, чтобы учитывались и вызовы,
+	// отклонённые аутентификацией или проверкой пространства имён.
+	serverOpts := []grpc.ServerOption{
+		deliverygrpc.TracingUnaryInterceptor(),
+		deliverygrpc.MetricsUnaryInterceptor(brokerMetrics),
+		deliverygrpc.IdentityUnaryInterceptor(),
+	}
+	if authn.Enabled() {
+		serverOpts = append(serverOpts,
+			deliverygrpc.AuthUnaryInterceptor(authn),
+			deliverygrpc.AuthStreamInterceptor(authn))
+		slog.Info("authentication enabled", "api_keys", len(cfg.Auth.APIKeys),
+			"jwt", cfg.Auth.JWT.KeyFile != "", "allow_anonymous", cfg.Auth.AllowAnonymous)
+	} else if cfg.Server.TLS.ClientCAFile == "" {
+		slog.Warn("authentication disabled: any client can reach the broker")
+	}
+	if tenantUC.Enabled() {
+		serverOpts = append(serverOpts, deliverygrpc.NamespaceUnaryInterceptor(tenantUC))
+	}
+	serverOpts = append(serverOpts,
+		deliverygrpc.LoggingUnaryInterceptor(logger, deliverygrpc.LogSampling{
+			Methods: cfg.Logging.Sampling.Methods,
+			Every:   cfg.Logging.Sampling.Every,
+		}),
 	)
+	stopReload := func() {}
+	if tlsCfg := cfg.Server.TLS; tlsCfg.CertFile != "" {
+		clientAuth, err := auth.ParseClientAuth(tlsCfg.ClientAuth)
+		if err != nil {
+			fatal("config: server.tls.client_auth", err)
+		}
+		certs, err := auth.NewCertReloader(auth.TLSConfig{
+			CertFile:     tlsCfg.CertFile,
+			KeyFile:      tlsCfg.KeyFile,
+			ClientCAFile: tlsCfg.ClientCAFile,
+			ClientAuth:   clientAuth,
+		})
+		if err != nil {
+			fatal("config: server.tls", err)
+		}
+		if tlsCfg.ReloadIntervalSeconds > 0 {
+			var reloadCtx context.Context
+			reloadCtx, stopReload = context.WithCancel(context.Background())
+			go certs.Run(reloadCtx, time.Duration(tlsCfg.ReloadIntervalSeconds)*time.Second)
+		}
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(certs.TLSConfig())))
+		slog.Info("gRPC TLS enabled", "mtls", tlsCfg.ClientCAFile != "")
+	}
+	srv := grpc.NewServer(serverOpts...)
 	pb.RegisterBrokerServer(srv, handler)
 	reflection.Register(srv)
+	checker := health.New(pb.Broker_ServiceDesc.ServiceName)
+	checker.Register(srv)
 
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.GRPCPort))
 	if err != nil {
-		log.Fatalf("listen: %v", err)
+		fatal("listen", err)
 	}
 
 	go func() {
-		log.Printf("broker gRPC server listening on :%d", cfg.Server.GRPCPort)
+		slog.Info("broker gRPC server listening", "port", cfg.Server.GRPCPort)
 		if err := srv.Serve(lis); err != nil {
-			log.Fatalf("serve: %v", err)
+			fatal("serve", err)
+		}
+	}()
+
+	var httpSrv *http.Server
+	if cfg.Server.HTTPPort > 0 {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", brokerMetrics.Handler())
+		mux.Handle("/healthz", checker.LivenessHandler())
+		mux.Handle("/readyz", checker.ReadinessHandler())
+		httpSrv = &http.Server{Addr: fmt.Sprintf(":%d", cfg.Server.HTTPPort), Handler: mux}
+		go func() {
+			slog.Info("broker HTTP server (/metrics, /healthz, /readyz) listening", "port", cfg.Server.HTTPPort)
+			if err := httpSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fatal("serve http", err)
+			}
+		}()
+	}
+
+	var stompSrv *stomp.Server
+	if cfg.Server.STOMPPort > 0 {
+		stompSrv = stomp.NewServer(publishUC, subscribeUC, consumeUC, redelivery, authn, aclUC, tenantUC, quotaUC, cfg.Broker.MaxMessageSize)
+		stompLis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.STOMPPort))
+		if err != nil {
+			fatal("listen stomp", err)
+		}
+		go func() {
+			slog.Info("broker STOMP server listening", "port", cfg.Server.STOMPPort)
+			if err := stompSrv.Serve(stompLis); err != nil && !errors.Is(err, stomp.ErrServerClosed) {
+				fatal("serve stomp", err)
+			}
+		}()
+	}
+
+	var kafkaSrv *kafka.Server
+	if cfg.Server.KafkaPort > 0 {
+		kafkaSrv = kafka.NewServer(topicUC, publishUC, messageUC, authn, aclUC, tenantUC, quotaUC, cfg.Server.KafkaAdvertisedHost, cfg.Server.KafkaPort)
+		kafkaLis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.KafkaPort))
+		if err != nil {
+			fatal("listen kafka", err)
+		}
+		go func() {
+			slog.Info("broker Kafka listener", "port", cfg.Server.KafkaPort)
+			if err := kafkaSrv.Serve(kafkaLis); err != nil && !errors.Is(err, kafka.ErrServerClosed) {
+				fatal("serve kafka", err)
+			}
+		}()
+	}
+
+	// Лимиты, таймауты, уровень логов, права и квоты перечитываются по
+	// SIGHUP и при изменении файла конфигурации.
+	reload := &reloader{
+		path:      cfgPath,
+		level:     logLevel,
+		publish:   publishUC,
+		subscribe: subscribeUC,
+		stomp:     stompSrv,
+		acl:       aclUC,
+		tenants:   tenantUC,
+		quotas:    quotaUC,
+		cfg:       cfg,
+	}
+	reloadConfig := func(trigger string) {
+		if err := reload.reload(context.Background()); err != nil {
+			slog.Error("config reload failed, keeping the current settings", "trigger", trigger, "error", err)
+		}
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			reloadConfig("sighup")
 		}
 	}()
+	if err := config.Watch(cfgPath, func() { reloadConfig("file") }); err != nil {
+		slog.Info("config file is not watched", "error", err)
+	}
+
+	// Хранилище в памяти восстанавливать не нужно: брокер готов, как только
+	// все слушатели открыты.
+	checker.MarkReady()
+	slog.Info("broker ready")
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("shutting down...")
-	srv.GracefulStop()
+	// Сначала брокер перестаёт быть готовым, и балансировщик убирает его из
+	// ротации; /healthz и /readyz отвечают до конца остановки.
+	checker.Shutdown()
+	drain := time.Duration(cfg.Server.DrainDelaySeconds) * time.Second
+	slog.Info("shutting down", "drain_delay", drain.String())
+	time.Sleep(drain)
+	if stompSrv != nil {
+		_ = stompSrv.Close()
+	}
+	if kafkaSrv != nil {
+		_ = kafkaSrv.Close()
+	}
+	gracefulStop(srv, time.Duration(cfg.Server.ShutdownTimeoutSeconds)*time.Second)
+	stopRedelivery()
+	stopReload()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if httpSrv != nil {
+		_ = httpSrv.Shutdown(ctx)
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Warn("tracing shutdown", "error", err)
+	}
+}
+
+// fatal пишет ошибку запуска и завершает процесс.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// newAuthenticator собирает проверку API-ключей и JWT из конфигурации.
+func newAuthenticator(cfg config.AuthConfig) (*auth.Authenticator, error) {
+	keys := make([]auth.APIKey, len(cfg.APIKeys))
+	for i, k := range cfg.APIKeys {
+		keys[i] = auth.APIKey{Name: k.Name, Key: k.Key, KeySHA256: k.KeySHA256}
+	}
+	return auth.NewAuthenticator(auth.Config{
+		APIKeys:        keys,
+		JWTKeyFile:     cfg.JWT.KeyFile,
+		JWTIssuer:      cfg.JWT.Issuer,
+		JWTAudience:    cfg.JWT.Audience,
+		AllowAnonymous: cfg.AllowAnonymous,
+	})
+}
+
+// newACLUseCase создаёт проверку прав и загружает правила из конфигурации.
+func newACLUseCase(cfg config.ACLConfig) (*usecase.ACLUseCase, error) {
+	rules, err := aclRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	acl := usecase.NewACLUseCase(memory.NewACLRepository(), usecase.ACLPolicy{
+		Enabled:    cfg.Enabled,
+		SuperUsers: cfg.SuperUsers,
+	})
+	if err := acl.LoadRules(context.Background(), rules); err != nil {
+		return nil, err
+	}
+	if cfg.Enabled {
+		slog.Info("access control enabled", "rules", len(cfg.Rules), "super_users", len(cfg.SuperUsers))
+	}
+	return acl, nil
+}
+
+// newTenantUseCase создаёт разделение на пространства имён с квотами из
+// конфигурации; квоты памяти задаются в бюджете памяти.
+func newTenantUseCase(cfg config.TenancyConfig, topics domain.TopicRepository, subs domain.SubscriptionRepository) (*usecase.TenantUseCase, error) {
+	tenants, err := usecase.NewTenantUseCase(topics, subs, tenantPolicy(cfg))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Enabled {
+		slog.Info("namespaces enabled", "namespaces", len(cfg.Namespaces), "trust_header", cfg.TrustHeader)
+	}
+	return tenants, nil
+}
+
+func newQuotaUseCase(cfg config.QuotasConfig, topics domain.TopicRepository, subs domain.SubscriptionRepository) (*usecase.QuotaUseCase, error) {
+	quotas, err := usecase.NewQuotaUseCase(topics, subs, quotaPolicy(cfg))
+	if err != nil {
+		return nil, err
+	}
+	if quotas.Enabled() {
+		slog.Info("rate limits enabled", "principals", len(cfg.Principals), "topics", len(cfg.Topics))
+	}
+	return quotas, nil
+}
+
+// gracefulStop ждёт завершения начатых RPC не дольше timeout (0 — без
+// ограничения), затем обрывает оставшиеся, например потоки Health.Watch.
+func gracefulStop(srv *grpc.Server, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+	if timeout <= 0 {
+		<-done
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		slog.Warn("graceful stop timed out, closing remaining RPCs", "timeout", timeout.String())
+		srv.Stop()
+	}
 }
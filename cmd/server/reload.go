@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"queue-service/internal/delivery/stomp"
+	"queue-service/internal/domain"
+	"queue-service/internal/logging"
+	"queue-service/internal/usecase"
+	"queue-service/pkg/config"
+)
+
+// reloader применяет изменённую конфигурацию без перезапуска брокера:
+// лимиты и бюджет памяти, ack timeout, уровень логов, права доступа,
+// пространства имён и квоты. Новая конфигурация сначала проверяется целиком
+// и применяется, только если верна вся. Остальные параметры (слушатели, TLS,
+// аутентификация, трассировка, формат логов, включение пространств имён)
+// требуют перезапуска: их изменения только отмечаются в логе.
+type reloader struct {
+	path      string
+	level     *slog.LevelVar
+	publish   *usecase.PublishUseCase
+	subscribe *usecase.SubscriptionUseCase
+	stomp     *stomp.Server // nil, если слушатель STOMP отключён
+	acl       *usecase.ACLUseCase
+	tenants   *usecase.TenantUseCase
+	quotas    *usecase.QuotaUseCase
+
+	mu  sync.Mutex
+	cfg *config.Config // применённая конфигурация
+}
+
+// reload перечитывает файл конфигурации и применяет изменения.
+func (r *reloader) reload(ctx context.Context) error {
+	cfg, err := config.Load(r.path)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.apply(ctx, cfg)
+}
+
+// apply проверяет cfg и применяет его отличия от r.cfg. Вызывается под r.mu.
+func (r *reloader) apply(ctx context.Context, cfg *config.Config) error {
+	old := r.cfg
+	if restart := restartRequired(old, cfg); len(restart) > 0 {
+		slog.WarnContext(ctx, "config changes require a restart", "settings", restart)
+	}
+	// Параметры, требующие перезапуска, остаются прежними, пока он не случится.
+	next := *cfg
+	next.Server, next.Auth, next.Tracing = old.Server, old.Auth, old.Tracing
+	next.Logging.Format, next.Logging.Sampling = old.Logging.Format, old.Logging.Sampling
+	next.Tenancy.Enabled = old.Tenancy.Enabled
+
+	level, err := logging.ParseLevel(next.Logging.Level)
+	if err != nil {
+		return fmt.Errorf("logging.level: %w", err)
+	}
+	budget, err := memoryBudget(&next)
+	if err != nil {
+		return err
+	}
+	rules, err := aclRules(next.ACL)
+	if err != nil {
+		return fmt.Errorf("acl: %w", err)
+	}
+	tenancy := tenantPolicy(next.Tenancy)
+	if err := tenancy.Validate(); err != nil {
+		return fmt.Errorf("tenancy: %w", err)
+	}
+	quotas := quotaPolicy(next.Quotas)
+	if err := quotas.Validate(); err != nil {
+		return fmt.Errorf("quotas: %w", err)
+	}
+
+	var changed []string
+	if next.Logging.Level != old.Logging.Level {
+		r.level.Set(level)
+		changed = append(changed, "logging.level")
+	}
+	if next.Broker.MaxMessageSize != old.Broker.MaxMessageSize || !reflect.DeepEqual(next.Broker.Memory, old.Broker.Memory) ||
+		!reflect.DeepEqual(next.Tenancy.Quota, old.Tenancy.Quota) || !reflect.DeepEqual(next.Tenancy.Namespaces, old.Tenancy.Namespaces) {
+		if err := r.publish.SetLimits(next.Broker.MaxMessageSize, budget); err != nil {
+			return err
+		}
+		if r.stomp != nil {
+			r.stomp.SetMaxMessageSize(next.Broker.MaxMessageSize)
+		}
+		changed = append(changed, "broker")
+	}
+	if next.Broker.AckTimeoutSeconds != old.Broker.AckTimeoutSeconds {
+		if err := r.subscribe.SetAckTimeout(ctx, time.Duration(next.Broker.AckTimeoutSeconds)*time.Second); err != nil {
+			return err
+		}
+		changed = append(changed, "broker.ack_timeout_seconds")
+	}
+	if !reflect.DeepEqual(next.ACL, old.ACL) {
+		r.acl.SetPolicy(usecase.ACLPolicy{Enabled: next.ACL.Enabled, SuperUsers: next.ACL.SuperUsers})
+		if err := r.acl.LoadRules(ctx, rules); err != nil {
+			return err
+		}
+		changed = append(changed, "acl")
+	}
+	if !reflect.DeepEqual(next.Tenancy, old.Tenancy) {
+		if err := r.tenants.SetPolicy(tenancy); err != nil {
+			return err
+		}
+		changed = append(changed, "tenancy")
+	}
+	if !reflect.DeepEqual(next.Quotas, old.Quotas) {
+		if err := r.quotas.SetPolicy(quotas); err != nil {
+			return err
+		}
+		changed = append(changed, "quotas")
+	}
+	r.cfg = &next
+	if len(changed) == 0 {
+		slog.DebugContext(ctx, "config unchanged")
+		return nil
+	}
+	slog.InfoContext(ctx, "config reloaded", "changed", changed)
+	return nil
+}
+
+// restartRequired возвращает изменённые разделы, которые применяются только
+// при запуске.
+func restartRequired(old, cfg *config.Config) []string {
+	var out []string
+	for _, s := range []struct {
+		key      string
+		old, new any
+	}{
+		{"server", old.Server, cfg.Server},
+		{"auth", old.Auth, cfg.Auth},
+		{"tracing", old.Tracing, cfg.Tracing},
+		{"logging.format", old.Logging.Format, cfg.Logging.Format},
+		{"logging.sampling", old.Logging.Sampling, cfg.Logging.Sampling},
+		{"tenancy.enabled", old.Tenancy.Enabled, cfg.Tenancy.Enabled},
+	} {
+		if !reflect.DeepEqual(s.old, s.new) {
+			out = append(out, s.key)
+		}
+	}
+	return out
+}
+
+// memoryBudget собирает бюджет памяти из broker.memory и квот памяти
+// пространств имён.
+func memoryBudget(cfg *config.Config) (usecase.MemoryBudget, error) {
+	policy, err := usecase.ParseOverflowPolicy(cfg.Broker.Memory.OverflowPolicy)
+	if err != nil {
+		return usecase.MemoryBudget{}, fmt.Errorf("broker.memory.overflow_policy: %w", err)
+	}
+	budget := usecase.MemoryBudget{
+		Limit:        cfg.Broker.Memory.LimitBytes,
+		TopicLimit:   cfg.Broker.Memory.TopicLimitBytes,
+		TopicLimits:  cfg.Broker.Memory.TopicLimits,
+		Policy:       policy,
+		BlockTimeout: time.Duration(cfg.Broker.Memory.BlockTimeoutMs) * time.Millisecond,
+	}
+	if cfg.Tenancy.Enabled {
+		budget.NamespaceLimit = cfg.Tenancy.Quota.MaxMemoryBytes
+		budget.NamespaceLimits = make(map[string]int64, len(cfg.Tenancy.Namespaces))
+		for _, ns := range cfg.Tenancy.Namespaces {
+			if ns.MaxMemoryBytes > 0 {
+				budget.NamespaceLimits[ns.Name] = ns.MaxMemoryBytes
+			}
+		}
+	}
+	return budget, nil
+}
+
+// aclRules переводит и проверяет правила доступа из конфигурации.
+func aclRules(cfg config.ACLConfig) ([]domain.ACLRule, error) {
+	rules := make([]domain.ACLRule, len(cfg.Rules))
+	for i, r := range cfg.Rules {
+		rules[i] = domain.ACLRule{
+			Principal:    r.Principal,
+			ResourceType: domain.ACLResourceType(r.ResourceType),
+			Namespace:    r.Namespace,
+			Pattern:      r.Pattern,
+		}
+		for _, op := range r.Operations {
+			rules[i].Operations = append(rules[i].Operations, domain.ACLOperation(op))
+		}
+		if err := usecase.ValidateACLRule(rules[i]); err != nil {
+			return nil, fmt.Errorf("rule #%d: %w", i+1, err)
+		}
+	}
+	return rules, nil
+}
+
+// tenantPolicy переводит tenancy.*; квоты памяти задаются в бюджете памяти.
+func tenantPolicy(cfg config.TenancyConfig) usecase.TenantPolicy {
+	policy := usecase.TenantPolicy{
+		Enabled:     cfg.Enabled,
+		Default:     cfg.DefaultNamespace,
+		TrustHeader: cfg.TrustHeader,
+		Admins:      cfg.Admins,
+		Quota:       usecase.TenantQuota{MaxTopics: cfg.Quota.MaxTopics, MaxSubscriptions: cfg.Quota.MaxSubscriptions},
+	}
+	for _, ns := range cfg.Namespaces {
+		policy.Tenants = append(policy.Tenants, usecase.Tenant{
+			Namespace:  ns.Name,
+			Principals: ns.Principals,
+			Quota:      usecase.TenantQuota{MaxTopics: ns.MaxTopics, MaxSubscriptions: ns.MaxSubscriptions},
+		})
+	}
+	return policy
+}
+
+func quotaPolicy(cfg config.QuotasConfig) usecase.QuotaPolicy {
+	policy := usecase.QuotaPolicy{
+		Principal:  quotaOf(cfg.Principal),
+		Topic:      quotaOf(cfg.Topic),
+		Principals: make(map[string]usecase.Quota, len(cfg.Principals)),
+		Topics:     make(map[string]usecase.Quota, len(cfg.Topics)),
+		Burst:      time.Duration(cfg.BurstSeconds * float64(time.Second)),
+	}
+	for _, p := range cfg.Principals {
+		policy.Principals[p.Name] = quotaOf(p.QuotaConfig)
+	}
+	for _, t := range cfg.Topics {
+		policy.Topics[t.Topic] = quotaOf(t.QuotaConfig)
+	}
+	return policy
+}
+
+func quotaOf(c config.QuotaConfig) usecase.Quota {
+	return usecase.Quota{
+		PublishMessagesPerSec: c.PublishMessagesPerSecond,
+		PublishBytesPerSec:    c.PublishBytesPerSecond,
+		ConsumeMessagesPerSec: c.ConsumeMessagesPerSecond,
+		MaxTopics:             c.MaxTopics,
+		MaxSubscriptions:      c.MaxSubscriptions,
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"queue-service/internal/domain"
+	"queue-service/internal/repository/memory"
+	"queue-service/internal/usecase"
+	"queue-service/pkg/config"
+)
+
+// newTestReloader собирает use case из cfg так же, как main.
+func newTestReloader(t *testing.T, cfg *config.Config) (*reloader, *usecase.TopicUseCase) {
+	t.Helper()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	subs := memory.NewSubscriptionRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	budget, err := memoryBudget(cfg)
+	if err != nil {
+		t.Fatalf("memoryBudget: %v", err)
+	}
+	acl, err := newACLUseCase(cfg.ACL)
+	if err != nil {
+		t.Fatalf("newACLUseCase: %v", err)
+	}
+	tenants, err := newTenantUseCase(cfg.Tenancy, topics, subs)
+	if err != nil {
+		t.Fatalf("newTenantUseCase: %v", err)
+	}
+	quotas, err := newQuotaUseCase(cfg.Quotas, topics, subs)
+	if err != nil {
+		t.Fatalf("newQuotaUseCase: %v", err)
+	}
+	level := new(slog.LevelVar)
+	return &reloader{
+		level:     level,
+		publish:   usecase.NewPublishUseCase(topics, queues, msgs, cfg.Broker.MaxMessageSize, budget, nil),
+		subscribe: usecase.NewSubscriptionUseCase(subs, topics, queues, msgs, pending, cfg.Broker.AckTimeoutSeconds),
+		acl:       acl,
+		tenants:   tenants,
+		quotas:    quotas,
+		cfg:       cfg,
+	}, usecase.NewTopicUseCase(topics, queues, msgs, subs, pending)
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Broker:  config.BrokerConfig{AckTimeoutSeconds: 30, MaxMessageSize: 16},
+		Logging: config.LoggingConfig{Level: "info"},
+		ACL: config.ACLConfig{Enabled: true, Rules: []config.ACLRuleConfig{
+			{Principal: "orders", ResourceType: "topic", Pattern: "orders.*", Operations: []string{"publish"}},
+		}},
+	}
+}
+
+func TestReloader_apply(t *testing.T) {
+	ctx := context.Background()
+	r, topicUC := newTestReloader(t, testConfig())
+	_, _ = topicUC.CreateTopic(ctx, "events", 0)
+	payload := bytes.Repeat([]byte("x"), 20)
+	if _, err := r.publish.Publish(ctx, "events", "0", payload, "", nil); !errors.Is(err, usecase.ErrMessageTooLarge) {
+		t.Fatalf("publish over the limit: %v", err)
+	}
+	sub, err := r.subscribe.Subscribe(ctx, "events", "0", "g", domain.AtLeastOnce)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	// Правило, созданное через API, переживает перезагрузку правил из конфигурации.
+	if _, err := r.acl.CreateRule(ctx, domain.ACLRule{Principal: "ops", ResourceType: domain.ACLCluster,
+		Operations: []domain.ACLOperation{domain.ACLAdmin}}); err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+
+	cfg := testConfig()
+	cfg.Broker.AckTimeoutSeconds = 5
+	cfg.Broker.MaxMessageSize = 32
+	cfg.Logging.Level = "debug"
+	cfg.ACL.Rules[0].Principal = "billing"
+	cfg.Quotas.Principal.PublishMessagesPerSecond = 10
+	cfg.Server.GRPCPort = 1234
+	if err := r.apply(ctx, cfg); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	if r.level.Level() != slog.LevelDebug {
+		t.Errorf("log level %s", r.level.Level())
+	}
+	if _, err := r.publish.Publish(ctx, "events", "0", payload, "", nil); err != nil {
+		t.Errorf("publish under the new size limit: %v", err)
+	}
+	if got, _ := r.subscribe.GetSubscription(ctx, sub.ID); got.AckTimeout != 5*time.Second {
+		t.Errorf("ack timeout of the existing subscription: %s", got.AckTimeout)
+	}
+	rules, _ := r.acl.ListRules(ctx, "")
+	if len(rules) != 2 {
+		t.Fatalf("rules after reload: %v", rules)
+	}
+	for _, rule := range rules {
+		if rule.Principal == "orders" {
+			t.Errorf("old config rule kept: %+v", rule)
+		}
+	}
+	if !r.quotas.Enabled() {
+		t.Error("rate limits not applied")
+	}
+	if r.cfg.Server.GRPCPort != 0 {
+		t.Errorf("listener settings applied without a restart: %+v", r.cfg.Server)
+	}
+}
+
+func TestReloader_invalid(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestReloader(t, testConfig())
+	for name, mutate := range map[string]func(*config.Config){
+		"log level":  func(c *config.Config) { c.Logging.Level = "verbose" },
+		"overflow":   func(c *config.Config) { c.Broker.Memory.OverflowPolicy = "drop" },
+		"acl rule":   func(c *config.Config) { c.ACL.Rules[0].Operations = []string{"delete"} },
+		"namespace":  func(c *config.Config) { c.Tenancy.Namespaces = []config.NamespaceConfig{{Name: "a/b"}} },
+		"quota rate": func(c *config.Config) { c.Quotas.Topic.PublishBytesPerSecond = -1 },
+	} {
+		cfg := testConfig()
+		cfg.Broker.MaxMessageSize = 32
+		cfg.Logging.Level = "debug"
+		mutate(cfg)
+		if err := r.apply(ctx, cfg); err == nil {
+			t.Errorf("%s: invalid config applied", name)
+		}
+	}
+	// Ни одно из верных изменений не применено.
+	if r.level.Level() != slog.LevelInfo || r.cfg.Broker.MaxMessageSize != 16 {
+		t.Errorf("partially applied: level %s, %+v", r.level.Level(), r.cfg.Broker)
+	}
+}
+
+func TestReloader_reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(yaml string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("broker:\n  ack_timeout_seconds: 30\n  max_message_size: 16\n")
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	r, _ := newTestReloader(t, cfg)
+	r.path = path
+
+	write("broker:\n  ack_timeout_seconds: 0\n  max_message_size: 16\n")
+	if err := r.reload(context.Background()); err == nil {
+		t.Error("zero ack timeout accepted")
+	}
+	write("broker:\n  ack_timeout_seconds: 30\n  max_message_size: 16\nquotas:\n  topic:\n    consume_messages_per_second: 5\n")
+	if err := r.reload(context.Background()); err != nil || !r.quotas.Enabled() {
+		t.Errorf("reload: %v, rate limits enabled %v", err, r.quotas.Enabled())
+	}
+	if err := r.reload(context.Background()); err != nil {
+		t.Errorf("reload without changes: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrNoCredentials — клиент не передал ни API-ключ, ни токен.
+	ErrNoCredentials = errors.New("auth: no credentials")
+	// ErrInvalidCredentials — ключ неизвестен или токен не прошёл проверку.
+	ErrInvalidCredentials = errors.New("auth: invalid credentials")
+)
+
+// APIKey — статический ключ клиента. Задаётся либо сам ключ, либо его
+// SHA-256 в hex, чтобы не хранить секрет в конфигурации открытым текстом.
+type APIKey struct {
+	Name      string
+	Key       string
+	KeySHA256 string
+}
+
+// Config — источники учётных данных. Без ключей и JWT аутентификация
+// выключена.
+type Config struct {
+	APIKeys     []APIKey
+	JWTKeyFile  string // секрет HMAC или открытый ключ RSA в PEM
+	JWTIssuer   string
+	JWTAudience string
+	// AllowAnonymous пропускает запросы без учётных данных; неверные
+	// учётные данные отклоняются всё равно.
+	AllowAnonymous bool
+}
+
+// Authenticator проверяет API-ключи и JWT.
+type Authenticator struct {
+	keys           map[[sha256.Size]byte]string // SHA-256 ключа → имя
+	jwt            *JWTVerifier
+	allowAnonymous bool
+}
+
+// NewAuthenticator создаёт проверку по cfg и читает ключ JWT.
+func NewAuthenticator(cfg Config) (*Authenticator, error) {
+	a := &Authenticator{
+		keys:           make(map[[sha256.Size]byte]string, len(cfg.APIKeys)),
+		allowAnonymous: cfg.AllowAnonymous,
+	}
+	for i, k := range cfg.APIKeys {
+		if k.Name == "" {
+			return nil, fmt.Errorf("api key #%d: name is required", i+1)
+		}
+		var digest [sha256.Size]byte
+		switch {
+		case k.Key != "" && k.KeySHA256 != "":
+			return nil, fmt.Errorf("api key %q: set either key or key_sha256", k.Name)
+		case k.Key != "":
+			digest = sha256.Sum256([]byte(k.Key))
+		case k.KeySHA256 != "":
+			raw, err := hex.DecodeString(k.KeySHA256)
+			if err != nil || len(raw) != sha256.Size {
+				return nil, fmt.Errorf("api key %q: key_sha256 must be 64 hex characters", k.Name)
+			}
+			copy(digest[:], raw)
+		default:
+			return nil, fmt.Errorf("api key %q: key is required", k.Name)
+		}
+		if other, dup := a.keys[digest]; dup {
+			return nil, fmt.Errorf("api key %q duplicates %q", k.Name, other)
+		}
+		a.keys[digest] = k.Name
+	}
+	if cfg.JWTKeyFile != "" {
+		key, err := os.ReadFile(cfg.JWTKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if a.jwt, err = NewJWTVerifier(key, cfg.JWTIssuer, cfg.JWTAudience); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// Enabled сообщает, настроен ли хотя бы один источник учётных данных.
+func (a *Authenticator) Enabled() bool {
+	return a != nil && (len(a.keys) > 0 || a.jwt != nil)
+}
+
+// AllowAnonymous сообщает, пропускаются ли запросы без учётных данных.
+func (a *Authenticator) AllowAnonymous() bool {
+	return a == nil || !a.Enabled() || a.allowAnonymous
+}
+
+// Authenticate проверяет API-ключ или bearer-токен; если переданы оба,
+// используется ключ.
+func (a *Authenticator) Authenticate(apiKey, token string) (Identity, error) {
+	switch {
+	case apiKey != "":
+		return a.apiKey(apiKey)
+	case token != "":
+		if a == nil || a.jwt == nil {
+			return Identity{}, ErrInvalidCredentials
+		}
+		id, err := a.jwt.Verify(token)
+		if err != nil {
+			return Identity{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+		}
+		return id, nil
+	}
+	return Identity{}, ErrNoCredentials
+}
+
+// AuthenticateSecret проверяет секрет, тип которого неизвестен (пароль
+// STOMP): строка из трёх частей через точку считается JWT, иначе API-ключом.
+func (a *Authenticator) AuthenticateSecret(secret string) (Identity, error) {
+	if strings.Count(secret, ".") == 2 && a != nil && a.jwt != nil {
+		return a.Authenticate("", secret)
+	}
+	return a.Authenticate(secret, "")
+}
+
+func (a *Authenticator) apiKey(key string) (Identity, error) {
+	if a == nil {
+		return Identity{}, ErrInvalidCredentials
+	}
+	// Ключи ищутся по SHA-256: время поиска не зависит от совпавшего
+	// префикса самого ключа.
+	name, ok := a.keys[sha256.Sum256([]byte(key))]
+	if !ok {
+		return Identity{}, ErrInvalidCredentials
+	}
+	return Identity{Name: name, Subject: name, Method: "api_key"}, nil
+}
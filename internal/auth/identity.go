@@ -0,0 +1,30 @@
+// Package auth определяет, кто обращается к брокеру: идентичность клиента
+// хранится в контексте запроса, и use case могут использовать её для
+// авторизации.
+package auth
+
+import "context"
+
+// Identity — аутентифицированный клиент.
+type Identity struct {
+	// Name — имя клиента: Common Name сертификата, имя API-ключа или sub JWT.
+	Name string
+	// Subject — полное имя субъекта сертификата, например "CN=orders-svc,O=shop".
+	Subject string
+	// Method — способ аутентификации: "mtls", "api_key" или "jwt".
+	Method string
+}
+
+type identityKey struct{}
+
+// WithIdentity возвращает ctx с идентичностью клиента.
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext возвращает идентичность клиента; false — клиент не
+// аутентифицирован (например, соединение без TLS).
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
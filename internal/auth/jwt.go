@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// jwtLeeway — допустимое расхождение часов при проверке exp и nbf.
+const jwtLeeway = time.Minute
+
+// JWTVerifier проверяет JWT, подписанные HMAC (HS256/384/512) общим секретом
+// или RSA (RS256/384/512) закрытым ключом издателя. Алгоритм токена должен
+// соответствовать типу ключа: токен HS256 не примется при RSA-ключе.
+type JWTVerifier struct {
+	secret   []byte
+	public   *rsa.PublicKey
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+// NewJWTVerifier создаёт проверку по ключу key: PEM с открытым ключом RSA
+// («PUBLIC KEY», «RSA PUBLIC KEY») или сертификатом — RSA, иначе key
+// считается секретом HMAC. Пустые issuer и audience не проверяются.
+func NewJWTVerifier(key []byte, issuer, audience string) (*JWTVerifier, error) {
+	v := &JWTVerifier{issuer: issuer, audience: audience, now: time.Now}
+	block, _ := pem.Decode(key)
+	if block == nil {
+		secret := []byte(strings.TrimSpace(string(key)))
+		if len(secret) < 32 {
+			return nil, errors.New("jwt: HMAC secret must be at least 32 bytes")
+		}
+		v.secret = secret
+		return v, nil
+	}
+	var pub any
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			pub = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("jwt: unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jwt: %w", err)
+	}
+	rsaKey, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("jwt: only RSA public keys are supported")
+	}
+	v.public = rsaKey
+	return v, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Subject   string       `json:"sub"`
+	Issuer    string       `json:"iss"`
+	Audience  jwtAudience  `json:"aud"`
+	ExpiresAt *json.Number `json:"exp"`
+	NotBefore *json.Number `json:"nbf"`
+}
+
+// jwtAudience — aud бывает строкой или массивом строк.
+type jwtAudience []string
+
+func (a *jwtAudience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = jwtAudience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// Verify проверяет подпись и сроки токена и возвращает идентичность его
+// субъекта (sub).
+func (v *JWTVerifier) Verify(token string) (Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Identity{}, errors.New("jwt: malformed token")
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Identity{}, fmt.Errorf("jwt: header: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Identity{}, errors.New("jwt: malformed signature")
+	}
+	if err := v.verifySignature(header.Alg, parts[0]+"."+parts[1], sig); err != nil {
+		return Identity{}, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Identity{}, fmt.Errorf("jwt: claims: %w", err)
+	}
+	now := v.now()
+	if claims.ExpiresAt != nil {
+		exp, err := claims.ExpiresAt.Float64()
+		if err != nil || now.After(unixTime(exp).Add(jwtLeeway)) {
+			return Identity{}, errors.New("jwt: token expired")
+		}
+	}
+	if claims.NotBefore != nil {
+		nbf, err := claims.NotBefore.Float64()
+		if err != nil || now.Add(jwtLeeway).Before(unixTime(nbf)) {
+			return Identity{}, errors.New("jwt: token not valid yet")
+		}
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return Identity{}, fmt.Errorf("jwt: unexpected issuer %q", claims.Issuer)
+	}
+	if v.audience != "" && !contains(claims.Audience, v.audience) {
+		return Identity{}, errors.New("jwt: token is not issued for this audience")
+	}
+	if claims.Subject == "" {
+		return Identity{}, errors.New("jwt: missing sub claim")
+	}
+	return Identity{Name: claims.Subject, Subject: claims.Subject, Method: "jwt"}, nil
+}
+
+func (v *JWTVerifier) verifySignature(alg, signed string, sig []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "HS256", "RS256":
+		hash = crypto.SHA256
+	case "HS384", "RS384":
+		hash = crypto.SHA384
+	case "HS512", "RS512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("jwt: unsupported algorithm %q", alg)
+	}
+	switch {
+	case strings.HasPrefix(alg, "HS") && v.secret != nil:
+		mac := hmac.New(hash.New, v.secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return errors.New("jwt: invalid signature")
+		}
+		return nil
+	case strings.HasPrefix(alg, "RS") && v.public != nil:
+		h := hash.New()
+		h.Write([]byte(signed))
+		if err := rsa.VerifyPKCS1v15(v.public, hash, h.Sum(nil), sig); err != nil {
+			return errors.New("jwt: invalid signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("jwt: algorithm %s does not match the configured key", alg)
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func unixTime(sec float64) time.Time {
+	return time.Unix(0, int64(sec*float64(time.Second)))
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
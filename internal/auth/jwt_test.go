@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// signJWT подписывает claims алгоритмом alg: HS* — секретом testSecret,
+// RS* — ключом rsaKey.
+func signJWT(t *testing.T, alg string, rsaKey *rsa.PrivateKey, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	body, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body)
+	var sig []byte
+	switch alg {
+	case "HS256":
+		mac := hmac.New(sha256.New, []byte(testSecret))
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case "RS256":
+		digest := sha256.Sum256([]byte(signed))
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	default:
+		t.Fatalf("unsupported alg %s", alg)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWTVerifier_HMAC(t *testing.T) {
+	v, err := NewJWTVerifier([]byte(testSecret+"\n"), "issuer", "broker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+	id, err := v.Verify(signJWT(t, "HS256", nil, map[string]any{
+		"sub": "orders-svc", "iss": "issuer", "aud": []string{"other", "broker"}, "exp": exp,
+	}))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if id != (Identity{Name: "orders-svc", Subject: "orders-svc", Method: "jwt"}) {
+		t.Errorf("identity = %+v", id)
+	}
+
+	for name, claims := range map[string]map[string]any{
+		"expired":      {"sub": "a", "iss": "issuer", "aud": "broker", "exp": time.Now().Add(-time.Hour).Unix()},
+		"not before":   {"sub": "a", "iss": "issuer", "aud": "broker", "nbf": time.Now().Add(time.Hour).Unix()},
+		"wrong issuer": {"sub": "a", "iss": "evil", "aud": "broker"},
+		"wrong aud":    {"sub": "a", "iss": "issuer", "aud": "other"},
+		"missing sub":  {"iss": "issuer", "aud": "broker"},
+	} {
+		if _, err := v.Verify(signJWT(t, "HS256", nil, claims)); err == nil {
+			t.Errorf("%s: token accepted", name)
+		}
+	}
+
+	token := signJWT(t, "HS256", nil, map[string]any{"sub": "a", "iss": "issuer", "aud": "broker"})
+	tampered := token[:len(token)-2] + "AA"
+	if _, err := v.Verify(tampered); err == nil {
+		t.Error("tampered signature accepted")
+	}
+	parts := strings.Split(token, ".")
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+	if _, err := v.Verify(none); err == nil {
+		t.Error("alg none accepted")
+	}
+}
+
+func TestJWTVerifier_RSA(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	pub := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	v, err := NewJWTVerifier(pub, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Verify(signJWT(t, "RS256", key, map[string]any{"sub": "billing"})); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	// Подмена алгоритма: HMAC с открытым ключом в роли секрета не проходит.
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256"}`))
+	body := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"evil"}`))
+	mac := hmac.New(sha256.New, pub)
+	mac.Write([]byte(header + "." + body))
+	forged := header + "." + body + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	if _, err := v.Verify(forged); err == nil {
+		t.Error("HS256 token accepted with RSA key")
+	}
+}
+
+func TestNewJWTVerifier_shortSecret(t *testing.T) {
+	if _, err := NewJWTVerifier([]byte("short"), "", ""); err == nil {
+		t.Error("short HMAC secret accepted")
+	}
+}
+
+func TestAuthenticator(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "jwt.key")
+	writeFile(t, keyFile, []byte(testSecret))
+	digest := sha256.Sum256([]byte("billing-key"))
+	a, err := NewAuthenticator(Config{
+		APIKeys: []APIKey{
+			{Name: "orders", Key: "orders-key"},
+			{Name: "billing", KeySHA256: hex.EncodeToString(digest[:])},
+		},
+		JWTKeyFile: keyFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.Enabled() || a.AllowAnonymous() {
+		t.Fatalf("Enabled=%v AllowAnonymous=%v", a.Enabled(), a.AllowAnonymous())
+	}
+
+	if id, err := a.Authenticate("orders-key", ""); err != nil || id.Name != "orders" || id.Method != "api_key" {
+		t.Errorf("api key: %+v, %v", id, err)
+	}
+	if id, err := a.Authenticate("billing-key", ""); err != nil || id.Name != "billing" {
+		t.Errorf("hashed api key: %+v, %v", id, err)
+	}
+	if _, err := a.Authenticate("wrong", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("wrong key: %v", err)
+	}
+	if _, err := a.Authenticate("", ""); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("no credentials: %v", err)
+	}
+	token := signJWT(t, "HS256", nil, map[string]any{"sub": "reporting"})
+	if id, err := a.Authenticate("", token); err != nil || id.Name != "reporting" {
+		t.Errorf("jwt: %+v, %v", id, err)
+	}
+	if id, err := a.AuthenticateSecret(token); err != nil || id.Method != "jwt" {
+		t.Errorf("secret jwt: %+v, %v", id, err)
+	}
+	if id, err := a.AuthenticateSecret("orders-key"); err != nil || id.Method != "api_key" {
+		t.Errorf("secret api key: %+v, %v", id, err)
+	}
+}
+
+func TestNewAuthenticator_invalidConfig(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"no name":    {APIKeys: []APIKey{{Key: "k"}}},
+		"no key":     {APIKeys: []APIKey{{Name: "a"}}},
+		"both":       {APIKeys: []APIKey{{Name: "a", Key: "k", KeySHA256: strings.Repeat("0", 64)}}},
+		"bad digest": {APIKeys: []APIKey{{Name: "a", KeySHA256: "zz"}}},
+		"duplicate":  {APIKeys: []APIKey{{Name: "a", Key: "k"}, {Name: "b", Key: "k"}}},
+		"no jwt key": {JWTKeyFile: filepath.Join(t.TempDir(), "missing")},
+	} {
+		if _, err := NewAuthenticator(cfg); err == nil {
+			t.Errorf("%s: config accepted", name)
+		}
+	}
+	a, err := NewAuthenticator(Config{})
+	if err != nil || a.Enabled() || !a.AllowAnonymous() {
+		t.Errorf("empty config: enabled=%v anonymous=%v err=%v", a.Enabled(), a.AllowAnonymous(), err)
+	}
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// ClientAuth — требуется ли от клиента сертификат, подписанный ClientCAFile.
+type ClientAuth int
+
+const (
+	RequireClientCert       ClientAuth = iota // без сертификата соединение отклоняется
+	VerifyClientCertIfGiven                   // сертификат проверяется, если клиент его передал
+)
+
+// ParseClientAuth разбирает server.tls.client_auth; пустая строка — require.
+func ParseClientAuth(s string) (ClientAuth, error) {
+	switch s {
+	case "", "require":
+		return RequireClientCert, nil
+	case "optional":
+		return VerifyClientCertIfGiven, nil
+	}
+	return 0, fmt.Errorf("unknown client auth %q", s)
+}
+
+// TLSConfig — файлы сертификатов сервера.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile — PEM с сертификатами CA клиентов; пустой — без mTLS.
+	ClientCAFile string
+	ClientAuth   ClientAuth
+}
+
+// CertReloader держит сертификат сервера и CA клиентов и перечитывает их
+// с диска, когда файлы меняются: обновлённые сертификаты применяются к
+// новым соединениям без перезапуска брокера.
+type CertReloader struct {
+	cfg TLSConfig
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	// raw — прочитанное содержимое файлов, чтобы замечать изменения.
+	raw [3][]byte
+}
+
+// NewCertReloader читает сертификаты; ошибка — если их нельзя загрузить.
+func NewCertReloader(cfg TLSConfig) (*CertReloader, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("tls: cert_file and key_file are required")
+	}
+	r := &CertReloader{cfg: cfg}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload перечитывает файлы и возвращает true, если сертификаты изменились.
+// При ошибке остаются прежние сертификаты.
+func (r *CertReloader) Reload() (bool, error) {
+	var raw [3][]byte
+	for i, path := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return false, fmt.Errorf("tls: %w", err)
+		}
+		raw[i] = data
+	}
+
+	r.mu.RLock()
+	unchanged := bytes.Equal(raw[0], r.raw[0]) && bytes.Equal(raw[1], r.raw[1]) && bytes.Equal(raw[2], r.raw[2])
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(raw[0], raw[1])
+	if err != nil {
+		return false, fmt.Errorf("tls: load key pair: %w", err)
+	}
+	var pool *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(raw[2]) {
+			return false, fmt.Errorf("tls: no certificates in %s", r.cfg.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.clientCAs, r.raw = &cert, pool, raw
+	r.mu.Unlock()
+	return true, nil
+}
+
+// Run проверяет файлы каждые interval, пока не отменён ctx. Ошибки чтения
+// (например, файл заменяется не атомарно) пишутся в лог, и брокер продолжает
+// работать с прежними сертификатами.
+func (r *CertReloader) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		changed, err := r.Reload()
+		switch {
+		case err != nil:
+			slog.WarnContext(ctx, "tls certificates not reloaded", "error", err)
+		case changed:
+			slog.InfoContext(ctx, "tls certificates reloaded", "cert_file", r.cfg.CertFile)
+		}
+	}
+}
+
+// TLSConfig возвращает настройки сервера. Сертификаты берутся из
+// CertReloader при каждом рукопожатии.
+func (r *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				NextProtos:   []string{"h2"},
+			}
+			if r.clientCAs != nil {
+				cfg.ClientCAs = r.clientCAs
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+				if r.cfg.ClientAuth == VerifyClientCertIfGiven {
+					cfg.ClientAuth = tls.VerifyClientCertIfGiven
+				}
+			}
+			return cfg, nil
+		},
+	}
+}
+
+// PeerIdentity возвращает идентичность владельца проверенного клиентского
+// сертификата; false — клиент сертификат не передал.
+func PeerIdentity(state tls.ConnectionState) (Identity, bool) {
+	if len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return Identity{}, false
+	}
+	leaf := state.VerifiedChains[0][0]
+	return Identity{Name: leaf.Subject.CommonName, Subject: leaf.Subject.String(), Method: "mtls"}, true
+}
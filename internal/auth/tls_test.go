@@ -0,0 +1,235 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue выпускает сертификат с Common Name cn и возвращает PEM сертификата и ключа.
+func (ca *testCA) issue(t *testing.T, cn string, serial int64, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"shop"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// handshake устанавливает TLS-соединение с сервером r и возвращает
+// состояние соединения на стороне сервера и CN сертификата сервера.
+func handshake(t *testing.T, r *CertReloader, client *tls.Config) (tls.ConnectionState, string, error) {
+	t.Helper()
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", r.TLSConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+
+	type result struct {
+		state tls.ConnectionState
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		defer conn.Close()
+		tc := conn.(*tls.Conn)
+		err = tc.Handshake()
+		done <- result{tc.ConnectionState(), err}
+	}()
+
+	conn, err := tls.Dial("tcp", lis.Addr().String(), client)
+	var serverCN string
+	if err == nil {
+		serverCN = conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+		// В TLS 1.3 клиентский сертификат проверяется после Dial:
+		// ошибка сервера приходит при первом чтении.
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, _ = conn.Read(make([]byte, 1))
+		conn.Close()
+	}
+	res := <-done
+	if err == nil {
+		err = res.err
+	}
+	return res.state, serverCN, err
+}
+
+func TestCertReloader_mutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, "broker", 2, x509.ExtKeyUsageServerAuth)
+	writeFile(t, filepath.Join(dir, "tls.crt"), certPEM)
+	writeFile(t, filepath.Join(dir, "tls.key"), keyPEM)
+	writeFile(t, filepath.Join(dir, "ca.crt"), ca.pem)
+	r, err := NewCertReloader(TLSConfig{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+	})
+	if err != nil {
+		t.Fatalf("NewCertReloader: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientCert, clientKey := ca.issue(t, "orders-svc", 3, x509.ExtKeyUsageClientAuth)
+	pair, _ := tls.X509KeyPair(clientCert, clientKey)
+
+	state, _, err := handshake(t, r, &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{pair}})
+	if err != nil {
+		t.Fatalf("handshake with client certificate: %v", err)
+	}
+	id, ok := PeerIdentity(state)
+	if !ok || id.Name != "orders-svc" || id.Subject != "CN=orders-svc,O=shop" || id.Method != "mtls" {
+		t.Errorf("identity %+v, %v", id, ok)
+	}
+
+	if _, _, err := handshake(t, r, &tls.Config{RootCAs: roots}); err == nil {
+		t.Error("handshake without client certificate must fail")
+	}
+
+	// Сертификат, выпущенный другим CA, не принимается.
+	otherCert, otherKey := newTestCA(t).issue(t, "intruder", 4, x509.ExtKeyUsageClientAuth)
+	other, _ := tls.X509KeyPair(otherCert, otherKey)
+	if _, _, err := handshake(t, r, &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{other}}); err == nil {
+		t.Error("certificate of an unknown CA must be rejected")
+	}
+}
+
+func TestCertReloader_optionalClientCert(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, "broker", 2, x509.ExtKeyUsageServerAuth)
+	writeFile(t, filepath.Join(dir, "tls.crt"), certPEM)
+	writeFile(t, filepath.Join(dir, "tls.key"), keyPEM)
+	writeFile(t, filepath.Join(dir, "ca.crt"), ca.pem)
+	r, err := NewCertReloader(TLSConfig{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+		ClientAuth:   VerifyClientCertIfGiven,
+	})
+	if err != nil {
+		t.Fatalf("NewCertReloader: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	state, _, err := handshake(t, r, &tls.Config{RootCAs: roots})
+	if err != nil {
+		t.Fatalf("handshake without client certificate: %v", err)
+	}
+	if _, ok := PeerIdentity(state); ok {
+		t.Error("anonymous client must have no identity")
+	}
+}
+
+func TestCertReloader_reload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, "broker-v1", 2, x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, certPEM)
+	writeFile(t, keyFile, keyPEM)
+	r, err := NewCertReloader(TLSConfig{CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("NewCertReloader: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	client := &tls.Config{RootCAs: roots}
+
+	if changed, err := r.Reload(); changed || err != nil {
+		t.Errorf("unchanged files: changed=%v err=%v", changed, err)
+	}
+
+	certPEM, keyPEM = ca.issue(t, "broker-v2", 3, x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, certPEM)
+	writeFile(t, keyFile, keyPEM)
+	if changed, err := r.Reload(); !changed || err != nil {
+		t.Fatalf("rotated files: changed=%v err=%v", changed, err)
+	}
+	if _, cn, err := handshake(t, r, client); err != nil || cn != "broker-v2" {
+		t.Errorf("after reload server presents %q (%v), want broker-v2", cn, err)
+	}
+
+	// Недописанный файл не ломает сервер: остаётся прежний сертификат.
+	writeFile(t, certFile, []byte("garbage"))
+	if _, err := r.Reload(); err == nil {
+		t.Error("broken certificate must fail to load")
+	}
+	if _, cn, err := handshake(t, r, client); err != nil || cn != "broker-v2" {
+		t.Errorf("after failed reload server presents %q (%v), want broker-v2", cn, err)
+	}
+}
+
+func TestParseClientAuth(t *testing.T) {
+	for in, want := range map[string]ClientAuth{"": RequireClientCert, "require": RequireClientCert, "optional": VerifyClientCertIfGiven} {
+		if got, err := ParseClientAuth(in); err != nil || got != want {
+			t.Errorf("%q: %v, %v", in, got, err)
+		}
+	}
+	if _, err := ParseClientAuth("none"); err == nil {
+		t.Error("unknown value must fail")
+	}
+}
@@ -0,0 +1,135 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"queue-service/internal/delivery/grpc/pb"
+	"queue-service/internal/domain"
+	"queue-service/internal/usecase"
+)
+
+var (
+	aclResourceTypes = map[pb.AclResourceType]domain.ACLResourceType{
+		pb.AclResourceType_ACL_RESOURCE_TOPIC:   domain.ACLTopic,
+		pb.AclResourceType_ACL_RESOURCE_GROUP:   domain.ACLGroup,
+		pb.AclResourceType_ACL_RESOURCE_CLUSTER: domain.ACLCluster,
+	}
+	aclOperations = map[pb.AclOperation]domain.ACLOperation{
+		pb.AclOperation_ACL_OPERATION_PUBLISH: domain.ACLPublish,
+		pb.AclOperation_ACL_OPERATION_CONSUME: domain.ACLConsume,
+		pb.AclOperation_ACL_OPERATION_ADMIN:   domain.ACLAdmin,
+	}
+)
+
+// authorize проверяет право клиента на операцию; отказ — PERMISSION_DENIED.
+func (h *BrokerHandler) authorize(ctx context.Context, op domain.ACLOperation, resourceType domain.ACLResourceType, name string) error {
+	if err := h.acl.Authorize(ctx, op, resourceType, name); err != nil {
+		return aclError(err)
+	}
+	return nil
+}
+
+// authorizeSubscription проверяет право consume на топик и группу подписки.
+// Неизвестную подписку пропускает: NOT_FOUND вернёт сам вызов.
+func (h *BrokerHandler) authorizeSubscription(ctx context.Context, subscriptionID string) error {
+	if !h.acl.Enabled() {
+		return nil
+	}
+	sub, err := h.subscribe.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil
+	}
+	if err := h.authorize(ctx, domain.ACLConsume, domain.ACLTopic, sub.TopicName); err != nil {
+		return err
+	}
+	return h.authorize(ctx, domain.ACLConsume, domain.ACLGroup, sub.ConsumerGroup)
+}
+
+func aclError(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrPermissionDenied):
+		return errPermissionDenied(err.Error())
+	case errors.Is(err, usecase.ErrInvalidACLRule):
+		return errInvalidArg(err.Error())
+	}
+	return errInternal(err)
+}
+
+func (h *BrokerHandler) CreateAclRule(ctx context.Context, req *pb.CreateAclRuleRequest) (*pb.CreateAclRuleResponse, error) {
+	if err := h.authorize(ctx, domain.ACLAdmin, domain.ACLCluster, "*"); err != nil {
+		return nil, err
+	}
+	if req.Rule == nil {
+		return nil, errInvalidArg("rule is required")
+	}
+	rule := domain.ACLRule{
+		Principal:    req.Rule.Principal,
+		ResourceType: aclResourceTypes[req.Rule.ResourceType],
+		Namespace:    req.Rule.Namespace,
+		Pattern:      req.Rule.Pattern,
+	}
+	// Правило топика или группы без пространства имён действует в пространстве запроса.
+	if rule.Namespace == "" && rule.ResourceType != domain.ACLCluster {
+		rule.Namespace = domain.Namespace(ctx)
+	}
+	for _, op := range req.Rule.Operations {
+		rule.Operations = append(rule.Operations, aclOperations[op])
+	}
+	created, err := h.acl.CreateRule(ctx, rule)
+	if err != nil {
+		return nil, aclError(err)
+	}
+	return &pb.CreateAclRuleResponse{Rule: toPbACLRule(created)}, nil
+}
+
+func (h *BrokerHandler) DeleteAclRule(ctx context.Context, req *pb.DeleteAclRuleRequest) (*pb.DeleteAclRuleResponse, error) {
+	if err := h.authorize(ctx, domain.ACLAdmin, domain.ACLCluster, "*"); err != nil {
+		return nil, err
+	}
+	if err := h.acl.DeleteRule(ctx, req.Id); err != nil {
+		if err == usecase.ErrACLRuleNotFound {
+			return nil, errNotFound("acl rule", req.Id)
+		}
+		return nil, errInternal(err)
+	}
+	return &pb.DeleteAclRuleResponse{}, nil
+}
+
+func (h *BrokerHandler) ListAclRules(ctx context.Context, req *pb.ListAclRulesRequest) (*pb.ListAclRulesResponse, error) {
+	if err := h.authorize(ctx, domain.ACLAdmin, domain.ACLCluster, "*"); err != nil {
+		return nil, err
+	}
+	list, err := h.acl.ListRules(ctx, req.Principal)
+	if err != nil {
+		return nil, errInternal(err)
+	}
+	rules := make([]*pb.AclRule, 0, len(list))
+	for _, r := range list {
+		rules = append(rules, toPbACLRule(r))
+	}
+	return &pb.ListAclRulesResponse{Rules: rules}, nil
+}
+
+func toPbACLRule(r *domain.ACLRule) *pb.AclRule {
+	out := &pb.AclRule{
+		Id:              r.ID,
+		Principal:       r.Principal,
+		Namespace:       r.Namespace,
+		Pattern:         r.Pattern,
+		CreatedAtUnixMs: r.CreatedAt.UnixMilli(),
+	}
+	for pbType, t := range aclResourceTypes {
+		if t == r.ResourceType {
+			out.ResourceType = pbType
+		}
+	}
+	for _, op := range r.Operations {
+		for pbOp, o := range aclOperations {
+			if o == op {
+				out.Operations = append(out.Operations, pbOp)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,121 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"queue-service/internal/auth"
+	"queue-service/internal/delivery/grpc/pb"
+	"queue-service/internal/domain"
+	"queue-service/internal/usecase"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func asClient(name string) context.Context {
+	return auth.WithIdentity(context.Background(), auth.Identity{Name: name, Method: "api_key"})
+}
+
+func TestBrokerHandler_ACL(t *testing.T) {
+	h := newTestHandlerWith(t, testPolicies{acl: usecase.ACLPolicy{Enabled: true, SuperUsers: []string{"root"}}})
+	root, orders, billing := asClient("root"), asClient("orders"), asClient("billing")
+
+	// Правила может менять только admin на cluster.
+	rule := &pb.AclRule{
+		Principal:    "orders",
+		ResourceType: pb.AclResourceType_ACL_RESOURCE_TOPIC,
+		Pattern:      "orders*",
+		Operations:   []pb.AclOperation{pb.AclOperation_ACL_OPERATION_PUBLISH, pb.AclOperation_ACL_OPERATION_CONSUME},
+	}
+	if _, err := h.CreateAclRule(orders, &pb.CreateAclRuleRequest{Rule: rule}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("CreateAclRule by orders: %v", err)
+	}
+	if _, err := h.CreateAclRule(root, &pb.CreateAclRuleRequest{Rule: &pb.AclRule{Principal: "orders"}}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("invalid rule: %v", err)
+	}
+	created, err := h.CreateAclRule(root, &pb.CreateAclRuleRequest{Rule: rule})
+	if err != nil {
+		t.Fatalf("CreateAclRule: %v", err)
+	}
+	if created.Rule.Id == "" || len(created.Rule.Operations) != 2 || created.Rule.ResourceType != pb.AclResourceType_ACL_RESOURCE_TOPIC ||
+		created.Rule.Namespace != domain.DefaultNamespace {
+		t.Errorf("created rule %+v", created.Rule)
+	}
+	// Без пространства имён правило действует в пространстве запроса.
+	inTeam, err := h.CreateAclRule(domain.WithNamespace(root, "team-a"), &pb.CreateAclRuleRequest{Rule: &pb.AclRule{
+		Principal:    "billing",
+		ResourceType: pb.AclResourceType_ACL_RESOURCE_TOPIC,
+		Pattern:      "*",
+		Operations:   []pb.AclOperation{pb.AclOperation_ACL_OPERATION_PUBLISH},
+	}})
+	if err != nil || inTeam.Rule.Namespace != "team-a" {
+		t.Fatalf("CreateAclRule in team-a: %v %+v", err, inTeam.GetRule())
+	}
+	_, err = h.CreateAclRule(root, &pb.CreateAclRuleRequest{Rule: &pb.AclRule{
+		Principal:    "orders",
+		ResourceType: pb.AclResourceType_ACL_RESOURCE_GROUP,
+		Pattern:      "orders-app",
+		Operations:   []pb.AclOperation{pb.AclOperation_ACL_OPERATION_CONSUME},
+	}})
+	if err != nil {
+		t.Fatalf("CreateAclRule group: %v", err)
+	}
+
+	for _, name := range []string{"orders", "billing"} {
+		if _, err := h.CreateTopic(root, &pb.CreateTopicRequest{Name: name}); err != nil {
+			t.Fatalf("CreateTopic %s: %v", name, err)
+		}
+	}
+	if _, err := h.CreateTopic(orders, &pb.CreateTopicRequest{Name: "orders2"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("CreateTopic without admin: %v", err)
+	}
+	if _, err := h.Publish(orders, &pb.PublishRequest{TopicName: "orders", QueueId: "0", Payload: []byte("m")}); err != nil {
+		t.Errorf("Publish to own topic: %v", err)
+	}
+	if _, err := h.Publish(orders, &pb.PublishRequest{TopicName: "billing", QueueId: "0"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Publish to foreign topic: %v", err)
+	}
+	batch, _ := h.PublishBatch(orders, &pb.PublishBatchRequest{Messages: []*pb.PublishRequest{
+		{TopicName: "orders", QueueId: "0"}, {TopicName: "billing", QueueId: "0"},
+	}})
+	if batch.Results[0].Code != 0 || codes.Code(batch.Results[1].Code) != codes.PermissionDenied {
+		t.Errorf("PublishBatch results %+v", batch.Results)
+	}
+
+	list, _ := h.ListTopics(orders, &pb.ListTopicsRequest{})
+	if len(list.Topics) != 1 || list.Topics[0].Name != "orders" {
+		t.Errorf("ListTopics for orders: %+v", list.Topics)
+	}
+
+	if _, err := h.Subscribe(orders, &pb.SubscribeRequest{TopicName: "orders", QueueId: "0", ConsumerGroup: "other"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Subscribe with foreign group: %v", err)
+	}
+	sub, err := h.Subscribe(orders, &pb.SubscribeRequest{TopicName: "orders", QueueId: "0", ConsumerGroup: "orders-app"})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if _, err := h.Consume(orders, &pb.ConsumeRequest{SubscriptionId: sub.SubscriptionId}); err != nil {
+		t.Errorf("Consume: %v", err)
+	}
+	if _, err := h.Consume(billing, &pb.ConsumeRequest{SubscriptionId: sub.SubscriptionId}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Consume from foreign subscription: %v", err)
+	}
+	if subs, _ := h.ListSubscriptions(billing, &pb.ListSubscriptionsRequest{}); len(subs.Subscriptions) != 0 {
+		t.Errorf("billing sees %d subscriptions", len(subs.Subscriptions))
+	}
+
+	rules, err := h.ListAclRules(root, &pb.ListAclRulesRequest{Principal: "orders"})
+	if err != nil || len(rules.Rules) != 2 {
+		t.Fatalf("ListAclRules: %v, %d rules", err, len(rules.GetRules()))
+	}
+	if _, err := h.DeleteAclRule(root, &pb.DeleteAclRuleRequest{Id: created.Rule.Id}); err != nil {
+		t.Fatalf("DeleteAclRule: %v", err)
+	}
+	if _, err := h.Publish(orders, &pb.PublishRequest{TopicName: "orders", QueueId: "0"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Publish after rule deleted: %v", err)
+	}
+	if _, err := h.DeleteAclRule(root, &pb.DeleteAclRuleRequest{Id: created.Rule.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("DeleteAclRule twice: %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"queue-service/internal/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	apiKeyHeader        = "x-api-key"
+	authorizationHeader = "authorization"
+	// Проверки состояния доступны без учётных данных: их вызывают
+	// балансировщики и kubelet.
+	healthServicePrefix = "/grpc.health.v1.Health/"
+)
+
+type authenticator struct {
+	auth *auth.Authenticator
+}
+
+// authenticate кладёт в контекст идентичность клиента. Переданные ключ или
+// токен проверяются всегда; без них клиента удостоверяет сертификат mTLS,
+// иначе вызов пропускается только при разрешённом анонимном доступе.
+func (a authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if strings.HasPrefix(method, healthServicePrefix) {
+		return ctx, nil
+	}
+	apiKey, token := credentialsFromMetadata(ctx)
+	id, err := a.auth.Authenticate(apiKey, token)
+	switch {
+	case err == nil:
+		return auth.WithIdentity(ctx, id), nil
+	case !errors.Is(err, auth.ErrNoCredentials):
+		a.reject(ctx, method, err)
+		return ctx, errUnauthenticated("invalid credentials")
+	}
+	if _, ok := auth.FromContext(ctx); ok {
+		return ctx, nil
+	}
+	if id, ok := tlsIdentity(ctx); ok {
+		return auth.WithIdentity(ctx, id), nil
+	}
+	if a.auth.AllowAnonymous() {
+		return ctx, nil
+	}
+	a.reject(ctx, method, err)
+	return ctx, errUnauthenticated("credentials required: x-api-key or authorization: Bearer <token>")
+}
+
+// reject пишет отказ в журнал: перехватчик логирования стоит после
+// аутентификации и отклонённые вызовы не видит.
+func (a authenticator) reject(ctx context.Context, method string, err error) {
+	peerAddr := "unknown"
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		peerAddr = p.Addr.String()
+	}
+	slog.WarnContext(ctx, "authentication failed", "method", method, "peer", peerAddr, "error", err)
+}
+
+func (a authenticator) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a authenticator) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+}
+
+// authStream подменяет контекст потока контекстом с идентичностью клиента.
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context { return s.ctx }
+
+// credentialsFromMetadata достаёт API-ключ и bearer-токен из метаданных.
+func credentialsFromMetadata(ctx context.Context) (apiKey, token string) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ""
+	}
+	if v := md.Get(apiKeyHeader); len(v) > 0 {
+		apiKey = v[0]
+	}
+	if v := md.Get(authorizationHeader); len(v) > 0 {
+		if scheme, rest, ok := strings.Cut(v[0], " "); ok && strings.EqualFold(scheme, "bearer") {
+			token = strings.TrimSpace(rest)
+		}
+	}
+	return apiKey, token
+}
+
+// AuthUnaryInterceptor возвращает параметр сервера, который проверяет
+// API-ключ (x-api-key) или JWT (authorization: Bearer) унарных вызовов и
+// кладёт идентичность клиента в контекст. Ставится после
+// IdentityUnaryInterceptor, чтобы клиенты mTLS проходили без ключа.
+func AuthUnaryInterceptor(a *auth.Authenticator) grpc.ServerOption {
+	return grpc.ChainUnaryInterceptor(authenticator{auth: a}.unary)
+}
+
+// AuthStreamInterceptor — то же для потоковых вызовов (reflection).
+func AuthStreamInterceptor(a *auth.Authenticator) grpc.ServerOption {
+	return grpc.ChainStreamInterceptor(authenticator{auth: a}.stream)
+}
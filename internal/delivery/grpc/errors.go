@@ -17,6 +17,22 @@ func errInvalidArg(msg string) error {
 	return status.Errorf(codes.InvalidArgument, "%s", msg)
 }
 
+func errFailedPrecondition(msg string) error {
+	return status.Errorf(codes.FailedPrecondition, "%s", msg)
+}
+
+func errResourceExhausted(msg string) error {
+	return status.Errorf(codes.ResourceExhausted, "%s", msg)
+}
+
 func errInternal(err error) error {
 	return status.Errorf(codes.Internal, "%v", err)
 }
+
+func errUnauthenticated(msg string) error {
+	return status.Errorf(codes.Unauthenticated, "%s", msg)
+}
+
+func errPermissionDenied(msg string) error {
+	return status.Errorf(codes.PermissionDenied, "%s", msg)
+}
@@ -2,10 +2,14 @@ package grpc
 
 import (
 	"context"
+	"time"
 
 	"queue-service/internal/delivery/grpc/pb"
 	"queue-service/internal/domain"
 	"queue-service/internal/usecase"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type BrokerHandler struct {
@@ -14,6 +18,10 @@ type BrokerHandler struct {
 	publish   *usecase.PublishUseCase
 	subscribe *usecase.SubscriptionUseCase
 	consume   *usecase.ConsumeUseCase
+	messages  *usecase.MessageUseCase
+	acl       *usecase.ACLUseCase
+	tenants   *usecase.TenantUseCase
+	quotas    *usecase.QuotaUseCase
 }
 
 func NewBrokerHandler(
@@ -21,16 +29,33 @@ func NewBrokerHandler(
 	publish *usecase.PublishUseCase,
 	subscribe *usecase.SubscriptionUseCase,
 	consume *usecase.ConsumeUseCase,
+	messages *usecase.MessageUseCase,
+	acl *usecase.ACLUseCase,
+	tenants *usecase.TenantUseCase,
+	quotas *usecase.QuotaUseCase,
 ) *BrokerHandler {
 	return &BrokerHandler{
 		topics:    topics,
 		publish:   publish,
 		subscribe: subscribe,
 		consume:   consume,
+		messages:  messages,
+		acl:       acl,
+		tenants:   tenants,
+		quotas:    quotas,
 	}
 }
 
 func (h *BrokerHandler) CreateTopic(ctx context.Context, req *pb.CreateTopicRequest) (*pb.CreateTopicResponse, error) {
+	if err := h.authorize(ctx, domain.ACLAdmin, domain.ACLTopic, req.Name); err != nil {
+		return nil, err
+	}
+	if err := h.tenants.CheckTopicQuota(ctx); err != nil {
+		return nil, tenantError(err)
+	}
+	if err := h.quotas.CheckTopicQuota(ctx); err != nil {
+		return nil, quotaError(err)
+	}
 	retention := int(req.RetentionMessages)
 	if retention <= 0 {
 		retention = 10000
@@ -46,6 +71,9 @@ func (h *BrokerHandler) CreateTopic(ctx context.Context, req *pb.CreateTopicRequ
 }
 
 func (h *BrokerHandler) CreateQueue(ctx context.Context, req *pb.CreateQueueRequest) (*pb.CreateQueueResponse, error) {
+	if err := h.authorize(ctx, domain.ACLAdmin, domain.ACLTopic, req.TopicName); err != nil {
+		return nil, err
+	}
 	queueID := req.QueueId
 	if queueID == "" {
 		queueID = "0"
@@ -67,12 +95,18 @@ func (h *BrokerHandler) ListTopics(ctx context.Context, _ *pb.ListTopicsRequest)
 	}
 	topics := make([]*pb.TopicInfo, 0, len(list))
 	for _, t := range list {
-		topics = append(topics, &pb.TopicInfo{Name: t.Name, RetentionMessages: int32(t.RetentionMessages)})
+		if !h.acl.CanAccess(ctx, domain.ACLTopic, t.Name) {
+			continue
+		}
+		topics = append(topics, &pb.TopicInfo{Name: t.Name, RetentionMessages: int32(t.RetentionMessages), Namespace: t.Namespace})
 	}
 	return &pb.ListTopicsResponse{Topics: topics}, nil
 }
 
 func (h *BrokerHandler) ListQueues(ctx context.Context, req *pb.ListQueuesRequest) (*pb.ListQueuesResponse, error) {
+	if !h.acl.CanAccess(ctx, domain.ACLTopic, req.TopicName) {
+		return nil, errPermissionDenied("no access to topic " + req.TopicName)
+	}
 	list, err := h.topics.ListQueues(ctx, req.TopicName)
 	if err != nil {
 		return nil, errInternal(err)
@@ -84,18 +118,164 @@ func (h *BrokerHandler) ListQueues(ctx context.Context, req *pb.ListQueuesReques
 	return &pb.ListQueuesResponse{Queues: queues}, nil
 }
 
+func (h *BrokerHandler) DeleteTopic(ctx context.Context, req *pb.DeleteTopicRequest) (*pb.DeleteTopicResponse, error) {
+	if err := h.authorize(ctx, domain.ACLAdmin, domain.ACLTopic, req.Name); err != nil {
+		return nil, err
+	}
+	if err := h.topics.DeleteTopic(ctx, req.Name, req.Force); err != nil {
+		switch err {
+		case usecase.ErrTopicNotFound:
+			return nil, errNotFound("topic", req.Name)
+		case usecase.ErrTopicHasSubscriptions:
+			return nil, errFailedPrecondition("topic " + req.Name + " has subscriptions; use force to delete them")
+		}
+		return nil, errInternal(err)
+	}
+	return &pb.DeleteTopicResponse{}, nil
+}
+
+func (h *BrokerHandler) DeleteQueue(ctx context.Context, req *pb.DeleteQueueRequest) (*pb.DeleteQueueResponse, error) {
+	if err := h.authorize(ctx, domain.ACLAdmin, domain.ACLTopic, req.TopicName); err != nil {
+		return nil, err
+	}
+	if err := h.topics.DeleteQueue(ctx, req.TopicName, req.QueueId, req.Force); err != nil {
+		if err == usecase.ErrQueueHasSubscriptions {
+			return nil, errFailedPrecondition("queue " + req.TopicName + "/" + req.QueueId + " has subscriptions; use force to delete them")
+		}
+		return nil, queueError(err, req.TopicName, req.QueueId)
+	}
+	return &pb.DeleteQueueResponse{}, nil
+}
+
+func (h *BrokerHandler) PurgeQueue(ctx context.Context, req *pb.PurgeQueueRequest) (*pb.PurgeQueueResponse, error) {
+	if err := h.authorize(ctx, domain.ACLAdmin, domain.ACLTopic, req.TopicName); err != nil {
+		return nil, err
+	}
+	start, err := h.topics.PurgeQueue(ctx, req.TopicName, req.QueueId)
+	if err != nil {
+		return nil, queueError(err, req.TopicName, req.QueueId)
+	}
+	return &pb.PurgeQueueResponse{LogStartOffset: start}, nil
+}
+
+func (h *BrokerHandler) BrowseMessages(ctx context.Context, req *pb.BrowseMessagesRequest) (*pb.BrowseMessagesResponse, error) {
+	if err := h.authorize(ctx, domain.ACLConsume, domain.ACLTopic, req.TopicName); err != nil {
+		return nil, err
+	}
+	var filter usecase.MessageFilter
+	if f := req.Filter; f != nil {
+		filter = usecase.MessageFilter{Key: f.Key, Headers: f.Headers, PayloadContains: f.PayloadContains}
+	}
+	msgs, next, err := h.messages.Browse(ctx, req.TopicName, req.QueueId, req.FromOffset, int(req.Limit), filter)
+	if err != nil {
+		return nil, queueError(err, req.TopicName, req.QueueId)
+	}
+	return &pb.BrowseMessagesResponse{Messages: toPbMessages(msgs), NextOffset: next}, nil
+}
+
+func (h *BrokerHandler) GetMessage(ctx context.Context, req *pb.GetMessageRequest) (*pb.GetMessageResponse, error) {
+	if err := h.authorize(ctx, domain.ACLConsume, domain.ACLTopic, req.TopicName); err != nil {
+		return nil, err
+	}
+	m, err := h.messages.GetMessage(ctx, req.TopicName, req.QueueId, req.MessageId)
+	if err != nil {
+		if err == usecase.ErrMessageNotFound {
+			return nil, errNotFound("message", req.MessageId)
+		}
+		return nil, queueError(err, req.TopicName, req.QueueId)
+	}
+	return &pb.GetMessageResponse{Message: toPbMessage(m)}, nil
+}
+
+func (h *BrokerHandler) GetMemoryUsage(ctx context.Context, req *pb.GetMemoryUsageRequest) (*pb.GetMemoryUsageResponse, error) {
+	if err := h.authorize(ctx, domain.ACLAdmin, domain.ACLCluster, "*"); err != nil {
+		return nil, err
+	}
+	usage, err := h.publish.MemoryUsage(ctx)
+	if err != nil {
+		return nil, errInternal(err)
+	}
+	resp := &pb.GetMemoryUsageResponse{
+		UsedBytes:           usage.Used,
+		LimitBytes:          usage.Limit,
+		OverflowPolicy:      usage.Policy.String(),
+		Topics:              make([]*pb.TopicMemoryUsage, 0, len(usage.Topics)),
+		Namespace:           usage.Namespace,
+		NamespaceUsedBytes:  usage.NamespaceUsed,
+		NamespaceLimitBytes: usage.NamespaceLimit,
+	}
+	for _, t := range usage.Topics {
+		resp.Topics = append(resp.Topics, &pb.TopicMemoryUsage{TopicName: t.TopicName, UsedBytes: t.Used, LimitBytes: t.Limit})
+	}
+	return resp, nil
+}
+
+func queueError(err error, topicName, queueID string) error {
+	switch err {
+	case usecase.ErrTopicNotFound:
+		return errNotFound("topic", topicName)
+	case usecase.ErrQueueNotFound:
+		return errNotFound("queue", topicName+"/"+queueID)
+	}
+	return errInternal(err)
+}
+
 func (h *BrokerHandler) Publish(ctx context.Context, req *pb.PublishRequest) (*pb.PublishResponse, error) {
+	if err := h.authorize(ctx, domain.ACLPublish, domain.ACLTopic, req.TopicName); err != nil {
+		return nil, err
+	}
+	if wait, err := h.quotas.AllowPublish(ctx, req.TopicName, len(req.Payload)); err != nil {
+		return nil, rateLimitError(ctx, err, wait)
+	}
 	msg, err := h.publish.Publish(ctx, req.TopicName, req.QueueId, req.Payload, req.Key, req.Headers)
 	if err != nil {
-		if err == usecase.ErrTopicNotFound {
-			return nil, errNotFound("topic", req.TopicName)
+		return nil, publishError(err, req)
+	}
+	return &pb.PublishResponse{MessageId: msg.ID, Offset: msg.Offset}, nil
+}
+
+// PublishBatch публикует сообщения по порядку; ошибка одного сообщения
+// не прерывает пакет и возвращается в его PublishResult. Если часть
+// сообщений отклонена лимитом частоты, в заголовке ответа — наибольшая пауза.
+func (h *BrokerHandler) PublishBatch(ctx context.Context, req *pb.PublishBatchRequest) (*pb.PublishBatchResponse, error) {
+	results := make([]*pb.PublishResult, 0, len(req.Messages))
+	var retryAfter time.Duration
+	for _, m := range req.Messages {
+		if err := h.authorize(ctx, domain.ACLPublish, domain.ACLTopic, m.TopicName); err != nil {
+			st := status.Convert(err)
+			results = append(results, &pb.PublishResult{Code: int32(st.Code()), Error: st.Message()})
+			continue
 		}
-		if err == usecase.ErrMessageTooLarge {
-			return nil, errInvalidArg("message too large")
+		if wait, err := h.quotas.AllowPublish(ctx, m.TopicName, len(m.Payload)); err != nil {
+			retryAfter = max(retryAfter, wait)
+			results = append(results, &pb.PublishResult{Code: int32(codes.ResourceExhausted), Error: err.Error()})
+			continue
 		}
-		return nil, errInternal(err)
+		msg, err := h.publish.Publish(ctx, m.TopicName, m.QueueId, m.Payload, m.Key, m.Headers)
+		if err != nil {
+			st := status.Convert(publishError(err, m))
+			results = append(results, &pb.PublishResult{Code: int32(st.Code()), Error: st.Message()})
+			continue
+		}
+		results = append(results, &pb.PublishResult{MessageId: msg.ID, Offset: msg.Offset})
 	}
-	return &pb.PublishResponse{MessageId: msg.ID, Offset: msg.Offset}, nil
+	if retryAfter > 0 {
+		setRetryAfter(ctx, retryAfter)
+	}
+	return &pb.PublishBatchResponse{Results: results}, nil
+}
+
+func publishError(err error, req *pb.PublishRequest) error {
+	if err == usecase.ErrTopicNotFound {
+		return errNotFound("topic", req.TopicName)
+	}
+	if err == usecase.ErrMessageTooLarge {
+		return errInvalidArg("message too large")
+	}
+	if err == usecase.ErrMemoryLimit {
+		return errResourceExhausted("memory limit exceeded for topic " + req.TopicName)
+	}
+	return errInternal(err)
 }
 
 func (h *BrokerHandler) Subscribe(ctx context.Context, req *pb.SubscribeRequest) (*pb.SubscribeResponse, error) {
@@ -106,7 +286,29 @@ func (h *BrokerHandler) Subscribe(ctx context.Context, req *pb.SubscribeRequest)
 	case pb.DeliveryGuarantee_AT_MOST_ONCE:
 		guarantee = domain.AtMostOnce
 	}
-	sub, err := h.subscribe.Subscribe(ctx, req.TopicName, req.QueueId, req.ConsumerGroup, guarantee)
+	start := usecase.Seek{Position: usecase.SeekEarliest}
+	switch req.StartPosition {
+	case pb.SeekPosition_SEEK_POSITION_UNSPECIFIED, pb.SeekPosition_EARLIEST:
+	case pb.SeekPosition_LATEST:
+		start.Position = usecase.SeekLatest
+	case pb.SeekPosition_TIMESTAMP:
+		start = usecase.Seek{Position: usecase.SeekTimestamp, Timestamp: time.UnixMilli(req.StartTimestampUnixMs)}
+	default:
+		return nil, errInvalidArg("start_position must be EARLIEST, LATEST or TIMESTAMP")
+	}
+	if err := h.authorize(ctx, domain.ACLConsume, domain.ACLTopic, req.TopicName); err != nil {
+		return nil, err
+	}
+	if err := h.authorize(ctx, domain.ACLConsume, domain.ACLGroup, req.ConsumerGroup); err != nil {
+		return nil, err
+	}
+	if err := h.tenants.CheckSubscriptionQuota(ctx); err != nil {
+		return nil, tenantError(err)
+	}
+	if err := h.quotas.CheckSubscriptionQuota(ctx); err != nil {
+		return nil, quotaError(err)
+	}
+	sub, err := h.subscribe.SubscribeFrom(ctx, req.TopicName, req.QueueId, req.ConsumerGroup, guarantee, start)
 	if err != nil {
 		if err == usecase.ErrTopicNotFound {
 			return nil, errNotFound("topic", req.TopicName)
@@ -121,43 +323,202 @@ func (h *BrokerHandler) Subscribe(ctx context.Context, req *pb.SubscribeRequest)
 		TopicName:      sub.TopicName,
 		QueueId:        sub.QueueID,
 		ConsumerGroup:  sub.ConsumerGroup,
+		Offset:         sub.Offset,
 	}, nil
 }
 
+func (h *BrokerHandler) Unsubscribe(ctx context.Context, req *pb.UnsubscribeRequest) (*pb.UnsubscribeResponse, error) {
+	if err := h.authorizeSubscription(ctx, req.SubscriptionId); err != nil {
+		return nil, err
+	}
+	if err := h.subscribe.Unsubscribe(ctx, req.SubscriptionId); err != nil {
+		if err == usecase.ErrSubscriptionNotFound {
+			return nil, errNotFound("subscription", req.SubscriptionId)
+		}
+		return nil, errInternal(err)
+	}
+	return &pb.UnsubscribeResponse{}, nil
+}
+
+func (h *BrokerHandler) ListSubscriptions(ctx context.Context, req *pb.ListSubscriptionsRequest) (*pb.ListSubscriptionsResponse, error) {
+	list, err := h.subscribe.ListSubscriptionStats(ctx, req.TopicName)
+	if err != nil {
+		return nil, errInternal(err)
+	}
+	subs := make([]*pb.SubscriptionInfo, 0, len(list))
+	now := time.Now()
+	for _, st := range list {
+		if !h.acl.CanAccess(ctx, domain.ACLGroup, st.Subscription.ConsumerGroup) {
+			continue
+		}
+		subs = append(subs, subscriptionInfo(st, now))
+	}
+	return &pb.ListSubscriptionsResponse{Subscriptions: subs}, nil
+}
+
+func (h *BrokerHandler) DescribeSubscription(ctx context.Context, req *pb.DescribeSubscriptionRequest) (*pb.DescribeSubscriptionResponse, error) {
+	if err := h.authorizeSubscription(ctx, req.SubscriptionId); err != nil {
+		return nil, err
+	}
+	st, err := h.subscribe.DescribeSubscription(ctx, req.SubscriptionId)
+	if err != nil {
+		if err == usecase.ErrSubscriptionNotFound {
+			return nil, errNotFound("subscription", req.SubscriptionId)
+		}
+		return nil, errInternal(err)
+	}
+	return &pb.DescribeSubscriptionResponse{Subscription: subscriptionInfo(st, time.Now())}, nil
+}
+
+func (h *BrokerHandler) SeekSubscription(ctx context.Context, req *pb.SeekSubscriptionRequest) (*pb.SeekSubscriptionResponse, error) {
+	if err := h.authorizeSubscription(ctx, req.SubscriptionId); err != nil {
+		return nil, err
+	}
+	seek := usecase.Seek{Offset: req.Offset, Timestamp: time.UnixMilli(req.TimestampUnixMs)}
+	switch req.Position {
+	case pb.SeekPosition_EARLIEST:
+		seek.Position = usecase.SeekEarliest
+	case pb.SeekPosition_LATEST:
+		seek.Position = usecase.SeekLatest
+	case pb.SeekPosition_OFFSET:
+		seek.Position = usecase.SeekOffset
+	case pb.SeekPosition_TIMESTAMP:
+		seek.Position = usecase.SeekTimestamp
+	default:
+		return nil, errInvalidArg("seek position is required")
+	}
+	offset, err := h.subscribe.SeekSubscription(ctx, req.SubscriptionId, seek)
+	if err != nil {
+		switch err {
+		case usecase.ErrSubscriptionNotFound:
+			return nil, errNotFound("subscription", req.SubscriptionId)
+		case usecase.ErrOffsetOutOfRange, usecase.ErrInvalidSeek:
+			return nil, errInvalidArg(err.Error())
+		}
+		return nil, errInternal(err)
+	}
+	return &pb.SeekSubscriptionResponse{Offset: offset}, nil
+}
+
+func subscriptionInfo(st *usecase.SubscriptionStats, now time.Time) *pb.SubscriptionInfo {
+	sub := st.Subscription
+	info := &pb.SubscriptionInfo{
+		SubscriptionId:    sub.ID,
+		Namespace:         sub.Namespace,
+		TopicName:         sub.TopicName,
+		QueueId:           sub.QueueID,
+		ConsumerGroup:     sub.ConsumerGroup,
+		DeliveryGuarantee: pb.DeliveryGuarantee_AT_MOST_ONCE,
+		CommittedOffset:   st.CommittedOffset,
+		HighWatermark:     st.HighWatermark,
+		Lag:               st.Lag,
+		PendingCount:      int32(st.Pending),
+	}
+	if sub.DeliveryGuarantee == domain.AtLeastOnce {
+		info.DeliveryGuarantee = pb.DeliveryGuarantee_AT_LEAST_ONCE
+	}
+	if !st.OldestPending.IsZero() {
+		info.OldestPendingAgeMs = now.Sub(st.OldestPending).Milliseconds()
+	}
+	if !sub.LastConsumedAt.IsZero() {
+		info.LastConsumedAtUnixMs = sub.LastConsumedAt.UnixMilli()
+	}
+	return info
+}
+
 func (h *BrokerHandler) Consume(ctx context.Context, req *pb.ConsumeRequest) (*pb.ConsumeResponse, error) {
+	if err := h.authorizeSubscription(ctx, req.SubscriptionId); err != nil {
+		return nil, err
+	}
 	max := int(req.MaxMessages)
 	if max <= 0 {
 		max = 10
 	}
-	msgs, err := h.consume.Consume(ctx, req.SubscriptionId, max)
+	n, release, err := h.reserveConsume(ctx, req.SubscriptionId, max)
+	if err != nil {
+		return nil, err
+	}
+	msgs, err := h.consume.Consume(ctx, req.SubscriptionId, n)
+	release(len(msgs))
 	if err != nil {
 		if err == usecase.ErrSubscriptionNotFound {
 			return nil, errNotFound("subscription", req.SubscriptionId)
 		}
 		return nil, errInternal(err)
 	}
-	out := make([]*pb.Message, 0, len(msgs))
-	for _, m := range msgs {
-		out = append(out, &pb.Message{
-			Id:         m.ID,
-			TopicName:  m.TopicName,
-			QueueId:    m.QueueID,
-			Payload:    m.Payload,
-			Key:        m.Key,
-			Headers:    m.Headers,
-			Offset:     m.Offset,
-			DeliveryId: m.DeliveryID,
-		})
+	return &pb.ConsumeResponse{Messages: toPbMessages(msgs)}, nil
+}
+
+// toPbMessages размещает все pb.Message одним блоком. Тело и заголовки не
+// копируются: сообщения брокера неизменяемы, а ответ только сериализуется.
+func toPbMessages(msgs []*domain.Message) []*pb.Message {
+	buf := make([]pb.Message, len(msgs))
+	out := make([]*pb.Message, len(msgs))
+	for i, m := range msgs {
+		fillPbMessage(&buf[i], m)
+		out[i] = &buf[i]
 	}
-	return &pb.ConsumeResponse{Messages: out}, nil
+	return out
+}
+
+func toPbMessage(m *domain.Message) *pb.Message {
+	out := &pb.Message{}
+	fillPbMessage(out, m)
+	return out
+}
+
+func fillPbMessage(out *pb.Message, m *domain.Message) {
+	out.Id = m.ID
+	out.TopicName = m.TopicName
+	out.QueueId = m.QueueID
+	out.Payload = m.Payload
+	out.Key = m.Key
+	out.Headers = m.Headers
+	out.Offset = m.Offset
+	out.DeliveryId = m.DeliveryID
+	out.CreatedAtUnixMs = m.CreatedAt.UnixMilli()
 }
 
 func (h *BrokerHandler) Ack(ctx context.Context, req *pb.AckRequest) (*pb.AckResponse, error) {
+	if err := h.authorizeSubscription(ctx, req.SubscriptionId); err != nil {
+		return nil, err
+	}
 	if err := h.consume.Ack(ctx, req.SubscriptionId, req.DeliveryId); err != nil {
-		if err == usecase.ErrSubscriptionNotFound {
-			return nil, errNotFound("subscription", req.SubscriptionId)
-		}
-		return nil, errInternal(err)
+		return nil, deliveryError(err, req.SubscriptionId, req.DeliveryId)
 	}
 	return &pb.AckResponse{}, nil
 }
+
+func (h *BrokerHandler) Nack(ctx context.Context, req *pb.NackRequest) (*pb.NackResponse, error) {
+	if err := h.authorizeSubscription(ctx, req.SubscriptionId); err != nil {
+		return nil, err
+	}
+	if err := h.consume.Nack(ctx, req.SubscriptionId, req.DeliveryId); err != nil {
+		return nil, deliveryError(err, req.SubscriptionId, req.DeliveryId)
+	}
+	return &pb.NackResponse{}, nil
+}
+
+func (h *BrokerHandler) ExtendAckDeadline(ctx context.Context, req *pb.ExtendAckDeadlineRequest) (*pb.ExtendAckDeadlineResponse, error) {
+	if req.ExtensionSeconds <= 0 {
+		return nil, errInvalidArg("extension_seconds must be positive")
+	}
+	if err := h.authorizeSubscription(ctx, req.SubscriptionId); err != nil {
+		return nil, err
+	}
+	extension := time.Duration(req.ExtensionSeconds) * time.Second
+	if err := h.consume.ExtendAckDeadline(ctx, req.SubscriptionId, req.DeliveryId, extension); err != nil {
+		return nil, deliveryError(err, req.SubscriptionId, req.DeliveryId)
+	}
+	return &pb.ExtendAckDeadlineResponse{}, nil
+}
+
+func deliveryError(err error, subscriptionID, deliveryID string) error {
+	switch err {
+	case usecase.ErrSubscriptionNotFound:
+		return errNotFound("subscription", subscriptionID)
+	case usecase.ErrDeliveryNotFound:
+		return errNotFound("delivery", deliveryID)
+	}
+	return errInternal(err)
+}
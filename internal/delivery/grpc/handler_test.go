@@ -13,17 +13,45 @@ import (
 )
 
 func newTestHandler(t *testing.T) *BrokerHandler {
+	t.Helper()
+	return newTestHandlerWithBudget(t, usecase.MemoryBudget{})
+}
+
+func newTestHandlerWithBudget(t *testing.T, budget usecase.MemoryBudget) *BrokerHandler {
+	t.Helper()
+	return newTestHandlerWith(t, testPolicies{budget: budget})
+}
+
+// testPolicies — настройки use case тестового обработчика; нулевые — без
+// ограничений.
+type testPolicies struct {
+	budget  usecase.MemoryBudget
+	acl     usecase.ACLPolicy
+	tenancy usecase.TenantPolicy
+	quotas  usecase.QuotaPolicy
+}
+
+func newTestHandlerWith(t *testing.T, p testPolicies) *BrokerHandler {
 	t.Helper()
 	topics := memory.NewTopicRepository()
 	queues := memory.NewQueueRepository()
 	msgs := memory.NewMessageRepository()
 	subs := memory.NewSubscriptionRepository()
 	pending := memory.NewPendingDeliveryRepository()
-	topicUC := usecase.NewTopicUseCase(topics, queues)
-	pub := usecase.NewPublishUseCase(topics, queues, msgs, 1024*1024)
-	subUC := usecase.NewSubscriptionUseCase(subs, topics, queues, 30)
-	consumeUC := usecase.NewConsumeUseCase(subs, msgs, pending)
-	return NewBrokerHandler(topicUC, pub, subUC, consumeUC)
+	topicUC := usecase.NewTopicUseCase(topics, queues, msgs, subs, pending)
+	pub := usecase.NewPublishUseCase(topics, queues, msgs, 1024*1024, p.budget, nil)
+	subUC := usecase.NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := usecase.NewConsumeUseCase(subs, msgs, pending, nil)
+	messageUC := usecase.NewMessageUseCase(topics, queues, msgs)
+	tenants, err := usecase.NewTenantUseCase(topics, subs, p.tenancy)
+	if err != nil {
+		t.Fatalf("NewTenantUseCase: %v", err)
+	}
+	quotas, err := usecase.NewQuotaUseCase(topics, subs, p.quotas)
+	if err != nil {
+		t.Fatalf("NewQuotaUseCase: %v", err)
+	}
+	return NewBrokerHandler(topicUC, pub, subUC, consumeUC, messageUC, usecase.NewACLUseCase(memory.NewACLRepository(), p.acl), tenants, quotas)
 }
 
 func TestBrokerHandler_CreateTopic(t *testing.T) {
@@ -142,3 +170,282 @@ func TestBrokerHandler_Subscribe_deliveryGuarantee(t *testing.T) {
 		t.Error("expected subscription id")
 	}
 }
+
+func TestBrokerHandler_PublishBatch(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHandler(t)
+	_, _ = h.CreateTopic(ctx, &pb.CreateTopicRequest{Name: "orders", RetentionMessages: 10000})
+
+	resp, err := h.PublishBatch(ctx, &pb.PublishBatchRequest{Messages: []*pb.PublishRequest{
+		{TopicName: "orders", Payload: []byte("a")},
+		{TopicName: "missing", Payload: []byte("b")},
+		{TopicName: "orders", Payload: []byte("c")},
+	}})
+	if err != nil {
+		t.Fatalf("PublishBatch: %v", err)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("want 3 results, got %d", len(resp.Results))
+	}
+	if resp.Results[0].Code != 0 || resp.Results[0].Offset != 0 || resp.Results[2].Offset != 1 {
+		t.Errorf("successful results: %+v %+v", resp.Results[0], resp.Results[2])
+	}
+	if codes.Code(resp.Results[1].Code) != codes.NotFound || resp.Results[1].Error == "" {
+		t.Errorf("failed result: %+v", resp.Results[1])
+	}
+}
+
+func TestBrokerHandler_Nack_ExtendAckDeadline(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHandler(t)
+	_, _ = h.CreateTopic(ctx, &pb.CreateTopicRequest{Name: "orders", RetentionMessages: 10000})
+	sub, _ := h.Subscribe(ctx, &pb.SubscribeRequest{
+		TopicName:         "orders",
+		ConsumerGroup:     "g1",
+		DeliveryGuarantee: pb.DeliveryGuarantee_AT_LEAST_ONCE,
+	})
+	_, _ = h.Publish(ctx, &pb.PublishRequest{TopicName: "orders", Payload: []byte("m1")})
+
+	consumed, _ := h.Consume(ctx, &pb.ConsumeRequest{SubscriptionId: sub.SubscriptionId, MaxMessages: 10})
+	deliveryID := consumed.Messages[0].DeliveryId
+
+	if _, err := h.ExtendAckDeadline(ctx, &pb.ExtendAckDeadlineRequest{
+		SubscriptionId: sub.SubscriptionId, DeliveryId: deliveryID, ExtensionSeconds: 60,
+	}); err != nil {
+		t.Fatalf("ExtendAckDeadline: %v", err)
+	}
+	_, err := h.ExtendAckDeadline(ctx, &pb.ExtendAckDeadlineRequest{SubscriptionId: sub.SubscriptionId, DeliveryId: deliveryID})
+	if st, _ := status.FromError(err); st.Code() != codes.InvalidArgument {
+		t.Errorf("zero extension: want InvalidArgument, got %v", err)
+	}
+
+	if _, err := h.Nack(ctx, &pb.NackRequest{SubscriptionId: sub.SubscriptionId, DeliveryId: deliveryID}); err != nil {
+		t.Fatalf("Nack: %v", err)
+	}
+	again, _ := h.Consume(ctx, &pb.ConsumeRequest{SubscriptionId: sub.SubscriptionId, MaxMessages: 10})
+	if len(again.Messages) != 1 || again.Messages[0].DeliveryId != deliveryID {
+		t.Fatalf("nacked message should be redelivered, got %+v", again.Messages)
+	}
+
+	_, _ = h.Ack(ctx, &pb.AckRequest{SubscriptionId: sub.SubscriptionId, DeliveryId: deliveryID})
+	_, err = h.Ack(ctx, &pb.AckRequest{SubscriptionId: sub.SubscriptionId, DeliveryId: deliveryID})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound {
+		t.Errorf("double ack: want NotFound, got %v", err)
+	}
+}
+
+func TestBrokerHandler_DeleteTopic(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHandler(t)
+
+	_, _ = h.CreateTopic(ctx, &pb.CreateTopicRequest{Name: "orders"})
+	_, _ = h.Subscribe(ctx, &pb.SubscribeRequest{TopicName: "orders", QueueId: "0", ConsumerGroup: "g1"})
+
+	_, err := h.DeleteTopic(ctx, &pb.DeleteTopicRequest{Name: "orders"})
+	if st, _ := status.FromError(err); st.Code() != codes.FailedPrecondition {
+		t.Fatalf("want FailedPrecondition, got %v", err)
+	}
+	if _, err := h.DeleteTopic(ctx, &pb.DeleteTopicRequest{Name: "orders", Force: true}); err != nil {
+		t.Fatalf("DeleteTopic force: %v", err)
+	}
+	_, err = h.DeleteTopic(ctx, &pb.DeleteTopicRequest{Name: "orders"})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound {
+		t.Errorf("want NotFound, got %v", err)
+	}
+}
+
+func TestBrokerHandler_DeleteQueue_PurgeQueue_Unsubscribe(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHandler(t)
+
+	_, _ = h.CreateTopic(ctx, &pb.CreateTopicRequest{Name: "orders"})
+	_, _ = h.CreateQueue(ctx, &pb.CreateQueueRequest{TopicName: "orders", QueueId: "1"})
+	_, _ = h.Publish(ctx, &pb.PublishRequest{TopicName: "orders", QueueId: "1", Payload: []byte("a")})
+	sub, _ := h.Subscribe(ctx, &pb.SubscribeRequest{TopicName: "orders", QueueId: "1", ConsumerGroup: "g1"})
+
+	purged, err := h.PurgeQueue(ctx, &pb.PurgeQueueRequest{TopicName: "orders", QueueId: "1"})
+	if err != nil || purged.LogStartOffset != 1 {
+		t.Fatalf("PurgeQueue: %v %+v", err, purged)
+	}
+	_, err = h.DeleteQueue(ctx, &pb.DeleteQueueRequest{TopicName: "orders", QueueId: "1"})
+	if st, _ := status.FromError(err); st.Code() != codes.FailedPrecondition {
+		t.Fatalf("want FailedPrecondition, got %v", err)
+	}
+
+	if _, err := h.Unsubscribe(ctx, &pb.UnsubscribeRequest{SubscriptionId: sub.SubscriptionId}); err != nil {
+		t.Fatalf("Unsubscribe: %v", err)
+	}
+	_, err = h.Unsubscribe(ctx, &pb.UnsubscribeRequest{SubscriptionId: sub.SubscriptionId})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound {
+		t.Errorf("second Unsubscribe: want NotFound, got %v", err)
+	}
+
+	if _, err := h.DeleteQueue(ctx, &pb.DeleteQueueRequest{TopicName: "orders", QueueId: "1"}); err != nil {
+		t.Fatalf("DeleteQueue: %v", err)
+	}
+	_, err = h.PurgeQueue(ctx, &pb.PurgeQueueRequest{TopicName: "orders", QueueId: "1"})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound {
+		t.Errorf("purge deleted queue: want NotFound, got %v", err)
+	}
+}
+
+func TestBrokerHandler_ListSubscriptions_DescribeSubscription(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHandler(t)
+
+	_, _ = h.CreateTopic(ctx, &pb.CreateTopicRequest{Name: "orders"})
+	for i := 0; i < 3; i++ {
+		_, _ = h.Publish(ctx, &pb.PublishRequest{TopicName: "orders", QueueId: "0", Payload: []byte("m")})
+	}
+	sub, _ := h.Subscribe(ctx, &pb.SubscribeRequest{TopicName: "orders", QueueId: "0", ConsumerGroup: "g2",
+		DeliveryGuarantee: pb.DeliveryGuarantee_AT_LEAST_ONCE})
+	_, _ = h.Subscribe(ctx, &pb.SubscribeRequest{TopicName: "orders", QueueId: "0", ConsumerGroup: "g1"})
+	_, _ = h.Consume(ctx, &pb.ConsumeRequest{SubscriptionId: sub.SubscriptionId, MaxMessages: 1})
+
+	list, err := h.ListSubscriptions(ctx, &pb.ListSubscriptionsRequest{TopicName: "orders"})
+	if err != nil || len(list.Subscriptions) != 2 {
+		t.Fatalf("ListSubscriptions: %v %+v", err, list)
+	}
+	if list.Subscriptions[0].ConsumerGroup != "g1" || list.Subscriptions[1].ConsumerGroup != "g2" {
+		t.Errorf("want order g1, g2; got %+v", list.Subscriptions)
+	}
+
+	resp, err := h.DescribeSubscription(ctx, &pb.DescribeSubscriptionRequest{SubscriptionId: sub.SubscriptionId})
+	if err != nil {
+		t.Fatalf("DescribeSubscription: %v", err)
+	}
+	info := resp.Subscription
+	if info.DeliveryGuarantee != pb.DeliveryGuarantee_AT_LEAST_ONCE || info.HighWatermark != 3 ||
+		info.CommittedOffset != 0 || info.Lag != 3 || info.PendingCount != 1 || info.LastConsumedAtUnixMs == 0 {
+		t.Errorf("got %+v", info)
+	}
+
+	_, err = h.DescribeSubscription(ctx, &pb.DescribeSubscriptionRequest{SubscriptionId: "missing"})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound {
+		t.Errorf("want NotFound, got %v", err)
+	}
+}
+
+func TestBrokerHandler_SeekSubscription(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHandler(t)
+
+	_, _ = h.CreateTopic(ctx, &pb.CreateTopicRequest{Name: "orders"})
+	for i := 0; i < 3; i++ {
+		_, _ = h.Publish(ctx, &pb.PublishRequest{TopicName: "orders", QueueId: "0", Payload: []byte("m")})
+	}
+	sub, _ := h.Subscribe(ctx, &pb.SubscribeRequest{TopicName: "orders", QueueId: "0", ConsumerGroup: "g1"})
+
+	resp, err := h.SeekSubscription(ctx, &pb.SeekSubscriptionRequest{SubscriptionId: sub.SubscriptionId, Position: pb.SeekPosition_LATEST})
+	if err != nil || resp.Offset != 3 {
+		t.Fatalf("seek latest: %v %+v", err, resp)
+	}
+	resp, err = h.SeekSubscription(ctx, &pb.SeekSubscriptionRequest{SubscriptionId: sub.SubscriptionId, Position: pb.SeekPosition_OFFSET, Offset: 1})
+	if err != nil || resp.Offset != 1 {
+		t.Fatalf("seek offset: %v %+v", err, resp)
+	}
+	got, _ := h.Consume(ctx, &pb.ConsumeRequest{SubscriptionId: sub.SubscriptionId, MaxMessages: 10})
+	if len(got.Messages) != 2 || got.Messages[0].Offset != 1 {
+		t.Errorf("consume after seek: %+v", got.Messages)
+	}
+
+	for _, req := range []*pb.SeekSubscriptionRequest{
+		{SubscriptionId: sub.SubscriptionId},
+		{SubscriptionId: sub.SubscriptionId, Position: pb.SeekPosition_OFFSET, Offset: 10},
+	} {
+		_, err = h.SeekSubscription(ctx, req)
+		if st, _ := status.FromError(err); st.Code() != codes.InvalidArgument {
+			t.Errorf("%+v: want InvalidArgument, got %v", req, err)
+		}
+	}
+	_, err = h.SeekSubscription(ctx, &pb.SeekSubscriptionRequest{SubscriptionId: "missing", Position: pb.SeekPosition_EARLIEST})
+	if st, _ := status.FromError(err); st.Code() != codes.NotFound {
+		t.Errorf("want NotFound, got %v", err)
+	}
+}
+
+func TestBrokerHandler_Subscribe_startPosition(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHandler(t)
+
+	_, _ = h.CreateTopic(ctx, &pb.CreateTopicRequest{Name: "orders"})
+	_, _ = h.Publish(ctx, &pb.PublishRequest{TopicName: "orders", QueueId: "0", Payload: []byte("old")})
+
+	sub, err := h.Subscribe(ctx, &pb.SubscribeRequest{TopicName: "orders", QueueId: "0", ConsumerGroup: "g1",
+		StartPosition: pb.SeekPosition_LATEST})
+	if err != nil || sub.Offset != 1 {
+		t.Fatalf("Subscribe LATEST: %v %+v", err, sub)
+	}
+	sub, err = h.Subscribe(ctx, &pb.SubscribeRequest{TopicName: "orders", QueueId: "0", ConsumerGroup: "g2",
+		StartPosition: pb.SeekPosition_TIMESTAMP, StartTimestampUnixMs: 1})
+	if err != nil || sub.Offset != 0 {
+		t.Fatalf("Subscribe TIMESTAMP: %v %+v", err, sub)
+	}
+	_, err = h.Subscribe(ctx, &pb.SubscribeRequest{TopicName: "orders", QueueId: "0", ConsumerGroup: "g3",
+		StartPosition: pb.SeekPosition_OFFSET})
+	if st, _ := status.FromError(err); st.Code() != codes.InvalidArgument {
+		t.Errorf("Subscribe OFFSET: want InvalidArgument, got %v", err)
+	}
+}
+
+func TestBrokerHandler_BrowseMessages_GetMessage(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHandler(t)
+
+	_, _ = h.CreateTopic(ctx, &pb.CreateTopicRequest{Name: "orders"})
+	var ids []string
+	for _, k := range []string{"a", "b", "a"} {
+		resp, _ := h.Publish(ctx, &pb.PublishRequest{TopicName: "orders", QueueId: "0", Key: k, Payload: []byte(k)})
+		ids = append(ids, resp.MessageId)
+	}
+
+	resp, err := h.BrowseMessages(ctx, &pb.BrowseMessagesRequest{TopicName: "orders", QueueId: "0",
+		Filter: &pb.MessageFilter{Key: "a"}})
+	if err != nil || len(resp.Messages) != 2 || resp.NextOffset != 3 {
+		t.Fatalf("BrowseMessages: %v %+v", err, resp)
+	}
+	if resp.Messages[1].Id != ids[2] || resp.Messages[1].CreatedAtUnixMs == 0 {
+		t.Errorf("got %+v", resp.Messages[1])
+	}
+
+	got, err := h.GetMessage(ctx, &pb.GetMessageRequest{TopicName: "orders", QueueId: "0", MessageId: ids[1]})
+	if err != nil || got.Message.Key != "b" || got.Message.Offset != 1 {
+		t.Errorf("GetMessage: %v %+v", err, got)
+	}
+
+	for _, req := range []*pb.GetMessageRequest{
+		{TopicName: "orders", QueueId: "0", MessageId: "missing"},
+		{TopicName: "orders", QueueId: "7", MessageId: ids[0]},
+		{TopicName: "missing", QueueId: "0", MessageId: ids[0]},
+	} {
+		_, err := h.GetMessage(ctx, req)
+		if st, _ := status.FromError(err); st.Code() != codes.NotFound {
+			t.Errorf("%+v: want NotFound, got %v", req, err)
+		}
+	}
+}
+
+func TestBrokerHandler_GetMemoryUsage(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHandlerWithBudget(t, usecase.MemoryBudget{TopicLimit: 1000})
+	_, _ = h.CreateTopic(ctx, &pb.CreateTopicRequest{Name: "orders"})
+
+	if _, err := h.Publish(ctx, &pb.PublishRequest{TopicName: "orders", Payload: []byte("hello")}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	_, err := h.Publish(ctx, &pb.PublishRequest{TopicName: "orders", Payload: make([]byte, 1000)})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("over budget: want ResourceExhausted, got %v", err)
+	}
+
+	resp, err := h.GetMemoryUsage(ctx, &pb.GetMemoryUsageRequest{})
+	if err != nil {
+		t.Fatalf("GetMemoryUsage: %v", err)
+	}
+	if resp.UsedBytes == 0 || resp.LimitBytes != 0 || resp.OverflowPolicy != "reject" {
+		t.Errorf("got %+v", resp)
+	}
+	if len(resp.Topics) != 1 || resp.Topics[0].UsedBytes != resp.UsedBytes || resp.Topics[0].LimitBytes != 1000 {
+		t.Errorf("topics: %+v", resp.Topics)
+	}
+}
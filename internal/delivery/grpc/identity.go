@@ -0,0 +1,35 @@
+package grpc
+
+import (
+	"context"
+
+	"queue-service/internal/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func identityUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if id, ok := tlsIdentity(ctx); ok {
+		ctx = auth.WithIdentity(ctx, id)
+	}
+	return handler(ctx, req)
+}
+
+// tlsIdentity возвращает идентичность из проверенного сертификата клиента.
+func tlsIdentity(ctx context.Context) (auth.Identity, bool) {
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			return auth.PeerIdentity(tlsInfo.State)
+		}
+	}
+	return auth.Identity{}, false
+}
+
+// IdentityUnaryInterceptor возвращает параметр сервера, который кладёт в
+// контекст запроса идентичность клиента из его сертификата (mTLS); use case
+// получают её через auth.FromContext.
+func IdentityUnaryInterceptor() grpc.ServerOption {
+	return grpc.ChainUnaryInterceptor(identityUnaryInterceptor)
+}
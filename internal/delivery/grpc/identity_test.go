@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"queue-service/internal/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func TestIdentityUnaryInterceptor(t *testing.T) {
+	leaf := &x509.Certificate{Subject: pkix.Name{CommonName: "orders-svc", Organization: []string{"shop"}}}
+	state := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{leaf}}}
+	info := &grpc.UnaryServerInfo{FullMethod: "/broker.Broker/Publish"}
+
+	for name, tc := range map[string]struct {
+		ctx  context.Context
+		want string
+	}{
+		"mtls":      {peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}}), "orders-svc"},
+		"tls":       {peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{}}), ""},
+		"plaintext": {peer.NewContext(context.Background(), &peer.Peer{}), ""},
+	} {
+		var got string
+		_, _ = identityUnaryInterceptor(tc.ctx, nil, info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+			if id, ok := auth.FromContext(ctx); ok {
+				got = id.Name
+			}
+			return nil, nil
+		})
+		if got != tc.want {
+			t.Errorf("%s: identity %q, want %q", name, got, tc.want)
+		}
+	}
+}
@@ -2,28 +2,126 @@ package grpc
 
 import (
 	"context"
-	"log"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"queue-service/internal/logging"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
-func loggingUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+// requestIDHeader — метаданные с идентификатором запроса. Если клиент его не
+// передал, брокер создаёт свой; в обоих случаях он возвращается в заголовках
+// ответа.
+const requestIDHeader = "x-request-id"
+
+// maxRequestIDLen ограничивает идентификатор, пришедший от клиента.
+const maxRequestIDLen = 128
+
+// LogSampling ограничивает логи частых RPC: из успешных вызовов методов
+// Methods пишется каждый Every-й. Ошибки пишутся всегда.
+type LogSampling struct {
+	Methods []string // полные имена, например /broker.Broker/Publish
+	Every   int
+}
+
+type rpcLogger struct {
+	logger *slog.Logger
+	every  uint64
+	// calls — счётчики успешных вызовов методов с сэмплированием.
+	calls map[string]*atomic.Uint64
+}
+
+func newRPCLogger(logger *slog.Logger, sampling LogSampling) *rpcLogger {
+	l := &rpcLogger{logger: logger, calls: make(map[string]*atomic.Uint64)}
+	if sampling.Every > 1 {
+		l.every = uint64(sampling.Every)
+		for _, m := range sampling.Methods {
+			l.calls[m] = new(atomic.Uint64)
+		}
+	}
+	return l
+}
+
+func (l *rpcLogger) intercept(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	begin := time.Now()
+	id := requestID(ctx)
+	ctx = logging.WithRequestID(ctx, id)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, id))
+
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err)
+	level := rpcLevel(code)
+	if !l.logger.Enabled(ctx, level) {
+		return resp, err
+	}
+	sampled := false
+	if code == codes.OK {
+		if n, ok := l.calls[info.FullMethod]; ok {
+			if n.Add(1)%l.every != 1 {
+				return resp, err
+			}
+			sampled = true
+		}
+	}
 	peerAddr := "unknown"
 	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
 		peerAddr = p.Addr.String()
 	}
-	log.Printf("[gRPC] %s from %s", info.FullMethod, peerAddr)
-	resp, err := handler(ctx, req)
+	attrs := make([]slog.Attr, 0, 6)
+	attrs = append(attrs,
+		slog.String("method", info.FullMethod),
+		slog.String("peer", peerAddr),
+		slog.Float64("duration_ms", float64(time.Since(begin).Microseconds())/1000),
+		slog.String("code", code.String()),
+	)
+	if sampled {
+		attrs = append(attrs, slog.Int("sample_every", int(l.every)))
+	}
 	if err != nil {
-		log.Printf("[gRPC] %s error: %v", info.FullMethod, err)
-		return resp, err
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+	l.logger.LogAttrs(ctx, level, "rpc", attrs...)
+	return resp, err
+}
+
+// rpcLevel — уровень записи о вызове: ошибки брокера — error, ошибки
+// клиента (неверный аргумент, нет ресурса, отмена) — warn.
+func rpcLevel(code codes.Code) slog.Level {
+	switch code {
+	case codes.OK:
+		return slog.LevelInfo
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unimplemented:
+		return slog.LevelError
+	}
+	return slog.LevelWarn
+}
+
+// requestID берёт идентификатор запроса из метаданных или создаёт новый.
+func requestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(requestIDHeader); len(v) > 0 && v[0] != "" && len(v[0]) <= maxRequestIDLen {
+			return v[0]
+		}
 	}
-	log.Printf("[gRPC] %s ok", info.FullMethod)
-	return resp, nil
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
 }
 
-// LoggingUnaryInterceptor возвращает параметр сервера, который регистрирует каждый унарный RPC-вызов.
-func LoggingUnaryInterceptor() grpc.ServerOption {
-	return grpc.UnaryInterceptor(loggingUnaryInterceptor)
+// LoggingUnaryInterceptor возвращает параметр сервера, который пишет по одной
+// записи на унарный RPC-вызов: метод, адрес клиента, длительность, код ответа
+// и request_id. request_id попадает в контекст запроса, так что его видят и
+// логи use case. Перехватчик добавляется в цепочку и сочетается с
+// MetricsUnaryInterceptor.
+func LoggingUnaryInterceptor(logger *slog.Logger, sampling LogSampling) grpc.ServerOption {
+	return grpc.ChainUnaryInterceptor(newRPCLogger(logger, sampling).intercept)
 }
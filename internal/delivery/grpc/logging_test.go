@@ -0,0 +1,102 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"queue-service/internal/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+func TestLoggingInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := logging.New(&buf, logging.Config{Level: "info"})
+	l := newRPCLogger(logger, LogSampling{})
+	info := &grpc.UnaryServerInfo{FullMethod: "/broker.Broker/Ack"}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-1"))
+
+	var seen string
+	_, _ = l.intercept(ctx, nil, info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		seen = logging.RequestID(ctx)
+		return nil, errNotFound("delivery", "d1")
+	})
+	if seen != "req-1" {
+		t.Errorf("handler request id %q", seen)
+	}
+
+	recs := decodeRecords(t, &buf)
+	if len(recs) != 1 {
+		t.Fatalf("want one record per RPC, got %d", len(recs))
+	}
+	rec := recs[0]
+	if rec["level"] != "WARN" || rec["method"] != "/broker.Broker/Ack" || rec["code"] != "NotFound" ||
+		rec["request_id"] != "req-1" || rec["peer"] != "unknown" || rec["duration_ms"] == nil || rec["error"] == nil {
+		t.Errorf("record %v", rec)
+	}
+}
+
+func TestLoggingInterceptor_sampling(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := logging.New(&buf, logging.Config{Level: "info"})
+	l := newRPCLogger(logger, LogSampling{Methods: []string{"/broker.Broker/Publish"}, Every: 10})
+	ok := func(context.Context, interface{}) (interface{}, error) { return nil, nil }
+	fail := func(context.Context, interface{}) (interface{}, error) { return nil, errInternal(context.Canceled) }
+	publish := &grpc.UnaryServerInfo{FullMethod: "/broker.Broker/Publish"}
+	other := &grpc.UnaryServerInfo{FullMethod: "/broker.Broker/CreateTopic"}
+
+	for i := 0; i < 25; i++ {
+		_, _ = l.intercept(context.Background(), nil, publish, ok)
+	}
+	_, _ = l.intercept(context.Background(), nil, publish, fail)
+	_, _ = l.intercept(context.Background(), nil, other, ok)
+
+	var sampled, errors, others int
+	for _, rec := range decodeRecords(t, &buf) {
+		switch {
+		case rec["method"] == "/broker.Broker/CreateTopic":
+			others++
+		case rec["level"] == "ERROR":
+			errors++
+		default:
+			sampled++
+			if rec["sample_every"] != float64(10) {
+				t.Errorf("sampled record without sample_every: %v", rec)
+			}
+		}
+	}
+	if sampled != 3 || errors != 1 || others != 1 {
+		t.Errorf("sampled=%d errors=%d others=%d, want 3, 1, 1", sampled, errors, others)
+	}
+}
+
+func TestLoggingInterceptor_level(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := logging.New(&buf, logging.Config{Level: "warn"})
+	l := newRPCLogger(logger, LogSampling{})
+	_, _ = l.intercept(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/broker.Broker/Publish"},
+		func(context.Context, interface{}) (interface{}, error) { return nil, nil })
+	if buf.Len() != 0 {
+		t.Errorf("successful RPC logged at level warn: %s", buf.String())
+	}
+}
@@ -0,0 +1,28 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RPCMetrics учитывает завершённые вызовы gRPC (реализация — internal/metrics).
+type RPCMetrics interface {
+	RPCCompleted(method string, code codes.Code)
+}
+
+func metricsUnaryInterceptor(m RPCMetrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		m.RPCCompleted(info.FullMethod, status.Code(err))
+		return resp, err
+	}
+}
+
+// MetricsUnaryInterceptor возвращает параметр сервера, который считает унарные
+// RPC-вызовы по методу и коду ответа.
+func MetricsUnaryInterceptor(m RPCMetrics) grpc.ServerOption {
+	return grpc.ChainUnaryInterceptor(metricsUnaryInterceptor(m))
+}
@@ -0,0 +1,30 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type rpcCounter map[string]codes.Code
+
+func (c rpcCounter) RPCCompleted(method string, code codes.Code) { c[method] = code }
+
+func TestMetricsUnaryInterceptor(t *testing.T) {
+	counter := rpcCounter{}
+	intercept := metricsUnaryInterceptor(counter)
+	info := &grpc.UnaryServerInfo{FullMethod: "/broker.Broker/Ack"}
+
+	_, err := intercept(context.Background(), nil, info, func(context.Context, interface{}) (interface{}, error) {
+		return nil, errNotFound("delivery", "d1")
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("handler error must pass through, got %v", err)
+	}
+	if counter["/broker.Broker/Ack"] != codes.NotFound {
+		t.Errorf("recorded %v", counter)
+	}
+}
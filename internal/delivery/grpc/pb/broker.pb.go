@@ -70,6 +70,165 @@ func (DeliveryGuarantee) EnumDescriptor() ([]byte, []int) {
 	return file_broker_proto_rawDescGZIP(), []int{0}
 }
 
+type SeekPosition int32
+
+const (
+	SeekPosition_SEEK_POSITION_UNSPECIFIED SeekPosition = 0
+	SeekPosition_EARLIEST                  SeekPosition = 1
+	SeekPosition_LATEST                    SeekPosition = 2
+	SeekPosition_OFFSET                    SeekPosition = 3
+	SeekPosition_TIMESTAMP                 SeekPosition = 4
+)
+
+// Enum value maps for SeekPosition.
+var (
+	SeekPosition_name = map[int32]string{
+		0: "SEEK_POSITION_UNSPECIFIED",
+		1: "EARLIEST",
+		2: "LATEST",
+		3: "OFFSET",
+		4: "TIMESTAMP",
+	}
+	SeekPosition_value = map[string]int32{
+		"SEEK_POSITION_UNSPECIFIED": 0,
+		"EARLIEST":                  1,
+		"LATEST":                    2,
+		"OFFSET":                    3,
+		"TIMESTAMP":                 4,
+	}
+)
+
+func (x SeekPosition) Enum() *SeekPosition {
+	p := new(SeekPosition)
+	*p = x
+	return p
+}
+
+func (x SeekPosition) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SeekPosition) Descriptor() protoreflect.EnumDescriptor {
+	return file_broker_proto_enumTypes[1].Descriptor()
+}
+
+func (SeekPosition) Type() protoreflect.EnumType {
+	return &file_broker_proto_enumTypes[1]
+}
+
+func (x SeekPosition) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SeekPosition.Descriptor instead.
+func (SeekPosition) EnumDescriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{1}
+}
+
+type AclResourceType int32
+
+const (
+	AclResourceType_ACL_RESOURCE_TYPE_UNSPECIFIED AclResourceType = 0
+	AclResourceType_ACL_RESOURCE_TOPIC            AclResourceType = 1
+	AclResourceType_ACL_RESOURCE_GROUP            AclResourceType = 2 // группа потребителей
+	AclResourceType_ACL_RESOURCE_CLUSTER          AclResourceType = 3 // брокер целиком: правила ACL, память
+)
+
+// Enum value maps for AclResourceType.
+var (
+	AclResourceType_name = map[int32]string{
+		0: "ACL_RESOURCE_TYPE_UNSPECIFIED",
+		1: "ACL_RESOURCE_TOPIC",
+		2: "ACL_RESOURCE_GROUP",
+		3: "ACL_RESOURCE_CLUSTER",
+	}
+	AclResourceType_value = map[string]int32{
+		"ACL_RESOURCE_TYPE_UNSPECIFIED": 0,
+		"ACL_RESOURCE_TOPIC":            1,
+		"ACL_RESOURCE_GROUP":            2,
+		"ACL_RESOURCE_CLUSTER":          3,
+	}
+)
+
+func (x AclResourceType) Enum() *AclResourceType {
+	p := new(AclResourceType)
+	*p = x
+	return p
+}
+
+func (x AclResourceType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AclResourceType) Descriptor() protoreflect.EnumDescriptor {
+	return file_broker_proto_enumTypes[2].Descriptor()
+}
+
+func (AclResourceType) Type() protoreflect.EnumType {
+	return &file_broker_proto_enumTypes[2]
+}
+
+func (x AclResourceType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AclResourceType.Descriptor instead.
+func (AclResourceType) EnumDescriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{2}
+}
+
+type AclOperation int32
+
+const (
+	AclOperation_ACL_OPERATION_UNSPECIFIED AclOperation = 0
+	AclOperation_ACL_OPERATION_PUBLISH     AclOperation = 1
+	AclOperation_ACL_OPERATION_CONSUME     AclOperation = 2
+	AclOperation_ACL_OPERATION_ADMIN       AclOperation = 3 // включает publish и consume
+)
+
+// Enum value maps for AclOperation.
+var (
+	AclOperation_name = map[int32]string{
+		0: "ACL_OPERATION_UNSPECIFIED",
+		1: "ACL_OPERATION_PUBLISH",
+		2: "ACL_OPERATION_CONSUME",
+		3: "ACL_OPERATION_ADMIN",
+	}
+	AclOperation_value = map[string]int32{
+		"ACL_OPERATION_UNSPECIFIED": 0,
+		"ACL_OPERATION_PUBLISH":     1,
+		"ACL_OPERATION_CONSUME":     2,
+		"ACL_OPERATION_ADMIN":       3,
+	}
+)
+
+func (x AclOperation) Enum() *AclOperation {
+	p := new(AclOperation)
+	*p = x
+	return p
+}
+
+func (x AclOperation) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AclOperation) Descriptor() protoreflect.EnumDescriptor {
+	return file_broker_proto_enumTypes[3].Descriptor()
+}
+
+func (AclOperation) Type() protoreflect.EnumType {
+	return &file_broker_proto_enumTypes[3]
+}
+
+func (x AclOperation) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AclOperation.Descriptor instead.
+func (AclOperation) EnumDescriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{3}
+}
+
 type CreateTopicRequest struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	Name              string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -278,6 +437,8 @@ func (x *CreateQueueResponse) GetQueueId() string {
 	return ""
 }
 
+// Топики пространства имён клиента (метаданные x-namespace или привязка
+// клиента к арендатору).
 type ListTopicsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -362,6 +523,7 @@ type TopicInfo struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
 	Name              string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	RetentionMessages int32                  `protobuf:"varint,2,opt,name=retention_messages,json=retentionMessages,proto3" json:"retention_messages,omitempty"`
+	Namespace         string                 `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
 }
@@ -410,6 +572,13 @@ func (x *TopicInfo) GetRetentionMessages() int32 {
 	return 0
 }
 
+func (x *TopicInfo) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
 type ListQueuesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TopicName     string                 `protobuf:"bytes,1,opt,name=topic_name,json=topicName,proto3" json:"topic_name,omitempty"`
@@ -550,31 +719,30 @@ func (x *QueueInfo) GetQueueId() string {
 	return ""
 }
 
-type PublishRequest struct {
+// Удаляет топик вместе с очередями, сообщениями и подписками.
+// Без force при наличии подписок возвращается FAILED_PRECONDITION.
+type DeleteTopicRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TopicName     string                 `protobuf:"bytes,1,opt,name=topic_name,json=topicName,proto3" json:"topic_name,omitempty"`
-	QueueId       string                 `protobuf:"bytes,2,opt,name=queue_id,json=queueId,proto3" json:"queue_id,omitempty"`
-	Payload       []byte                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
-	Key           string                 `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`
-	Headers       map[string]string      `protobuf:"bytes,5,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Force         bool                   `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PublishRequest) Reset() {
-	*x = PublishRequest{}
+func (x *DeleteTopicRequest) Reset() {
+	*x = DeleteTopicRequest{}
 	mi := &file_broker_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PublishRequest) String() string {
+func (x *DeleteTopicRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PublishRequest) ProtoMessage() {}
+func (*DeleteTopicRequest) ProtoMessage() {}
 
-func (x *PublishRequest) ProtoReflect() protoreflect.Message {
+func (x *DeleteTopicRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_broker_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -586,68 +754,45 @@ func (x *PublishRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PublishRequest.ProtoReflect.Descriptor instead.
-func (*PublishRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use DeleteTopicRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTopicRequest) Descriptor() ([]byte, []int) {
 	return file_broker_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *PublishRequest) GetTopicName() string {
-	if x != nil {
-		return x.TopicName
-	}
-	return ""
-}
-
-func (x *PublishRequest) GetQueueId() string {
-	if x != nil {
-		return x.QueueId
-	}
-	return ""
-}
-
-func (x *PublishRequest) GetPayload() []byte {
-	if x != nil {
-		return x.Payload
-	}
-	return nil
-}
-
-func (x *PublishRequest) GetKey() string {
+func (x *DeleteTopicRequest) GetName() string {
 	if x != nil {
-		return x.Key
+		return x.Name
 	}
 	return ""
 }
 
-func (x *PublishRequest) GetHeaders() map[string]string {
+func (x *DeleteTopicRequest) GetForce() bool {
 	if x != nil {
-		return x.Headers
+		return x.Force
 	}
-	return nil
+	return false
 }
 
-type PublishResponse struct {
+type DeleteTopicResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
-	Offset        int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PublishResponse) Reset() {
-	*x = PublishResponse{}
+func (x *DeleteTopicResponse) Reset() {
+	*x = DeleteTopicResponse{}
 	mi := &file_broker_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PublishResponse) String() string {
+func (x *DeleteTopicResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PublishResponse) ProtoMessage() {}
+func (*DeleteTopicResponse) ProtoMessage() {}
 
-func (x *PublishResponse) ProtoReflect() protoreflect.Message {
+func (x *DeleteTopicResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_broker_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -659,49 +804,36 @@ func (x *PublishResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PublishResponse.ProtoReflect.Descriptor instead.
-func (*PublishResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use DeleteTopicResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTopicResponse) Descriptor() ([]byte, []int) {
 	return file_broker_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *PublishResponse) GetMessageId() string {
-	if x != nil {
-		return x.MessageId
-	}
-	return ""
-}
-
-func (x *PublishResponse) GetOffset() int64 {
-	if x != nil {
-		return x.Offset
-	}
-	return 0
-}
-
-type SubscribeRequest struct {
-	state             protoimpl.MessageState `protogen:"open.v1"`
-	TopicName         string                 `protobuf:"bytes,1,opt,name=topic_name,json=topicName,proto3" json:"topic_name,omitempty"`
-	QueueId           string                 `protobuf:"bytes,2,opt,name=queue_id,json=queueId,proto3" json:"queue_id,omitempty"`
-	ConsumerGroup     string                 `protobuf:"bytes,3,opt,name=consumer_group,json=consumerGroup,proto3" json:"consumer_group,omitempty"`
-	DeliveryGuarantee DeliveryGuarantee      `protobuf:"varint,4,opt,name=delivery_guarantee,json=deliveryGuarantee,proto3,enum=broker.DeliveryGuarantee" json:"delivery_guarantee,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+// Удаляет очередь, её сообщения и подписки на неё.
+// Без force при наличии подписок возвращается FAILED_PRECONDITION.
+type DeleteQueueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TopicName     string                 `protobuf:"bytes,1,opt,name=topic_name,json=topicName,proto3" json:"topic_name,omitempty"`
+	QueueId       string                 `protobuf:"bytes,2,opt,name=queue_id,json=queueId,proto3" json:"queue_id,omitempty"`
+	Force         bool                   `protobuf:"varint,3,opt,name=force,proto3" json:"force,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SubscribeRequest) Reset() {
-	*x = SubscribeRequest{}
+func (x *DeleteQueueRequest) Reset() {
+	*x = DeleteQueueRequest{}
 	mi := &file_broker_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SubscribeRequest) String() string {
+func (x *DeleteQueueRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SubscribeRequest) ProtoMessage() {}
+func (*DeleteQueueRequest) ProtoMessage() {}
 
-func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+func (x *DeleteQueueRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_broker_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -713,63 +845,52 @@ func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
-func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use DeleteQueueRequest.ProtoReflect.Descriptor instead.
+func (*DeleteQueueRequest) Descriptor() ([]byte, []int) {
 	return file_broker_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *SubscribeRequest) GetTopicName() string {
+func (x *DeleteQueueRequest) GetTopicName() string {
 	if x != nil {
 		return x.TopicName
 	}
 	return ""
 }
 
-func (x *SubscribeRequest) GetQueueId() string {
+func (x *DeleteQueueRequest) GetQueueId() string {
 	if x != nil {
 		return x.QueueId
 	}
 	return ""
 }
 
-func (x *SubscribeRequest) GetConsumerGroup() string {
-	if x != nil {
-		return x.ConsumerGroup
-	}
-	return ""
-}
-
-func (x *SubscribeRequest) GetDeliveryGuarantee() DeliveryGuarantee {
+func (x *DeleteQueueRequest) GetForce() bool {
 	if x != nil {
-		return x.DeliveryGuarantee
+		return x.Force
 	}
-	return DeliveryGuarantee_DELIVERY_GUARANTEE_UNSPECIFIED
+	return false
 }
 
-type SubscribeResponse struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	SubscriptionId string                 `protobuf:"bytes,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
-	TopicName      string                 `protobuf:"bytes,2,opt,name=topic_name,json=topicName,proto3" json:"topic_name,omitempty"`
-	QueueId        string                 `protobuf:"bytes,3,opt,name=queue_id,json=queueId,proto3" json:"queue_id,omitempty"`
-	ConsumerGroup  string                 `protobuf:"bytes,4,opt,name=consumer_group,json=consumerGroup,proto3" json:"consumer_group,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+type DeleteQueueResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SubscribeResponse) Reset() {
-	*x = SubscribeResponse{}
+func (x *DeleteQueueResponse) Reset() {
+	*x = DeleteQueueResponse{}
 	mi := &file_broker_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SubscribeResponse) String() string {
+func (x *DeleteQueueResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SubscribeResponse) ProtoMessage() {}
+func (*DeleteQueueResponse) ProtoMessage() {}
 
-func (x *SubscribeResponse) ProtoReflect() protoreflect.Message {
+func (x *DeleteQueueResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_broker_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -781,62 +902,86 @@ func (x *SubscribeResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SubscribeResponse.ProtoReflect.Descriptor instead.
-func (*SubscribeResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use DeleteQueueResponse.ProtoReflect.Descriptor instead.
+func (*DeleteQueueResponse) Descriptor() ([]byte, []int) {
 	return file_broker_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *SubscribeResponse) GetSubscriptionId() string {
-	if x != nil {
-		return x.SubscriptionId
-	}
-	return ""
+// Удаляет все сообщения очереди; смещения новых сообщений продолжают прежнюю нумерацию.
+type PurgeQueueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TopicName     string                 `protobuf:"bytes,1,opt,name=topic_name,json=topicName,proto3" json:"topic_name,omitempty"`
+	QueueId       string                 `protobuf:"bytes,2,opt,name=queue_id,json=queueId,proto3" json:"queue_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SubscribeResponse) GetTopicName() string {
+func (x *PurgeQueueRequest) Reset() {
+	*x = PurgeQueueRequest{}
+	mi := &file_broker_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeQueueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeQueueRequest) ProtoMessage() {}
+
+func (x *PurgeQueueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[14]
 	if x != nil {
-		return x.TopicName
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *SubscribeResponse) GetQueueId() string {
+// Deprecated: Use PurgeQueueRequest.ProtoReflect.Descriptor instead.
+func (*PurgeQueueRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *PurgeQueueRequest) GetTopicName() string {
 	if x != nil {
-		return x.QueueId
+		return x.TopicName
 	}
 	return ""
 }
 
-func (x *SubscribeResponse) GetConsumerGroup() string {
+func (x *PurgeQueueRequest) GetQueueId() string {
 	if x != nil {
-		return x.ConsumerGroup
+		return x.QueueId
 	}
 	return ""
 }
 
-type ConsumeRequest struct {
+type PurgeQueueResponse struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
-	SubscriptionId string                 `protobuf:"bytes,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
-	MaxMessages    int32                  `protobuf:"varint,2,opt,name=max_messages,json=maxMessages,proto3" json:"max_messages,omitempty"`
+	LogStartOffset int64                  `protobuf:"varint,1,opt,name=log_start_offset,json=logStartOffset,proto3" json:"log_start_offset,omitempty"`
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
 
-func (x *ConsumeRequest) Reset() {
-	*x = ConsumeRequest{}
-	mi := &file_broker_proto_msgTypes[14]
+func (x *PurgeQueueResponse) Reset() {
+	*x = PurgeQueueResponse{}
+	mi := &file_broker_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ConsumeRequest) String() string {
+func (x *PurgeQueueResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ConsumeRequest) ProtoMessage() {}
+func (*PurgeQueueResponse) ProtoMessage() {}
 
-func (x *ConsumeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_broker_proto_msgTypes[14]
+func (x *PurgeQueueResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -847,47 +992,1865 @@ func (x *ConsumeRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ConsumeRequest.ProtoReflect.Descriptor instead.
-func (*ConsumeRequest) Descriptor() ([]byte, []int) {
-	return file_broker_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use PurgeQueueResponse.ProtoReflect.Descriptor instead.
+func (*PurgeQueueResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *ConsumeRequest) GetSubscriptionId() string {
+func (x *PurgeQueueResponse) GetLogStartOffset() int64 {
 	if x != nil {
-		return x.SubscriptionId
+		return x.LogStartOffset
 	}
-	return ""
+	return 0
 }
 
-func (x *ConsumeRequest) GetMaxMessages() int32 {
-	if x != nil {
-		return x.MaxMessages
-	}
+// Просмотр сообщений очереди без подписки и без изменения смещений.
+// limit по умолчанию и не больше 1000. С фильтром за один вызов
+// просматривается не больше 10000 сообщений; продолжать — с next_offset.
+type BrowseMessagesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TopicName     string                 `protobuf:"bytes,1,opt,name=topic_name,json=topicName,proto3" json:"topic_name,omitempty"`
+	QueueId       string                 `protobuf:"bytes,2,opt,name=queue_id,json=queueId,proto3" json:"queue_id,omitempty"`
+	FromOffset    int64                  `protobuf:"varint,3,opt,name=from_offset,json=fromOffset,proto3" json:"from_offset,omitempty"`
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Filter        *MessageFilter         `protobuf:"bytes,5,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BrowseMessagesRequest) Reset() {
+	*x = BrowseMessagesRequest{}
+	mi := &file_broker_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BrowseMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BrowseMessagesRequest) ProtoMessage() {}
+
+func (x *BrowseMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BrowseMessagesRequest.ProtoReflect.Descriptor instead.
+func (*BrowseMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *BrowseMessagesRequest) GetTopicName() string {
+	if x != nil {
+		return x.TopicName
+	}
+	return ""
+}
+
+func (x *BrowseMessagesRequest) GetQueueId() string {
+	if x != nil {
+		return x.QueueId
+	}
+	return ""
+}
+
+func (x *BrowseMessagesRequest) GetFromOffset() int64 {
+	if x != nil {
+		return x.FromOffset
+	}
 	return 0
 }
 
-type ConsumeResponse struct {
+func (x *BrowseMessagesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *BrowseMessagesRequest) GetFilter() *MessageFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+// Пустые поля не проверяются; headers должны совпасть все.
+type MessageFilter struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Key             string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Headers         map[string]string      `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	PayloadContains []byte                 `protobuf:"bytes,3,opt,name=payload_contains,json=payloadContains,proto3" json:"payload_contains,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *MessageFilter) Reset() {
+	*x = MessageFilter{}
+	mi := &file_broker_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageFilter) ProtoMessage() {}
+
+func (x *MessageFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageFilter.ProtoReflect.Descriptor instead.
+func (*MessageFilter) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *MessageFilter) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *MessageFilter) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *MessageFilter) GetPayloadContains() []byte {
+	if x != nil {
+		return x.PayloadContains
+	}
+	return nil
+}
+
+type BrowseMessagesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Messages      []*Message             `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	NextOffset    int64                  `protobuf:"varint,2,opt,name=next_offset,json=nextOffset,proto3" json:"next_offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BrowseMessagesResponse) Reset() {
+	*x = BrowseMessagesResponse{}
+	mi := &file_broker_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BrowseMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BrowseMessagesResponse) ProtoMessage() {}
+
+func (x *BrowseMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BrowseMessagesResponse.ProtoReflect.Descriptor instead.
+func (*BrowseMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *BrowseMessagesResponse) GetMessages() []*Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *BrowseMessagesResponse) GetNextOffset() int64 {
+	if x != nil {
+		return x.NextOffset
+	}
+	return 0
+}
+
+type GetMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TopicName     string                 `protobuf:"bytes,1,opt,name=topic_name,json=topicName,proto3" json:"topic_name,omitempty"`
+	QueueId       string                 `protobuf:"bytes,2,opt,name=queue_id,json=queueId,proto3" json:"queue_id,omitempty"`
+	MessageId     string                 `protobuf:"bytes,3,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMessageRequest) Reset() {
+	*x = GetMessageRequest{}
+	mi := &file_broker_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMessageRequest) ProtoMessage() {}
+
+func (x *GetMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMessageRequest.ProtoReflect.Descriptor instead.
+func (*GetMessageRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetMessageRequest) GetTopicName() string {
+	if x != nil {
+		return x.TopicName
+	}
+	return ""
+}
+
+func (x *GetMessageRequest) GetQueueId() string {
+	if x != nil {
+		return x.QueueId
+	}
+	return ""
+}
+
+func (x *GetMessageRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+type GetMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       *Message               `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMessageResponse) Reset() {
+	*x = GetMessageResponse{}
+	mi := &file_broker_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMessageResponse) ProtoMessage() {}
+
+func (x *GetMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMessageResponse.ProtoReflect.Descriptor instead.
+func (*GetMessageResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetMessageResponse) GetMessage() *Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+type GetMemoryUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ConsumeResponse) Reset() {
-	*x = ConsumeResponse{}
-	mi := &file_broker_proto_msgTypes[15]
+func (x *GetMemoryUsageRequest) Reset() {
+	*x = GetMemoryUsageRequest{}
+	mi := &file_broker_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMemoryUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMemoryUsageRequest) ProtoMessage() {}
+
+func (x *GetMemoryUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMemoryUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetMemoryUsageRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{21}
+}
+
+// Память, занятая сообщениями (приблизительный объём), и лимиты брокера.
+// Нулевой limit_bytes — без ограничения. overflow_policy — reject, block или evict:
+// что делает Publish при превышении лимита. namespace_* и topics — по
+// пространству имён клиента.
+type GetMemoryUsageResponse struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	UsedBytes           int64                  `protobuf:"varint,1,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`
+	LimitBytes          int64                  `protobuf:"varint,2,opt,name=limit_bytes,json=limitBytes,proto3" json:"limit_bytes,omitempty"`
+	OverflowPolicy      string                 `protobuf:"bytes,3,opt,name=overflow_policy,json=overflowPolicy,proto3" json:"overflow_policy,omitempty"`
+	Topics              []*TopicMemoryUsage    `protobuf:"bytes,4,rep,name=topics,proto3" json:"topics,omitempty"`
+	Namespace           string                 `protobuf:"bytes,5,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	NamespaceUsedBytes  int64                  `protobuf:"varint,6,opt,name=namespace_used_bytes,json=namespaceUsedBytes,proto3" json:"namespace_used_bytes,omitempty"`
+	NamespaceLimitBytes int64                  `protobuf:"varint,7,opt,name=namespace_limit_bytes,json=namespaceLimitBytes,proto3" json:"namespace_limit_bytes,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *GetMemoryUsageResponse) Reset() {
+	*x = GetMemoryUsageResponse{}
+	mi := &file_broker_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMemoryUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMemoryUsageResponse) ProtoMessage() {}
+
+func (x *GetMemoryUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMemoryUsageResponse.ProtoReflect.Descriptor instead.
+func (*GetMemoryUsageResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetMemoryUsageResponse) GetUsedBytes() int64 {
+	if x != nil {
+		return x.UsedBytes
+	}
+	return 0
+}
+
+func (x *GetMemoryUsageResponse) GetLimitBytes() int64 {
+	if x != nil {
+		return x.LimitBytes
+	}
+	return 0
+}
+
+func (x *GetMemoryUsageResponse) GetOverflowPolicy() string {
+	if x != nil {
+		return x.OverflowPolicy
+	}
+	return ""
+}
+
+func (x *GetMemoryUsageResponse) GetTopics() []*TopicMemoryUsage {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+func (x *GetMemoryUsageResponse) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *GetMemoryUsageResponse) GetNamespaceUsedBytes() int64 {
+	if x != nil {
+		return x.NamespaceUsedBytes
+	}
+	return 0
+}
+
+func (x *GetMemoryUsageResponse) GetNamespaceLimitBytes() int64 {
+	if x != nil {
+		return x.NamespaceLimitBytes
+	}
+	return 0
+}
+
+type TopicMemoryUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TopicName     string                 `protobuf:"bytes,1,opt,name=topic_name,json=topicName,proto3" json:"topic_name,omitempty"`
+	UsedBytes     int64                  `protobuf:"varint,2,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`
+	LimitBytes    int64                  `protobuf:"varint,3,opt,name=limit_bytes,json=limitBytes,proto3" json:"limit_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopicMemoryUsage) Reset() {
+	*x = TopicMemoryUsage{}
+	mi := &file_broker_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopicMemoryUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopicMemoryUsage) ProtoMessage() {}
+
+func (x *TopicMemoryUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopicMemoryUsage.ProtoReflect.Descriptor instead.
+func (*TopicMemoryUsage) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *TopicMemoryUsage) GetTopicName() string {
+	if x != nil {
+		return x.TopicName
+	}
+	return ""
+}
+
+func (x *TopicMemoryUsage) GetUsedBytes() int64 {
+	if x != nil {
+		return x.UsedBytes
+	}
+	return 0
+}
+
+func (x *TopicMemoryUsage) GetLimitBytes() int64 {
+	if x != nil {
+		return x.LimitBytes
+	}
+	return 0
+}
+
+type PublishRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TopicName     string                 `protobuf:"bytes,1,opt,name=topic_name,json=topicName,proto3" json:"topic_name,omitempty"`
+	QueueId       string                 `protobuf:"bytes,2,opt,name=queue_id,json=queueId,proto3" json:"queue_id,omitempty"`
+	Payload       []byte                 `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	Key           string                 `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`
+	Headers       map[string]string      `protobuf:"bytes,5,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishRequest) Reset() {
+	*x = PublishRequest{}
+	mi := &file_broker_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishRequest) ProtoMessage() {}
+
+func (x *PublishRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishRequest.ProtoReflect.Descriptor instead.
+func (*PublishRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *PublishRequest) GetTopicName() string {
+	if x != nil {
+		return x.TopicName
+	}
+	return ""
+}
+
+func (x *PublishRequest) GetQueueId() string {
+	if x != nil {
+		return x.QueueId
+	}
+	return ""
+}
+
+func (x *PublishRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *PublishRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *PublishRequest) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+type PublishResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Offset        int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishResponse) Reset() {
+	*x = PublishResponse{}
+	mi := &file_broker_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishResponse) ProtoMessage() {}
+
+func (x *PublishResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishResponse.ProtoReflect.Descriptor instead.
+func (*PublishResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *PublishResponse) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *PublishResponse) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type PublishBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*PublishRequest      `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishBatchRequest) Reset() {
+	*x = PublishBatchRequest{}
+	mi := &file_broker_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishBatchRequest) ProtoMessage() {}
+
+func (x *PublishBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishBatchRequest.ProtoReflect.Descriptor instead.
+func (*PublishBatchRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *PublishBatchRequest) GetMessages() []*PublishRequest {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+// Результат публикации одного сообщения пакета. code — код gRPC (0 — успех).
+type PublishResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Offset        int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Code          int32                  `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishResult) Reset() {
+	*x = PublishResult{}
+	mi := &file_broker_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishResult) ProtoMessage() {}
+
+func (x *PublishResult) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishResult.ProtoReflect.Descriptor instead.
+func (*PublishResult) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *PublishResult) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *PublishResult) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *PublishResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *PublishResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type PublishBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*PublishResult       `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishBatchResponse) Reset() {
+	*x = PublishBatchResponse{}
+	mi := &file_broker_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishBatchResponse) ProtoMessage() {}
+
+func (x *PublishBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishBatchResponse.ProtoReflect.Descriptor instead.
+func (*PublishBatchResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *PublishBatchResponse) GetResults() []*PublishResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type SubscribeRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	TopicName         string                 `protobuf:"bytes,1,opt,name=topic_name,json=topicName,proto3" json:"topic_name,omitempty"`
+	QueueId           string                 `protobuf:"bytes,2,opt,name=queue_id,json=queueId,proto3" json:"queue_id,omitempty"`
+	ConsumerGroup     string                 `protobuf:"bytes,3,opt,name=consumer_group,json=consumerGroup,proto3" json:"consumer_group,omitempty"`
+	DeliveryGuarantee DeliveryGuarantee      `protobuf:"varint,4,opt,name=delivery_guarantee,json=deliveryGuarantee,proto3,enum=broker.DeliveryGuarantee" json:"delivery_guarantee,omitempty"`
+	// Откуда читать новой подписке: EARLIEST (по умолчанию) — с начала очереди,
+	// LATEST — только сообщения, опубликованные после подписки, TIMESTAMP — с
+	// первого сообщения, опубликованного не раньше start_timestamp_unix_ms.
+	StartPosition        SeekPosition `protobuf:"varint,5,opt,name=start_position,json=startPosition,proto3,enum=broker.SeekPosition" json:"start_position,omitempty"`
+	StartTimestampUnixMs int64        `protobuf:"varint,6,opt,name=start_timestamp_unix_ms,json=startTimestampUnixMs,proto3" json:"start_timestamp_unix_ms,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_broker_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SubscribeRequest) GetTopicName() string {
+	if x != nil {
+		return x.TopicName
+	}
+	return ""
+}
+
+func (x *SubscribeRequest) GetQueueId() string {
+	if x != nil {
+		return x.QueueId
+	}
+	return ""
+}
+
+func (x *SubscribeRequest) GetConsumerGroup() string {
+	if x != nil {
+		return x.ConsumerGroup
+	}
+	return ""
+}
+
+func (x *SubscribeRequest) GetDeliveryGuarantee() DeliveryGuarantee {
+	if x != nil {
+		return x.DeliveryGuarantee
+	}
+	return DeliveryGuarantee_DELIVERY_GUARANTEE_UNSPECIFIED
+}
+
+func (x *SubscribeRequest) GetStartPosition() SeekPosition {
+	if x != nil {
+		return x.StartPosition
+	}
+	return SeekPosition_SEEK_POSITION_UNSPECIFIED
+}
+
+func (x *SubscribeRequest) GetStartTimestampUnixMs() int64 {
+	if x != nil {
+		return x.StartTimestampUnixMs
+	}
+	return 0
+}
+
+type SubscribeResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SubscriptionId string                 `protobuf:"bytes,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	TopicName      string                 `protobuf:"bytes,2,opt,name=topic_name,json=topicName,proto3" json:"topic_name,omitempty"`
+	QueueId        string                 `protobuf:"bytes,3,opt,name=queue_id,json=queueId,proto3" json:"queue_id,omitempty"`
+	ConsumerGroup  string                 `protobuf:"bytes,4,opt,name=consumer_group,json=consumerGroup,proto3" json:"consumer_group,omitempty"`
+	Offset         int64                  `protobuf:"varint,5,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SubscribeResponse) Reset() {
+	*x = SubscribeResponse{}
+	mi := &file_broker_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeResponse) ProtoMessage() {}
+
+func (x *SubscribeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeResponse.ProtoReflect.Descriptor instead.
+func (*SubscribeResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *SubscribeResponse) GetSubscriptionId() string {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return ""
+}
+
+func (x *SubscribeResponse) GetTopicName() string {
+	if x != nil {
+		return x.TopicName
+	}
+	return ""
+}
+
+func (x *SubscribeResponse) GetQueueId() string {
+	if x != nil {
+		return x.QueueId
+	}
+	return ""
+}
+
+func (x *SubscribeResponse) GetConsumerGroup() string {
+	if x != nil {
+		return x.ConsumerGroup
+	}
+	return ""
+}
+
+func (x *SubscribeResponse) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type UnsubscribeRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SubscriptionId string                 `protobuf:"bytes,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UnsubscribeRequest) Reset() {
+	*x = UnsubscribeRequest{}
+	mi := &file_broker_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsubscribeRequest) ProtoMessage() {}
+
+func (x *UnsubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsubscribeRequest.ProtoReflect.Descriptor instead.
+func (*UnsubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *UnsubscribeRequest) GetSubscriptionId() string {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return ""
+}
+
+type UnsubscribeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnsubscribeResponse) Reset() {
+	*x = UnsubscribeResponse{}
+	mi := &file_broker_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsubscribeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsubscribeResponse) ProtoMessage() {}
+
+func (x *UnsubscribeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsubscribeResponse.ProtoReflect.Descriptor instead.
+func (*UnsubscribeResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{32}
+}
+
+// Пустой topic_name — подписки всех топиков.
+type ListSubscriptionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TopicName     string                 `protobuf:"bytes,1,opt,name=topic_name,json=topicName,proto3" json:"topic_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSubscriptionsRequest) Reset() {
+	*x = ListSubscriptionsRequest{}
+	mi := &file_broker_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSubscriptionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSubscriptionsRequest) ProtoMessage() {}
+
+func (x *ListSubscriptionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSubscriptionsRequest.ProtoReflect.Descriptor instead.
+func (*ListSubscriptionsRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ListSubscriptionsRequest) GetTopicName() string {
+	if x != nil {
+		return x.TopicName
+	}
+	return ""
+}
+
+type ListSubscriptionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subscriptions []*SubscriptionInfo    `protobuf:"bytes,1,rep,name=subscriptions,proto3" json:"subscriptions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSubscriptionsResponse) Reset() {
+	*x = ListSubscriptionsResponse{}
+	mi := &file_broker_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSubscriptionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSubscriptionsResponse) ProtoMessage() {}
+
+func (x *ListSubscriptionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSubscriptionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSubscriptionsResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ListSubscriptionsResponse) GetSubscriptions() []*SubscriptionInfo {
+	if x != nil {
+		return x.Subscriptions
+	}
+	return nil
+}
+
+type DescribeSubscriptionRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SubscriptionId string                 `protobuf:"bytes,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DescribeSubscriptionRequest) Reset() {
+	*x = DescribeSubscriptionRequest{}
+	mi := &file_broker_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DescribeSubscriptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeSubscriptionRequest) ProtoMessage() {}
+
+func (x *DescribeSubscriptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeSubscriptionRequest.ProtoReflect.Descriptor instead.
+func (*DescribeSubscriptionRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *DescribeSubscriptionRequest) GetSubscriptionId() string {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return ""
+}
+
+type DescribeSubscriptionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subscription  *SubscriptionInfo      `protobuf:"bytes,1,opt,name=subscription,proto3" json:"subscription,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DescribeSubscriptionResponse) Reset() {
+	*x = DescribeSubscriptionResponse{}
+	mi := &file_broker_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DescribeSubscriptionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeSubscriptionResponse) ProtoMessage() {}
+
+func (x *DescribeSubscriptionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeSubscriptionResponse.ProtoReflect.Descriptor instead.
+func (*DescribeSubscriptionResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *DescribeSubscriptionResponse) GetSubscription() *SubscriptionInfo {
+	if x != nil {
+		return x.Subscription
+	}
+	return nil
+}
+
+// Состояние подписки. lag = high_watermark - committed_offset и включает
+// выданные, но не подтверждённые сообщения (pending_count).
+// Нулевые oldest_pending_age_ms и last_consumed_at_unix_ms — значения нет.
+type SubscriptionInfo struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	SubscriptionId       string                 `protobuf:"bytes,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	TopicName            string                 `protobuf:"bytes,2,opt,name=topic_name,json=topicName,proto3" json:"topic_name,omitempty"`
+	QueueId              string                 `protobuf:"bytes,3,opt,name=queue_id,json=queueId,proto3" json:"queue_id,omitempty"`
+	ConsumerGroup        string                 `protobuf:"bytes,4,opt,name=consumer_group,json=consumerGroup,proto3" json:"consumer_group,omitempty"`
+	DeliveryGuarantee    DeliveryGuarantee      `protobuf:"varint,5,opt,name=delivery_guarantee,json=deliveryGuarantee,proto3,enum=broker.DeliveryGuarantee" json:"delivery_guarantee,omitempty"`
+	CommittedOffset      int64                  `protobuf:"varint,6,opt,name=committed_offset,json=committedOffset,proto3" json:"committed_offset,omitempty"`
+	HighWatermark        int64                  `protobuf:"varint,7,opt,name=high_watermark,json=highWatermark,proto3" json:"high_watermark,omitempty"`
+	Lag                  int64                  `protobuf:"varint,8,opt,name=lag,proto3" json:"lag,omitempty"`
+	PendingCount         int32                  `protobuf:"varint,9,opt,name=pending_count,json=pendingCount,proto3" json:"pending_count,omitempty"`
+	OldestPendingAgeMs   int64                  `protobuf:"varint,10,opt,name=oldest_pending_age_ms,json=oldestPendingAgeMs,proto3" json:"oldest_pending_age_ms,omitempty"`
+	LastConsumedAtUnixMs int64                  `protobuf:"varint,11,opt,name=last_consumed_at_unix_ms,json=lastConsumedAtUnixMs,proto3" json:"last_consumed_at_unix_ms,omitempty"`
+	Namespace            string                 `protobuf:"bytes,12,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *SubscriptionInfo) Reset() {
+	*x = SubscriptionInfo{}
+	mi := &file_broker_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscriptionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscriptionInfo) ProtoMessage() {}
+
+func (x *SubscriptionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscriptionInfo.ProtoReflect.Descriptor instead.
+func (*SubscriptionInfo) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *SubscriptionInfo) GetSubscriptionId() string {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return ""
+}
+
+func (x *SubscriptionInfo) GetTopicName() string {
+	if x != nil {
+		return x.TopicName
+	}
+	return ""
+}
+
+func (x *SubscriptionInfo) GetQueueId() string {
+	if x != nil {
+		return x.QueueId
+	}
+	return ""
+}
+
+func (x *SubscriptionInfo) GetConsumerGroup() string {
+	if x != nil {
+		return x.ConsumerGroup
+	}
+	return ""
+}
+
+func (x *SubscriptionInfo) GetDeliveryGuarantee() DeliveryGuarantee {
+	if x != nil {
+		return x.DeliveryGuarantee
+	}
+	return DeliveryGuarantee_DELIVERY_GUARANTEE_UNSPECIFIED
+}
+
+func (x *SubscriptionInfo) GetCommittedOffset() int64 {
+	if x != nil {
+		return x.CommittedOffset
+	}
+	return 0
+}
+
+func (x *SubscriptionInfo) GetHighWatermark() int64 {
+	if x != nil {
+		return x.HighWatermark
+	}
+	return 0
+}
+
+func (x *SubscriptionInfo) GetLag() int64 {
+	if x != nil {
+		return x.Lag
+	}
+	return 0
+}
+
+func (x *SubscriptionInfo) GetPendingCount() int32 {
+	if x != nil {
+		return x.PendingCount
+	}
+	return 0
+}
+
+func (x *SubscriptionInfo) GetOldestPendingAgeMs() int64 {
+	if x != nil {
+		return x.OldestPendingAgeMs
+	}
+	return 0
+}
+
+func (x *SubscriptionInfo) GetLastConsumedAtUnixMs() int64 {
+	if x != nil {
+		return x.LastConsumedAtUnixMs
+	}
+	return 0
+}
+
+func (x *SubscriptionInfo) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+// Перемещает смещение подписки; неподтверждённые доставки сбрасываются.
+// offset учитывается для OFFSET, timestamp_unix_ms — для TIMESTAMP
+// (первое сообщение, опубликованное не раньше этого момента).
+type SeekSubscriptionRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	SubscriptionId  string                 `protobuf:"bytes,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	Position        SeekPosition           `protobuf:"varint,2,opt,name=position,proto3,enum=broker.SeekPosition" json:"position,omitempty"`
+	Offset          int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	TimestampUnixMs int64                  `protobuf:"varint,4,opt,name=timestamp_unix_ms,json=timestampUnixMs,proto3" json:"timestamp_unix_ms,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SeekSubscriptionRequest) Reset() {
+	*x = SeekSubscriptionRequest{}
+	mi := &file_broker_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SeekSubscriptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeekSubscriptionRequest) ProtoMessage() {}
+
+func (x *SeekSubscriptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeekSubscriptionRequest.ProtoReflect.Descriptor instead.
+func (*SeekSubscriptionRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *SeekSubscriptionRequest) GetSubscriptionId() string {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return ""
+}
+
+func (x *SeekSubscriptionRequest) GetPosition() SeekPosition {
+	if x != nil {
+		return x.Position
+	}
+	return SeekPosition_SEEK_POSITION_UNSPECIFIED
+}
+
+func (x *SeekSubscriptionRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *SeekSubscriptionRequest) GetTimestampUnixMs() int64 {
+	if x != nil {
+		return x.TimestampUnixMs
+	}
+	return 0
+}
+
+type SeekSubscriptionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Offset        int64                  `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SeekSubscriptionResponse) Reset() {
+	*x = SeekSubscriptionResponse{}
+	mi := &file_broker_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SeekSubscriptionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SeekSubscriptionResponse) ProtoMessage() {}
+
+func (x *SeekSubscriptionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SeekSubscriptionResponse.ProtoReflect.Descriptor instead.
+func (*SeekSubscriptionResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *SeekSubscriptionResponse) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ConsumeRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SubscriptionId string                 `protobuf:"bytes,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	MaxMessages    int32                  `protobuf:"varint,2,opt,name=max_messages,json=maxMessages,proto3" json:"max_messages,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ConsumeRequest) Reset() {
+	*x = ConsumeRequest{}
+	mi := &file_broker_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConsumeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsumeRequest) ProtoMessage() {}
+
+func (x *ConsumeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsumeRequest.ProtoReflect.Descriptor instead.
+func (*ConsumeRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ConsumeRequest) GetSubscriptionId() string {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return ""
+}
+
+func (x *ConsumeRequest) GetMaxMessages() int32 {
+	if x != nil {
+		return x.MaxMessages
+	}
+	return 0
+}
+
+type ConsumeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*Message             `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConsumeResponse) Reset() {
+	*x = ConsumeResponse{}
+	mi := &file_broker_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConsumeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsumeResponse) ProtoMessage() {}
+
+func (x *ConsumeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsumeResponse.ProtoReflect.Descriptor instead.
+func (*ConsumeResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ConsumeResponse) GetMessages() []*Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type Message struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TopicName       string                 `protobuf:"bytes,2,opt,name=topic_name,json=topicName,proto3" json:"topic_name,omitempty"`
+	QueueId         string                 `protobuf:"bytes,3,opt,name=queue_id,json=queueId,proto3" json:"queue_id,omitempty"`
+	Payload         []byte                 `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
+	Key             string                 `protobuf:"bytes,5,opt,name=key,proto3" json:"key,omitempty"`
+	Headers         map[string]string      `protobuf:"bytes,6,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Offset          int64                  `protobuf:"varint,7,opt,name=offset,proto3" json:"offset,omitempty"`
+	DeliveryId      string                 `protobuf:"bytes,8,opt,name=delivery_id,json=deliveryId,proto3" json:"delivery_id,omitempty"`
+	CreatedAtUnixMs int64                  `protobuf:"varint,9,opt,name=created_at_unix_ms,json=createdAtUnixMs,proto3" json:"created_at_unix_ms,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	mi := &file_broker_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *Message) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Message) GetTopicName() string {
+	if x != nil {
+		return x.TopicName
+	}
+	return ""
+}
+
+func (x *Message) GetQueueId() string {
+	if x != nil {
+		return x.QueueId
+	}
+	return ""
+}
+
+func (x *Message) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Message) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Message) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *Message) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *Message) GetDeliveryId() string {
+	if x != nil {
+		return x.DeliveryId
+	}
+	return ""
+}
+
+func (x *Message) GetCreatedAtUnixMs() int64 {
+	if x != nil {
+		return x.CreatedAtUnixMs
+	}
+	return 0
+}
+
+type AckRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SubscriptionId string                 `protobuf:"bytes,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	DeliveryId     string                 `protobuf:"bytes,2,opt,name=delivery_id,json=deliveryId,proto3" json:"delivery_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AckRequest) Reset() {
+	*x = AckRequest{}
+	mi := &file_broker_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AckRequest) ProtoMessage() {}
+
+func (x *AckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AckRequest.ProtoReflect.Descriptor instead.
+func (*AckRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *AckRequest) GetSubscriptionId() string {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return ""
+}
+
+func (x *AckRequest) GetDeliveryId() string {
+	if x != nil {
+		return x.DeliveryId
+	}
+	return ""
+}
+
+type AckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AckResponse) Reset() {
+	*x = AckResponse{}
+	mi := &file_broker_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AckResponse) ProtoMessage() {}
+
+func (x *AckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AckResponse.ProtoReflect.Descriptor instead.
+func (*AckResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{44}
+}
+
+type NackRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SubscriptionId string                 `protobuf:"bytes,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	DeliveryId     string                 `protobuf:"bytes,2,opt,name=delivery_id,json=deliveryId,proto3" json:"delivery_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *NackRequest) Reset() {
+	*x = NackRequest{}
+	mi := &file_broker_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NackRequest) ProtoMessage() {}
+
+func (x *NackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NackRequest.ProtoReflect.Descriptor instead.
+func (*NackRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *NackRequest) GetSubscriptionId() string {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return ""
+}
+
+func (x *NackRequest) GetDeliveryId() string {
+	if x != nil {
+		return x.DeliveryId
+	}
+	return ""
+}
+
+type NackResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NackResponse) Reset() {
+	*x = NackResponse{}
+	mi := &file_broker_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NackResponse) ProtoMessage() {}
+
+func (x *NackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NackResponse.ProtoReflect.Descriptor instead.
+func (*NackResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{46}
+}
+
+type ExtendAckDeadlineRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	SubscriptionId   string                 `protobuf:"bytes,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	DeliveryId       string                 `protobuf:"bytes,2,opt,name=delivery_id,json=deliveryId,proto3" json:"delivery_id,omitempty"`
+	ExtensionSeconds int32                  `protobuf:"varint,3,opt,name=extension_seconds,json=extensionSeconds,proto3" json:"extension_seconds,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ExtendAckDeadlineRequest) Reset() {
+	*x = ExtendAckDeadlineRequest{}
+	mi := &file_broker_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ConsumeResponse) String() string {
+func (x *ExtendAckDeadlineRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ConsumeResponse) ProtoMessage() {}
+func (*ExtendAckDeadlineRequest) ProtoMessage() {}
 
-func (x *ConsumeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_broker_proto_msgTypes[15]
+func (x *ExtendAckDeadlineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -898,47 +2861,53 @@ func (x *ConsumeResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ConsumeResponse.ProtoReflect.Descriptor instead.
-func (*ConsumeResponse) Descriptor() ([]byte, []int) {
-	return file_broker_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use ExtendAckDeadlineRequest.ProtoReflect.Descriptor instead.
+func (*ExtendAckDeadlineRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{47}
 }
 
-func (x *ConsumeResponse) GetMessages() []*Message {
+func (x *ExtendAckDeadlineRequest) GetSubscriptionId() string {
 	if x != nil {
-		return x.Messages
+		return x.SubscriptionId
 	}
-	return nil
+	return ""
 }
 
-type Message struct {
+func (x *ExtendAckDeadlineRequest) GetDeliveryId() string {
+	if x != nil {
+		return x.DeliveryId
+	}
+	return ""
+}
+
+func (x *ExtendAckDeadlineRequest) GetExtensionSeconds() int32 {
+	if x != nil {
+		return x.ExtensionSeconds
+	}
+	return 0
+}
+
+type ExtendAckDeadlineResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	TopicName     string                 `protobuf:"bytes,2,opt,name=topic_name,json=topicName,proto3" json:"topic_name,omitempty"`
-	QueueId       string                 `protobuf:"bytes,3,opt,name=queue_id,json=queueId,proto3" json:"queue_id,omitempty"`
-	Payload       []byte                 `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
-	Key           string                 `protobuf:"bytes,5,opt,name=key,proto3" json:"key,omitempty"`
-	Headers       map[string]string      `protobuf:"bytes,6,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Offset        int64                  `protobuf:"varint,7,opt,name=offset,proto3" json:"offset,omitempty"`
-	DeliveryId    string                 `protobuf:"bytes,8,opt,name=delivery_id,json=deliveryId,proto3" json:"delivery_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Message) Reset() {
-	*x = Message{}
-	mi := &file_broker_proto_msgTypes[16]
+func (x *ExtendAckDeadlineResponse) Reset() {
+	*x = ExtendAckDeadlineResponse{}
+	mi := &file_broker_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Message) String() string {
+func (x *ExtendAckDeadlineResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Message) ProtoMessage() {}
+func (*ExtendAckDeadlineResponse) ProtoMessage() {}
 
-func (x *Message) ProtoReflect() protoreflect.Message {
-	mi := &file_broker_proto_msgTypes[16]
+func (x *ExtendAckDeadlineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[48]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -949,90 +2918,219 @@ func (x *Message) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Message.ProtoReflect.Descriptor instead.
-func (*Message) Descriptor() ([]byte, []int) {
-	return file_broker_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use ExtendAckDeadlineResponse.ProtoReflect.Descriptor instead.
+func (*ExtendAckDeadlineResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{48}
+}
+
+// Правило разрешает клиенту principal ("*" — любому, "anonymous" — без
+// учётных данных) операции над ресурсами, имена которых подходят под
+// pattern: точное имя, префикс с "*" на конце или "*".
+type AclRule struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Principal       string                 `protobuf:"bytes,2,opt,name=principal,proto3" json:"principal,omitempty"`
+	ResourceType    AclResourceType        `protobuf:"varint,3,opt,name=resource_type,json=resourceType,proto3,enum=broker.AclResourceType" json:"resource_type,omitempty"`
+	Pattern         string                 `protobuf:"bytes,4,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	Operations      []AclOperation         `protobuf:"varint,5,rep,packed,name=operations,proto3,enum=broker.AclOperation" json:"operations,omitempty"`
+	CreatedAtUnixMs int64                  `protobuf:"varint,6,opt,name=created_at_unix_ms,json=createdAtUnixMs,proto3" json:"created_at_unix_ms,omitempty"`
+	// Пространство имён ресурсов: "*" — любое; пусто при создании —
+	// пространство запроса. У правил cluster не задаётся.
+	Namespace     string `protobuf:"bytes,7,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Message) GetId() string {
+func (x *AclRule) Reset() {
+	*x = AclRule{}
+	mi := &file_broker_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AclRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AclRule) ProtoMessage() {}
+
+func (x *AclRule) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AclRule.ProtoReflect.Descriptor instead.
+func (*AclRule) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *AclRule) GetId() string {
 	if x != nil {
 		return x.Id
 	}
 	return ""
 }
 
-func (x *Message) GetTopicName() string {
+func (x *AclRule) GetPrincipal() string {
 	if x != nil {
-		return x.TopicName
+		return x.Principal
 	}
 	return ""
 }
 
-func (x *Message) GetQueueId() string {
+func (x *AclRule) GetResourceType() AclResourceType {
 	if x != nil {
-		return x.QueueId
+		return x.ResourceType
+	}
+	return AclResourceType_ACL_RESOURCE_TYPE_UNSPECIFIED
+}
+
+func (x *AclRule) GetPattern() string {
+	if x != nil {
+		return x.Pattern
 	}
 	return ""
 }
 
-func (x *Message) GetPayload() []byte {
+func (x *AclRule) GetOperations() []AclOperation {
 	if x != nil {
-		return x.Payload
+		return x.Operations
 	}
 	return nil
 }
 
-func (x *Message) GetKey() string {
+func (x *AclRule) GetCreatedAtUnixMs() int64 {
 	if x != nil {
-		return x.Key
+		return x.CreatedAtUnixMs
+	}
+	return 0
+}
+
+func (x *AclRule) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
 	}
 	return ""
 }
 
-func (x *Message) GetHeaders() map[string]string {
+// id и created_at_unix_ms заполняет брокер.
+type CreateAclRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          *AclRule               `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAclRuleRequest) Reset() {
+	*x = CreateAclRuleRequest{}
+	mi := &file_broker_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAclRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAclRuleRequest) ProtoMessage() {}
+
+func (x *CreateAclRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[50]
 	if x != nil {
-		return x.Headers
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAclRuleRequest.ProtoReflect.Descriptor instead.
+func (*CreateAclRuleRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *CreateAclRuleRequest) GetRule() *AclRule {
+	if x != nil {
+		return x.Rule
 	}
 	return nil
 }
 
-func (x *Message) GetOffset() int64 {
+type CreateAclRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          *AclRule               `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAclRuleResponse) Reset() {
+	*x = CreateAclRuleResponse{}
+	mi := &file_broker_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAclRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAclRuleResponse) ProtoMessage() {}
+
+func (x *CreateAclRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[51]
 	if x != nil {
-		return x.Offset
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *Message) GetDeliveryId() string {
+// Deprecated: Use CreateAclRuleResponse.ProtoReflect.Descriptor instead.
+func (*CreateAclRuleResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *CreateAclRuleResponse) GetRule() *AclRule {
 	if x != nil {
-		return x.DeliveryId
+		return x.Rule
 	}
-	return ""
+	return nil
 }
 
-type AckRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	SubscriptionId string                 `protobuf:"bytes,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
-	DeliveryId     string                 `protobuf:"bytes,2,opt,name=delivery_id,json=deliveryId,proto3" json:"delivery_id,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+type DeleteAclRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AckRequest) Reset() {
-	*x = AckRequest{}
-	mi := &file_broker_proto_msgTypes[17]
+func (x *DeleteAclRuleRequest) Reset() {
+	*x = DeleteAclRuleRequest{}
+	mi := &file_broker_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AckRequest) String() string {
+func (x *DeleteAclRuleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AckRequest) ProtoMessage() {}
+func (*DeleteAclRuleRequest) ProtoMessage() {}
 
-func (x *AckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_broker_proto_msgTypes[17]
+func (x *DeleteAclRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1043,46 +3141,121 @@ func (x *AckRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AckRequest.ProtoReflect.Descriptor instead.
-func (*AckRequest) Descriptor() ([]byte, []int) {
-	return file_broker_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use DeleteAclRuleRequest.ProtoReflect.Descriptor instead.
+func (*DeleteAclRuleRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{52}
 }
 
-func (x *AckRequest) GetSubscriptionId() string {
+func (x *DeleteAclRuleRequest) GetId() string {
 	if x != nil {
-		return x.SubscriptionId
+		return x.Id
 	}
 	return ""
 }
 
-func (x *AckRequest) GetDeliveryId() string {
+type DeleteAclRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteAclRuleResponse) Reset() {
+	*x = DeleteAclRuleResponse{}
+	mi := &file_broker_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAclRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAclRuleResponse) ProtoMessage() {}
+
+func (x *DeleteAclRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[53]
 	if x != nil {
-		return x.DeliveryId
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAclRuleResponse.ProtoReflect.Descriptor instead.
+func (*DeleteAclRuleResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{53}
+}
+
+// Пустой principal — правила всех клиентов.
+type ListAclRulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Principal     string                 `protobuf:"bytes,1,opt,name=principal,proto3" json:"principal,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAclRulesRequest) Reset() {
+	*x = ListAclRulesRequest{}
+	mi := &file_broker_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAclRulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAclRulesRequest) ProtoMessage() {}
+
+func (x *ListAclRulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAclRulesRequest.ProtoReflect.Descriptor instead.
+func (*ListAclRulesRequest) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *ListAclRulesRequest) GetPrincipal() string {
+	if x != nil {
+		return x.Principal
 	}
 	return ""
 }
 
-type AckResponse struct {
+type ListAclRulesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rules         []*AclRule             `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AckResponse) Reset() {
-	*x = AckResponse{}
-	mi := &file_broker_proto_msgTypes[18]
+func (x *ListAclRulesResponse) Reset() {
+	*x = ListAclRulesResponse{}
+	mi := &file_broker_proto_msgTypes[55]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AckResponse) String() string {
+func (x *ListAclRulesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AckResponse) ProtoMessage() {}
+func (*ListAclRulesResponse) ProtoMessage() {}
 
-func (x *AckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_broker_proto_msgTypes[18]
+func (x *ListAclRulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_broker_proto_msgTypes[55]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1093,9 +3266,16 @@ func (x *AckResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AckResponse.ProtoReflect.Descriptor instead.
-func (*AckResponse) Descriptor() ([]byte, []int) {
-	return file_broker_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use ListAclRulesResponse.ProtoReflect.Descriptor instead.
+func (*ListAclRulesResponse) Descriptor() ([]byte, []int) {
+	return file_broker_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *ListAclRulesResponse) GetRules() []*AclRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
 }
 
 var File_broker_proto protoreflect.FileDescriptor
@@ -1119,10 +3299,11 @@ const file_broker_proto_rawDesc = "" +
 	"\bqueue_id\x18\x02 \x01(\tR\aqueueId\"\x13\n" +
 	"\x11ListTopicsRequest\"?\n" +
 	"\x12ListTopicsResponse\x12)\n" +
-	"\x06topics\x18\x01 \x03(\v2\x11.broker.TopicInfoR\x06topics\"N\n" +
+	"\x06topics\x18\x01 \x03(\v2\x11.broker.TopicInfoR\x06topics\"l\n" +
 	"\tTopicInfo\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12-\n" +
-	"\x12retention_messages\x18\x02 \x01(\x05R\x11retentionMessages\"2\n" +
+	"\x12retention_messages\x18\x02 \x01(\x05R\x11retentionMessages\x12\x1c\n" +
+	"\tnamespace\x18\x03 \x01(\tR\tnamespace\"2\n" +
 	"\x11ListQueuesRequest\x12\x1d\n" +
 	"\n" +
 	"topic_name\x18\x01 \x01(\tR\ttopicName\"?\n" +
@@ -1131,7 +3312,68 @@ const file_broker_proto_rawDesc = "" +
 	"\tQueueInfo\x12\x1d\n" +
 	"\n" +
 	"topic_name\x18\x01 \x01(\tR\ttopicName\x12\x19\n" +
-	"\bqueue_id\x18\x02 \x01(\tR\aqueueId\"\xf1\x01\n" +
+	"\bqueue_id\x18\x02 \x01(\tR\aqueueId\">\n" +
+	"\x12DeleteTopicRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05force\x18\x02 \x01(\bR\x05force\"\x15\n" +
+	"\x13DeleteTopicResponse\"d\n" +
+	"\x12DeleteQueueRequest\x12\x1d\n" +
+	"\n" +
+	"topic_name\x18\x01 \x01(\tR\ttopicName\x12\x19\n" +
+	"\bqueue_id\x18\x02 \x01(\tR\aqueueId\x12\x14\n" +
+	"\x05force\x18\x03 \x01(\bR\x05force\"\x15\n" +
+	"\x13DeleteQueueResponse\"M\n" +
+	"\x11PurgeQueueRequest\x12\x1d\n" +
+	"\n" +
+	"topic_name\x18\x01 \x01(\tR\ttopicName\x12\x19\n" +
+	"\bqueue_id\x18\x02 \x01(\tR\aqueueId\">\n" +
+	"\x12PurgeQueueResponse\x12(\n" +
+	"\x10log_start_offset\x18\x01 \x01(\x03R\x0elogStartOffset\"\xb7\x01\n" +
+	"\x15BrowseMessagesRequest\x12\x1d\n" +
+	"\n" +
+	"topic_name\x18\x01 \x01(\tR\ttopicName\x12\x19\n" +
+	"\bqueue_id\x18\x02 \x01(\tR\aqueueId\x12\x1f\n" +
+	"\vfrom_offset\x18\x03 \x01(\x03R\n" +
+	"fromOffset\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12-\n" +
+	"\x06filter\x18\x05 \x01(\v2\x15.broker.MessageFilterR\x06filter\"\xc6\x01\n" +
+	"\rMessageFilter\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12<\n" +
+	"\aheaders\x18\x02 \x03(\v2\".broker.MessageFilter.HeadersEntryR\aheaders\x12)\n" +
+	"\x10payload_contains\x18\x03 \x01(\fR\x0fpayloadContains\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"f\n" +
+	"\x16BrowseMessagesResponse\x12+\n" +
+	"\bmessages\x18\x01 \x03(\v2\x0f.broker.MessageR\bmessages\x12\x1f\n" +
+	"\vnext_offset\x18\x02 \x01(\x03R\n" +
+	"nextOffset\"l\n" +
+	"\x11GetMessageRequest\x12\x1d\n" +
+	"\n" +
+	"topic_name\x18\x01 \x01(\tR\ttopicName\x12\x19\n" +
+	"\bqueue_id\x18\x02 \x01(\tR\aqueueId\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x03 \x01(\tR\tmessageId\"?\n" +
+	"\x12GetMessageResponse\x12)\n" +
+	"\amessage\x18\x01 \x01(\v2\x0f.broker.MessageR\amessage\"\x17\n" +
+	"\x15GetMemoryUsageRequest\"\xb7\x02\n" +
+	"\x16GetMemoryUsageResponse\x12\x1d\n" +
+	"\n" +
+	"used_bytes\x18\x01 \x01(\x03R\tusedBytes\x12\x1f\n" +
+	"\vlimit_bytes\x18\x02 \x01(\x03R\n" +
+	"limitBytes\x12'\n" +
+	"\x0foverflow_policy\x18\x03 \x01(\tR\x0eoverflowPolicy\x120\n" +
+	"\x06topics\x18\x04 \x03(\v2\x18.broker.TopicMemoryUsageR\x06topics\x12\x1c\n" +
+	"\tnamespace\x18\x05 \x01(\tR\tnamespace\x120\n" +
+	"\x14namespace_used_bytes\x18\x06 \x01(\x03R\x12namespaceUsedBytes\x122\n" +
+	"\x15namespace_limit_bytes\x18\a \x01(\x03R\x13namespaceLimitBytes\"q\n" +
+	"\x10TopicMemoryUsage\x12\x1d\n" +
+	"\n" +
+	"topic_name\x18\x01 \x01(\tR\ttopicName\x12\x1d\n" +
+	"\n" +
+	"used_bytes\x18\x02 \x01(\x03R\tusedBytes\x12\x1f\n" +
+	"\vlimit_bytes\x18\x03 \x01(\x03R\n" +
+	"limitBytes\"\xf1\x01\n" +
 	"\x0ePublishRequest\x12\x1d\n" +
 	"\n" +
 	"topic_name\x18\x01 \x01(\tR\ttopicName\x12\x19\n" +
@@ -1145,24 +3387,71 @@ const file_broker_proto_rawDesc = "" +
 	"\x0fPublishResponse\x12\x1d\n" +
 	"\n" +
 	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x16\n" +
-	"\x06offset\x18\x02 \x01(\x03R\x06offset\"\xbd\x01\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\"I\n" +
+	"\x13PublishBatchRequest\x122\n" +
+	"\bmessages\x18\x01 \x03(\v2\x16.broker.PublishRequestR\bmessages\"p\n" +
+	"\rPublishResult\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\x05R\x04code\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"G\n" +
+	"\x14PublishBatchResponse\x12/\n" +
+	"\aresults\x18\x01 \x03(\v2\x15.broker.PublishResultR\aresults\"\xb1\x02\n" +
 	"\x10SubscribeRequest\x12\x1d\n" +
 	"\n" +
 	"topic_name\x18\x01 \x01(\tR\ttopicName\x12\x19\n" +
 	"\bqueue_id\x18\x02 \x01(\tR\aqueueId\x12%\n" +
 	"\x0econsumer_group\x18\x03 \x01(\tR\rconsumerGroup\x12H\n" +
-	"\x12delivery_guarantee\x18\x04 \x01(\x0e2\x19.broker.DeliveryGuaranteeR\x11deliveryGuarantee\"\x9d\x01\n" +
+	"\x12delivery_guarantee\x18\x04 \x01(\x0e2\x19.broker.DeliveryGuaranteeR\x11deliveryGuarantee\x12;\n" +
+	"\x0estart_position\x18\x05 \x01(\x0e2\x14.broker.SeekPositionR\rstartPosition\x125\n" +
+	"\x17start_timestamp_unix_ms\x18\x06 \x01(\x03R\x14startTimestampUnixMs\"\xb5\x01\n" +
 	"\x11SubscribeResponse\x12'\n" +
 	"\x0fsubscription_id\x18\x01 \x01(\tR\x0esubscriptionId\x12\x1d\n" +
 	"\n" +
 	"topic_name\x18\x02 \x01(\tR\ttopicName\x12\x19\n" +
 	"\bqueue_id\x18\x03 \x01(\tR\aqueueId\x12%\n" +
-	"\x0econsumer_group\x18\x04 \x01(\tR\rconsumerGroup\"\\\n" +
+	"\x0econsumer_group\x18\x04 \x01(\tR\rconsumerGroup\x12\x16\n" +
+	"\x06offset\x18\x05 \x01(\x03R\x06offset\"=\n" +
+	"\x12UnsubscribeRequest\x12'\n" +
+	"\x0fsubscription_id\x18\x01 \x01(\tR\x0esubscriptionId\"\x15\n" +
+	"\x13UnsubscribeResponse\"9\n" +
+	"\x18ListSubscriptionsRequest\x12\x1d\n" +
+	"\n" +
+	"topic_name\x18\x01 \x01(\tR\ttopicName\"[\n" +
+	"\x19ListSubscriptionsResponse\x12>\n" +
+	"\rsubscriptions\x18\x01 \x03(\v2\x18.broker.SubscriptionInfoR\rsubscriptions\"F\n" +
+	"\x1bDescribeSubscriptionRequest\x12'\n" +
+	"\x0fsubscription_id\x18\x01 \x01(\tR\x0esubscriptionId\"\\\n" +
+	"\x1cDescribeSubscriptionResponse\x12<\n" +
+	"\fsubscription\x18\x01 \x01(\v2\x18.broker.SubscriptionInfoR\fsubscription\"\xf8\x03\n" +
+	"\x10SubscriptionInfo\x12'\n" +
+	"\x0fsubscription_id\x18\x01 \x01(\tR\x0esubscriptionId\x12\x1d\n" +
+	"\n" +
+	"topic_name\x18\x02 \x01(\tR\ttopicName\x12\x19\n" +
+	"\bqueue_id\x18\x03 \x01(\tR\aqueueId\x12%\n" +
+	"\x0econsumer_group\x18\x04 \x01(\tR\rconsumerGroup\x12H\n" +
+	"\x12delivery_guarantee\x18\x05 \x01(\x0e2\x19.broker.DeliveryGuaranteeR\x11deliveryGuarantee\x12)\n" +
+	"\x10committed_offset\x18\x06 \x01(\x03R\x0fcommittedOffset\x12%\n" +
+	"\x0ehigh_watermark\x18\a \x01(\x03R\rhighWatermark\x12\x10\n" +
+	"\x03lag\x18\b \x01(\x03R\x03lag\x12#\n" +
+	"\rpending_count\x18\t \x01(\x05R\fpendingCount\x121\n" +
+	"\x15oldest_pending_age_ms\x18\n" +
+	" \x01(\x03R\x12oldestPendingAgeMs\x126\n" +
+	"\x18last_consumed_at_unix_ms\x18\v \x01(\x03R\x14lastConsumedAtUnixMs\x12\x1c\n" +
+	"\tnamespace\x18\f \x01(\tR\tnamespace\"\xb8\x01\n" +
+	"\x17SeekSubscriptionRequest\x12'\n" +
+	"\x0fsubscription_id\x18\x01 \x01(\tR\x0esubscriptionId\x120\n" +
+	"\bposition\x18\x02 \x01(\x0e2\x14.broker.SeekPositionR\bposition\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\x12*\n" +
+	"\x11timestamp_unix_ms\x18\x04 \x01(\x03R\x0ftimestampUnixMs\"2\n" +
+	"\x18SeekSubscriptionResponse\x12\x16\n" +
+	"\x06offset\x18\x01 \x01(\x03R\x06offset\"\\\n" +
 	"\x0eConsumeRequest\x12'\n" +
 	"\x0fsubscription_id\x18\x01 \x01(\tR\x0esubscriptionId\x12!\n" +
 	"\fmax_messages\x18\x02 \x01(\x05R\vmaxMessages\">\n" +
 	"\x0fConsumeResponse\x12+\n" +
-	"\bmessages\x18\x01 \x03(\v2\x0f.broker.MessageR\bmessages\"\xac\x02\n" +
+	"\bmessages\x18\x01 \x03(\v2\x0f.broker.MessageR\bmessages\"\xd9\x02\n" +
 	"\aMessage\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
 	"\n" +
@@ -1173,7 +3462,8 @@ const file_broker_proto_rawDesc = "" +
 	"\aheaders\x18\x06 \x03(\v2\x1c.broker.Message.HeadersEntryR\aheaders\x12\x16\n" +
 	"\x06offset\x18\a \x01(\x03R\x06offset\x12\x1f\n" +
 	"\vdelivery_id\x18\b \x01(\tR\n" +
-	"deliveryId\x1a:\n" +
+	"deliveryId\x12+\n" +
+	"\x12created_at_unix_ms\x18\t \x01(\x03R\x0fcreatedAtUnixMs\x1a:\n" +
 	"\fHeadersEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"V\n" +
@@ -1182,22 +3472,90 @@ const file_broker_proto_rawDesc = "" +
 	"\x0fsubscription_id\x18\x01 \x01(\tR\x0esubscriptionId\x12\x1f\n" +
 	"\vdelivery_id\x18\x02 \x01(\tR\n" +
 	"deliveryId\"\r\n" +
-	"\vAckResponse*\\\n" +
+	"\vAckResponse\"W\n" +
+	"\vNackRequest\x12'\n" +
+	"\x0fsubscription_id\x18\x01 \x01(\tR\x0esubscriptionId\x12\x1f\n" +
+	"\vdelivery_id\x18\x02 \x01(\tR\n" +
+	"deliveryId\"\x0e\n" +
+	"\fNackResponse\"\x91\x01\n" +
+	"\x18ExtendAckDeadlineRequest\x12'\n" +
+	"\x0fsubscription_id\x18\x01 \x01(\tR\x0esubscriptionId\x12\x1f\n" +
+	"\vdelivery_id\x18\x02 \x01(\tR\n" +
+	"deliveryId\x12+\n" +
+	"\x11extension_seconds\x18\x03 \x01(\x05R\x10extensionSeconds\"\x1b\n" +
+	"\x19ExtendAckDeadlineResponse\"\x90\x02\n" +
+	"\aAclRule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1c\n" +
+	"\tprincipal\x18\x02 \x01(\tR\tprincipal\x12<\n" +
+	"\rresource_type\x18\x03 \x01(\x0e2\x17.broker.AclResourceTypeR\fresourceType\x12\x18\n" +
+	"\apattern\x18\x04 \x01(\tR\apattern\x124\n" +
+	"\n" +
+	"operations\x18\x05 \x03(\x0e2\x14.broker.AclOperationR\n" +
+	"operations\x12+\n" +
+	"\x12created_at_unix_ms\x18\x06 \x01(\x03R\x0fcreatedAtUnixMs\x12\x1c\n" +
+	"\tnamespace\x18\a \x01(\tR\tnamespace\";\n" +
+	"\x14CreateAclRuleRequest\x12#\n" +
+	"\x04rule\x18\x01 \x01(\v2\x0f.broker.AclRuleR\x04rule\"<\n" +
+	"\x15CreateAclRuleResponse\x12#\n" +
+	"\x04rule\x18\x01 \x01(\v2\x0f.broker.AclRuleR\x04rule\"&\n" +
+	"\x14DeleteAclRuleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x17\n" +
+	"\x15DeleteAclRuleResponse\"3\n" +
+	"\x13ListAclRulesRequest\x12\x1c\n" +
+	"\tprincipal\x18\x01 \x01(\tR\tprincipal\"=\n" +
+	"\x14ListAclRulesResponse\x12%\n" +
+	"\x05rules\x18\x01 \x03(\v2\x0f.broker.AclRuleR\x05rules*\\\n" +
 	"\x11DeliveryGuarantee\x12\"\n" +
 	"\x1eDELIVERY_GUARANTEE_UNSPECIFIED\x10\x00\x12\x10\n" +
 	"\fAT_MOST_ONCE\x10\x01\x12\x11\n" +
-	"\rAT_LEAST_ONCE\x10\x022\x8c\x04\n" +
+	"\rAT_LEAST_ONCE\x10\x02*b\n" +
+	"\fSeekPosition\x12\x1d\n" +
+	"\x19SEEK_POSITION_UNSPECIFIED\x10\x00\x12\f\n" +
+	"\bEARLIEST\x10\x01\x12\n" +
+	"\n" +
+	"\x06LATEST\x10\x02\x12\n" +
+	"\n" +
+	"\x06OFFSET\x10\x03\x12\r\n" +
+	"\tTIMESTAMP\x10\x04*~\n" +
+	"\x0fAclResourceType\x12!\n" +
+	"\x1dACL_RESOURCE_TYPE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12ACL_RESOURCE_TOPIC\x10\x01\x12\x16\n" +
+	"\x12ACL_RESOURCE_GROUP\x10\x02\x12\x18\n" +
+	"\x14ACL_RESOURCE_CLUSTER\x10\x03*|\n" +
+	"\fAclOperation\x12\x1d\n" +
+	"\x19ACL_OPERATION_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15ACL_OPERATION_PUBLISH\x10\x01\x12\x19\n" +
+	"\x15ACL_OPERATION_CONSUME\x10\x02\x12\x17\n" +
+	"\x13ACL_OPERATION_ADMIN\x10\x032\xe3\r\n" +
 	"\x06Broker\x12F\n" +
 	"\vCreateTopic\x12\x1a.broker.CreateTopicRequest\x1a\x1b.broker.CreateTopicResponse\x12F\n" +
 	"\vCreateQueue\x12\x1a.broker.CreateQueueRequest\x1a\x1b.broker.CreateQueueResponse\x12C\n" +
 	"\n" +
 	"ListTopics\x12\x19.broker.ListTopicsRequest\x1a\x1a.broker.ListTopicsResponse\x12C\n" +
 	"\n" +
-	"ListQueues\x12\x19.broker.ListQueuesRequest\x1a\x1a.broker.ListQueuesResponse\x12:\n" +
-	"\aPublish\x12\x16.broker.PublishRequest\x1a\x17.broker.PublishResponse\x12@\n" +
-	"\tSubscribe\x12\x18.broker.SubscribeRequest\x1a\x19.broker.SubscribeResponse\x12:\n" +
+	"ListQueues\x12\x19.broker.ListQueuesRequest\x1a\x1a.broker.ListQueuesResponse\x12F\n" +
+	"\vDeleteTopic\x12\x1a.broker.DeleteTopicRequest\x1a\x1b.broker.DeleteTopicResponse\x12F\n" +
+	"\vDeleteQueue\x12\x1a.broker.DeleteQueueRequest\x1a\x1b.broker.DeleteQueueResponse\x12C\n" +
+	"\n" +
+	"PurgeQueue\x12\x19.broker.PurgeQueueRequest\x1a\x1a.broker.PurgeQueueResponse\x12O\n" +
+	"\x0eBrowseMessages\x12\x1d.broker.BrowseMessagesRequest\x1a\x1e.broker.BrowseMessagesResponse\x12C\n" +
+	"\n" +
+	"GetMessage\x12\x19.broker.GetMessageRequest\x1a\x1a.broker.GetMessageResponse\x12O\n" +
+	"\x0eGetMemoryUsage\x12\x1d.broker.GetMemoryUsageRequest\x1a\x1e.broker.GetMemoryUsageResponse\x12:\n" +
+	"\aPublish\x12\x16.broker.PublishRequest\x1a\x17.broker.PublishResponse\x12I\n" +
+	"\fPublishBatch\x12\x1b.broker.PublishBatchRequest\x1a\x1c.broker.PublishBatchResponse\x12@\n" +
+	"\tSubscribe\x12\x18.broker.SubscribeRequest\x1a\x19.broker.SubscribeResponse\x12F\n" +
+	"\vUnsubscribe\x12\x1a.broker.UnsubscribeRequest\x1a\x1b.broker.UnsubscribeResponse\x12X\n" +
+	"\x11ListSubscriptions\x12 .broker.ListSubscriptionsRequest\x1a!.broker.ListSubscriptionsResponse\x12a\n" +
+	"\x14DescribeSubscription\x12#.broker.DescribeSubscriptionRequest\x1a$.broker.DescribeSubscriptionResponse\x12U\n" +
+	"\x10SeekSubscription\x12\x1f.broker.SeekSubscriptionRequest\x1a .broker.SeekSubscriptionResponse\x12:\n" +
 	"\aConsume\x12\x16.broker.ConsumeRequest\x1a\x17.broker.ConsumeResponse\x12.\n" +
-	"\x03Ack\x12\x12.broker.AckRequest\x1a\x13.broker.AckResponseB,Z*queue-service/internal/delivery/grpc/pb;pbb\x06proto3"
+	"\x03Ack\x12\x12.broker.AckRequest\x1a\x13.broker.AckResponse\x121\n" +
+	"\x04Nack\x12\x13.broker.NackRequest\x1a\x14.broker.NackResponse\x12X\n" +
+	"\x11ExtendAckDeadline\x12 .broker.ExtendAckDeadlineRequest\x1a!.broker.ExtendAckDeadlineResponse\x12L\n" +
+	"\rCreateAclRule\x12\x1c.broker.CreateAclRuleRequest\x1a\x1d.broker.CreateAclRuleResponse\x12L\n" +
+	"\rDeleteAclRule\x12\x1c.broker.DeleteAclRuleRequest\x1a\x1d.broker.DeleteAclRuleResponse\x12I\n" +
+	"\fListAclRules\x12\x1b.broker.ListAclRulesRequest\x1a\x1c.broker.ListAclRulesResponseB,Z*queue-service/internal/delivery/grpc/pb;pbb\x06proto3"
 
 var (
 	file_broker_proto_rawDescOnce sync.Once
@@ -1211,60 +3569,150 @@ func file_broker_proto_rawDescGZIP() []byte {
 	return file_broker_proto_rawDescData
 }
 
-var file_broker_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_broker_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_broker_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_broker_proto_msgTypes = make([]protoimpl.MessageInfo, 59)
 var file_broker_proto_goTypes = []any{
-	(DeliveryGuarantee)(0),      // 0: broker.DeliveryGuarantee
-	(*CreateTopicRequest)(nil),  // 1: broker.CreateTopicRequest
-	(*CreateTopicResponse)(nil), // 2: broker.CreateTopicResponse
-	(*CreateQueueRequest)(nil),  // 3: broker.CreateQueueRequest
-	(*CreateQueueResponse)(nil), // 4: broker.CreateQueueResponse
-	(*ListTopicsRequest)(nil),   // 5: broker.ListTopicsRequest
-	(*ListTopicsResponse)(nil),  // 6: broker.ListTopicsResponse
-	(*TopicInfo)(nil),           // 7: broker.TopicInfo
-	(*ListQueuesRequest)(nil),   // 8: broker.ListQueuesRequest
-	(*ListQueuesResponse)(nil),  // 9: broker.ListQueuesResponse
-	(*QueueInfo)(nil),           // 10: broker.QueueInfo
-	(*PublishRequest)(nil),      // 11: broker.PublishRequest
-	(*PublishResponse)(nil),     // 12: broker.PublishResponse
-	(*SubscribeRequest)(nil),    // 13: broker.SubscribeRequest
-	(*SubscribeResponse)(nil),   // 14: broker.SubscribeResponse
-	(*ConsumeRequest)(nil),      // 15: broker.ConsumeRequest
-	(*ConsumeResponse)(nil),     // 16: broker.ConsumeResponse
-	(*Message)(nil),             // 17: broker.Message
-	(*AckRequest)(nil),          // 18: broker.AckRequest
-	(*AckResponse)(nil),         // 19: broker.AckResponse
-	nil,                         // 20: broker.PublishRequest.HeadersEntry
-	nil,                         // 21: broker.Message.HeadersEntry
+	(DeliveryGuarantee)(0),               // 0: broker.DeliveryGuarantee
+	(SeekPosition)(0),                    // 1: broker.SeekPosition
+	(AclResourceType)(0),                 // 2: broker.AclResourceType
+	(AclOperation)(0),                    // 3: broker.AclOperation
+	(*CreateTopicRequest)(nil),           // 4: broker.CreateTopicRequest
+	(*CreateTopicResponse)(nil),          // 5: broker.CreateTopicResponse
+	(*CreateQueueRequest)(nil),           // 6: broker.CreateQueueRequest
+	(*CreateQueueResponse)(nil),          // 7: broker.CreateQueueResponse
+	(*ListTopicsRequest)(nil),            // 8: broker.ListTopicsRequest
+	(*ListTopicsResponse)(nil),           // 9: broker.ListTopicsResponse
+	(*TopicInfo)(nil),                    // 10: broker.TopicInfo
+	(*ListQueuesRequest)(nil),            // 11: broker.ListQueuesRequest
+	(*ListQueuesResponse)(nil),           // 12: broker.ListQueuesResponse
+	(*QueueInfo)(nil),                    // 13: broker.QueueInfo
+	(*DeleteTopicRequest)(nil),           // 14: broker.DeleteTopicRequest
+	(*DeleteTopicResponse)(nil),          // 15: broker.DeleteTopicResponse
+	(*DeleteQueueRequest)(nil),           // 16: broker.DeleteQueueRequest
+	(*DeleteQueueResponse)(nil),          // 17: broker.DeleteQueueResponse
+	(*PurgeQueueRequest)(nil),            // 18: broker.PurgeQueueRequest
+	(*PurgeQueueResponse)(nil),           // 19: broker.PurgeQueueResponse
+	(*BrowseMessagesRequest)(nil),        // 20: broker.BrowseMessagesRequest
+	(*MessageFilter)(nil),                // 21: broker.MessageFilter
+	(*BrowseMessagesResponse)(nil),       // 22: broker.BrowseMessagesResponse
+	(*GetMessageRequest)(nil),            // 23: broker.GetMessageRequest
+	(*GetMessageResponse)(nil),           // 24: broker.GetMessageResponse
+	(*GetMemoryUsageRequest)(nil),        // 25: broker.GetMemoryUsageRequest
+	(*GetMemoryUsageResponse)(nil),       // 26: broker.GetMemoryUsageResponse
+	(*TopicMemoryUsage)(nil),             // 27: broker.TopicMemoryUsage
+	(*PublishRequest)(nil),               // 28: broker.PublishRequest
+	(*PublishResponse)(nil),              // 29: broker.PublishResponse
+	(*PublishBatchRequest)(nil),          // 30: broker.PublishBatchRequest
+	(*PublishResult)(nil),                // 31: broker.PublishResult
+	(*PublishBatchResponse)(nil),         // 32: broker.PublishBatchResponse
+	(*SubscribeRequest)(nil),             // 33: broker.SubscribeRequest
+	(*SubscribeResponse)(nil),            // 34: broker.SubscribeResponse
+	(*UnsubscribeRequest)(nil),           // 35: broker.UnsubscribeRequest
+	(*UnsubscribeResponse)(nil),          // 36: broker.UnsubscribeResponse
+	(*ListSubscriptionsRequest)(nil),     // 37: broker.ListSubscriptionsRequest
+	(*ListSubscriptionsResponse)(nil),    // 38: broker.ListSubscriptionsResponse
+	(*DescribeSubscriptionRequest)(nil),  // 39: broker.DescribeSubscriptionRequest
+	(*DescribeSubscriptionResponse)(nil), // 40: broker.DescribeSubscriptionResponse
+	(*SubscriptionInfo)(nil),             // 41: broker.SubscriptionInfo
+	(*SeekSubscriptionRequest)(nil),      // 42: broker.SeekSubscriptionRequest
+	(*SeekSubscriptionResponse)(nil),     // 43: broker.SeekSubscriptionResponse
+	(*ConsumeRequest)(nil),               // 44: broker.ConsumeRequest
+	(*ConsumeResponse)(nil),              // 45: broker.ConsumeResponse
+	(*Message)(nil),                      // 46: broker.Message
+	(*AckRequest)(nil),                   // 47: broker.AckRequest
+	(*AckResponse)(nil),                  // 48: broker.AckResponse
+	(*NackRequest)(nil),                  // 49: broker.NackRequest
+	(*NackResponse)(nil),                 // 50: broker.NackResponse
+	(*ExtendAckDeadlineRequest)(nil),     // 51: broker.ExtendAckDeadlineRequest
+	(*ExtendAckDeadlineResponse)(nil),    // 52: broker.ExtendAckDeadlineResponse
+	(*AclRule)(nil),                      // 53: broker.AclRule
+	(*CreateAclRuleRequest)(nil),         // 54: broker.CreateAclRuleRequest
+	(*CreateAclRuleResponse)(nil),        // 55: broker.CreateAclRuleResponse
+	(*DeleteAclRuleRequest)(nil),         // 56: broker.DeleteAclRuleRequest
+	(*DeleteAclRuleResponse)(nil),        // 57: broker.DeleteAclRuleResponse
+	(*ListAclRulesRequest)(nil),          // 58: broker.ListAclRulesRequest
+	(*ListAclRulesResponse)(nil),         // 59: broker.ListAclRulesResponse
+	nil,                                  // 60: broker.MessageFilter.HeadersEntry
+	nil,                                  // 61: broker.PublishRequest.HeadersEntry
+	nil,                                  // 62: broker.Message.HeadersEntry
 }
 var file_broker_proto_depIdxs = []int32{
-	7,  // 0: broker.ListTopicsResponse.topics:type_name -> broker.TopicInfo
-	10, // 1: broker.ListQueuesResponse.queues:type_name -> broker.QueueInfo
-	20, // 2: broker.PublishRequest.headers:type_name -> broker.PublishRequest.HeadersEntry
-	0,  // 3: broker.SubscribeRequest.delivery_guarantee:type_name -> broker.DeliveryGuarantee
-	17, // 4: broker.ConsumeResponse.messages:type_name -> broker.Message
-	21, // 5: broker.Message.headers:type_name -> broker.Message.HeadersEntry
-	1,  // 6: broker.Broker.CreateTopic:input_type -> broker.CreateTopicRequest
-	3,  // 7: broker.Broker.CreateQueue:input_type -> broker.CreateQueueRequest
-	5,  // 8: broker.Broker.ListTopics:input_type -> broker.ListTopicsRequest
-	8,  // 9: broker.Broker.ListQueues:input_type -> broker.ListQueuesRequest
-	11, // 10: broker.Broker.Publish:input_type -> broker.PublishRequest
-	13, // 11: broker.Broker.Subscribe:input_type -> broker.SubscribeRequest
-	15, // 12: broker.Broker.Consume:input_type -> broker.ConsumeRequest
-	18, // 13: broker.Broker.Ack:input_type -> broker.AckRequest
-	2,  // 14: broker.Broker.CreateTopic:output_type -> broker.CreateTopicResponse
-	4,  // 15: broker.Broker.CreateQueue:output_type -> broker.CreateQueueResponse
-	6,  // 16: broker.Broker.ListTopics:output_type -> broker.ListTopicsResponse
-	9,  // 17: broker.Broker.ListQueues:output_type -> broker.ListQueuesResponse
-	12, // 18: broker.Broker.Publish:output_type -> broker.PublishResponse
-	14, // 19: broker.Broker.Subscribe:output_type -> broker.SubscribeResponse
-	16, // 20: broker.Broker.Consume:output_type -> broker.ConsumeResponse
-	19, // 21: broker.Broker.Ack:output_type -> broker.AckResponse
-	14, // [14:22] is the sub-list for method output_type
-	6,  // [6:14] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	10, // 0: broker.ListTopicsResponse.topics:type_name -> broker.TopicInfo
+	13, // 1: broker.ListQueuesResponse.queues:type_name -> broker.QueueInfo
+	21, // 2: broker.BrowseMessagesRequest.filter:type_name -> broker.MessageFilter
+	60, // 3: broker.MessageFilter.headers:type_name -> broker.MessageFilter.HeadersEntry
+	46, // 4: broker.BrowseMessagesResponse.messages:type_name -> broker.Message
+	46, // 5: broker.GetMessageResponse.message:type_name -> broker.Message
+	27, // 6: broker.GetMemoryUsageResponse.topics:type_name -> broker.TopicMemoryUsage
+	61, // 7: broker.PublishRequest.headers:type_name -> broker.PublishRequest.HeadersEntry
+	28, // 8: broker.PublishBatchRequest.messages:type_name -> broker.PublishRequest
+	31, // 9: broker.PublishBatchResponse.results:type_name -> broker.PublishResult
+	0,  // 10: broker.SubscribeRequest.delivery_guarantee:type_name -> broker.DeliveryGuarantee
+	1,  // 11: broker.SubscribeRequest.start_position:type_name -> broker.SeekPosition
+	41, // 12: broker.ListSubscriptionsResponse.subscriptions:type_name -> broker.SubscriptionInfo
+	41, // 13: broker.DescribeSubscriptionResponse.subscription:type_name -> broker.SubscriptionInfo
+	0,  // 14: broker.SubscriptionInfo.delivery_guarantee:type_name -> broker.DeliveryGuarantee
+	1,  // 15: broker.SeekSubscriptionRequest.position:type_name -> broker.SeekPosition
+	46, // 16: broker.ConsumeResponse.messages:type_name -> broker.Message
+	62, // 17: broker.Message.headers:type_name -> broker.Message.HeadersEntry
+	2,  // 18: broker.AclRule.resource_type:type_name -> broker.AclResourceType
+	3,  // 19: broker.AclRule.operations:type_name -> broker.AclOperation
+	53, // 20: broker.CreateAclRuleRequest.rule:type_name -> broker.AclRule
+	53, // 21: broker.CreateAclRuleResponse.rule:type_name -> broker.AclRule
+	53, // 22: broker.ListAclRulesResponse.rules:type_name -> broker.AclRule
+	4,  // 23: broker.Broker.CreateTopic:input_type -> broker.CreateTopicRequest
+	6,  // 24: broker.Broker.CreateQueue:input_type -> broker.CreateQueueRequest
+	8,  // 25: broker.Broker.ListTopics:input_type -> broker.ListTopicsRequest
+	11, // 26: broker.Broker.ListQueues:input_type -> broker.ListQueuesRequest
+	14, // 27: broker.Broker.DeleteTopic:input_type -> broker.DeleteTopicRequest
+	16, // 28: broker.Broker.DeleteQueue:input_type -> broker.DeleteQueueRequest
+	18, // 29: broker.Broker.PurgeQueue:input_type -> broker.PurgeQueueRequest
+	20, // 30: broker.Broker.BrowseMessages:input_type -> broker.BrowseMessagesRequest
+	23, // 31: broker.Broker.GetMessage:input_type -> broker.GetMessageRequest
+	25, // 32: broker.Broker.GetMemoryUsage:input_type -> broker.GetMemoryUsageRequest
+	28, // 33: broker.Broker.Publish:input_type -> broker.PublishRequest
+	30, // 34: broker.Broker.PublishBatch:input_type -> broker.PublishBatchRequest
+	33, // 35: broker.Broker.Subscribe:input_type -> broker.SubscribeRequest
+	35, // 36: broker.Broker.Unsubscribe:input_type -> broker.UnsubscribeRequest
+	37, // 37: broker.Broker.ListSubscriptions:input_type -> broker.ListSubscriptionsRequest
+	39, // 38: broker.Broker.DescribeSubscription:input_type -> broker.DescribeSubscriptionRequest
+	42, // 39: broker.Broker.SeekSubscription:input_type -> broker.SeekSubscriptionRequest
+	44, // 40: broker.Broker.Consume:input_type -> broker.ConsumeRequest
+	47, // 41: broker.Broker.Ack:input_type -> broker.AckRequest
+	49, // 42: broker.Broker.Nack:input_type -> broker.NackRequest
+	51, // 43: broker.Broker.ExtendAckDeadline:input_type -> broker.ExtendAckDeadlineRequest
+	54, // 44: broker.Broker.CreateAclRule:input_type -> broker.CreateAclRuleRequest
+	56, // 45: broker.Broker.DeleteAclRule:input_type -> broker.DeleteAclRuleRequest
+	58, // 46: broker.Broker.ListAclRules:input_type -> broker.ListAclRulesRequest
+	5,  // 47: broker.Broker.CreateTopic:output_type -> broker.CreateTopicResponse
+	7,  // 48: broker.Broker.CreateQueue:output_type -> broker.CreateQueueResponse
+	9,  // 49: broker.Broker.ListTopics:output_type -> broker.ListTopicsResponse
+	12, // 50: broker.Broker.ListQueues:output_type -> broker.ListQueuesResponse
+	15, // 51: broker.Broker.DeleteTopic:output_type -> broker.DeleteTopicResponse
+	17, // 52: broker.Broker.DeleteQueue:output_type -> broker.DeleteQueueResponse
+	19, // 53: broker.Broker.PurgeQueue:output_type -> broker.PurgeQueueResponse
+	22, // 54: broker.Broker.BrowseMessages:output_type -> broker.BrowseMessagesResponse
+	24, // 55: broker.Broker.GetMessage:output_type -> broker.GetMessageResponse
+	26, // 56: broker.Broker.GetMemoryUsage:output_type -> broker.GetMemoryUsageResponse
+	29, // 57: broker.Broker.Publish:output_type -> broker.PublishResponse
+	32, // 58: broker.Broker.PublishBatch:output_type -> broker.PublishBatchResponse
+	34, // 59: broker.Broker.Subscribe:output_type -> broker.SubscribeResponse
+	36, // 60: broker.Broker.Unsubscribe:output_type -> broker.UnsubscribeResponse
+	38, // 61: broker.Broker.ListSubscriptions:output_type -> broker.ListSubscriptionsResponse
+	40, // 62: broker.Broker.DescribeSubscription:output_type -> broker.DescribeSubscriptionResponse
+	43, // 63: broker.Broker.SeekSubscription:output_type -> broker.SeekSubscriptionResponse
+	45, // 64: broker.Broker.Consume:output_type -> broker.ConsumeResponse
+	48, // 65: broker.Broker.Ack:output_type -> broker.AckResponse
+	50, // 66: broker.Broker.Nack:output_type -> broker.NackResponse
+	52, // 67: broker.Broker.ExtendAckDeadline:output_type -> broker.ExtendAckDeadlineResponse
+	55, // 68: broker.Broker.CreateAclRule:output_type -> broker.CreateAclRuleResponse
+	57, // 69: broker.Broker.DeleteAclRule:output_type -> broker.DeleteAclRuleResponse
+	59, // 70: broker.Broker.ListAclRules:output_type -> broker.ListAclRulesResponse
+	47, // [47:71] is the sub-list for method output_type
+	23, // [23:47] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
 }
 
 func init() { file_broker_proto_init() }
@@ -1277,8 +3725,8 @@ func file_broker_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_broker_proto_rawDesc), len(file_broker_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   21,
+			NumEnums:      4,
+			NumMessages:   59,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,14 +19,30 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Broker_CreateTopic_FullMethodName = "/broker.Broker/CreateTopic"
-	Broker_CreateQueue_FullMethodName = "/broker.Broker/CreateQueue"
-	Broker_ListTopics_FullMethodName  = "/broker.Broker/ListTopics"
-	Broker_ListQueues_FullMethodName  = "/broker.Broker/ListQueues"
-	Broker_Publish_FullMethodName     = "/broker.Broker/Publish"
-	Broker_Subscribe_FullMethodName   = "/broker.Broker/Subscribe"
-	Broker_Consume_FullMethodName     = "/broker.Broker/Consume"
-	Broker_Ack_FullMethodName         = "/broker.Broker/Ack"
+	Broker_CreateTopic_FullMethodName          = "/broker.Broker/CreateTopic"
+	Broker_CreateQueue_FullMethodName          = "/broker.Broker/CreateQueue"
+	Broker_ListTopics_FullMethodName           = "/broker.Broker/ListTopics"
+	Broker_ListQueues_FullMethodName           = "/broker.Broker/ListQueues"
+	Broker_DeleteTopic_FullMethodName          = "/broker.Broker/DeleteTopic"
+	Broker_DeleteQueue_FullMethodName          = "/broker.Broker/DeleteQueue"
+	Broker_PurgeQueue_FullMethodName           = "/broker.Broker/PurgeQueue"
+	Broker_BrowseMessages_FullMethodName       = "/broker.Broker/BrowseMessages"
+	Broker_GetMessage_FullMethodName           = "/broker.Broker/GetMessage"
+	Broker_GetMemoryUsage_FullMethodName       = "/broker.Broker/GetMemoryUsage"
+	Broker_Publish_FullMethodName              = "/broker.Broker/Publish"
+	Broker_PublishBatch_FullMethodName         = "/broker.Broker/PublishBatch"
+	Broker_Subscribe_FullMethodName            = "/broker.Broker/Subscribe"
+	Broker_Unsubscribe_FullMethodName          = "/broker.Broker/Unsubscribe"
+	Broker_ListSubscriptions_FullMethodName    = "/broker.Broker/ListSubscriptions"
+	Broker_DescribeSubscription_FullMethodName = "/broker.Broker/DescribeSubscription"
+	Broker_SeekSubscription_FullMethodName     = "/broker.Broker/SeekSubscription"
+	Broker_Consume_FullMethodName              = "/broker.Broker/Consume"
+	Broker_Ack_FullMethodName                  = "/broker.Broker/Ack"
+	Broker_Nack_FullMethodName                 = "/broker.Broker/Nack"
+	Broker_ExtendAckDeadline_FullMethodName    = "/broker.Broker/ExtendAckDeadline"
+	Broker_CreateAclRule_FullMethodName        = "/broker.Broker/CreateAclRule"
+	Broker_DeleteAclRule_FullMethodName        = "/broker.Broker/DeleteAclRule"
+	Broker_ListAclRules_FullMethodName         = "/broker.Broker/ListAclRules"
 )
 
 // BrokerClient is the client API for Broker service.
@@ -37,10 +53,27 @@ type BrokerClient interface {
 	CreateQueue(ctx context.Context, in *CreateQueueRequest, opts ...grpc.CallOption) (*CreateQueueResponse, error)
 	ListTopics(ctx context.Context, in *ListTopicsRequest, opts ...grpc.CallOption) (*ListTopicsResponse, error)
 	ListQueues(ctx context.Context, in *ListQueuesRequest, opts ...grpc.CallOption) (*ListQueuesResponse, error)
+	DeleteTopic(ctx context.Context, in *DeleteTopicRequest, opts ...grpc.CallOption) (*DeleteTopicResponse, error)
+	DeleteQueue(ctx context.Context, in *DeleteQueueRequest, opts ...grpc.CallOption) (*DeleteQueueResponse, error)
+	PurgeQueue(ctx context.Context, in *PurgeQueueRequest, opts ...grpc.CallOption) (*PurgeQueueResponse, error)
+	BrowseMessages(ctx context.Context, in *BrowseMessagesRequest, opts ...grpc.CallOption) (*BrowseMessagesResponse, error)
+	GetMessage(ctx context.Context, in *GetMessageRequest, opts ...grpc.CallOption) (*GetMessageResponse, error)
+	GetMemoryUsage(ctx context.Context, in *GetMemoryUsageRequest, opts ...grpc.CallOption) (*GetMemoryUsageResponse, error)
 	Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*PublishResponse, error)
+	PublishBatch(ctx context.Context, in *PublishBatchRequest, opts ...grpc.CallOption) (*PublishBatchResponse, error)
 	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (*SubscribeResponse, error)
+	Unsubscribe(ctx context.Context, in *UnsubscribeRequest, opts ...grpc.CallOption) (*UnsubscribeResponse, error)
+	ListSubscriptions(ctx context.Context, in *ListSubscriptionsRequest, opts ...grpc.CallOption) (*ListSubscriptionsResponse, error)
+	DescribeSubscription(ctx context.Context, in *DescribeSubscriptionRequest, opts ...grpc.CallOption) (*DescribeSubscriptionResponse, error)
+	SeekSubscription(ctx context.Context, in *SeekSubscriptionRequest, opts ...grpc.CallOption) (*SeekSubscriptionResponse, error)
 	Consume(ctx context.Context, in *ConsumeRequest, opts ...grpc.CallOption) (*ConsumeResponse, error)
 	Ack(ctx context.Context, in *AckRequest, opts ...grpc.CallOption) (*AckResponse, error)
+	Nack(ctx context.Context, in *NackRequest, opts ...grpc.CallOption) (*NackResponse, error)
+	ExtendAckDeadline(ctx context.Context, in *ExtendAckDeadlineRequest, opts ...grpc.CallOption) (*ExtendAckDeadlineResponse, error)
+	// Правила доступа; требуют права admin на ресурс cluster.
+	CreateAclRule(ctx context.Context, in *CreateAclRuleRequest, opts ...grpc.CallOption) (*CreateAclRuleResponse, error)
+	DeleteAclRule(ctx context.Context, in *DeleteAclRuleRequest, opts ...grpc.CallOption) (*DeleteAclRuleResponse, error)
+	ListAclRules(ctx context.Context, in *ListAclRulesRequest, opts ...grpc.CallOption) (*ListAclRulesResponse, error)
 }
 
 type brokerClient struct {
@@ -91,6 +124,66 @@ func (c *brokerClient) ListQueues(ctx context.Context, in *ListQueuesRequest, op
 	return out, nil
 }
 
+func (c *brokerClient) DeleteTopic(ctx context.Context, in *DeleteTopicRequest, opts ...grpc.CallOption) (*DeleteTopicResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteTopicResponse)
+	err := c.cc.Invoke(ctx, Broker_DeleteTopic_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) DeleteQueue(ctx context.Context, in *DeleteQueueRequest, opts ...grpc.CallOption) (*DeleteQueueResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteQueueResponse)
+	err := c.cc.Invoke(ctx, Broker_DeleteQueue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) PurgeQueue(ctx context.Context, in *PurgeQueueRequest, opts ...grpc.CallOption) (*PurgeQueueResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurgeQueueResponse)
+	err := c.cc.Invoke(ctx, Broker_PurgeQueue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) BrowseMessages(ctx context.Context, in *BrowseMessagesRequest, opts ...grpc.CallOption) (*BrowseMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BrowseMessagesResponse)
+	err := c.cc.Invoke(ctx, Broker_BrowseMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) GetMessage(ctx context.Context, in *GetMessageRequest, opts ...grpc.CallOption) (*GetMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMessageResponse)
+	err := c.cc.Invoke(ctx, Broker_GetMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) GetMemoryUsage(ctx context.Context, in *GetMemoryUsageRequest, opts ...grpc.CallOption) (*GetMemoryUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMemoryUsageResponse)
+	err := c.cc.Invoke(ctx, Broker_GetMemoryUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *brokerClient) Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*PublishResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PublishResponse)
@@ -101,6 +194,16 @@ func (c *brokerClient) Publish(ctx context.Context, in *PublishRequest, opts ...
 	return out, nil
 }
 
+func (c *brokerClient) PublishBatch(ctx context.Context, in *PublishBatchRequest, opts ...grpc.CallOption) (*PublishBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PublishBatchResponse)
+	err := c.cc.Invoke(ctx, Broker_PublishBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *brokerClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (*SubscribeResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SubscribeResponse)
@@ -111,6 +214,46 @@ func (c *brokerClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts
 	return out, nil
 }
 
+func (c *brokerClient) Unsubscribe(ctx context.Context, in *UnsubscribeRequest, opts ...grpc.CallOption) (*UnsubscribeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnsubscribeResponse)
+	err := c.cc.Invoke(ctx, Broker_Unsubscribe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) ListSubscriptions(ctx context.Context, in *ListSubscriptionsRequest, opts ...grpc.CallOption) (*ListSubscriptionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSubscriptionsResponse)
+	err := c.cc.Invoke(ctx, Broker_ListSubscriptions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) DescribeSubscription(ctx context.Context, in *DescribeSubscriptionRequest, opts ...grpc.CallOption) (*DescribeSubscriptionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DescribeSubscriptionResponse)
+	err := c.cc.Invoke(ctx, Broker_DescribeSubscription_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) SeekSubscription(ctx context.Context, in *SeekSubscriptionRequest, opts ...grpc.CallOption) (*SeekSubscriptionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SeekSubscriptionResponse)
+	err := c.cc.Invoke(ctx, Broker_SeekSubscription_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *brokerClient) Consume(ctx context.Context, in *ConsumeRequest, opts ...grpc.CallOption) (*ConsumeResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ConsumeResponse)
@@ -131,6 +274,56 @@ func (c *brokerClient) Ack(ctx context.Context, in *AckRequest, opts ...grpc.Cal
 	return out, nil
 }
 
+func (c *brokerClient) Nack(ctx context.Context, in *NackRequest, opts ...grpc.CallOption) (*NackResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NackResponse)
+	err := c.cc.Invoke(ctx, Broker_Nack_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) ExtendAckDeadline(ctx context.Context, in *ExtendAckDeadlineRequest, opts ...grpc.CallOption) (*ExtendAckDeadlineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExtendAckDeadlineResponse)
+	err := c.cc.Invoke(ctx, Broker_ExtendAckDeadline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) CreateAclRule(ctx context.Context, in *CreateAclRuleRequest, opts ...grpc.CallOption) (*CreateAclRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateAclRuleResponse)
+	err := c.cc.Invoke(ctx, Broker_CreateAclRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) DeleteAclRule(ctx context.Context, in *DeleteAclRuleRequest, opts ...grpc.CallOption) (*DeleteAclRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteAclRuleResponse)
+	err := c.cc.Invoke(ctx, Broker_DeleteAclRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *brokerClient) ListAclRules(ctx context.Context, in *ListAclRulesRequest, opts ...grpc.CallOption) (*ListAclRulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAclRulesResponse)
+	err := c.cc.Invoke(ctx, Broker_ListAclRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BrokerServer is the server API for Broker service.
 // All implementations must embed UnimplementedBrokerServer
 // for forward compatibility.
@@ -139,10 +332,27 @@ type BrokerServer interface {
 	CreateQueue(context.Context, *CreateQueueRequest) (*CreateQueueResponse, error)
 	ListTopics(context.Context, *ListTopicsRequest) (*ListTopicsResponse, error)
 	ListQueues(context.Context, *ListQueuesRequest) (*ListQueuesResponse, error)
+	DeleteTopic(context.Context, *DeleteTopicRequest) (*DeleteTopicResponse, error)
+	DeleteQueue(context.Context, *DeleteQueueRequest) (*DeleteQueueResponse, error)
+	PurgeQueue(context.Context, *PurgeQueueRequest) (*PurgeQueueResponse, error)
+	BrowseMessages(context.Context, *BrowseMessagesRequest) (*BrowseMessagesResponse, error)
+	GetMessage(context.Context, *GetMessageRequest) (*GetMessageResponse, error)
+	GetMemoryUsage(context.Context, *GetMemoryUsageRequest) (*GetMemoryUsageResponse, error)
 	Publish(context.Context, *PublishRequest) (*PublishResponse, error)
+	PublishBatch(context.Context, *PublishBatchRequest) (*PublishBatchResponse, error)
 	Subscribe(context.Context, *SubscribeRequest) (*SubscribeResponse, error)
+	Unsubscribe(context.Context, *UnsubscribeRequest) (*UnsubscribeResponse, error)
+	ListSubscriptions(context.Context, *ListSubscriptionsRequest) (*ListSubscriptionsResponse, error)
+	DescribeSubscription(context.Context, *DescribeSubscriptionRequest) (*DescribeSubscriptionResponse, error)
+	SeekSubscription(context.Context, *SeekSubscriptionRequest) (*SeekSubscriptionResponse, error)
 	Consume(context.Context, *ConsumeRequest) (*ConsumeResponse, error)
 	Ack(context.Context, *AckRequest) (*AckResponse, error)
+	Nack(context.Context, *NackRequest) (*NackResponse, error)
+	ExtendAckDeadline(context.Context, *ExtendAckDeadlineRequest) (*ExtendAckDeadlineResponse, error)
+	// Правила доступа; требуют права admin на ресурс cluster.
+	CreateAclRule(context.Context, *CreateAclRuleRequest) (*CreateAclRuleResponse, error)
+	DeleteAclRule(context.Context, *DeleteAclRuleRequest) (*DeleteAclRuleResponse, error)
+	ListAclRules(context.Context, *ListAclRulesRequest) (*ListAclRulesResponse, error)
 	mustEmbedUnimplementedBrokerServer()
 }
 
@@ -165,18 +375,66 @@ func (UnimplementedBrokerServer) ListTopics(context.Context, *ListTopicsRequest)
 func (UnimplementedBrokerServer) ListQueues(context.Context, *ListQueuesRequest) (*ListQueuesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ListQueues not implemented")
 }
+func (UnimplementedBrokerServer) DeleteTopic(context.Context, *DeleteTopicRequest) (*DeleteTopicResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteTopic not implemented")
+}
+func (UnimplementedBrokerServer) DeleteQueue(context.Context, *DeleteQueueRequest) (*DeleteQueueResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteQueue not implemented")
+}
+func (UnimplementedBrokerServer) PurgeQueue(context.Context, *PurgeQueueRequest) (*PurgeQueueResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PurgeQueue not implemented")
+}
+func (UnimplementedBrokerServer) BrowseMessages(context.Context, *BrowseMessagesRequest) (*BrowseMessagesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BrowseMessages not implemented")
+}
+func (UnimplementedBrokerServer) GetMessage(context.Context, *GetMessageRequest) (*GetMessageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetMessage not implemented")
+}
+func (UnimplementedBrokerServer) GetMemoryUsage(context.Context, *GetMemoryUsageRequest) (*GetMemoryUsageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetMemoryUsage not implemented")
+}
 func (UnimplementedBrokerServer) Publish(context.Context, *PublishRequest) (*PublishResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Publish not implemented")
 }
+func (UnimplementedBrokerServer) PublishBatch(context.Context, *PublishBatchRequest) (*PublishBatchResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PublishBatch not implemented")
+}
 func (UnimplementedBrokerServer) Subscribe(context.Context, *SubscribeRequest) (*SubscribeResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Subscribe not implemented")
 }
+func (UnimplementedBrokerServer) Unsubscribe(context.Context, *UnsubscribeRequest) (*UnsubscribeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Unsubscribe not implemented")
+}
+func (UnimplementedBrokerServer) ListSubscriptions(context.Context, *ListSubscriptionsRequest) (*ListSubscriptionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSubscriptions not implemented")
+}
+func (UnimplementedBrokerServer) DescribeSubscription(context.Context, *DescribeSubscriptionRequest) (*DescribeSubscriptionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DescribeSubscription not implemented")
+}
+func (UnimplementedBrokerServer) SeekSubscription(context.Context, *SeekSubscriptionRequest) (*SeekSubscriptionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SeekSubscription not implemented")
+}
 func (UnimplementedBrokerServer) Consume(context.Context, *ConsumeRequest) (*ConsumeResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Consume not implemented")
 }
 func (UnimplementedBrokerServer) Ack(context.Context, *AckRequest) (*AckResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Ack not implemented")
 }
+func (UnimplementedBrokerServer) Nack(context.Context, *NackRequest) (*NackResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Nack not implemented")
+}
+func (UnimplementedBrokerServer) ExtendAckDeadline(context.Context, *ExtendAckDeadlineRequest) (*ExtendAckDeadlineResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ExtendAckDeadline not implemented")
+}
+func (UnimplementedBrokerServer) CreateAclRule(context.Context, *CreateAclRuleRequest) (*CreateAclRuleResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateAclRule not implemented")
+}
+func (UnimplementedBrokerServer) DeleteAclRule(context.Context, *DeleteAclRuleRequest) (*DeleteAclRuleResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteAclRule not implemented")
+}
+func (UnimplementedBrokerServer) ListAclRules(context.Context, *ListAclRulesRequest) (*ListAclRulesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListAclRules not implemented")
+}
 func (UnimplementedBrokerServer) mustEmbedUnimplementedBrokerServer() {}
 func (UnimplementedBrokerServer) testEmbeddedByValue()                {}
 
@@ -270,6 +528,114 @@ func _Broker_ListQueues_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Broker_DeleteTopic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTopicRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).DeleteTopic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_DeleteTopic_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).DeleteTopic(ctx, req.(*DeleteTopicRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_DeleteQueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteQueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).DeleteQueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_DeleteQueue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).DeleteQueue(ctx, req.(*DeleteQueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_PurgeQueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeQueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).PurgeQueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_PurgeQueue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).PurgeQueue(ctx, req.(*PurgeQueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_BrowseMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BrowseMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).BrowseMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_BrowseMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).BrowseMessages(ctx, req.(*BrowseMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_GetMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).GetMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_GetMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).GetMessage(ctx, req.(*GetMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_GetMemoryUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMemoryUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).GetMemoryUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_GetMemoryUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).GetMemoryUsage(ctx, req.(*GetMemoryUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Broker_Publish_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PublishRequest)
 	if err := dec(in); err != nil {
@@ -288,6 +654,24 @@ func _Broker_Publish_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Broker_PublishBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).PublishBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_PublishBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).PublishBatch(ctx, req.(*PublishBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Broker_Subscribe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SubscribeRequest)
 	if err := dec(in); err != nil {
@@ -306,6 +690,78 @@ func _Broker_Subscribe_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Broker_Unsubscribe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnsubscribeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).Unsubscribe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_Unsubscribe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).Unsubscribe(ctx, req.(*UnsubscribeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_ListSubscriptions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSubscriptionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).ListSubscriptions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_ListSubscriptions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).ListSubscriptions(ctx, req.(*ListSubscriptionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_DescribeSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribeSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).DescribeSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_DescribeSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).DescribeSubscription(ctx, req.(*DescribeSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_SeekSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SeekSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).SeekSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_SeekSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).SeekSubscription(ctx, req.(*SeekSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Broker_Consume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ConsumeRequest)
 	if err := dec(in); err != nil {
@@ -342,6 +798,96 @@ func _Broker_Ack_Handler(srv interface{}, ctx context.Context, dec func(interfac
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Broker_Nack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).Nack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_Nack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).Nack(ctx, req.(*NackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_ExtendAckDeadline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExtendAckDeadlineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).ExtendAckDeadline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_ExtendAckDeadline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).ExtendAckDeadline(ctx, req.(*ExtendAckDeadlineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_CreateAclRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAclRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).CreateAclRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_CreateAclRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).CreateAclRule(ctx, req.(*CreateAclRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_DeleteAclRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAclRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).DeleteAclRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_DeleteAclRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).DeleteAclRule(ctx, req.(*DeleteAclRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Broker_ListAclRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAclRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BrokerServer).ListAclRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Broker_ListAclRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BrokerServer).ListAclRules(ctx, req.(*ListAclRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Broker_ServiceDesc is the grpc.ServiceDesc for Broker service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -365,14 +911,58 @@ var Broker_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListQueues",
 			Handler:    _Broker_ListQueues_Handler,
 		},
+		{
+			MethodName: "DeleteTopic",
+			Handler:    _Broker_DeleteTopic_Handler,
+		},
+		{
+			MethodName: "DeleteQueue",
+			Handler:    _Broker_DeleteQueue_Handler,
+		},
+		{
+			MethodName: "PurgeQueue",
+			Handler:    _Broker_PurgeQueue_Handler,
+		},
+		{
+			MethodName: "BrowseMessages",
+			Handler:    _Broker_BrowseMessages_Handler,
+		},
+		{
+			MethodName: "GetMessage",
+			Handler:    _Broker_GetMessage_Handler,
+		},
+		{
+			MethodName: "GetMemoryUsage",
+			Handler:    _Broker_GetMemoryUsage_Handler,
+		},
 		{
 			MethodName: "Publish",
 			Handler:    _Broker_Publish_Handler,
 		},
+		{
+			MethodName: "PublishBatch",
+			Handler:    _Broker_PublishBatch_Handler,
+		},
 		{
 			MethodName: "Subscribe",
 			Handler:    _Broker_Subscribe_Handler,
 		},
+		{
+			MethodName: "Unsubscribe",
+			Handler:    _Broker_Unsubscribe_Handler,
+		},
+		{
+			MethodName: "ListSubscriptions",
+			Handler:    _Broker_ListSubscriptions_Handler,
+		},
+		{
+			MethodName: "DescribeSubscription",
+			Handler:    _Broker_DescribeSubscription_Handler,
+		},
+		{
+			MethodName: "SeekSubscription",
+			Handler:    _Broker_SeekSubscription_Handler,
+		},
 		{
 			MethodName: "Consume",
 			Handler:    _Broker_Consume_Handler,
@@ -381,6 +971,26 @@ var Broker_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Ack",
 			Handler:    _Broker_Ack_Handler,
 		},
+		{
+			MethodName: "Nack",
+			Handler:    _Broker_Nack_Handler,
+		},
+		{
+			MethodName: "ExtendAckDeadline",
+			Handler:    _Broker_ExtendAckDeadline_Handler,
+		},
+		{
+			MethodName: "CreateAclRule",
+			Handler:    _Broker_CreateAclRule_Handler,
+		},
+		{
+			MethodName: "DeleteAclRule",
+			Handler:    _Broker_DeleteAclRule_Handler,
+		},
+		{
+			MethodName: "ListAclRules",
+			Handler:    _Broker_ListAclRules_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "broker.proto",
@@ -0,0 +1,55 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"queue-service/internal/usecase"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// retryAfterHeader — метаданные ответа RESOURCE_EXHAUSTED из-за лимита
+// частоты: через сколько миллисекунд стоит повторить вызов.
+const retryAfterHeader = "retry-after-ms"
+
+// setRetryAfter ставит в заголовки ответа паузу перед повтором, округлённую
+// вверх до миллисекунды. Вне RPC-сервера (в тестах) ничего не делает.
+func setRetryAfter(ctx context.Context, wait time.Duration) {
+	ms := max((wait+time.Millisecond-1)/time.Millisecond, 1)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(retryAfterHeader, strconv.FormatInt(int64(ms), 10)))
+}
+
+func rateLimitError(ctx context.Context, err error, wait time.Duration) error {
+	setRetryAfter(ctx, wait)
+	return errResourceExhausted(err.Error())
+}
+
+func quotaError(err error) error {
+	if errors.Is(err, usecase.ErrQuotaExceeded) {
+		return errResourceExhausted(err.Error())
+	}
+	return errInternal(err)
+}
+
+// reserveConsume резервирует до want сообщений из лимитов чтения клиента и
+// топика подписки; release возвращает то, что не было выдано. Неизвестную
+// подписку пропускает: NOT_FOUND вернёт сам вызов.
+func (h *BrokerHandler) reserveConsume(ctx context.Context, subscriptionID string, want int) (n int, release func(consumed int), err error) {
+	nop := func(int) {}
+	if !h.quotas.Enabled() {
+		return want, nop, nil
+	}
+	sub, err := h.subscribe.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return want, nop, nil
+	}
+	n, wait, err := h.quotas.ReserveConsume(ctx, sub.TopicName, want)
+	if err != nil {
+		return 0, nop, rateLimitError(ctx, err, wait)
+	}
+	return n, func(consumed int) { h.quotas.ReleaseConsume(ctx, sub.TopicName, n-consumed) }, nil
+}
@@ -0,0 +1,89 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"queue-service/internal/delivery/grpc/pb"
+	"queue-service/internal/usecase"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// headerStream запоминает заголовки ответа, которые ставит обработчик.
+type headerStream struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func withHeaderStream(ctx context.Context) (context.Context, *headerStream) {
+	s := &headerStream{}
+	return grpc.NewContextWithServerTransportStream(ctx, s), s
+}
+
+func TestBrokerHandler_rateLimits(t *testing.T) {
+	h := newTestHandlerWith(t, testPolicies{quotas: usecase.QuotaPolicy{
+		Principal: usecase.Quota{PublishMessagesPerSec: 1, MaxTopics: 1},
+		Topic:     usecase.Quota{ConsumeMessagesPerSec: 2},
+	}})
+	orders := asClient("orders")
+	if _, err := h.CreateTopic(orders, &pb.CreateTopicRequest{Name: "events"}); err != nil {
+		t.Fatalf("CreateTopic: %v", err)
+	}
+	if _, err := h.CreateTopic(orders, &pb.CreateTopicRequest{Name: "more"}); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("second topic: %v", err)
+	}
+
+	if _, err := h.Publish(orders, &pb.PublishRequest{TopicName: "events", QueueId: "0", Payload: []byte("a")}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	ctx, stream := withHeaderStream(orders)
+	_, err := h.Publish(ctx, &pb.PublishRequest{TopicName: "events", QueueId: "0", Payload: []byte("b")})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("throttled Publish: %v", err)
+	}
+	if v := stream.header.Get(retryAfterHeader); len(v) != 1 || v[0] != "1000" {
+		t.Fatalf("retry-after-ms: %v", v)
+	}
+
+	// У другого клиента своя корзина; в пакете отклоняются только сообщения сверх лимита.
+	if _, err := h.Publish(asClient("billing"), &pb.PublishRequest{TopicName: "events", QueueId: "0", Payload: []byte("c")}); err != nil {
+		t.Fatalf("Publish by billing: %v", err)
+	}
+	ctx, stream = withHeaderStream(asClient("batch"))
+	resp, err := h.PublishBatch(ctx, &pb.PublishBatchRequest{Messages: []*pb.PublishRequest{
+		{TopicName: "events", QueueId: "0", Payload: []byte("d")},
+		{TopicName: "events", QueueId: "0", Payload: []byte("e")},
+	}})
+	if err != nil {
+		t.Fatalf("PublishBatch: %v", err)
+	}
+	if resp.Results[0].Code != 0 || resp.Results[1].Code != int32(codes.ResourceExhausted) || len(stream.header.Get(retryAfterHeader)) != 1 {
+		t.Fatalf("batch results %v, header %v", resp.Results, stream.header)
+	}
+
+	// Три сообщения в очереди, а лимит чтения топика — 2 в секунду.
+	sub, err := h.Subscribe(orders, &pb.SubscribeRequest{TopicName: "events", QueueId: "0", ConsumerGroup: "g"})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	got, err := h.Consume(orders, &pb.ConsumeRequest{SubscriptionId: sub.SubscriptionId, MaxMessages: 10})
+	if err != nil || len(got.Messages) != 2 {
+		t.Fatalf("Consume: %v, %v", got, err)
+	}
+	ctx, stream = withHeaderStream(orders)
+	if _, err := h.Consume(ctx, &pb.ConsumeRequest{SubscriptionId: sub.SubscriptionId, MaxMessages: 10}); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("throttled Consume: %v", err)
+	}
+	if len(stream.header.Get(retryAfterHeader)) != 1 {
+		t.Fatalf("Consume header: %v", stream.header)
+	}
+}
@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"queue-service/internal/domain"
+	"queue-service/internal/usecase"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// namespaceHeader — метаданные, которыми клиент выбирает пространство имён.
+const namespaceHeader = "x-namespace"
+
+type namespaceResolver struct {
+	tenants *usecase.TenantUseCase
+}
+
+func (r namespaceResolver) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+		return handler(ctx, req)
+	}
+	var requested string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(namespaceHeader); len(v) > 0 {
+			requested = v[0]
+		}
+	}
+	ns, err := r.tenants.Resolve(ctx, requested)
+	if err != nil {
+		return nil, tenantError(err)
+	}
+	return handler(domain.WithNamespace(ctx, ns), req)
+}
+
+func tenantError(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrNamespaceDenied):
+		return errPermissionDenied(err.Error())
+	case errors.Is(err, usecase.ErrInvalidNamespace):
+		return errInvalidArg(err.Error())
+	case errors.Is(err, usecase.ErrQuotaExceeded):
+		return errResourceExhausted(err.Error())
+	}
+	return errInternal(err)
+}
+
+// NamespaceUnaryInterceptor возвращает параметр сервера, который определяет
+// пространство имён вызова по клиенту и метаданным x-namespace и кладёт его
+// в контекст. Ставится после аутентификации: привязка к арендатору идёт по
+// имени клиента.
+func NamespaceUnaryInterceptor(t *usecase.TenantUseCase) grpc.ServerOption {
+	return grpc.ChainUnaryInterceptor(namespaceResolver{tenants: t}.unary)
+}
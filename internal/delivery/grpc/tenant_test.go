@@ -0,0 +1,86 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"queue-service/internal/delivery/grpc/pb"
+	"queue-service/internal/domain"
+	"queue-service/internal/usecase"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestNamespaceResolver(t *testing.T) {
+	h := newTestHandlerWith(t, testPolicies{tenancy: usecase.TenantPolicy{
+		Enabled: true,
+		Admins:  []string{"root"},
+		Quota:   usecase.TenantQuota{MaxTopics: 1},
+		Tenants: []usecase.Tenant{
+			{Namespace: "shop", Principals: []string{"orders"}},
+			{Namespace: "blog", Principals: []string{"posts"}},
+		},
+	}})
+	r := namespaceResolver{tenants: h.tenants}
+	call := func(ctx context.Context, header string, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+		if header != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(namespaceHeader, header))
+		}
+		return r.unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/broker.Broker/CreateTopic"},
+			func(ctx context.Context, _ interface{}) (interface{}, error) { return fn(ctx) })
+	}
+	createTopic := func(ctx context.Context, header string) error {
+		_, err := call(ctx, header, func(ctx context.Context) (interface{}, error) {
+			return h.CreateTopic(ctx, &pb.CreateTopicRequest{Name: "orders", RetentionMessages: 100})
+		})
+		return err
+	}
+	listTopics := func(ctx context.Context, header string) []*pb.TopicInfo {
+		resp, err := call(ctx, header, func(ctx context.Context) (interface{}, error) {
+			return h.ListTopics(ctx, &pb.ListTopicsRequest{})
+		})
+		if err != nil {
+			t.Fatalf("ListTopics: %v", err)
+		}
+		return resp.(*pb.ListTopicsResponse).Topics
+	}
+
+	// Одноимённые топики в разных пространствах не конфликтуют.
+	if err := createTopic(asClient("orders"), ""); err != nil {
+		t.Fatalf("CreateTopic in shop: %v", err)
+	}
+	if err := createTopic(asClient("posts"), ""); err != nil {
+		t.Fatalf("CreateTopic in blog: %v", err)
+	}
+	if err := createTopic(asClient("root"), "blog"); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("topic quota: %v", err)
+	}
+	if err := createTopic(asClient("orders"), "blog"); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("foreign namespace: %v", err)
+	}
+	if err := createTopic(asClient("root"), "a/b"); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("invalid namespace: %v", err)
+	}
+
+	topics := listTopics(asClient("root"), "shop")
+	if len(topics) != 1 || topics[0].Namespace != "shop" {
+		t.Fatalf("ListTopics in shop: %v", topics)
+	}
+	if topics := listTopics(context.Background(), ""); len(topics) != 0 {
+		t.Fatalf("default namespace sees %v", topics)
+	}
+
+	// Подписка из другого пространства не видна по ID.
+	shop := domain.WithNamespace(context.Background(), "shop")
+	sub, err := h.Subscribe(shop, &pb.SubscribeRequest{TopicName: "orders", QueueId: "0", ConsumerGroup: "g"})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	blog := domain.WithNamespace(context.Background(), "blog")
+	if _, err := h.Consume(blog, &pb.ConsumeRequest{SubscriptionId: sub.SubscriptionId, MaxMessages: 1}); status.Code(err) != codes.NotFound {
+		t.Fatalf("Consume foreign subscription: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package grpc
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// metadataCarrier читает заголовки W3C Trace Context из метаданных gRPC.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) { metadata.MD(c).Set(key, value) }
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func tracingUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = propagation.TraceContext{}.Extract(ctx, metadataCarrier(md))
+	}
+	return handler(ctx, req)
+}
+
+// TracingUnaryInterceptor возвращает параметр сервера, который переносит
+// контекст трассы из метаданных traceparent/tracestate в контекст запроса:
+// span публикации становится дочерним для span отправителя.
+func TracingUnaryInterceptor() grpc.ServerOption {
+	return grpc.ChainUnaryInterceptor(tracingUnaryInterceptor)
+}
@@ -0,0 +1,28 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestTracingUnaryInterceptor(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/broker.Broker/Publish"}
+
+	var got trace.SpanContext
+	_, err := tracingUnaryInterceptor(ctx, nil, info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		got = trace.SpanContextFromContext(ctx)
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || got.SpanID().String() != "00f067aa0ba902b7" || !got.IsRemote() {
+		t.Errorf("span context = %+v", got)
+	}
+}
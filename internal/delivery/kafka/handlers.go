@@ -0,0 +1,817 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"queue-service/internal/domain"
+	"queue-service/internal/usecase"
+)
+
+const (
+	nodeID    int32 = 0
+	clusterID       = "mini-message-broker"
+
+	timestampLatest   = -1
+	timestampEarliest = -2
+
+	coordinatorKeyGroup = 0
+
+	// Сколько сообщений читать из очереди за один вызов при сборке Fetch.
+	fetchChunk = 100
+	// Оценка накладных расходов записи в RecordBatch сверх ключа и значения.
+	recordOverhead = 20
+	// Операции авторизации не поддерживаются (INT32_MIN по протоколу).
+	authorizedOpsUnknown int32 = -2147483648
+)
+
+func (s *Server) apiVersions(version int16, errorCode int16) []byte {
+	keys := make([]int16, 0, len(supportedAPIs))
+	for k := range supportedAPIs {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	var e encoder
+	e.int16(errorCode)
+	e.arrayLen(len(keys))
+	for _, k := range keys {
+		e.int16(k)
+		e.int16(supportedAPIs[k].min)
+		e.int16(supportedAPIs[k].max)
+	}
+	if version >= 1 {
+		e.int32(0) // throttle_time_ms
+	}
+	return e.buf
+}
+
+// partitions возвращает номера партиций топика — очереди с числовыми ID.
+func (s *Server) partitions(ctx context.Context, topic string) ([]int32, error) {
+	if _, err := s.topics.GetTopic(ctx, topic); err != nil {
+		return nil, usecase.ErrTopicNotFound
+	}
+	queues, err := s.topics.ListQueues(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+	var out []int32
+	for _, q := range queues {
+		if p, ok := partitionOf(q.QueueID); ok {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out, nil
+}
+
+// partitionOf: в Kafka видны только очереди вида "0", "1", ... (без ведущих нулей).
+func partitionOf(queueID string) (int32, bool) {
+	n, err := strconv.ParseInt(queueID, 10, 32)
+	if err != nil || n < 0 || strconv.FormatInt(n, 10) != queueID {
+		return 0, false
+	}
+	return int32(n), true
+}
+
+func queueOf(partition int32) string { return strconv.FormatInt(int64(partition), 10) }
+
+func errorCode(err error) int16 {
+	switch {
+	case err == nil:
+		return errNone
+	case errors.Is(err, usecase.ErrTopicNotFound), errors.Is(err, usecase.ErrQueueNotFound), errors.Is(err, domain.ErrNotFound):
+		return errUnknownTopicOrPartition
+	case errors.Is(err, usecase.ErrPermissionDenied):
+		return errTopicAuthFailed
+	case errors.Is(err, usecase.ErrMessageTooLarge):
+		return errMessageTooLarge
+	case errors.Is(err, usecase.ErrMemoryLimit):
+		// Повторяемая ошибка: продюсер Kafka повторит запись позже.
+		return errKafkaStorageError
+	case errors.Is(err, usecase.ErrRateLimited):
+		// Лимит не восстановился за timeout_ms запроса; тоже повторяемая.
+		return errRequestTimedOut
+	}
+	return errUnknownServerError
+}
+
+func (s *Server) handleMetadata(ctx context.Context, v int16, d *decoder) ([]byte, error) {
+	n := d.arrayLen()
+	all := n == -1 || (v == 0 && n == 0)
+	names := make([]string, 0, max(n, 0))
+	for i := 0; i < n; i++ {
+		names = append(names, d.string())
+	}
+	if v >= 4 {
+		_ = d.bool() // allow_auto_topic_creation: топики создаются только явно
+	}
+	if v >= 8 {
+		_ = d.bool() // include_cluster_authorized_operations
+		_ = d.bool() // include_topic_authorized_operations
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if all {
+		topics, err := s.topics.ListTopics(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// Список всех топиков показывает только доступные клиенту.
+		for _, t := range topics {
+			if s.acl.CanAccess(ctx, domain.ACLTopic, t.Name) {
+				names = append(names, t.Name)
+			}
+		}
+		sort.Strings(names)
+	}
+
+	var e encoder
+	if v >= 3 {
+		e.int32(0) // throttle_time_ms
+	}
+	e.arrayLen(1)
+	e.int32(nodeID)
+	e.string(s.host)
+	e.int32(s.port)
+	if v >= 1 {
+		e.nullableString(nil) // rack
+	}
+	if v >= 2 {
+		id := clusterID
+		e.nullableString(&id)
+	}
+	if v >= 1 {
+		e.int32(nodeID) // controller_id
+	}
+	e.arrayLen(len(names))
+	for _, name := range names {
+		var parts []int32
+		err := s.describe(ctx, name)
+		if err == nil {
+			parts, err = s.partitions(ctx, name)
+		}
+		e.int16(errorCode(err))
+		e.string(name)
+		if v >= 1 {
+			e.bool(false) // is_internal
+		}
+		e.arrayLen(len(parts))
+		for _, p := range parts {
+			e.int16(errNone)
+			e.int32(p)
+			e.int32(nodeID) // leader_id
+			if v >= 7 {
+				e.int32(0) // leader_epoch
+			}
+			e.int32Array([]int32{nodeID}) // replica_nodes
+			e.int32Array([]int32{nodeID}) // isr_nodes
+			if v >= 5 {
+				e.int32Array(nil) // offline_replicas
+			}
+		}
+		if v >= 8 {
+			e.int32(authorizedOpsUnknown)
+		}
+	}
+	if v >= 8 {
+		e.int32(authorizedOpsUnknown)
+	}
+	return e.buf, nil
+}
+
+type produceResult struct {
+	partition  int32
+	errorCode  int16
+	baseOffset int64
+	appendTime int64 // log_append_time_ms
+	logStart   int64
+}
+
+func (s *Server) handleProduce(ctx context.Context, v int16, d *decoder) ([]byte, bool, error) {
+	_ = d.nullableString() // transactional_id: транзакции не поддерживаются
+	acks := d.int16()
+	deadline := time.Now().Add(time.Duration(d.int32()) * time.Millisecond)
+	var throttle time.Duration
+
+	type topicResults struct {
+		name    string
+		results []produceResult
+	}
+	var out []topicResults
+	nt := d.arrayLen()
+	for i := 0; i < nt && d.err == nil; i++ {
+		tr := topicResults{name: d.string()}
+		authErr := s.acl.Authorize(ctx, domain.ACLPublish, domain.ACLTopic, tr.name)
+		np := d.arrayLen()
+		for j := 0; j < np && d.err == nil; j++ {
+			partition := d.int32()
+			raw := d.bytes()
+			if d.err != nil {
+				break
+			}
+			if authErr != nil {
+				tr.results = append(tr.results, produceResult{partition: partition, errorCode: errTopicAuthFailed, baseOffset: -1, appendTime: -1, logStart: -1})
+				continue
+			}
+			res, waited := s.produce(ctx, tr.name, partition, raw, deadline)
+			tr.results = append(tr.results, res)
+			throttle += waited
+		}
+		out = append(out, tr)
+	}
+	if d.err != nil {
+		return nil, false, d.err
+	}
+	if acks == 0 {
+		return nil, false, nil
+	}
+
+	var e encoder
+	e.arrayLen(len(out))
+	for _, tr := range out {
+		e.string(tr.name)
+		e.arrayLen(len(tr.results))
+		for _, r := range tr.results {
+			e.int32(r.partition)
+			e.int16(r.errorCode)
+			e.int64(r.baseOffset)
+			e.int64(r.appendTime)
+			if v >= 5 {
+				e.int64(r.logStart)
+			}
+			if v >= 8 {
+				e.arrayLen(0)         // record_errors
+				e.nullableString(nil) // error_message
+			}
+		}
+	}
+	e.int32(int32(throttle.Milliseconds())) // throttle_time_ms
+	return e.buf, true, nil
+}
+
+// produce публикует записи одной партиции одним пакетом PublishUseCase и
+// возвращает, сколько пришлось ждать лимита частоты. Пакет записывается
+// целиком или не записывается вовсе, поэтому повтор после ошибки не
+// дублирует записи.
+func (s *Server) produce(ctx context.Context, topic string, partition int32, raw []byte, deadline time.Time) (produceResult, time.Duration) {
+	res := produceResult{partition: partition, baseOffset: -1, appendTime: -1, logStart: -1}
+	records, err := decodeRecordBatches(raw)
+	if err != nil {
+		res.errorCode = recordsErrorCode(err)
+		return res, 0
+	}
+	queueID := queueOf(partition)
+	batch := make([]usecase.Record, len(records))
+	size := 0
+	for i, rec := range records {
+		batch[i] = usecase.Record{Payload: rec.value, Key: string(rec.key), Headers: rec.headers}
+		size += len(rec.value)
+	}
+	// Лимит частоты проверяется для всего пакета до записи.
+	throttle, err := s.allowPublish(ctx, topic, len(batch), size, deadline)
+	if err != nil {
+		res.errorCode = errorCode(err)
+		return res, throttle
+	}
+	msgs, err := s.publish.PublishBatch(ctx, topic, queueID, batch)
+	if err != nil {
+		res.errorCode = errorCode(err)
+		return res, throttle
+	}
+	if len(msgs) > 0 {
+		res.baseOffset = msgs[0].Offset
+		res.appendTime = msgs[0].CreatedAt.UnixMilli()
+	}
+	if start, _, err := s.messages.Offsets(ctx, topic, queueID); err == nil {
+		res.logStart = start
+	}
+	return res, throttle
+}
+
+// allowPublish ждёт, пока лимит частоты разрешит публикацию count сообщений
+// общим размером size байт в topic, и возвращает время ожидания: на него задерживается ответ Produce, как у
+// Kafka до KIP-219. Если лимит не восстановится до deadline, возвращается
+// ErrRateLimited, а время ожидания включает и недождавшийся остаток, чтобы
+// клиент выдержал паузу сам.
+func (s *Server) allowPublish(ctx context.Context, topic string, count, size int, deadline time.Time) (time.Duration, error) {
+	var waited time.Duration
+	for {
+		wait, err := s.quotas.AllowPublishBatch(ctx, topic, count, size)
+		if err == nil {
+			return waited, nil
+		}
+		if time.Now().Add(wait).After(deadline) {
+			return waited + wait, err
+		}
+		select {
+		case <-ctx.Done():
+			return waited, ctx.Err()
+		case <-time.After(wait):
+		}
+		waited += wait
+	}
+}
+
+type fetchPartition struct {
+	partition int32
+	offset    int64
+	maxBytes  int32
+}
+
+type fetchTopic struct {
+	name       string
+	partitions []fetchPartition
+}
+
+type fetchResult struct {
+	partition int32
+	errorCode int16
+	hwm       int64
+	logStart  int64
+	records   []byte
+	count     int // сообщений в records, списанных с лимита чтения
+}
+
+func (s *Server) handleFetch(ctx context.Context, v int16, d *decoder) ([]byte, error) {
+	_ = d.int32() // replica_id
+	maxWait := time.Duration(d.int32()) * time.Millisecond
+	minBytes := int(d.int32())
+	maxBytes := int(d.int32())
+	_ = d.int8() // isolation_level: транзакций нет, read_committed = read_uncommitted
+	if v >= 7 {
+		_ = d.int32() // session_id: сессии не поддерживаются, каждый запрос полный
+		_ = d.int32() // session_epoch
+	}
+	var topics []fetchTopic
+	nt := d.arrayLen()
+	for i := 0; i < nt && d.err == nil; i++ {
+		ft := fetchTopic{name: d.string()}
+		np := d.arrayLen()
+		for j := 0; j < np && d.err == nil; j++ {
+			fp := fetchPartition{partition: d.int32()}
+			if v >= 9 {
+				_ = d.int32() // current_leader_epoch
+			}
+			fp.offset = d.int64()
+			if v >= 5 {
+				_ = d.int64() // log_start_offset (только для реплик)
+			}
+			fp.maxBytes = d.int32()
+			ft.partitions = append(ft.partitions, fp)
+		}
+		topics = append(topics, ft)
+	}
+	if v >= 7 {
+		nf := d.arrayLen()
+		for i := 0; i < nf && d.err == nil; i++ {
+			_ = d.string()
+			_ = d.int32Array()
+		}
+	}
+	if v >= 11 {
+		_ = d.string() // rack_id
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	// Long polling: ждём, пока наберётся min_bytes или истечёт max_wait_ms.
+	// Непригодившийся ответ возвращает сообщения лимиту чтения, а при
+	// исчерпанном лимите опрос ждёт его восстановления.
+	deadline := time.Now().Add(maxWait)
+	results, size, throttle := s.fetch(ctx, topics, maxBytes)
+	for size < minBytes && time.Now().Before(deadline) {
+		s.release(ctx, topics, results)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(min(max(s.pollInterval, throttle), time.Until(deadline))):
+		}
+		results, size, throttle = s.fetch(ctx, topics, maxBytes)
+	}
+
+	var e encoder
+	e.int32(int32(throttle.Milliseconds())) // throttle_time_ms
+	if v >= 7 {
+		e.int16(errNone)
+		e.int32(0) // session_id: 0 — сессия не создана
+	}
+	e.arrayLen(len(topics))
+	for i, ft := range topics {
+		e.string(ft.name)
+		e.arrayLen(len(results[i]))
+		for _, r := range results[i] {
+			e.int32(r.partition)
+			e.int16(r.errorCode)
+			e.int64(r.hwm)
+			e.int64(r.hwm) // last_stable_offset
+			if v >= 5 {
+				e.int64(r.logStart)
+			}
+			e.arrayLen(-1) // aborted_transactions
+			if v >= 11 {
+				e.int32(-1) // preferred_read_replica
+			}
+			e.bytes(r.records)
+		}
+	}
+	return e.buf, nil
+}
+
+// fetch читает сообщения для всех партиций запроса. Бюджет maxBytes общий,
+// но первая непустая партиция отдаётся хотя бы одним сообщением (KIP-74).
+// Число сообщений ограничивает лимит чтения; партиции, на которые он
+// исчерпан, возвращаются пустыми, а fetch возвращает время до его
+// восстановления.
+func (s *Server) fetch(ctx context.Context, topics []fetchTopic, maxBytes int) ([][]fetchResult, int, time.Duration) {
+	out := make([][]fetchResult, len(topics))
+	total := 0
+	var throttle time.Duration
+	for i, ft := range topics {
+		out[i] = make([]fetchResult, 0, len(ft.partitions))
+		authErr := s.acl.Authorize(ctx, domain.ACLConsume, domain.ACLTopic, ft.name)
+		for _, fp := range ft.partitions {
+			r := fetchResult{partition: fp.partition, hwm: -1, logStart: -1, records: []byte{}}
+			if authErr != nil {
+				r.errorCode = errTopicAuthFailed
+				out[i] = append(out[i], r)
+				continue
+			}
+			queueID := queueOf(fp.partition)
+			start, end, err := s.messages.Offsets(ctx, ft.name, queueID)
+			if err != nil {
+				r.errorCode = errorCode(err)
+				out[i] = append(out[i], r)
+				continue
+			}
+			r.hwm, r.logStart = end, start
+			if fp.offset < start || fp.offset > end {
+				r.errorCode = errOffsetOutOfRange
+				out[i] = append(out[i], r)
+				continue
+			}
+			n, wait, err := s.quotas.ReserveConsume(ctx, ft.name, int(end-fp.offset))
+			if err != nil {
+				throttle = max(throttle, wait)
+				out[i] = append(out[i], r)
+				continue
+			}
+			budget := min(int(fp.maxBytes), maxBytes-total)
+			msgs, err := s.readUpTo(ctx, ft.name, queueID, fp.offset, end, budget, n, total == 0)
+			s.quotas.ReleaseConsume(ctx, ft.name, n-len(msgs))
+			if err != nil {
+				r.errorCode = errorCode(err)
+			} else if len(msgs) > 0 {
+				r.records = encodeRecordBatches(msgs)
+				r.count = len(msgs)
+				total += len(r.records)
+			}
+			out[i] = append(out[i], r)
+		}
+	}
+	return out, total, throttle
+}
+
+// release возвращает лимиту чтения сообщения ответа, который не будет отправлен.
+func (s *Server) release(ctx context.Context, topics []fetchTopic, results [][]fetchResult) {
+	for i, ft := range topics {
+		for _, r := range results[i] {
+			s.quotas.ReleaseConsume(ctx, ft.name, r.count)
+		}
+	}
+}
+
+// readUpTo читает не больше limit сообщений с offset до end в пределах budget байт.
+func (s *Server) readUpTo(ctx context.Context, topic, queueID string, offset, end int64, budget, limit int, atLeastOne bool) ([]*domain.Message, error) {
+	var out []*domain.Message
+	size := 0
+	for offset < end && len(out) < limit {
+		chunk, err := s.messages.Read(ctx, topic, queueID, offset, fetchChunk)
+		if err != nil {
+			return nil, err
+		}
+		if len(chunk) == 0 {
+			break
+		}
+		for _, m := range chunk {
+			sz := len(m.Payload) + len(m.Key) + recordOverhead
+			for k, v := range m.Headers {
+				sz += len(k) + len(v)
+			}
+			if len(out) == limit || size+sz > budget && !(atLeastOne && len(out) == 0) {
+				return out, nil
+			}
+			out = append(out, m)
+			size += sz
+			offset = m.Offset + 1
+		}
+	}
+	return out, nil
+}
+
+func (s *Server) handleListOffsets(ctx context.Context, v int16, d *decoder) ([]byte, error) {
+	_ = d.int32() // replica_id
+	if v >= 2 {
+		_ = d.int8() // isolation_level
+	}
+	type result struct {
+		partition int32
+		errorCode int16
+		timestamp int64
+		offset    int64
+	}
+	type topicResults struct {
+		name    string
+		results []result
+	}
+	var out []topicResults
+	nt := d.arrayLen()
+	for i := 0; i < nt && d.err == nil; i++ {
+		tr := topicResults{name: d.string()}
+		authErr := s.describe(ctx, tr.name)
+		np := d.arrayLen()
+		for j := 0; j < np && d.err == nil; j++ {
+			partition := d.int32()
+			if v >= 4 {
+				_ = d.int32() // current_leader_epoch
+			}
+			ts := d.int64()
+			if d.err != nil {
+				break
+			}
+			r := result{partition: partition, timestamp: -1, offset: -1}
+			var offset, at int64
+			err := authErr
+			if err == nil {
+				offset, at, err = s.listOffset(ctx, tr.name, queueOf(partition), ts)
+			}
+			if err != nil {
+				r.errorCode = errorCode(err)
+			} else {
+				r.offset, r.timestamp = offset, at
+			}
+			tr.results = append(tr.results, r)
+		}
+		out = append(out, tr)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	var e encoder
+	if v >= 2 {
+		e.int32(0) // throttle_time_ms
+	}
+	e.arrayLen(len(out))
+	for _, tr := range out {
+		e.string(tr.name)
+		e.arrayLen(len(tr.results))
+		for _, r := range tr.results {
+			e.int32(r.partition)
+			e.int16(r.errorCode)
+			e.int64(r.timestamp)
+			e.int64(r.offset)
+			if v >= 4 {
+				e.int32(0) // leader_epoch
+			}
+		}
+	}
+	return e.buf, nil
+}
+
+// listOffset возвращает смещение и временную метку для ListOffsets:
+// -1 — high-water mark, -2 — начало журнала, иначе поиск по времени.
+func (s *Server) listOffset(ctx context.Context, topic, queueID string, ts int64) (offset, timestamp int64, err error) {
+	start, end, err := s.messages.Offsets(ctx, topic, queueID)
+	if err != nil {
+		return 0, 0, err
+	}
+	switch ts {
+	case timestampLatest:
+		return end, -1, nil
+	case timestampEarliest:
+		return start, -1, nil
+	}
+	offset, err = s.messages.OffsetForTime(ctx, topic, queueID, time.UnixMilli(ts))
+	if err != nil {
+		return 0, 0, err
+	}
+	if offset >= end {
+		return -1, -1, nil
+	}
+	msgs, err := s.messages.Read(ctx, topic, queueID, offset, 1)
+	if err != nil || len(msgs) == 0 {
+		return -1, -1, err
+	}
+	return offset, msgs[0].CreatedAt.UnixMilli(), nil
+}
+
+func (s *Server) handleOffsetCommit(ctx context.Context, v int16, d *decoder) ([]byte, error) {
+	group := d.string()
+	_ = d.int32()  // generation_id: членство в группах не поддерживается
+	_ = d.string() // member_id
+	if v <= 4 {
+		_ = d.int64() // retention_time_ms
+	}
+	if v >= 7 {
+		_ = d.nullableString() // group_instance_id
+	}
+	type result struct {
+		partition int32
+		errorCode int16
+	}
+	type topicResults struct {
+		name    string
+		results []result
+	}
+	var out []topicResults
+	groupErr := s.acl.Authorize(ctx, domain.ACLConsume, domain.ACLGroup, group)
+	nt := d.arrayLen()
+	for i := 0; i < nt && d.err == nil; i++ {
+		tr := topicResults{name: d.string()}
+		authErr := s.acl.Authorize(ctx, domain.ACLConsume, domain.ACLTopic, tr.name)
+		parts, perr := s.partitions(ctx, tr.name)
+		np := d.arrayLen()
+		for j := 0; j < np && d.err == nil; j++ {
+			partition := d.int32()
+			offset := d.int64()
+			if v >= 6 {
+				_ = d.int32() // committed_leader_epoch
+			}
+			meta := d.nullableString()
+			if d.err != nil {
+				break
+			}
+			r := result{partition: partition}
+			switch {
+			case group == "":
+				r.errorCode = errInvalidRequest
+			case groupErr != nil:
+				r.errorCode = errGroupAuthFailed
+			case authErr != nil:
+				r.errorCode = errTopicAuthFailed
+			case perr != nil || !containsPartition(parts, partition):
+				r.errorCode = errUnknownTopicOrPartition
+			default:
+				c := committedOffset{offset: offset}
+				if meta != nil {
+					c.metadata = *meta
+				}
+				s.offsets.commit(ctx, group, topicPartition{tr.name, partition}, c)
+			}
+			tr.results = append(tr.results, r)
+		}
+		out = append(out, tr)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	var e encoder
+	if v >= 3 {
+		e.int32(0) // throttle_time_ms
+	}
+	e.arrayLen(len(out))
+	for _, tr := range out {
+		e.string(tr.name)
+		e.arrayLen(len(tr.results))
+		for _, r := range tr.results {
+			e.int32(r.partition)
+			e.int16(r.errorCode)
+		}
+	}
+	return e.buf, nil
+}
+
+func (s *Server) handleOffsetFetch(ctx context.Context, v int16, d *decoder) ([]byte, error) {
+	group := d.string()
+	requested := make(map[string][]int32)
+	var order []string
+	nt := d.arrayLen()
+	for i := 0; i < nt && d.err == nil; i++ {
+		name := d.string()
+		if _, seen := requested[name]; !seen {
+			order = append(order, name)
+		}
+		requested[name] = append(requested[name], d.int32Array()...)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	groupErr := s.acl.Authorize(ctx, domain.ACLConsume, domain.ACLGroup, group)
+	if nt == -1 && groupErr == nil {
+		// topics = null: все смещения группы по доступным клиенту топикам.
+		for tp := range s.offsets.all(ctx, group) {
+			if !s.acl.CanAccess(ctx, domain.ACLTopic, tp.topic) {
+				continue
+			}
+			if _, seen := requested[tp.topic]; !seen {
+				order = append(order, tp.topic)
+			}
+			requested[tp.topic] = append(requested[tp.topic], tp.partition)
+		}
+		sort.Strings(order)
+	}
+
+	var e encoder
+	if v >= 3 {
+		e.int32(0) // throttle_time_ms
+	}
+	e.arrayLen(len(order))
+	for _, name := range order {
+		parts := requested[name]
+		sort.Slice(parts, func(i, j int) bool { return parts[i] < parts[j] })
+		code := errNone
+		switch {
+		case groupErr != nil:
+			code = errGroupAuthFailed
+		case s.describe(ctx, name) != nil:
+			code = errTopicAuthFailed
+		}
+		e.string(name)
+		e.arrayLen(len(parts))
+		for _, p := range parts {
+			c, ok := s.offsets.get(ctx, group, topicPartition{name, p})
+			if code != errNone {
+				c, ok = committedOffset{}, false
+			}
+			e.int32(p)
+			if ok {
+				e.int64(c.offset)
+			} else {
+				e.int64(-1)
+			}
+			if v >= 5 {
+				e.int32(-1) // committed_leader_epoch
+			}
+			e.nullableString(&c.metadata)
+			e.int16(code)
+		}
+	}
+	if v >= 2 {
+		if groupErr != nil {
+			e.int16(errGroupAuthFailed)
+		} else {
+			e.int16(errNone)
+		}
+	}
+	return e.buf, nil
+}
+
+func (s *Server) handleFindCoordinator(v int16, d *decoder) ([]byte, error) {
+	_ = d.string() // key
+	keyType := int8(coordinatorKeyGroup)
+	if v >= 1 {
+		keyType = d.int8()
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	var e encoder
+	if v >= 1 {
+		e.int32(0) // throttle_time_ms
+	}
+	if keyType != coordinatorKeyGroup {
+		msg := "transactions are not supported"
+		e.int16(errCoordinatorNotAvailable)
+		if v >= 1 {
+			e.nullableString(&msg)
+		}
+		e.int32(-1)
+		e.string("")
+		e.int32(-1)
+		return e.buf, nil
+	}
+	e.int16(errNone)
+	if v >= 1 {
+		e.nullableString(nil)
+	}
+	e.int32(nodeID)
+	e.string(s.host)
+	e.int32(s.port)
+	return e.buf, nil
+}
+
+// describe проверяет, что клиенту виден топик: Metadata, ListOffsets и
+// OffsetFetch требуют хоть какого-то права на него.
+func (s *Server) describe(ctx context.Context, topic string) error {
+	if !s.acl.CanAccess(ctx, domain.ACLTopic, topic) {
+		return usecase.ErrPermissionDenied
+	}
+	return nil
+}
+
+func containsPartition(parts []int32, p int32) bool {
+	for _, x := range parts {
+		if x == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+
+	"queue-service/internal/domain"
+)
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+type committedOffset struct {
+	offset   int64
+	metadata string
+}
+
+// groupKey — группа в своём пространстве имён: одноимённые группы разных
+// арендаторов не пересекаются.
+type groupKey struct {
+	namespace string
+	group     string
+}
+
+// offsetStore хранит смещения, зафиксированные через OffsetCommit. Подписки
+// брокера уникальны по (топик, группа) и не покрывают группу, читающую
+// несколько партиций, поэтому смещения Kafka-групп живут отдельно.
+type offsetStore struct {
+	mu     sync.RWMutex
+	groups map[groupKey]map[topicPartition]committedOffset
+}
+
+func newOffsetStore() *offsetStore {
+	return &offsetStore{groups: make(map[groupKey]map[topicPartition]committedOffset)}
+}
+
+func keyOf(ctx context.Context, group string) groupKey {
+	return groupKey{domain.Namespace(ctx), group}
+}
+
+func (s *offsetStore) commit(ctx context.Context, group string, tp topicPartition, c committedOffset) {
+	key := keyOf(ctx, group)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.groups[key] == nil {
+		s.groups[key] = make(map[topicPartition]committedOffset)
+	}
+	s.groups[key][tp] = c
+}
+
+func (s *offsetStore) get(ctx context.Context, group string, tp topicPartition) (committedOffset, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.groups[keyOf(ctx, group)][tp]
+	return c, ok
+}
+
+// forget удаляет смещения всех групп пространства имён ctx для топика или,
+// если queueID задан, для одной его партиции.
+func (s *offsetStore) forget(ctx context.Context, topic, queueID string) {
+	partition, byPartition := int32(0), queueID != ""
+	if byPartition {
+		var ok bool
+		if partition, ok = partitionOf(queueID); !ok {
+			return
+		}
+	}
+	ns := domain.Namespace(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, offsets := range s.groups {
+		if key.namespace != ns {
+			continue
+		}
+		for tp := range offsets {
+			if tp.topic == topic && (!byPartition || tp.partition == partition) {
+				delete(offsets, tp)
+			}
+		}
+		if len(offsets) == 0 {
+			delete(s.groups, key)
+		}
+	}
+}
+
+// all возвращает все смещения группы (OffsetFetch с topics = null).
+func (s *offsetStore) all(ctx context.Context, group string) map[topicPartition]committedOffset {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key := keyOf(ctx, group)
+	out := make(map[topicPartition]committedOffset, len(s.groups[key]))
+	for tp, c := range s.groups[key] {
+		out[tp] = c
+	}
+	return out
+}
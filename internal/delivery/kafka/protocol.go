@@ -0,0 +1,247 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Ключи API Kafka, которые поддерживает слушатель.
+const (
+	apiProduce          int16 = 0
+	apiFetch            int16 = 1
+	apiListOffsets      int16 = 2
+	apiMetadata         int16 = 3
+	apiOffsetCommit     int16 = 8
+	apiOffsetFetch      int16 = 9
+	apiFindCoordinator  int16 = 10
+	apiSaslHandshake    int16 = 17
+	apiApiVersions      int16 = 18
+	apiSaslAuthenticate int16 = 36
+)
+
+// Коды ошибок протокола Kafka.
+const (
+	errNone                    int16 = 0
+	errOffsetOutOfRange        int16 = 1
+	errCorruptMessage          int16 = 2
+	errUnknownTopicOrPartition int16 = 3
+	errRequestTimedOut         int16 = 7
+	errMessageTooLarge         int16 = 10
+	errCoordinatorNotAvailable int16 = 15
+	errTopicAuthFailed         int16 = 29
+	errGroupAuthFailed         int16 = 30
+	errUnsupportedSaslMech     int16 = 33
+	errIllegalSaslState        int16 = 34
+	errUnsupportedVersion      int16 = 35
+	errKafkaStorageError       int16 = 56
+	errInvalidRequest          int16 = 42
+	errSaslAuthFailed          int16 = 58
+	errUnsupportedCompression  int16 = 76
+	errUnknownServerError      int16 = -1
+)
+
+// versionRange — поддерживаемые версии API. Только негибкие (non-flexible)
+// версии: компактная кодировка и tagged fields не реализованы.
+type versionRange struct{ min, max int16 }
+
+var supportedAPIs = map[int16]versionRange{
+	apiProduce:         {3, 8},
+	apiFetch:           {4, 11},
+	apiListOffsets:     {1, 5},
+	apiMetadata:        {0, 8},
+	apiOffsetCommit:    {2, 7},
+	apiOffsetFetch:     {1, 5},
+	apiFindCoordinator: {0, 2},
+	apiApiVersions:     {0, 2},
+	// SaslHandshake v0 передаёт токены без кадров Kafka — не поддерживается.
+	apiSaslHandshake:    {1, 1},
+	apiSaslAuthenticate: {0, 1},
+}
+
+var errShortBuffer = errors.New("kafka: short buffer")
+
+// decoder читает примитивы протокола; первая ошибка запоминается,
+// последующие чтения возвращают нулевые значения.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.buf) {
+		d.err = errShortBuffer
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) int8() int8 {
+	b := d.take(1)
+	if b == nil {
+		return 0
+	}
+	return int8(b[0])
+}
+
+func (d *decoder) bool() bool { return d.int8() != 0 }
+
+func (d *decoder) int16() int16 {
+	b := d.take(2)
+	if b == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(b))
+}
+
+func (d *decoder) int32() int32 {
+	b := d.take(4)
+	if b == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(b))
+}
+
+func (d *decoder) int64() int64 {
+	b := d.take(8)
+	if b == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(b))
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.err = errShortBuffer
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) string() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.take(int(n)))
+}
+
+func (d *decoder) nullableString() *string {
+	n := d.int16()
+	if n < 0 {
+		return nil
+	}
+	s := string(d.take(int(n)))
+	return &s
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.int32()
+	if n < 0 {
+		return nil
+	}
+	return d.take(int(n))
+}
+
+// varbytes — поле записи с длиной varint; -1 означает null.
+func (d *decoder) varbytes() []byte {
+	n := d.varint()
+	if n < 0 {
+		return nil
+	}
+	return d.take(int(n))
+}
+
+// arrayLen читает длину массива; null-массив возвращается как -1.
+func (d *decoder) arrayLen() int {
+	n := d.int32()
+	if n < -1 || int(n) > len(d.buf) {
+		// Каждый элемент занимает хотя бы байт — защита от огромных аллокаций.
+		if d.err == nil {
+			d.err = errShortBuffer
+		}
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) int32Array() []int32 {
+	n := d.arrayLen()
+	if n <= 0 {
+		return nil
+	}
+	out := make([]int32, 0, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		out = append(out, d.int32())
+	}
+	return out
+}
+
+// encoder собирает ответ в буфер.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) int8(v int8) { e.buf = append(e.buf, byte(v)) }
+
+func (e *encoder) bool(v bool) {
+	if v {
+		e.int8(1)
+	} else {
+		e.int8(0)
+	}
+}
+
+func (e *encoder) int16(v int16)  { e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v)) }
+func (e *encoder) int32(v int32)  { e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v)) }
+func (e *encoder) int64(v int64)  { e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(v)) }
+func (e *encoder) varint(v int64) { e.buf = binary.AppendVarint(e.buf, v) }
+
+func (e *encoder) string(s string) {
+	e.int16(int16(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) nullableString(s *string) {
+	if s == nil {
+		e.int16(-1)
+		return
+	}
+	e.string(*s)
+}
+
+func (e *encoder) bytes(b []byte) {
+	if b == nil {
+		e.int32(-1)
+		return
+	}
+	e.int32(int32(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) varbytes(b []byte) {
+	if b == nil {
+		e.varint(-1)
+		return
+	}
+	e.varint(int64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) arrayLen(n int) { e.int32(int32(n)) }
+
+func (e *encoder) int32Array(v []int32) {
+	e.arrayLen(len(v))
+	for _, x := range v {
+		e.int32(x)
+	}
+}
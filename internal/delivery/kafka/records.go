@@ -0,0 +1,221 @@
+package kafka
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+
+	"queue-service/internal/domain"
+)
+
+const (
+	recordBatchMagic = 2
+	// Размер заголовка RecordBatch до поля records включительно (count).
+	recordBatchHeaderSize = 61
+
+	compressionMask = 0x07
+	compressionNone = 0
+	compressionGzip = 1
+
+	attrLogAppendTime = 0x08
+	attrControl       = 0x20
+)
+
+var (
+	castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+	errMagicUnsupported = errors.New("kafka: only record batch magic v2 is supported")
+	errCodecUnsupported = errors.New("kafka: unsupported compression codec")
+	errCRCMismatch      = errors.New("kafka: record batch crc mismatch")
+)
+
+// record — запись из Produce, уже без деталей кодировки. Временная метка
+// клиента не сохраняется: брокер проставляет CreatedAt сам (LogAppendTime).
+type record struct {
+	key     []byte
+	value   []byte
+	headers map[string]string
+}
+
+// decodeRecordBatches разбирает поле records запроса Produce (одна или несколько
+// RecordBatch v2). Поддерживаются записи без сжатия и gzip.
+func decodeRecordBatches(raw []byte) ([]record, error) {
+	var out []record
+	for len(raw) > 0 {
+		if len(raw) < 12 {
+			return nil, errShortBuffer
+		}
+		batchLen := int(int32(binary.BigEndian.Uint32(raw[8:12])))
+		if batchLen < recordBatchHeaderSize-12 || 12+batchLen > len(raw) {
+			return nil, errShortBuffer
+		}
+		batch := raw[:12+batchLen]
+		raw = raw[12+batchLen:]
+
+		if batch[16] != recordBatchMagic {
+			return nil, errMagicUnsupported
+		}
+		if crc32.Checksum(batch[21:], castagnoli) != binary.BigEndian.Uint32(batch[17:21]) {
+			return nil, errCRCMismatch
+		}
+		d := &decoder{buf: batch[21:]}
+		attrs := d.int16()
+		_ = d.int32() // lastOffsetDelta
+		_ = d.int64() // baseTimestamp
+		_ = d.int64() // maxTimestamp
+		_ = d.int64() // producerId
+		_ = d.int16() // producerEpoch
+		_ = d.int32() // baseSequence
+		count := d.int32()
+		if d.err != nil {
+			return nil, d.err
+		}
+		if attrs&attrControl != 0 {
+			continue
+		}
+
+		body := d.buf
+		switch attrs & compressionMask {
+		case compressionNone:
+		case compressionGzip:
+			zr, err := gzip.NewReader(bytes.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+			body, err = io.ReadAll(io.LimitReader(zr, maxRequestSize))
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errCodecUnsupported
+		}
+
+		rd := &decoder{buf: body}
+		for i := int32(0); i < count; i++ {
+			rec, err := decodeRecord(rd)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, rec)
+		}
+	}
+	return out, nil
+}
+
+func decodeRecord(d *decoder) (record, error) {
+	length := d.varint()
+	if d.err != nil {
+		return record{}, d.err
+	}
+	rd := &decoder{buf: d.take(int(length))}
+	if d.err != nil {
+		return record{}, d.err
+	}
+	_ = rd.int8()   // attributes
+	_ = rd.varint() // timestampDelta
+	_ = rd.varint() // offsetDelta
+	rec := record{
+		key:   rd.varbytes(),
+		value: rd.varbytes(),
+	}
+	nh := rd.varint()
+	for i := int64(0); i < nh && rd.err == nil; i++ {
+		k := rd.varbytes()
+		v := rd.varbytes()
+		if rec.headers == nil {
+			rec.headers = make(map[string]string)
+		}
+		rec.headers[string(k)] = string(v)
+	}
+	if rd.err != nil {
+		return record{}, rd.err
+	}
+	return rec, nil
+}
+
+// encodeRecordBatches кодирует подряд идущие сообщения очереди в RecordBatch v2,
+// по одной на сообщения с одинаковым временем записи: с LogAppendTime клиент
+// берёт время всех записей пакета из его maxTimestamp.
+func encodeRecordBatches(msgs []*domain.Message) []byte {
+	var out []byte
+	for len(msgs) > 0 {
+		ts, n := msgs[0].CreatedAt.UnixMilli(), 1
+		for n < len(msgs) && msgs[n].CreatedAt.UnixMilli() == ts {
+			n++
+		}
+		out = append(out, encodeRecordBatch(msgs[:n])...)
+		msgs = msgs[n:]
+	}
+	return out
+}
+
+// encodeRecordBatch кодирует подряд идущие сообщения очереди в одну RecordBatch v2
+// без сжатия. Смещения сообщений должны быть последовательными.
+func encodeRecordBatch(msgs []*domain.Message) []byte {
+	if len(msgs) == 0 {
+		return nil
+	}
+	baseOffset := msgs[0].Offset
+	baseTs := msgs[0].CreatedAt.UnixMilli()
+	maxTs := baseTs
+
+	var recs encoder
+	var rec encoder
+	for _, m := range msgs {
+		ts := m.CreatedAt.UnixMilli()
+		if ts > maxTs {
+			maxTs = ts
+		}
+		rec.buf = rec.buf[:0]
+		rec.int8(0)
+		rec.varint(ts - baseTs)
+		rec.varint(m.Offset - baseOffset)
+		if m.Key == "" {
+			rec.varbytes(nil)
+		} else {
+			rec.varbytes([]byte(m.Key))
+		}
+		rec.varbytes(m.Payload)
+		rec.varint(int64(len(m.Headers)))
+		for k, v := range m.Headers {
+			rec.varbytes([]byte(k))
+			rec.varbytes([]byte(v))
+		}
+		recs.varint(int64(len(rec.buf)))
+		recs.buf = append(recs.buf, rec.buf...)
+	}
+
+	var e encoder
+	e.int64(baseOffset)
+	e.int32(0) // batchLength, заполняется ниже
+	e.int32(0) // partitionLeaderEpoch
+	e.int8(recordBatchMagic)
+	e.int32(0)                 // crc, заполняется ниже
+	e.int16(attrLogAppendTime) // attributes: без сжатия
+	e.int32(int32(msgs[len(msgs)-1].Offset - baseOffset))
+	e.int64(baseTs)
+	e.int64(maxTs)
+	e.int64(-1) // producerId
+	e.int16(-1) // producerEpoch
+	e.int32(-1) // baseSequence
+	e.int32(int32(len(msgs)))
+	e.buf = append(e.buf, recs.buf...)
+
+	binary.BigEndian.PutUint32(e.buf[8:12], uint32(len(e.buf)-12))
+	binary.BigEndian.PutUint32(e.buf[17:21], crc32.Checksum(e.buf[21:], castagnoli))
+	return e.buf
+}
+
+// recordsErrorCode сопоставляет ошибку разбора записей с кодом Kafka.
+func recordsErrorCode(err error) int16 {
+	switch {
+	case errors.Is(err, errCodecUnsupported):
+		return errUnsupportedCompression
+	case errors.Is(err, errMagicUnsupported), errors.Is(err, errCRCMismatch), errors.Is(err, errShortBuffer):
+		return errCorruptMessage
+	}
+	return errUnknownServerError
+}
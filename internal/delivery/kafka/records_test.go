@@ -0,0 +1,104 @@
+package kafka
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+	"time"
+
+	"queue-service/internal/domain"
+)
+
+func testMessages() []*domain.Message {
+	now := time.Now()
+	return []*domain.Message{
+		{ID: "a", Offset: 5, Key: "k1", Payload: []byte("v1"), Headers: map[string]string{"h": "x"}, CreatedAt: now},
+		{ID: "b", Offset: 6, Payload: []byte("v2"), CreatedAt: now.Add(time.Millisecond)},
+	}
+}
+
+func TestRecordBatch_roundTrip(t *testing.T) {
+	raw := encodeRecordBatch(testMessages())
+	if got := int64(binary.BigEndian.Uint64(raw[:8])); got != 5 {
+		t.Errorf("base offset want 5, got %d", got)
+	}
+
+	recs, err := decodeRecordBatches(raw)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("want 2 records, got %d", len(recs))
+	}
+	if string(recs[0].key) != "k1" || string(recs[0].value) != "v1" || recs[0].headers["h"] != "x" {
+		t.Errorf("record 0: %+v", recs[0])
+	}
+	if recs[1].key != nil || string(recs[1].value) != "v2" {
+		t.Errorf("record 1: %+v", recs[1])
+	}
+}
+
+func TestRecordBatches_logAppendTime(t *testing.T) {
+	msgs := testMessages()
+	raw := encodeRecordBatches(append(msgs, &domain.Message{ID: "c", Offset: 7, CreatedAt: msgs[1].CreatedAt}))
+	// Сообщения с разным временем записи — в разных пакетах.
+	var batches []int64
+	for b := raw; len(b) > 0; b = b[12+binary.BigEndian.Uint32(b[8:12]):] {
+		if attrs := binary.BigEndian.Uint16(b[21:23]); attrs&attrLogAppendTime == 0 {
+			t.Errorf("batch attributes %#x: want LogAppendTime", attrs)
+		}
+		batches = append(batches, int64(binary.BigEndian.Uint64(b[:8])))
+		if maxTs := int64(binary.BigEndian.Uint64(b[35:43])); maxTs != msgs[len(batches)-1].CreatedAt.UnixMilli() {
+			t.Errorf("batch %d max timestamp %d", len(batches), maxTs)
+		}
+	}
+	if len(batches) != 2 || batches[0] != 5 || batches[1] != 6 {
+		t.Errorf("batch base offsets: %v", batches)
+	}
+	if recs, err := decodeRecordBatches(raw); err != nil || len(recs) != 3 {
+		t.Errorf("decode: %d records, %v", len(recs), err)
+	}
+}
+
+func TestRecordBatch_gzip(t *testing.T) {
+	plain := encodeRecordBatch(testMessages())
+
+	var zbuf bytes.Buffer
+	zw := gzip.NewWriter(&zbuf)
+	_, _ = zw.Write(plain[recordBatchHeaderSize:])
+	_ = zw.Close()
+
+	batch := append([]byte{}, plain[:recordBatchHeaderSize]...)
+	batch = append(batch, zbuf.Bytes()...)
+	binary.BigEndian.PutUint16(batch[21:23], compressionGzip)
+	binary.BigEndian.PutUint32(batch[8:12], uint32(len(batch)-12))
+	binary.BigEndian.PutUint32(batch[17:21], crc32.Checksum(batch[21:], castagnoli))
+
+	recs, err := decodeRecordBatches(batch)
+	if err != nil {
+		t.Fatalf("decode gzip: %v", err)
+	}
+	if len(recs) != 2 || string(recs[1].value) != "v2" {
+		t.Errorf("got %+v", recs)
+	}
+}
+
+func TestRecordBatch_errors(t *testing.T) {
+	raw := encodeRecordBatch(testMessages())
+
+	corrupt := append([]byte{}, raw...)
+	corrupt[len(corrupt)-1] ^= 0xff
+	if _, err := decodeRecordBatches(corrupt); recordsErrorCode(err) != errCorruptMessage {
+		t.Errorf("crc mismatch: got %v", err)
+	}
+
+	snappy := append([]byte{}, raw...)
+	binary.BigEndian.PutUint16(snappy[21:23], 2)
+	binary.BigEndian.PutUint32(snappy[17:21], crc32.Checksum(snappy[21:], castagnoli))
+	if _, err := decodeRecordBatches(snappy); !errors.Is(err, errCodecUnsupported) {
+		t.Errorf("snappy: want errCodecUnsupported, got %v", err)
+	}
+}
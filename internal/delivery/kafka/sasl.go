@@ -0,0 +1,96 @@
+package kafka
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+
+	"queue-service/internal/auth"
+	"queue-service/internal/usecase"
+)
+
+// saslPlain — единственный поддерживаемый механизм SASL (RFC 4616): пароль —
+// API-ключ или JWT, имя пользователя не проверяется, authzid — пространство
+// имён клиента (необязательно).
+const saslPlain = "PLAIN"
+
+func (s *Server) handleSaslHandshake(c *client, d *decoder) ([]byte, error) {
+	mechanism := d.string()
+	if d.err != nil {
+		return nil, d.err
+	}
+	code, mechanisms := errNone, []string{saslPlain}
+	switch {
+	case !s.authn.Enabled():
+		// Аутентификация выключена: SASL не нужен.
+		code, mechanisms = errUnsupportedSaslMech, nil
+	case c.authenticated || c.mechanism != "":
+		code = errIllegalSaslState
+	case mechanism != saslPlain:
+		code = errUnsupportedSaslMech
+	default:
+		c.mechanism = mechanism
+	}
+
+	var e encoder
+	e.int16(code)
+	e.arrayLen(len(mechanisms))
+	for _, m := range mechanisms {
+		e.string(m)
+	}
+	return e.buf, nil
+}
+
+// handleSaslAuthenticate проверяет сообщение PLAIN. После отказа соединение
+// закрывается, как и в Kafka. Повторная аутентификация (KIP-368) не нужна:
+// session_lifetime_ms = 0.
+func (s *Server) handleSaslAuthenticate(c *client, v int16, d *decoder) ([]byte, error) {
+	token := d.bytes()
+	if d.err != nil {
+		return nil, d.err
+	}
+	var (
+		code int16
+		msg  *string
+	)
+	if c.mechanism != saslPlain || c.authenticated {
+		code, msg = errIllegalSaslState, ptr("SaslHandshake with mechanism PLAIN must precede SaslAuthenticate")
+	} else if err := s.authenticatePlain(c, token); err != nil {
+		slog.Warn("authentication failed", "component", "kafka", "peer", c.peer, "error", err)
+		code, msg = errSaslAuthFailed, ptr("authentication failed: invalid credentials")
+		if errors.Is(err, usecase.ErrNamespaceDenied) || errors.Is(err, usecase.ErrInvalidNamespace) {
+			msg = ptr(err.Error())
+		}
+	}
+	c.closing = code != errNone
+
+	var e encoder
+	e.int16(code)
+	e.nullableString(msg)
+	e.bytes([]byte{})
+	if v >= 1 {
+		e.int64(0) // session_lifetime_ms
+	}
+	return e.buf, nil
+}
+
+// authenticatePlain разбирает сообщение authzid NUL authcid NUL passwd и
+// кладёт идентичность и пространство имён клиента в контекст соединения.
+// Пустой пароль — клиент без учётных данных.
+func (s *Server) authenticatePlain(c *client, token []byte) error {
+	parts := strings.Split(string(token), "\x00")
+	if len(parts) != 3 {
+		return errors.New("malformed PLAIN message")
+	}
+	id, err := s.authn.AuthenticateSecret(parts[2])
+	switch {
+	case err == nil:
+		c.ctx = auth.WithIdentity(c.ctx, id)
+	case errors.Is(err, auth.ErrNoCredentials) && s.authn.AllowAnonymous():
+	default:
+		return err
+	}
+	return s.login(c, parts[0])
+}
+
+func ptr(s string) *string { return &s }
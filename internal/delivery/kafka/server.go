@@ -0,0 +1,298 @@
+package kafka
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"queue-service/internal/auth"
+	"queue-service/internal/domain"
+	"queue-service/internal/usecase"
+)
+
+// Kafka по умолчанию ограничивает запрос 100 МБ (socket.request.max.bytes).
+const maxRequestSize = 100 << 20
+
+// maxUnauthenticatedRequestSize ограничивает запросы до аутентификации:
+// ApiVersions, SaslHandshake и SaslAuthenticate помещаются в несколько КБ, и
+// неаутентифицированный клиент не заставит брокер выделять большие буферы.
+const maxUnauthenticatedRequestSize = 16 << 10
+
+// ErrServerClosed возвращается из Serve после Close.
+var ErrServerClosed = errors.New("kafka: server closed")
+
+// Server — слушатель минимального подмножества протокола Kafka. Топики
+// отображаются на топики брокера, партиции — на очереди с числовыми ID.
+type Server struct {
+	topics   *usecase.TopicUseCase
+	publish  *usecase.PublishUseCase
+	messages *usecase.MessageUseCase
+	offsets  *offsetStore
+	// authn проверяет пароль SASL/PLAIN; nil — без аутентификации.
+	authn   *auth.Authenticator
+	acl     *usecase.ACLUseCase
+	tenants *usecase.TenantUseCase
+	quotas  *usecase.QuotaUseCase
+
+	host         string
+	port         int32
+	pollInterval time.Duration
+
+	mu     sync.Mutex
+	lis    net.Listener
+	conns  map[net.Conn]struct{}
+	closed bool
+}
+
+// NewServer создаёт слушатель; advertisedHost и advertisedPort возвращаются
+// клиентам в Metadata и FindCoordinator как адрес единственного узла кластера.
+func NewServer(
+	topics *usecase.TopicUseCase,
+	publish *usecase.PublishUseCase,
+	messages *usecase.MessageUseCase,
+	authn *auth.Authenticator,
+	acl *usecase.ACLUseCase,
+	tenants *usecase.TenantUseCase,
+	quotas *usecase.QuotaUseCase,
+	advertisedHost string,
+	advertisedPort int,
+) *Server {
+	s := &Server{
+		topics:       topics,
+		publish:      publish,
+		messages:     messages,
+		offsets:      newOffsetStore(),
+		authn:        authn,
+		acl:          acl,
+		tenants:      tenants,
+		quotas:       quotas,
+		host:         advertisedHost,
+		port:         int32(advertisedPort),
+		pollInterval: 50 * time.Millisecond,
+		conns:        make(map[net.Conn]struct{}),
+	}
+	// Смещения групп удалённого топика не должны достаться пересозданному.
+	topics.OnDelete(s.offsets.forget)
+	return s
+}
+
+// Serve принимает соединения, пока listener не будет закрыт.
+func (s *Server) Serve(lis net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.lis = lis
+	s.mu.Unlock()
+
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		if !s.track(conn) {
+			_ = conn.Close()
+			return ErrServerClosed
+		}
+		go func() {
+			s.serveConn(conn)
+			s.untrack(conn)
+		}()
+	}
+}
+
+// Close закрывает listener и все соединения.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	lis := s.lis
+	conns := make([]net.Conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+
+	var err error
+	if lis != nil {
+		err = lis.Close()
+	}
+	for _, c := range conns {
+		_ = c.Close()
+	}
+	return err
+}
+
+func (s *Server) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	return true
+}
+
+func (s *Server) untrack(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+	_ = conn.Close()
+}
+
+// client — состояние соединения.
+type client struct {
+	ctx  context.Context // идентичность и пространство имён клиента
+	peer string
+	// mechanism — механизм из SaslHandshake; пусто, пока рукопожатия не было.
+	mechanism     string
+	authenticated bool
+	// closing — закрыть соединение после ответа (отказ в аутентификации).
+	closing bool
+}
+
+// serveConn обрабатывает запросы соединения строго по очереди: Kafka требует,
+// чтобы ответы шли в порядке запросов.
+func (s *Server) serveConn(conn net.Conn) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := &client{ctx: ctx, peer: conn.RemoteAddr().String()}
+	if !s.authn.Enabled() {
+		if err := s.login(c, ""); err != nil {
+			slog.Warn("namespace denied", "component", "kafka", "peer", c.peer, "error", err)
+			return
+		}
+	}
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	var sizeBuf [4]byte
+	for {
+		if _, err := io.ReadFull(r, sizeBuf[:]); err != nil {
+			return
+		}
+		size := int32(binary.BigEndian.Uint32(sizeBuf[:]))
+		if size < 8 || size > s.maxRequestSize(c) {
+			slog.Warn("invalid request size", "component", "kafka", "peer", conn.RemoteAddr().String(), "size", size)
+			return
+		}
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return
+		}
+
+		d := &decoder{buf: buf}
+		apiKey, version, correlationID := d.int16(), d.int16(), d.int32()
+		_ = d.nullableString() // client_id
+		if d.err != nil {
+			return
+		}
+
+		body, respond, err := s.handle(c, apiKey, version, d)
+		if err != nil {
+			slog.Warn("request failed", "component", "kafka", "peer", c.peer, "api_key", apiKey, "api_version", version, "error", err)
+			return
+		}
+		if !respond {
+			continue
+		}
+
+		var hdr [8]byte
+		binary.BigEndian.PutUint32(hdr[:4], uint32(len(body)+4))
+		binary.BigEndian.PutUint32(hdr[4:], uint32(correlationID))
+		_, _ = w.Write(hdr[:])
+		_, _ = w.Write(body)
+		if err := w.Flush(); err != nil || c.closing {
+			return
+		}
+	}
+}
+
+// maxRequestSize возвращает наибольший размер следующего запроса клиента c.
+func (s *Server) maxRequestSize(c *client) int32 {
+	if c.authenticated || c.mechanism == "" && s.authn.AllowAnonymous() {
+		return maxRequestSize
+	}
+	return maxUnauthenticatedRequestSize
+}
+
+// login завершает аутентификацию клиента c: определяет его пространство имён
+// по идентичности и запрошенному requested.
+func (s *Server) login(c *client, requested string) error {
+	if s.tenants.Enabled() {
+		ns, err := s.tenants.Resolve(c.ctx, requested)
+		if err != nil {
+			return err
+		}
+		c.ctx = domain.WithNamespace(c.ctx, ns)
+	}
+	c.authenticated = true
+	return nil
+}
+
+// handle разбирает тело запроса и возвращает тело ответа. respond=false —
+// ответ не нужен (Produce с acks=0). Ошибка приводит к закрытию соединения,
+// как и в самом Kafka при неизвестном API или версии и при запросах до
+// аутентификации.
+func (s *Server) handle(c *client, apiKey, version int16, d *decoder) (body []byte, respond bool, err error) {
+	vr, ok := supportedAPIs[apiKey]
+	if !ok {
+		return nil, false, errors.New("unsupported api key")
+	}
+	if version < vr.min || version > vr.max {
+		if apiKey == apiApiVersions {
+			// KIP-511: на неизвестную версию ApiVersions отвечаем в формате v0.
+			return s.apiVersions(0, errUnsupportedVersion), true, nil
+		}
+		return nil, false, errors.New("unsupported version")
+	}
+	if !c.authenticated && apiKey != apiApiVersions && apiKey != apiSaslHandshake && apiKey != apiSaslAuthenticate {
+		// Без SASL клиент работает анонимно, если это разрешено.
+		if c.mechanism != "" || !s.authn.AllowAnonymous() {
+			return nil, false, errors.New("authentication required")
+		}
+		if err := s.login(c, ""); err != nil {
+			return nil, false, err
+		}
+	}
+
+	ctx := c.ctx
+	switch apiKey {
+	case apiApiVersions:
+		return s.apiVersions(version, errNone), true, nil
+	case apiSaslHandshake:
+		body, err = s.handleSaslHandshake(c, d)
+	case apiSaslAuthenticate:
+		body, err = s.handleSaslAuthenticate(c, version, d)
+	case apiMetadata:
+		body, err = s.handleMetadata(ctx, version, d)
+	case apiProduce:
+		return s.handleProduce(ctx, version, d)
+	case apiFetch:
+		body, err = s.handleFetch(ctx, version, d)
+	case apiListOffsets:
+		body, err = s.handleListOffsets(ctx, version, d)
+	case apiOffsetCommit:
+		body, err = s.handleOffsetCommit(ctx, version, d)
+	case apiOffsetFetch:
+		body, err = s.handleOffsetFetch(ctx, version, d)
+	case apiFindCoordinator:
+		body, err = s.handleFindCoordinator(version, d)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return body, true, nil
+}
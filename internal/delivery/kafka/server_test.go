@@ -0,0 +1,730 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"queue-service/internal/auth"
+	"queue-service/internal/domain"
+	"queue-service/internal/repository/memory"
+	"queue-service/internal/usecase"
+)
+
+type testConn struct {
+	t    *testing.T
+	conn net.Conn
+	corr int32
+}
+
+func newTestServer(t *testing.T) (*usecase.TopicUseCase, *testConn) {
+	t.Helper()
+	topicUC, addr := newTestServerWith(t, testOptions{})
+	return topicUC, dial(t, addr)
+}
+
+// testOptions — проверки тестового сервера; нулевые — выключены.
+type testOptions struct {
+	authn    *auth.Authenticator
+	acl      usecase.ACLPolicy
+	aclRules []domain.ACLRule
+	tenancy  usecase.TenantPolicy
+	quotas   usecase.QuotaPolicy
+}
+
+// newTestServerWith запускает сервер с проверками opts и возвращает его адрес.
+func newTestServerWith(t *testing.T, opts testOptions) (*usecase.TopicUseCase, string) {
+	t.Helper()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	subs := memory.NewSubscriptionRepository()
+	topicUC := usecase.NewTopicUseCase(topics, queues, msgs, subs, memory.NewPendingDeliveryRepository())
+	pub := usecase.NewPublishUseCase(topics, queues, msgs, 1024*1024, usecase.MemoryBudget{}, nil)
+	messageUC := usecase.NewMessageUseCase(topics, queues, msgs)
+	acl := usecase.NewACLUseCase(memory.NewACLRepository(), opts.acl)
+	if err := acl.LoadRules(context.Background(), opts.aclRules); err != nil {
+		t.Fatalf("LoadRules: %v", err)
+	}
+	tenants, err := usecase.NewTenantUseCase(topics, subs, opts.tenancy)
+	if err != nil {
+		t.Fatalf("NewTenantUseCase: %v", err)
+	}
+	quotas, err := usecase.NewQuotaUseCase(topics, subs, opts.quotas)
+	if err != nil {
+		t.Fatalf("NewQuotaUseCase: %v", err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := NewServer(topicUC, pub, messageUC, opts.authn, acl, tenants, quotas, "127.0.0.1", lis.Addr().(*net.TCPAddr).Port)
+	srv.pollInterval = 5 * time.Millisecond
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return topicUC, lis.Addr().String()
+}
+
+func dial(t *testing.T, addr string) *testConn {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return &testConn{t: t, conn: conn}
+}
+
+// roundTrip отправляет запрос с заголовком v1 и возвращает тело ответа.
+func (c *testConn) roundTrip(apiKey, version int16, body []byte) *decoder {
+	c.t.Helper()
+	c.corr++
+	var e encoder
+	e.int32(0)
+	e.int16(apiKey)
+	e.int16(version)
+	e.int32(c.corr)
+	clientID := "test"
+	e.nullableString(&clientID)
+	e.buf = append(e.buf, body...)
+	binary.BigEndian.PutUint32(e.buf[:4], uint32(len(e.buf)-4))
+	if _, err := c.conn.Write(e.buf); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+
+	_ = c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var hdr [8]byte
+	if _, err := io.ReadFull(c.conn, hdr[:]); err != nil {
+		c.t.Fatalf("read header: %v", err)
+	}
+	if corr := int32(binary.BigEndian.Uint32(hdr[4:])); corr != c.corr {
+		c.t.Fatalf("correlation id want %d, got %d", c.corr, corr)
+	}
+	resp := make([]byte, binary.BigEndian.Uint32(hdr[:4])-4)
+	if _, err := io.ReadFull(c.conn, resp); err != nil {
+		c.t.Fatalf("read body: %v", err)
+	}
+	return &decoder{buf: resp}
+}
+
+// expectClosed проверяет, что сервер закрыл соединение.
+func (c *testConn) expectClosed() {
+	c.t.Helper()
+	_ = c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := c.conn.Read(make([]byte, 1)); err != io.EOF {
+		c.t.Errorf("want connection closed, got %v", err)
+	}
+}
+
+// send отправляет запрос, ответа на который не будет.
+func (c *testConn) send(apiKey, version int16, body []byte) {
+	c.t.Helper()
+	c.corr++
+	var e encoder
+	e.int32(0)
+	e.int16(apiKey)
+	e.int16(version)
+	e.int32(c.corr)
+	e.nullableString(nil)
+	e.buf = append(e.buf, body...)
+	binary.BigEndian.PutUint32(e.buf[:4], uint32(len(e.buf)-4))
+	if _, err := c.conn.Write(e.buf); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+}
+
+// saslPlain выполняет SaslHandshake и SaslAuthenticate с паролем password
+// и возвращает код ошибки SaslAuthenticate.
+func (c *testConn) saslPlain(password string) int16 {
+	c.t.Helper()
+	return c.saslPlainAs("", password)
+}
+
+// saslPlainAs — то же с authzid, пространством имён клиента.
+func (c *testConn) saslPlainAs(authzid, password string) int16 {
+	c.t.Helper()
+	var h encoder
+	h.string("PLAIN")
+	if code := c.roundTrip(apiSaslHandshake, 1, h.buf).int16(); code != errNone {
+		c.t.Fatalf("SaslHandshake: %d", code)
+	}
+	var a encoder
+	a.bytes([]byte(authzid + "\x00client\x00" + password))
+	return c.roundTrip(apiSaslAuthenticate, 1, a.buf).int16()
+}
+
+func TestServer_ApiVersions(t *testing.T) {
+	_, c := newTestServer(t)
+
+	d := c.roundTrip(apiApiVersions, 2, nil)
+	if code := d.int16(); code != errNone {
+		t.Fatalf("error code: %d", code)
+	}
+	found := map[int16]versionRange{}
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		found[d.int16()] = versionRange{d.int16(), d.int16()}
+	}
+	if found[apiProduce] != supportedAPIs[apiProduce] || len(found) != len(supportedAPIs) {
+		t.Errorf("api versions: %+v", found)
+	}
+
+	// Неподдерживаемая (flexible) версия — ответ v0 с UNSUPPORTED_VERSION.
+	d = c.roundTrip(apiApiVersions, 3, nil)
+	if code := d.int16(); code != errUnsupportedVersion {
+		t.Errorf("want UNSUPPORTED_VERSION, got %d", code)
+	}
+}
+
+func TestServer_Produce_Fetch(t *testing.T) {
+	topicUC, c := newTestServer(t)
+	_, _ = topicUC.CreateTopic(context.Background(), "orders", 10000)
+
+	var p encoder
+	p.nullableString(nil) // transactional_id
+	p.int16(1)            // acks
+	p.int32(1000)
+	p.arrayLen(1)
+	p.string("orders")
+	p.arrayLen(2)
+	p.int32(0)
+	p.bytes(encodeRecordBatch([]*domain.Message{
+		{Key: "k", Payload: []byte("hello"), CreatedAt: time.Now()},
+		{Payload: []byte("world"), Offset: 1, CreatedAt: time.Now()},
+	}))
+	p.int32(7) // несуществующая партиция
+	p.bytes(encodeRecordBatch([]*domain.Message{{Payload: []byte("x"), CreatedAt: time.Now()}}))
+
+	d := c.roundTrip(apiProduce, 7, p.buf)
+	if n := d.arrayLen(); n != 1 || d.string() != "orders" {
+		t.Fatalf("produce response topics")
+	}
+	d.arrayLen()
+	if part, code, base := d.int32(), d.int16(), d.int64(); part != 0 || code != errNone || base != 0 {
+		t.Errorf("partition 0: code=%d base=%d", code, base)
+	}
+	d.int64() // log_append_time
+	d.int64() // log_start_offset
+	if part, code := d.int32(), d.int16(); part != 7 || code != errUnknownTopicOrPartition {
+		t.Errorf("partition 7: want UNKNOWN_TOPIC_OR_PARTITION, got %d", code)
+	}
+
+	var f encoder
+	f.int32(-1)  // replica_id
+	f.int32(100) // max_wait_ms
+	f.int32(1)   // min_bytes
+	f.int32(1 << 20)
+	f.int8(0)
+	f.arrayLen(1)
+	f.string("orders")
+	f.arrayLen(1)
+	f.int32(0)
+	f.int64(1) // fetch_offset
+	f.int32(1 << 20)
+
+	d = c.roundTrip(apiFetch, 4, f.buf)
+	d.int32() // throttle
+	d.arrayLen()
+	d.string()
+	d.arrayLen()
+	part, code, hwm := d.int32(), d.int16(), d.int64()
+	if part != 0 || code != errNone || hwm != 2 {
+		t.Fatalf("fetch: partition=%d code=%d hwm=%d", part, code, hwm)
+	}
+	d.int64()    // last_stable_offset
+	d.arrayLen() // aborted_transactions
+	recs, err := decodeRecordBatches(d.bytes())
+	if err != nil || d.err != nil {
+		t.Fatalf("decode fetched records: %v %v", err, d.err)
+	}
+	if len(recs) != 1 || string(recs[0].value) != "world" {
+		t.Errorf("fetched: %+v", recs)
+	}
+}
+
+func TestServer_Produce_batches(t *testing.T) {
+	topicUC, addr := newTestServerWith(t, testOptions{})
+	_, _ = topicUC.CreateTopic(context.Background(), "orders", 10000)
+
+	// Пакеты параллельных продюсеров не перемежаются: запись i пакета
+	// лежит по смещению base_offset+i.
+	const producers, batches, size = 4, 20, 10
+	var wg sync.WaitGroup
+	bases := make([][]int64, producers)
+	for p := range producers {
+		c := dial(t, addr)
+		wg.Go(func() {
+			for b := range batches {
+				payloads := make([][]byte, size)
+				for i := range payloads {
+					payloads[i] = fmt.Appendf(nil, "%d-%d-%d", p, b, i)
+				}
+				d := c.roundTrip(apiProduce, 7, produceRecords("orders", payloads...))
+				if code := produceCode(d); code != errNone {
+					t.Errorf("produce: code %d", code)
+					return
+				}
+				bases[p] = append(bases[p], d.int64())
+				if appendTime := d.int64(); appendTime <= 0 {
+					t.Errorf("log_append_time_ms %d", appendTime)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	c := dial(t, addr)
+	d := c.roundTrip(apiFetch, 4, fetchRequest("orders"))
+	d.int32()
+	d.arrayLen()
+	d.string()
+	d.arrayLen()
+	d.int32()
+	d.int16()
+	d.int64()
+	d.int64()
+	d.arrayLen()
+	recs, err := decodeRecordBatches(d.bytes())
+	if err != nil || len(recs) != producers*batches*size {
+		t.Fatalf("fetched %d records: %v", len(recs), err)
+	}
+	for p, list := range bases {
+		for b, base := range list {
+			for i := range size {
+				if got, want := string(recs[base+int64(i)].value), fmt.Sprintf("%d-%d-%d", p, b, i); got != want {
+					t.Fatalf("offset %d: want %s, got %s", base+int64(i), want, got)
+				}
+			}
+		}
+	}
+
+	// Пакет с отклонённой записью не записывается целиком.
+	d = c.roundTrip(apiProduce, 7, produceRecords("orders", []byte("ok"), make([]byte, 2<<20)))
+	if code := produceCode(d); code != errMessageTooLarge {
+		t.Errorf("oversized record: want MESSAGE_TOO_LARGE, got %d", code)
+	}
+	d = c.roundTrip(apiFetch, 4, fetchRequest("orders"))
+	d.int32()
+	d.arrayLen()
+	d.string()
+	d.arrayLen()
+	d.int32()
+	d.int16()
+	if hwm := d.int64(); hwm != producers*batches*size {
+		t.Errorf("high watermark after rejected batch: %d", hwm)
+	}
+}
+
+func TestServer_OffsetCommit_OffsetFetch(t *testing.T) {
+	topicUC, c := newTestServer(t)
+	_, _ = topicUC.CreateTopic(context.Background(), "orders", 10000)
+
+	var oc encoder
+	oc.string("g1")
+	oc.int32(-1)
+	oc.string("")
+	oc.int64(-1) // retention_time_ms
+	oc.arrayLen(1)
+	oc.string("orders")
+	oc.arrayLen(1)
+	oc.int32(0)
+	oc.int64(42)
+	meta := "m"
+	oc.nullableString(&meta)
+	d := c.roundTrip(apiOffsetCommit, 2, oc.buf)
+	d.arrayLen()
+	d.string()
+	d.arrayLen()
+	if _, code := d.int32(), d.int16(); code != errNone {
+		t.Fatalf("commit error code %d", code)
+	}
+
+	var of encoder
+	of.string("g1")
+	of.arrayLen(-1) // все топики
+	d = c.roundTrip(apiOffsetFetch, 3, of.buf)
+	d.int32() // throttle
+	if n := d.arrayLen(); n != 1 || d.string() != "orders" {
+		t.Fatalf("offset fetch topics")
+	}
+	d.arrayLen()
+	part, offset, gotMeta, code := d.int32(), d.int64(), d.nullableString(), d.int16()
+	if part != 0 || offset != 42 || gotMeta == nil || *gotMeta != "m" || code != errNone {
+		t.Errorf("fetched offset: part=%d offset=%d code=%d", part, offset, code)
+	}
+}
+
+func TestServer_OffsetFetch_deletedTopic(t *testing.T) {
+	topicUC, c := newTestServer(t)
+	ctx := context.Background()
+	_, _ = topicUC.CreateTopic(ctx, "orders", 100)
+	_, _ = topicUC.CreateTopic(ctx, "payments", 100)
+	for _, topic := range []string{"orders", "payments"} {
+		d := c.roundTrip(apiOffsetCommit, 2, commitRequest("g1", topic))
+		d.arrayLen()
+		d.string()
+		d.arrayLen()
+		if _, code := d.int32(), d.int16(); code != errNone {
+			t.Fatalf("commit %s: code %d", topic, code)
+		}
+	}
+	committed := func() []string {
+		var of encoder
+		of.string("g1")
+		of.arrayLen(-1)
+		d := c.roundTrip(apiOffsetFetch, 3, of.buf)
+		d.int32()
+		var topics []string
+		for range d.arrayLen() {
+			topics = append(topics, d.string())
+			for range d.arrayLen() {
+				d.int32()
+				d.int64()
+				d.nullableString()
+				d.int16()
+			}
+		}
+		return topics
+	}
+
+	// Пересозданный топик не наследует смещения удалённого.
+	if err := topicUC.DeleteTopic(ctx, "orders", true); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = topicUC.CreateTopic(ctx, "orders", 100)
+	if got := committed(); !slices.Equal(got, []string{"payments"}) {
+		t.Errorf("after DeleteTopic: committed topics %v", got)
+	}
+	if err := topicUC.DeleteQueue(ctx, "payments", "0", true); err != nil {
+		t.Fatal(err)
+	}
+	if got := committed(); len(got) != 0 {
+		t.Errorf("after DeleteQueue: committed topics %v", got)
+	}
+}
+
+func TestServer_saslPlain(t *testing.T) {
+	authn, err := auth.NewAuthenticator(auth.Config{APIKeys: []auth.APIKey{{Name: "orders", Key: "secret"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, addr := newTestServerWith(t, testOptions{authn: authn})
+	var metadata encoder
+	metadata.arrayLen(0) // v0: все топики
+
+	// До аутентификации доступны только ApiVersions и SASL.
+	c := dial(t, addr)
+	if code := c.roundTrip(apiApiVersions, 2, nil).int16(); code != errNone {
+		t.Errorf("ApiVersions before SASL: %d", code)
+	}
+	c.send(apiMetadata, 0, metadata.buf)
+	c.expectClosed()
+
+	// Большой запрос до аутентификации отклоняется по заголовку, до чтения тела.
+	c = dial(t, addr)
+	_, _ = c.conn.Write(binary.BigEndian.AppendUint32(nil, 1<<20))
+	c.expectClosed()
+
+	c = dial(t, addr)
+	var h encoder
+	h.string("SCRAM-SHA-256")
+	d := c.roundTrip(apiSaslHandshake, 1, h.buf)
+	if code, n := d.int16(), d.arrayLen(); code != errUnsupportedSaslMech || n != 1 || d.string() != "PLAIN" {
+		t.Errorf("unsupported mechanism: code %d", code)
+	}
+	if code := c.saslPlain("wrong"); code != errSaslAuthFailed {
+		t.Errorf("wrong password: want SASL_AUTHENTICATION_FAILED, got %d", code)
+	}
+	c.expectClosed()
+
+	c = dial(t, addr)
+	if code := c.saslPlain("secret"); code != errNone {
+		t.Fatalf("SaslAuthenticate: %d", code)
+	}
+	d = c.roundTrip(apiMetadata, 0, metadata.buf)
+	if n := d.arrayLen(); n != 1 || d.err != nil {
+		t.Errorf("metadata after SASL: brokers %d, %v", n, d.err)
+	}
+	// После аутентификации действует обычный предел.
+	if code := produceCode(c.roundTrip(apiProduce, 7, produceRecords("orders", make([]byte, 64<<10)))); code != errUnknownTopicOrPartition {
+		t.Errorf("large produce after SASL: code %d", code)
+	}
+}
+
+// produceRequest — Produce v7 с одной записью в партицию 0 топика.
+func produceRequest(topic string) []byte {
+	return produceRecords(topic, []byte("x"))
+}
+
+// produceRecords — Produce v7 пакета записей payloads в партицию 0 топика.
+func produceRecords(topic string, payloads ...[]byte) []byte {
+	msgs := make([]*domain.Message, len(payloads))
+	for i, v := range payloads {
+		msgs[i] = &domain.Message{Offset: int64(i), Payload: v, CreatedAt: time.Now()}
+	}
+	var p encoder
+	p.nullableString(nil) // transactional_id
+	p.int16(1)            // acks
+	p.int32(1000)
+	p.arrayLen(1)
+	p.string(topic)
+	p.arrayLen(1)
+	p.int32(0)
+	p.bytes(encodeRecordBatch(msgs))
+	return p.buf
+}
+
+// produceCode возвращает код ошибки первой партиции ответа Produce v7.
+func produceCode(d *decoder) int16 {
+	d.arrayLen()
+	d.string()
+	d.arrayLen()
+	d.int32()
+	return d.int16()
+}
+
+// fetchRequest — Fetch v4 партиции 0 топика с начала без ожидания.
+func fetchRequest(topic string) []byte {
+	var f encoder
+	f.int32(-1) // replica_id
+	f.int32(0)  // max_wait_ms
+	f.int32(0)  // min_bytes
+	f.int32(1 << 20)
+	f.int8(0)
+	f.arrayLen(1)
+	f.string(topic)
+	f.arrayLen(1)
+	f.int32(0)
+	f.int64(0)
+	f.int32(1 << 20)
+	return f.buf
+}
+
+// commitRequest — OffsetCommit v2 смещения 1 партиции 0 топика.
+func commitRequest(group, topic string) []byte {
+	var oc encoder
+	oc.string(group)
+	oc.int32(-1)
+	oc.string("")
+	oc.int64(-1) // retention_time_ms
+	oc.arrayLen(1)
+	oc.string(topic)
+	oc.arrayLen(1)
+	oc.int32(0)
+	oc.int64(1)
+	oc.nullableString(nil)
+	return oc.buf
+}
+
+func TestServer_acl(t *testing.T) {
+	authn, err := auth.NewAuthenticator(auth.Config{APIKeys: []auth.APIKey{{Name: "orders", Key: "secret"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicUC, addr := newTestServerWith(t, testOptions{
+		authn: authn,
+		acl:   usecase.ACLPolicy{Enabled: true},
+		aclRules: []domain.ACLRule{
+			{Principal: "orders", ResourceType: domain.ACLTopic, Pattern: "orders",
+				Operations: []domain.ACLOperation{domain.ACLPublish, domain.ACLConsume}},
+			{Principal: "orders", ResourceType: domain.ACLGroup, Pattern: "billing",
+				Operations: []domain.ACLOperation{domain.ACLConsume}},
+		},
+	})
+	ctx := context.Background()
+	_, _ = topicUC.CreateTopic(ctx, "orders", 100)
+	_, _ = topicUC.CreateTopic(ctx, "payments", 100)
+	c := dial(t, addr)
+	if code := c.saslPlain("secret"); code != errNone {
+		t.Fatalf("SaslAuthenticate: %d", code)
+	}
+
+	// Metadata со всеми топиками показывает только доступные.
+	var m encoder
+	m.arrayLen(-1)
+	d := c.roundTrip(apiMetadata, 1, m.buf)
+	d.arrayLen()
+	d.int32()
+	d.string()
+	d.int32()
+	d.nullableString() // rack
+	d.int32()          // controller_id
+	if n := d.arrayLen(); n != 1 || d.int16() != errNone || d.string() != "orders" {
+		t.Errorf("metadata should list only orders, got %d topics", n)
+	}
+
+	if code := produceCode(c.roundTrip(apiProduce, 7, produceRequest("payments"))); code != errTopicAuthFailed {
+		t.Errorf("produce to payments: want TOPIC_AUTHORIZATION_FAILED, got %d", code)
+	}
+	if code := produceCode(c.roundTrip(apiProduce, 7, produceRequest("orders"))); code != errNone {
+		t.Errorf("produce to orders: %d", code)
+	}
+
+	d = c.roundTrip(apiFetch, 4, fetchRequest("payments"))
+	d.int32()
+	d.arrayLen()
+	d.string()
+	d.arrayLen()
+	if _, code := d.int32(), d.int16(); code != errTopicAuthFailed {
+		t.Errorf("fetch from payments: want TOPIC_AUTHORIZATION_FAILED, got %d", code)
+	}
+
+	for _, tc := range []struct {
+		group, topic string
+		want         int16
+	}{
+		{"billing", "orders", errNone},
+		{"audit", "orders", errGroupAuthFailed},
+		{"billing", "payments", errTopicAuthFailed},
+	} {
+		d = c.roundTrip(apiOffsetCommit, 2, commitRequest(tc.group, tc.topic))
+		d.arrayLen()
+		d.string()
+		d.arrayLen()
+		if _, code := d.int32(), d.int16(); code != tc.want {
+			t.Errorf("commit %s/%s: want %d, got %d", tc.group, tc.topic, tc.want, code)
+		}
+	}
+}
+
+func TestServer_namespace(t *testing.T) {
+	authn, err := auth.NewAuthenticator(auth.Config{APIKeys: []auth.APIKey{
+		{Name: "alice", Key: "alice-key"},
+		{Name: "bob", Key: "bob-key"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	topicUC, addr := newTestServerWith(t, testOptions{
+		authn: authn,
+		tenancy: usecase.TenantPolicy{Enabled: true, Tenants: []usecase.Tenant{
+			{Namespace: "team-a", Principals: []string{"alice"}},
+			{Namespace: "team-b", Principals: []string{"bob"}},
+		}},
+	})
+	for _, ns := range []string{"team-a", "team-b"} {
+		_, _ = topicUC.CreateTopic(domain.WithNamespace(context.Background(), ns), "orders", 100)
+	}
+
+	// Клиент, привязанный к арендатору, не может выбрать чужое пространство.
+	c := dial(t, addr)
+	if code := c.saslPlainAs("team-b", "alice-key"); code != errSaslAuthFailed {
+		t.Errorf("foreign namespace: want SASL_AUTHENTICATION_FAILED, got %d", code)
+	}
+
+	alice, bob := dial(t, addr), dial(t, addr)
+	if alice.saslPlain("alice-key") != errNone || bob.saslPlainAs("team-b", "bob-key") != errNone {
+		t.Fatal("SASL failed")
+	}
+	for range 2 {
+		alice.roundTrip(apiProduce, 7, produceRequest("orders"))
+	}
+	d := bob.roundTrip(apiProduce, 7, produceRequest("orders"))
+	d.arrayLen()
+	d.string()
+	d.arrayLen()
+	if _, code, base := d.int32(), d.int16(), d.int64(); code != errNone || base != 0 {
+		t.Errorf("team-b orders: code %d, base offset %d", code, base)
+	}
+
+	// Одноимённые группы разных пространств не делят смещения.
+	alice.roundTrip(apiOffsetCommit, 2, commitRequest("app", "orders"))
+	var of encoder
+	of.string("app")
+	of.arrayLen(-1)
+	if d := bob.roundTrip(apiOffsetFetch, 3, of.buf); d.int32() >= 0 && d.arrayLen() != 0 {
+		t.Error("team-b sees offsets of team-a")
+	}
+}
+
+func TestServer_quotas(t *testing.T) {
+	topicUC, addr := newTestServerWith(t, testOptions{quotas: usecase.QuotaPolicy{Topics: map[string]usecase.Quota{
+		"orders": {PublishMessagesPerSec: 10, ConsumeMessagesPerSec: 2},
+		"audit":  {PublishMessagesPerSec: 1},
+	}}})
+	_, _ = topicUC.CreateTopic(context.Background(), "orders", 100)
+	_, _ = topicUC.CreateTopic(context.Background(), "audit", 100)
+	c := dial(t, addr)
+
+	// Пакет больше ёмкости корзины проходит целиком, а следующий не дождётся
+	// лимита за timeout_ms и отклоняется без записи.
+	if code := produceCode(c.roundTrip(apiProduce, 7, produceRecords("audit", []byte("a"), []byte("b"), []byte("c")))); code != errNone {
+		t.Fatalf("first audit batch: code %d", code)
+	}
+	d := c.roundTrip(apiProduce, 7, produceRecords("audit", []byte("d"), []byte("e")))
+	if code := produceCode(d); code != errRequestTimedOut {
+		t.Errorf("throttled audit batch: want REQUEST_TIMED_OUT, got %d", code)
+	}
+	d.int64()
+	d.int64()
+	d.int64()
+	if throttle := d.int32(); throttle < 1000 {
+		t.Errorf("throttle_time_ms of the rejected batch: %d", throttle)
+	}
+	var lo encoder
+	lo.int32(-1) // replica_id
+	lo.arrayLen(1)
+	lo.string("audit")
+	lo.arrayLen(1)
+	lo.int32(0)
+	lo.int64(-1) // latest
+	d = c.roundTrip(apiListOffsets, 1, lo.buf)
+	d.arrayLen()
+	d.string()
+	d.arrayLen()
+	d.int32()
+	d.int16()
+	d.int64()
+	if end := d.int64(); end != 3 {
+		t.Errorf("audit high watermark: want 3, got %d", end)
+	}
+
+	// Одиннадцатая запись ждёт восстановления лимита, и ответ сообщает об этом.
+	var throttle int32
+	for range 11 {
+		d := c.roundTrip(apiProduce, 7, produceRequest("orders"))
+		if code := produceCode(d); code != errNone {
+			t.Fatalf("produce: code %d", code)
+		}
+		d.int64()
+		d.int64()
+		d.int64()
+		throttle = d.int32()
+	}
+	if throttle <= 0 {
+		t.Errorf("throttle_time_ms of the throttled produce: %d", throttle)
+	}
+
+	// Fetch отдаёт столько записей, сколько разрешает лимит чтения.
+	fetch := func() (records int, throttle int32) {
+		d := c.roundTrip(apiFetch, 4, fetchRequest("orders"))
+		throttle = d.int32()
+		d.arrayLen()
+		d.string()
+		d.arrayLen()
+		d.int32()
+		if code := d.int16(); code != errNone {
+			t.Fatalf("fetch: code %d", code)
+		}
+		d.int64()
+		d.int64()
+		d.arrayLen()
+		recs, err := decodeRecordBatches(d.bytes())
+		if err != nil {
+			t.Fatalf("decode records: %v", err)
+		}
+		return len(recs), throttle
+	}
+	if n, throttle := fetch(); n != 2 || throttle != 0 {
+		t.Errorf("first fetch: %d records, throttle %d", n, throttle)
+	}
+	if n, throttle := fetch(); n != 0 || throttle <= 0 {
+		t.Errorf("throttled fetch: %d records, throttle %d", n, throttle)
+	}
+}
@@ -0,0 +1,211 @@
+package stomp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Команды клиента и сервера STOMP 1.2.
+const (
+	cmdConnect     = "CONNECT"
+	cmdStomp       = "STOMP"
+	cmdSend        = "SEND"
+	cmdSubscribe   = "SUBSCRIBE"
+	cmdUnsubscribe = "UNSUBSCRIBE"
+	cmdAck         = "ACK"
+	cmdNack        = "NACK"
+	cmdDisconnect  = "DISCONNECT"
+
+	cmdConnected = "CONNECTED"
+	cmdMessage   = "MESSAGE"
+	cmdReceipt   = "RECEIPT"
+	cmdError     = "ERROR"
+)
+
+// Пределы строки команды или заголовка и числа заголовков кадра: без них
+// клиент исчерпал бы память, не отправив ни байта тела.
+const (
+	maxLineLength = 8 << 10
+	maxHeaders    = 128
+)
+
+var (
+	errFrameTooLarge = errors.New("frame too large")
+	errBadFrame      = errors.New("malformed frame")
+)
+
+// Frame — один кадр STOMP. Заголовки хранятся в порядке получения:
+// по спецификации при повторах значим первый.
+type Frame struct {
+	Command string
+	Headers [][2]string
+	Body    []byte
+}
+
+func newFrame(command string, headers ...string) *Frame {
+	f := &Frame{Command: command}
+	for i := 0; i+1 < len(headers); i += 2 {
+		f.Set(headers[i], headers[i+1])
+	}
+	return f
+}
+
+// Get возвращает первое значение заголовка.
+func (f *Frame) Get(name string) (string, bool) {
+	for _, h := range f.Headers {
+		if h[0] == name {
+			return h[1], true
+		}
+	}
+	return "", false
+}
+
+// Set добавляет заголовок в конец списка.
+func (f *Frame) Set(name, value string) {
+	f.Headers = append(f.Headers, [2]string{name, value})
+}
+
+// escapeHeaders: в CONNECT/CONNECTED экранирование не применяется (совместимость с 1.0).
+func escapeHeaders(command string) bool {
+	return command != cmdConnect && command != cmdConnected
+}
+
+var (
+	headerEscaper   = strings.NewReplacer("\\", "\\\\", "\r", "\\r", "\n", "\\n", ":", "\\c")
+	headerUnescaper = strings.NewReplacer("\\\\", "\\", "\\r", "\r", "\\n", "\n", "\\c", ":")
+)
+
+// readFrame читает следующий кадр, пропуская heart-beat (пустые строки).
+// maxBody ограничивает размер тела, чтобы клиент не мог исчерпать память.
+func readFrame(r *bufio.Reader, maxBody int) (*Frame, error) {
+	var command string
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if line != "" {
+			command = line
+			break
+		}
+	}
+
+	f := &Frame{Command: command}
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if line == "" {
+			break
+		}
+		if len(f.Headers) == maxHeaders {
+			return nil, fmt.Errorf("%w: more than %d headers", errFrameTooLarge, maxHeaders)
+		}
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			return nil, fmt.Errorf("%w: header without colon", errBadFrame)
+		}
+		name, value := line[:i], line[i+1:]
+		if escapeHeaders(command) {
+			name, value = headerUnescaper.Replace(name), headerUnescaper.Replace(value)
+		}
+		f.Set(name, value)
+	}
+
+	if cl, ok := f.Get("content-length"); ok {
+		n, err := strconv.Atoi(cl)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%w: bad content-length", errBadFrame)
+		}
+		if n > maxBody {
+			return nil, errFrameTooLarge
+		}
+		f.Body = make([]byte, n)
+		if _, err := io.ReadFull(r, f.Body); err != nil {
+			return nil, err
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b != 0 {
+			return nil, fmt.Errorf("%w: missing NUL after body", errBadFrame)
+		}
+		return f, nil
+	}
+
+	var body bytes.Buffer
+	for {
+		chunk, err := r.ReadSlice(0)
+		if body.Len()+len(chunk) > maxBody+1 {
+			return nil, errFrameTooLarge
+		}
+		body.Write(chunk)
+		if err == nil {
+			break
+		}
+		if err != bufio.ErrBufferFull {
+			return nil, err
+		}
+	}
+	f.Body = body.Bytes()[:body.Len()-1]
+	return f, nil
+}
+
+// readLine читает строку без конца строки; строка длиннее maxLineLength
+// отклоняется, не дочитываясь до конца.
+func readLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if len(line)+len(chunk) > maxLineLength+2 { // с "\r\n"
+			return "", fmt.Errorf("%w: line longer than %d bytes", errFrameTooLarge, maxLineLength)
+		}
+		line = append(line, chunk...)
+		if err == nil {
+			break
+		}
+		if err != bufio.ErrBufferFull {
+			return "", err
+		}
+	}
+	line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+	if len(line) > maxLineLength {
+		return "", fmt.Errorf("%w: line longer than %d bytes", errFrameTooLarge, maxLineLength)
+	}
+	return string(line), nil
+}
+
+// writeFrame сериализует кадр; content-length проставляется всегда, когда есть тело.
+func writeFrame(w *bufio.Writer, f *Frame) error {
+	esc := escapeHeaders(f.Command)
+	w.WriteString(f.Command)
+	w.WriteByte('\n')
+	for _, h := range f.Headers {
+		name, value := h[0], h[1]
+		if esc {
+			name, value = headerEscaper.Replace(name), headerEscaper.Replace(value)
+		}
+		w.WriteString(name)
+		w.WriteByte(':')
+		w.WriteString(value)
+		w.WriteByte('\n')
+	}
+	if len(f.Body) > 0 {
+		if _, ok := f.Get("content-length"); !ok {
+			w.WriteString("content-length:")
+			w.WriteString(strconv.Itoa(len(f.Body)))
+			w.WriteByte('\n')
+		}
+	}
+	w.WriteByte('\n')
+	w.Write(f.Body)
+	w.WriteByte(0)
+	return w.Flush()
+}
@@ -0,0 +1,129 @@
+package stomp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadFrame_contentLength(t *testing.T) {
+	raw := "SEND\ndestination:/topic/orders\ncontent-length:5\n\nhe\x00lo\x00"
+	f, err := readFrame(bufio.NewReader(strings.NewReader(raw)), 1024)
+	if err != nil {
+		t.Fatalf("readFrame: %v", err)
+	}
+	if f.Command != cmdSend {
+		t.Errorf("command want SEND, got %s", f.Command)
+	}
+	if dest, _ := f.Get("destination"); dest != "/topic/orders" {
+		t.Errorf("destination: %q", dest)
+	}
+	if string(f.Body) != "he\x00lo" {
+		t.Errorf("body: %q", f.Body)
+	}
+}
+
+func TestReadFrame_nulTerminated_heartbeats_escaping(t *testing.T) {
+	raw := "\n\r\nSEND\r\ndestination:/topic/t\r\nx-note:a\\cb\\nc\r\nx-note:ignored\r\n\r\nhello\x00"
+	f, err := readFrame(bufio.NewReader(strings.NewReader(raw)), 1024)
+	if err != nil {
+		t.Fatalf("readFrame: %v", err)
+	}
+	if v, _ := f.Get("x-note"); v != "a:b\nc" {
+		t.Errorf("unescaped header: %q", v)
+	}
+	if string(f.Body) != "hello" {
+		t.Errorf("body: %q", f.Body)
+	}
+}
+
+func TestReadFrame_tooLarge(t *testing.T) {
+	raw := "SEND\ndestination:/topic/t\n\n" + strings.Repeat("x", 100) + "\x00"
+	_, err := readFrame(bufio.NewReader(strings.NewReader(raw)), 10)
+	if !errors.Is(err, errFrameTooLarge) {
+		t.Errorf("want errFrameTooLarge, got %v", err)
+	}
+}
+
+func TestReadFrame_headerLimits(t *testing.T) {
+	long := "SEND\nx-note:" + strings.Repeat("x", maxLineLength) + "\n\n\x00"
+	if _, err := readFrame(bufio.NewReader(strings.NewReader(long)), 1024); !errors.Is(err, errFrameTooLarge) {
+		t.Errorf("long header line: want errFrameTooLarge, got %v", err)
+	}
+	// Предел проверяется по мере чтения: бесконечная строка не накапливается.
+	endless := io.MultiReader(strings.NewReader("SEND\nx-note:"), neverNewline{})
+	if _, err := readFrame(bufio.NewReader(endless), 1024); !errors.Is(err, errFrameTooLarge) {
+		t.Errorf("endless header line: want errFrameTooLarge, got %v", err)
+	}
+	fits := "SEND\nx-note:" + strings.Repeat("x", maxLineLength-len("x-note:")) + "\r\n\nok\x00"
+	if f, err := readFrame(bufio.NewReader(strings.NewReader(fits)), 1024); err != nil || string(f.Body) != "ok" {
+		t.Errorf("header line at the limit: %v", err)
+	}
+
+	var b strings.Builder
+	b.WriteString("SEND\n")
+	for i := range maxHeaders + 1 {
+		fmt.Fprintf(&b, "x-h%d:v\n", i)
+	}
+	b.WriteString("\n\x00")
+	if _, err := readFrame(bufio.NewReader(strings.NewReader(b.String())), 1024); !errors.Is(err, errFrameTooLarge) {
+		t.Errorf("too many headers: want errFrameTooLarge, got %v", err)
+	}
+}
+
+// neverNewline — поток без конца строки.
+type neverNewline struct{}
+
+func (neverNewline) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'x'
+	}
+	return len(p), nil
+}
+
+func TestWriteFrame_roundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	f := newFrame(cmdMessage, "destination", "/topic/t", "x-note", "a:b")
+	f.Body = []byte("payload")
+	if err := writeFrame(w, f); err != nil {
+		t.Fatalf("writeFrame: %v", err)
+	}
+	if !strings.Contains(buf.String(), "x-note:a\\cb\n") || !strings.Contains(buf.String(), "content-length:7\n") {
+		t.Errorf("unexpected encoding: %q", buf.String())
+	}
+
+	got, err := readFrame(bufio.NewReader(&buf), 1024)
+	if err != nil {
+		t.Fatalf("readFrame: %v", err)
+	}
+	if v, _ := got.Get("x-note"); v != "a:b" || string(got.Body) != "payload" {
+		t.Errorf("round trip: %+v", got)
+	}
+}
+
+func TestParseDestination(t *testing.T) {
+	tests := []struct {
+		dest, topic, queue string
+		wantErr            bool
+	}{
+		{"/topic/orders", "orders", "0", false},
+		{"/topic/orders/1", "orders", "1", false},
+		{"/queue/orders", "", "", true},
+		{"/topic/", "", "", true},
+	}
+	for _, tt := range tests {
+		topic, queue, err := parseDestination(tt.dest)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err=%v", tt.dest, err)
+			continue
+		}
+		if topic != tt.topic || queue != tt.queue {
+			t.Errorf("%s: got %s/%s", tt.dest, topic, queue)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package stomp
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"queue-service/internal/auth"
+	"queue-service/internal/usecase"
+)
+
+// Server — слушатель STOMP 1.2 поверх того же слоя use case, что и BrokerHandler.
+type Server struct {
+	publish   *usecase.PublishUseCase
+	subscribe *usecase.SubscriptionUseCase
+	consume   *usecase.ConsumeUseCase
+	// redelivery будит подписки с истёкшим сроком подтверждения раньше
+	// очередного опроса; nil — только опрос.
+	redelivery *usecase.RedeliveryScheduler
+	// authn проверяет passcode из CONNECT; nil — без аутентификации.
+	authn   *auth.Authenticator
+	acl     *usecase.ACLUseCase
+	tenants *usecase.TenantUseCase
+	quotas  *usecase.QuotaUseCase
+
+	maxBody      atomic.Int64
+	pollInterval time.Duration
+
+	mu       sync.Mutex
+	lis      net.Listener
+	sessions map[*session]struct{}
+	closed   bool
+}
+
+func NewServer(
+	publish *usecase.PublishUseCase,
+	subscribe *usecase.SubscriptionUseCase,
+	consume *usecase.ConsumeUseCase,
+	redelivery *usecase.RedeliveryScheduler,
+	authn *auth.Authenticator,
+	acl *usecase.ACLUseCase,
+	tenants *usecase.TenantUseCase,
+	quotas *usecase.QuotaUseCase,
+	maxMessageSize int,
+) *Server {
+	s := &Server{
+		publish:      publish,
+		subscribe:    subscribe,
+		consume:      consume,
+		redelivery:   redelivery,
+		authn:        authn,
+		acl:          acl,
+		tenants:      tenants,
+		quotas:       quotas,
+		pollInterval: 100 * time.Millisecond,
+		sessions:     make(map[*session]struct{}),
+	}
+	s.SetMaxMessageSize(maxMessageSize)
+	return s
+}
+
+// SetMaxMessageSize меняет предельный размер тела кадра; действует и в
+// открытых сессиях со следующего кадра.
+func (s *Server) SetMaxMessageSize(n int) {
+	s.maxBody.Store(int64(n))
+}
+
+// ErrServerClosed возвращается из Serve после Close.
+var ErrServerClosed = errors.New("stomp: server closed")
+
+// Serve принимает соединения, пока listener не будет закрыт.
+func (s *Server) Serve(lis net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.lis = lis
+	s.mu.Unlock()
+
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		sess := newSession(s, conn)
+		if !s.track(sess) {
+			_ = conn.Close()
+			return ErrServerClosed
+		}
+		go func() {
+			sess.serve()
+			s.untrack(sess)
+		}()
+	}
+}
+
+// Close закрывает listener и все активные сессии.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	lis := s.lis
+	sessions := make([]*session, 0, len(s.sessions))
+	for sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.mu.Unlock()
+
+	var err error
+	if lis != nil {
+		err = lis.Close()
+	}
+	for _, sess := range sessions {
+		sess.close()
+	}
+	return err
+}
+
+func (s *Server) track(sess *session) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.sessions[sess] = struct{}{}
+	return true
+}
+
+func (s *Server) untrack(sess *session) {
+	s.mu.Lock()
+	delete(s.sessions, sess)
+	s.mu.Unlock()
+	slog.Info("session closed", "component", "stomp", "session", sess.id, "peer", sess.conn.RemoteAddr().String())
+}
@@ -0,0 +1,420 @@
+package stomp
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"queue-service/internal/auth"
+	"queue-service/internal/domain"
+	"queue-service/internal/repository/memory"
+	"queue-service/internal/usecase"
+)
+
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+func newTestServer(t *testing.T) (*Server, *usecase.TopicUseCase, string) {
+	t.Helper()
+	return newTestServerPolling(t, 10*time.Millisecond)
+}
+
+// newTestServerPolling запускает сервер с заданным интервалом опроса и
+// планировщиком повторной доставки.
+func newTestServerPolling(t *testing.T, pollInterval time.Duration) (*Server, *usecase.TopicUseCase, string) {
+	t.Helper()
+	return newTestServerWith(t, pollInterval, testOptions{})
+}
+
+// testOptions — проверки тестового сервера; нулевые — выключены.
+type testOptions struct {
+	authn   *auth.Authenticator // проверка passcode
+	acl     usecase.ACLPolicy
+	tenancy usecase.TenantPolicy
+	quotas  usecase.QuotaPolicy
+}
+
+// newTestServerWith запускает сервер с проверками opts.
+func newTestServerWith(t *testing.T, pollInterval time.Duration, opts testOptions) (*Server, *usecase.TopicUseCase, string) {
+	t.Helper()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	subs := memory.NewSubscriptionRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	topicUC := usecase.NewTopicUseCase(topics, queues, msgs, subs, pending)
+	pub := usecase.NewPublishUseCase(topics, queues, msgs, 1024*1024, usecase.MemoryBudget{}, nil)
+	subUC := usecase.NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := usecase.NewConsumeUseCase(subs, msgs, pending, nil)
+
+	redelivery := usecase.NewRedeliveryScheduler(pending, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go redelivery.Run(ctx)
+
+	tenants, err := usecase.NewTenantUseCase(topics, subs, opts.tenancy)
+	if err != nil {
+		t.Fatalf("NewTenantUseCase: %v", err)
+	}
+	quotas, err := usecase.NewQuotaUseCase(topics, subs, opts.quotas)
+	if err != nil {
+		t.Fatalf("NewQuotaUseCase: %v", err)
+	}
+	acl := usecase.NewACLUseCase(memory.NewACLRepository(), opts.acl)
+	srv := NewServer(pub, subUC, consumeUC, redelivery, opts.authn, acl, tenants, quotas, 1024*1024)
+	srv.pollInterval = pollInterval
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return srv, topicUC, lis.Addr().String()
+}
+
+func dial(t *testing.T, addr string) *testClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	c := &testClient{t: t, conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+	c.send(newFrame(cmdConnect, "accept-version", "1.2", "host", "localhost"))
+	if f := c.expect(cmdConnected); f != nil {
+		if v, _ := f.Get("version"); v != "1.2" {
+			t.Errorf("version: %q", v)
+		}
+	}
+	return c
+}
+
+func (c *testClient) send(f *Frame) {
+	c.t.Helper()
+	if err := writeFrame(c.w, f); err != nil {
+		c.t.Fatalf("write %s: %v", f.Command, err)
+	}
+}
+
+func (c *testClient) expect(command string) *Frame {
+	c.t.Helper()
+	_ = c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	f, err := readFrame(c.r, 1024*1024)
+	if err != nil {
+		c.t.Fatalf("read %s: %v", command, err)
+	}
+	if f.Command != command {
+		msg, _ := f.Get("message")
+		c.t.Fatalf("want %s, got %s (%s)", command, f.Command, msg)
+	}
+	return f
+}
+
+func TestServer_Send_Subscribe_Ack(t *testing.T) {
+	_, topicUC, addr := newTestServer(t)
+	_, _ = topicUC.CreateTopic(context.Background(), "orders", 10000)
+	c := dial(t, addr)
+
+	send := newFrame(cmdSend, "destination", "/topic/orders", "key", "k1", "x-trace", "abc", "receipt", "r1")
+	send.Body = []byte("hello")
+	c.send(send)
+	if r := c.expect(cmdReceipt); r != nil {
+		if id, _ := r.Get("receipt-id"); id != "r1" {
+			t.Errorf("receipt-id: %q", id)
+		}
+	}
+
+	c.send(newFrame(cmdSubscribe, "id", "s1", "destination", "/topic/orders", "ack", "client-individual", "consumer-group", "g1"))
+	msg := c.expect(cmdMessage)
+	if string(msg.Body) != "hello" {
+		t.Errorf("body: %q", msg.Body)
+	}
+	if v, _ := msg.Get("key"); v != "k1" {
+		t.Errorf("key: %q", v)
+	}
+	if v, _ := msg.Get("x-trace"); v != "abc" {
+		t.Errorf("header x-trace: %q", v)
+	}
+	ackID, ok := msg.Get("ack")
+	if !ok {
+		t.Fatal("client ack mode must set ack header")
+	}
+
+	c.send(newFrame(cmdAck, "id", ackID, "receipt", "r2"))
+	c.expect(cmdReceipt)
+
+	// Повторный ACK того же id — ошибка протокола.
+	c.send(newFrame(cmdAck, "id", ackID))
+	c.expect(cmdError)
+}
+
+func TestServer_clientAck_cumulative(t *testing.T) {
+	_, topicUC, addr := newTestServer(t)
+	_, _ = topicUC.CreateTopic(context.Background(), "orders", 10000)
+	c := dial(t, addr)
+
+	for _, body := range []string{"m1", "m2", "m3"} {
+		send := newFrame(cmdSend, "destination", "/topic/orders", "key", "k")
+		send.Body = []byte(body)
+		c.send(send)
+	}
+	c.send(newFrame(cmdSubscribe, "id", "s1", "destination", "/topic/orders", "ack", "client", "consumer-group", "g1"))
+	var ackIDs []string
+	for range 3 {
+		id, _ := c.expect(cmdMessage).Get("ack")
+		ackIDs = append(ackIDs, id)
+	}
+
+	// ACK последнего сообщения подтверждает и все предыдущие.
+	c.send(newFrame(cmdAck, "id", ackIDs[2], "receipt", "r1"))
+	c.expect(cmdReceipt)
+	c.send(newFrame(cmdAck, "id", ackIDs[0]))
+	c.expect(cmdError)
+}
+
+func TestServer_Nack_redelivers(t *testing.T) {
+	_, topicUC, addr := newTestServer(t)
+	_, _ = topicUC.CreateTopic(context.Background(), "orders", 10000)
+	c := dial(t, addr)
+
+	send := newFrame(cmdSend, "destination", "/topic/orders")
+	send.Body = []byte("m1")
+	c.send(send)
+
+	c.send(newFrame(cmdSubscribe, "id", "s1", "destination", "/topic/orders", "ack", "client"))
+	first := c.expect(cmdMessage)
+	ackID, _ := first.Get("ack")
+	c.send(newFrame(cmdNack, "id", ackID))
+
+	second := c.expect(cmdMessage)
+	id1, _ := first.Get("message-id")
+	id2, _ := second.Get("message-id")
+	if id1 != id2 {
+		t.Errorf("nack should redeliver the same message: %s != %s", id1, id2)
+	}
+}
+
+func TestServer_errors(t *testing.T) {
+	_, _, addr := newTestServer(t)
+
+	c := dial(t, addr)
+	c.send(newFrame(cmdSend, "destination", "/topic/missing"))
+	if f := c.expect(cmdError); f != nil {
+		if msg, _ := f.Get("message"); msg != "topic not found: missing" {
+			t.Errorf("message: %q", msg)
+		}
+	}
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	raw := &testClient{t: t, conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+	raw.send(newFrame(cmdSend, "destination", "/topic/orders"))
+	raw.expect(cmdError)
+
+	// Слишком длинный заголовок отклоняется кадром ERROR.
+	c = dial(t, addr)
+	c.send(newFrame(cmdSend, "destination", "/topic/orders", "x-note", strings.Repeat("x", maxLineLength)))
+	if f := c.expect(cmdError); f != nil {
+		if msg, _ := f.Get("message"); !strings.HasPrefix(msg, errFrameTooLarge.Error()) {
+			t.Errorf("message: %q", msg)
+		}
+	}
+}
+
+func TestServer_redeliveryWakesPump(t *testing.T) {
+	// Опрос почти отключён: повторную доставку должен разбудить планировщик.
+	_, topicUC, addr := newTestServerPolling(t, time.Hour)
+	_, _ = topicUC.CreateTopic(context.Background(), "orders", 10000)
+	c := dial(t, addr)
+
+	send := newFrame(cmdSend, "destination", "/topic/orders")
+	send.Body = []byte("m1")
+	c.send(send)
+
+	c.send(newFrame(cmdSubscribe, "id", "s1", "destination", "/topic/orders", "ack", "client"))
+	first := c.expect(cmdMessage)
+	ackID, _ := first.Get("ack")
+	c.send(newFrame(cmdNack, "id", ackID))
+
+	second := c.expect(cmdMessage)
+	id1, _ := first.Get("message-id")
+	id2, _ := second.Get("message-id")
+	if id1 != id2 {
+		t.Errorf("nack should redeliver the same message: %s != %s", id1, id2)
+	}
+}
+
+func TestServer_authentication(t *testing.T) {
+	authn, err := auth.NewAuthenticator(auth.Config{APIKeys: []auth.APIKey{
+		{Name: "orders", Key: "secret"},
+		{Name: "guest", Key: "guest-key"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, topicUC, addr := newTestServerWith(t, 10*time.Millisecond, testOptions{
+		authn: authn,
+		acl:   usecase.ACLPolicy{Enabled: true, SuperUsers: []string{"orders"}},
+	})
+	_, _ = topicUC.CreateTopic(context.Background(), "orders", 100)
+
+	connect := func(passcode string) (*testClient, *Frame) {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		c := &testClient{t: t, conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+		f := newFrame(cmdConnect, "accept-version", "1.2", "host", "localhost", "login", "orders")
+		if passcode != "" {
+			f.Set("passcode", passcode)
+		}
+		c.send(f)
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		reply, err := readFrame(c.r, 1024)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return c, reply
+	}
+
+	for _, passcode := range []string{"", "wrong"} {
+		if _, f := connect(passcode); f.Command != cmdError {
+			t.Errorf("passcode %q: want ERROR, got %s", passcode, f.Command)
+		}
+	}
+	c, f := connect("secret")
+	if f.Command != cmdConnected {
+		t.Fatalf("valid passcode: got %s", f.Command)
+	}
+	c.send(newFrame(cmdSend, "destination", "/topic/orders", "receipt", "r1"))
+	c.expect(cmdReceipt)
+
+	// guest аутентифицирован, но прав на топик не имеет.
+	guest, f := connect("guest-key")
+	if f.Command != cmdConnected {
+		t.Fatalf("guest: got %s", f.Command)
+	}
+	guest.send(newFrame(cmdSend, "destination", "/topic/orders"))
+	if f := guest.expect(cmdError); f != nil {
+		if msg, _ := f.Get("message"); !strings.Contains(msg, "permission denied") {
+			t.Errorf("guest SEND: %q", msg)
+		}
+	}
+}
+
+func TestServer_namespace(t *testing.T) {
+	_, topicUC, addr := newTestServerWith(t, 10*time.Millisecond, testOptions{
+		tenancy: usecase.TenantPolicy{Enabled: true, TrustHeader: true},
+	})
+	_, _ = topicUC.CreateTopic(context.Background(), "orders", 100)
+	_, _ = topicUC.CreateTopic(domain.WithNamespace(context.Background(), "shop"), "payments", 100)
+
+	connect := func(ns string) (*testClient, *Frame) {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		c := &testClient{t: t, conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+		c.send(newFrame(cmdConnect, "accept-version", "1.2", "host", "localhost", "namespace", ns))
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		reply, err := readFrame(c.r, 1024)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return c, reply
+	}
+
+	if _, f := connect("a/b"); f.Command != cmdError {
+		t.Errorf("invalid namespace: got %s", f.Command)
+	}
+	c, f := connect("shop")
+	if f.Command != cmdConnected {
+		t.Fatalf("CONNECT: got %s", f.Command)
+	}
+	c.send(newFrame(cmdSend, "destination", "/topic/payments", "receipt", "r1"))
+	c.expect(cmdReceipt)
+	// Топик пространства по умолчанию из shop не виден.
+	c.send(newFrame(cmdSend, "destination", "/topic/orders"))
+	if f := c.expect(cmdError); f != nil {
+		if msg, _ := f.Get("message"); !strings.Contains(msg, "not found") {
+			t.Errorf("SEND to default topic: %q", msg)
+		}
+	}
+}
+
+func TestServer_rateLimits(t *testing.T) {
+	srv, topicUC, addr := newTestServerWith(t, 10*time.Millisecond, testOptions{quotas: usecase.QuotaPolicy{
+		Principal: usecase.Quota{PublishMessagesPerSec: 1},
+		Topic:     usecase.Quota{ConsumeMessagesPerSec: 10},
+	}})
+	ctx := context.Background()
+	_, _ = topicUC.CreateTopic(ctx, "orders", 100)
+
+	c := dial(t, addr)
+	c.send(newFrame(cmdSend, "destination", "/topic/orders", "receipt", "r1"))
+	c.expect(cmdReceipt)
+	c.send(newFrame(cmdSend, "destination", "/topic/orders"))
+	if f := c.expect(cmdError); f != nil {
+		if v, _ := f.Get(retryAfterHeader); v == "" {
+			t.Errorf("ERROR without %s: %v", retryAfterHeader, f.Headers)
+		}
+	}
+
+	// Сверх лимита чтения pump не падает, а дожидается новых токенов.
+	for i := 0; i < 12; i++ {
+		_, _ = srv.publish.Publish(ctx, "orders", "0", []byte("m"), "", nil)
+	}
+	sub := dial(t, addr)
+	sub.send(newFrame(cmdSubscribe, "id", "s1", "destination", "/topic/orders"))
+	for i := 0; i < 13; i++ {
+		sub.expect(cmdMessage)
+	}
+}
+
+func TestServer_temporarySubscriptions(t *testing.T) {
+	srv, topicUC, addr := newTestServer(t)
+	ctx := context.Background()
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+	count := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for {
+			subs, _ := srv.subscribe.ListSubscriptions(ctx, "orders")
+			if len(subs) == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("subscriptions: want %d, got %d", want, len(subs))
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	c := dial(t, addr)
+	c.send(newFrame(cmdSubscribe, "id", "s1", "destination", "/topic/orders"))
+	c.send(newFrame(cmdSubscribe, "id", "s2", "destination", "/topic/orders", "consumer-group", "g1"))
+	c.send(newFrame(cmdSubscribe, "id", "s3", "destination", "/topic/orders", "ack", "client"))
+	count(3)
+	// Подписка без consumer-group удаляется при UNSUBSCRIBE...
+	c.send(newFrame(cmdUnsubscribe, "id", "s1", "receipt", "r1"))
+	c.expect(cmdReceipt)
+	count(2)
+	// ...и при закрытии сессии; подписка именованной группы остаётся.
+	_ = c.conn.Close()
+	count(1)
+	if sub, err := srv.subscribe.FindSubscription(ctx, "orders", "g1"); err != nil {
+		t.Errorf("named group subscription: %v, %v", sub, err)
+	}
+}
@@ -0,0 +1,579 @@
+package stomp
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"queue-service/internal/auth"
+	"queue-service/internal/domain"
+	"queue-service/internal/usecase"
+)
+
+const (
+	destinationPrefix = "/topic/"
+	consumeBatch      = 10
+
+	ackAuto             = "auto"
+	ackClient           = "client"
+	ackClientIndividual = "client-individual"
+)
+
+// Заголовки SEND, которые не попадают в Message.Headers.
+var reservedSendHeaders = map[string]bool{
+	"destination":    true,
+	"content-length": true,
+	"receipt":        true,
+	"transaction":    true,
+	"key":            true,
+}
+
+type subscription struct {
+	id             string // id из SUBSCRIBE, уникален в рамках сессии
+	subscriptionID string
+	topic          string
+	destination    string
+	ackMode        string
+	// temporary — подписка группы, созданной сессией без consumer-group:
+	// она удаляется при UNSUBSCRIBE и закрытии сессии.
+	temporary bool
+	stop      chan struct{}
+}
+
+type ackRef struct {
+	subscriptionID string
+	deliveryID     string
+	// cumulative — подписка с ack:client: ACK подтверждает и все выданные
+	// раньше (seq меньше) сообщения этой подписки.
+	cumulative bool
+	seq        uint64
+}
+
+type session struct {
+	srv  *Server
+	conn net.Conn
+	id   string
+	r    *bufio.Reader
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	wmu sync.Mutex
+	w   *bufio.Writer
+
+	mu        sync.Mutex
+	connected bool
+	subs      map[string]*subscription
+	acks      map[string]ackRef
+	ackSeq    uint64
+
+	closeOnce sync.Once
+}
+
+func newSession(srv *Server, conn net.Conn) *session {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &session{
+		srv:    srv,
+		conn:   conn,
+		id:     genSessionID(),
+		r:      bufio.NewReader(conn),
+		w:      bufio.NewWriter(conn),
+		ctx:    ctx,
+		cancel: cancel,
+		subs:   make(map[string]*subscription),
+		acks:   make(map[string]ackRef),
+	}
+}
+
+func (s *session) serve() {
+	defer s.close()
+	slog.Info("session opened", "component", "stomp", "session", s.id, "peer", s.conn.RemoteAddr().String())
+	for {
+		f, err := readFrame(s.r, int(s.srv.maxBody.Load()))
+		if err != nil {
+			if errors.Is(err, errFrameTooLarge) || errors.Is(err, errBadFrame) {
+				s.sendError(nil, err.Error(), "")
+			}
+			return
+		}
+		if err := s.handle(f); err != nil {
+			var headers []string
+			var throttled *throttledError
+			if errors.As(err, &throttled) {
+				headers = []string{retryAfterHeader, throttled.retryAfter()}
+			}
+			s.sendError(f, err.Error(), "", headers...)
+			return
+		}
+		if f.Command == cmdDisconnect {
+			return
+		}
+	}
+}
+
+func (s *session) handle(f *Frame) error {
+	s.mu.Lock()
+	connected := s.connected
+	s.mu.Unlock()
+
+	if !connected {
+		if f.Command != cmdConnect && f.Command != cmdStomp {
+			return errors.New("expected CONNECT frame")
+		}
+		return s.handleConnect(f)
+	}
+
+	var err error
+	switch f.Command {
+	case cmdSend:
+		err = s.handleSend(f)
+	case cmdSubscribe:
+		err = s.handleSubscribe(f)
+	case cmdUnsubscribe:
+		err = s.handleUnsubscribe(f)
+	case cmdAck:
+		err = s.handleAck(f, false)
+	case cmdNack:
+		err = s.handleAck(f, true)
+	case cmdDisconnect:
+	default:
+		err = fmt.Errorf("unsupported command %q", f.Command)
+	}
+	if err != nil {
+		return err
+	}
+	if receipt, ok := f.Get("receipt"); ok {
+		return s.write(newFrame(cmdReceipt, "receipt-id", receipt))
+	}
+	return nil
+}
+
+func (s *session) handleConnect(f *Frame) error {
+	if versions, ok := f.Get("accept-version"); ok && !containsVersion(versions, "1.2") {
+		return errors.New("supported protocol versions: 1.2")
+	}
+	if err := s.authenticate(f); err != nil {
+		return err
+	}
+	if err := s.selectNamespace(f); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.connected = true
+	s.mu.Unlock()
+	return s.write(newFrame(cmdConnected,
+		"version", "1.2",
+		"heart-beat", "0,0",
+		"server", "mini-message-broker",
+		"session", s.id,
+	))
+}
+
+// authenticate проверяет passcode из CONNECT — API-ключ или JWT — и кладёт
+// идентичность клиента в контекст сессии. login не проверяется.
+func (s *session) authenticate(f *Frame) error {
+	if !s.srv.authn.Enabled() {
+		return nil
+	}
+	passcode, _ := f.Get("passcode")
+	id, err := s.srv.authn.AuthenticateSecret(passcode)
+	switch {
+	case err == nil:
+		s.ctx = auth.WithIdentity(s.ctx, id)
+		return nil
+	case errors.Is(err, auth.ErrNoCredentials) && s.srv.authn.AllowAnonymous():
+		return nil
+	}
+	slog.Warn("authentication failed", "component", "stomp", "session", s.id,
+		"peer", s.conn.RemoteAddr().String(), "error", err)
+	return errors.New("access refused: invalid passcode")
+}
+
+// selectNamespace определяет пространство имён сессии по клиенту и
+// заголовку namespace кадра CONNECT.
+func (s *session) selectNamespace(f *Frame) error {
+	if !s.srv.tenants.Enabled() {
+		return nil
+	}
+	requested, _ := f.Get("namespace")
+	ns, err := s.srv.tenants.Resolve(s.ctx, requested)
+	if err != nil {
+		return err
+	}
+	s.ctx = domain.WithNamespace(s.ctx, ns)
+	return nil
+}
+
+func (s *session) handleSend(f *Frame) error {
+	dest, _ := f.Get("destination")
+	topic, queueID, err := parseDestination(dest)
+	if err != nil {
+		return err
+	}
+	if err := s.srv.acl.Authorize(s.ctx, domain.ACLPublish, domain.ACLTopic, topic); err != nil {
+		return err
+	}
+	if wait, err := s.srv.quotas.AllowPublish(s.ctx, topic, len(f.Body)); err != nil {
+		return &throttledError{err: err, wait: wait}
+	}
+	key, _ := f.Get("key")
+	var headers map[string]string
+	for _, h := range f.Headers {
+		if reservedSendHeaders[h[0]] {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		if _, dup := headers[h[0]]; !dup {
+			headers[h[0]] = h[1]
+		}
+	}
+	if _, err := s.srv.publish.Publish(s.ctx, topic, queueID, f.Body, key, headers); err != nil {
+		return usecaseError(err, topic, queueID)
+	}
+	return nil
+}
+
+func (s *session) handleSubscribe(f *Frame) error {
+	id, ok := f.Get("id")
+	if !ok || id == "" {
+		return errors.New("SUBSCRIBE requires id header")
+	}
+	dest, _ := f.Get("destination")
+	topic, queueID, err := parseDestination(dest)
+	if err != nil {
+		return err
+	}
+	ackMode, ok := f.Get("ack")
+	if !ok {
+		ackMode = ackAuto
+	}
+	guarantee := domain.AtMostOnce
+	switch ackMode {
+	case ackAuto:
+	case ackClient, ackClientIndividual:
+		guarantee = domain.AtLeastOnce
+	default:
+		return fmt.Errorf("unsupported ack mode %q", ackMode)
+	}
+	group, _ := f.Get("consumer-group")
+	temporary := group == ""
+	if temporary {
+		group = "stomp-" + s.id + "-" + id
+	}
+
+	if err := s.srv.acl.Authorize(s.ctx, domain.ACLConsume, domain.ACLTopic, topic); err != nil {
+		return err
+	}
+	if err := s.srv.acl.Authorize(s.ctx, domain.ACLConsume, domain.ACLGroup, group); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	_, dup := s.subs[id]
+	s.mu.Unlock()
+	if dup {
+		return fmt.Errorf("subscription id %q already in use", id)
+	}
+
+	sub, err := s.srv.subscribe.FindSubscription(s.ctx, topic, group)
+	switch {
+	case err == nil:
+		if sub.QueueID != queueID {
+			return fmt.Errorf("consumer group %q is subscribed to queue %q", group, sub.QueueID)
+		}
+		if sub.DeliveryGuarantee == domain.AtMostOnce && guarantee == domain.AtLeastOnce {
+			return fmt.Errorf("consumer group %q uses at-most-once delivery", group)
+		}
+	case errors.Is(err, usecase.ErrSubscriptionNotFound):
+		if err := s.srv.tenants.CheckSubscriptionQuota(s.ctx); err != nil {
+			return err
+		}
+		if err := s.srv.quotas.CheckSubscriptionQuota(s.ctx); err != nil {
+			return err
+		}
+		sub, err = s.srv.subscribe.Subscribe(s.ctx, topic, queueID, group, guarantee)
+		if err != nil {
+			return usecaseError(err, topic, queueID)
+		}
+	default:
+		return err
+	}
+
+	st := &subscription{
+		id:             id,
+		subscriptionID: sub.ID,
+		topic:          topic,
+		destination:    dest,
+		ackMode:        ackMode,
+		temporary:      temporary,
+		stop:           make(chan struct{}),
+	}
+	s.mu.Lock()
+	s.subs[id] = st
+	s.mu.Unlock()
+	go s.pump(st)
+	return nil
+}
+
+func (s *session) handleUnsubscribe(f *Frame) error {
+	id, _ := f.Get("id")
+	s.mu.Lock()
+	st, ok := s.subs[id]
+	delete(s.subs, id)
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown subscription id %q", id)
+	}
+	close(st.stop)
+	return nil
+}
+
+func (s *session) handleAck(f *Frame, nack bool) error {
+	id, ok := f.Get("id")
+	if !ok || id == "" {
+		return fmt.Errorf("%s requires id header", f.Command)
+	}
+	s.mu.Lock()
+	ref, ok := s.acks[id]
+	delete(s.acks, id)
+	var earlier []ackRef
+	if ok && !nack && ref.cumulative {
+		for k, r := range s.acks {
+			if r.subscriptionID == ref.subscriptionID && r.seq < ref.seq {
+				earlier = append(earlier, r)
+				delete(s.acks, k)
+			}
+		}
+	}
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown ack id %q", id)
+	}
+	if nack {
+		return s.srv.consume.Nack(s.ctx, ref.subscriptionID, ref.deliveryID)
+	}
+	// Ранние доставки могли быть уже подтверждены иначе (например, после
+	// переподписки): их отсутствие не ошибка.
+	sort.Slice(earlier, func(i, j int) bool { return earlier[i].seq < earlier[j].seq })
+	for _, r := range earlier {
+		if err := s.srv.consume.Ack(s.ctx, r.subscriptionID, r.deliveryID); err != nil && !errors.Is(err, usecase.ErrDeliveryNotFound) {
+			return err
+		}
+	}
+	return s.srv.consume.Ack(s.ctx, ref.subscriptionID, ref.deliveryID)
+}
+
+// pump опрашивает ConsumeUseCase и отправляет сообщения клиенту кадрами MESSAGE.
+// Между опросами он просыпается и по сигналу планировщика повторной доставки.
+// При исчерпанном лимите чтения pump ждёт, пока он восстановится.
+// Временную подписку pump удаляет при выходе, когда чтение из неё закончено.
+func (s *session) pump(st *subscription) {
+	defer s.drop(st)
+	for {
+		n, wait, err := s.srv.quotas.ReserveConsume(s.ctx, st.topic, consumeBatch)
+		if err != nil {
+			select {
+			case <-st.stop:
+				return
+			case <-s.ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			continue
+		}
+		var redeliver <-chan struct{}
+		if s.srv.redelivery != nil {
+			redeliver = s.srv.redelivery.Ready(st.subscriptionID)
+		}
+		msgs, err := s.srv.consume.Consume(s.ctx, st.subscriptionID, n)
+		s.srv.quotas.ReleaseConsume(s.ctx, st.topic, n-len(msgs))
+		if err != nil {
+			if s.ctx.Err() == nil {
+				s.sendError(nil, "consume failed", err.Error())
+				s.close()
+			}
+			return
+		}
+		for _, m := range msgs {
+			if err := s.deliver(st, m); err != nil {
+				s.close()
+				return
+			}
+		}
+		if len(msgs) == n {
+			select {
+			case <-st.stop:
+				return
+			case <-s.ctx.Done():
+				return
+			default:
+			}
+			continue
+		}
+		select {
+		case <-st.stop:
+			return
+		case <-s.ctx.Done():
+			return
+		case <-redeliver:
+		case <-time.After(s.srv.pollInterval):
+		}
+	}
+}
+
+// drop удаляет временную подписку st вместе с её доставками.
+func (s *session) drop(st *subscription) {
+	if !st.temporary {
+		return
+	}
+	// Сессия может быть уже закрыта, а удалить подписку нужно всё равно.
+	ctx := context.WithoutCancel(s.ctx)
+	if err := s.srv.subscribe.Unsubscribe(ctx, st.subscriptionID); err != nil && !errors.Is(err, usecase.ErrSubscriptionNotFound) {
+		slog.WarnContext(ctx, "temporary subscription not deleted", "component", "stomp", "session", s.id,
+			"subscription", st.subscriptionID, "error", err)
+	}
+}
+
+func (s *session) deliver(st *subscription, m *domain.Message) error {
+	f := newFrame(cmdMessage,
+		"subscription", st.id,
+		"message-id", m.ID,
+		"destination", st.destination,
+		"offset", strconv.FormatInt(m.Offset, 10),
+	)
+	if m.Key != "" {
+		f.Set("key", m.Key)
+	}
+	manualAck := m.DeliveryID != "" && st.ackMode != ackAuto
+	if manualAck {
+		ackID := st.subscriptionID + "/" + m.DeliveryID
+		s.mu.Lock()
+		s.ackSeq++
+		s.acks[ackID] = ackRef{
+			subscriptionID: st.subscriptionID,
+			deliveryID:     m.DeliveryID,
+			cumulative:     st.ackMode == ackClient,
+			seq:            s.ackSeq,
+		}
+		s.mu.Unlock()
+		f.Set("ack", ackID)
+	}
+	for k, v := range m.Headers {
+		if _, exists := f.Get(k); !exists && k != "content-length" {
+			f.Set(k, v)
+		}
+	}
+	f.Body = m.Payload
+	if err := s.write(f); err != nil {
+		return err
+	}
+	// Группа с at-least-once, но клиент выбрал ack:auto — подтверждаем сами.
+	if m.DeliveryID != "" && !manualAck {
+		return s.srv.consume.Ack(s.ctx, st.subscriptionID, m.DeliveryID)
+	}
+	return nil
+}
+
+func (s *session) write(f *Frame) error {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	return writeFrame(s.w, f)
+}
+
+// sendError отправляет ERROR с дополнительными заголовками headers (пары
+// имя, значение); после него соединение закрывается (STOMP 1.2).
+func (s *session) sendError(req *Frame, message, detail string, headers ...string) {
+	f := newFrame(cmdError, append([]string{"message", message, "content-type", "text/plain"}, headers...)...)
+	if req != nil {
+		if receipt, ok := req.Get("receipt"); ok {
+			f.Set("receipt-id", receipt)
+		}
+	}
+	if detail != "" {
+		f.Body = []byte(detail)
+	}
+	_ = s.write(f)
+}
+
+func (s *session) close() {
+	s.closeOnce.Do(func() {
+		s.cancel()
+		_ = s.conn.Close()
+	})
+}
+
+// parseDestination разбирает "/topic/<topic>[/<queue_id>]"; очередь по умолчанию "0".
+func parseDestination(dest string) (topic, queueID string, err error) {
+	rest, ok := strings.CutPrefix(dest, destinationPrefix)
+	if !ok || rest == "" {
+		return "", "", fmt.Errorf("invalid destination %q, want /topic/<topic>[/<queue>]", dest)
+	}
+	topic, queueID, _ = strings.Cut(rest, "/")
+	if topic == "" {
+		return "", "", fmt.Errorf("invalid destination %q", dest)
+	}
+	if queueID == "" {
+		queueID = "0"
+	}
+	return topic, queueID, nil
+}
+
+func containsVersion(list, version string) bool {
+	for _, v := range strings.Split(list, ",") {
+		if strings.TrimSpace(v) == version {
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfterHeader — заголовок ERROR при отказе по лимиту частоты: через
+// сколько миллисекунд стоит переподключиться и повторить SEND.
+const retryAfterHeader = "retry-after-ms"
+
+// throttledError — отказ по лимиту частоты публикации.
+type throttledError struct {
+	err  error
+	wait time.Duration
+}
+
+func (e *throttledError) Error() string { return e.err.Error() }
+
+func (e *throttledError) Unwrap() error { return e.err }
+
+// retryAfter — пауза в миллисекундах, округлённая вверх.
+func (e *throttledError) retryAfter() string {
+	return strconv.FormatInt(int64(max((e.wait+time.Millisecond-1)/time.Millisecond, 1)), 10)
+}
+
+func usecaseError(err error, topic, queueID string) error {
+	switch {
+	case errors.Is(err, usecase.ErrTopicNotFound):
+		return fmt.Errorf("topic not found: %s", topic)
+	case errors.Is(err, domain.ErrNotFound):
+		return fmt.Errorf("queue not found: %s/%s", topic, queueID)
+	case errors.Is(err, usecase.ErrMessageTooLarge):
+		return errors.New("message too large")
+	case errors.Is(err, usecase.ErrMemoryLimit):
+		return fmt.Errorf("memory limit exceeded for topic %s", topic)
+	}
+	return err
+}
+
+func genSessionID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -20,13 +20,16 @@ const (
 
 // Topic represents a named stream of messages (like Kafka topic).
 type Topic struct {
+	Namespace         string // пространство имён арендатора
 	Name              string
 	RetentionMessages int
+	CreatedBy         string // клиент, создавший топик
 	CreatedAt         time.Time
 }
 
 // Queue is a FIFO queue bound to a topic (partition/queue abstraction).
 type Queue struct {
+	Namespace string
 	TopicName string
 	QueueID   string // partition or queue identifier
 	CreatedAt time.Time
@@ -35,13 +38,16 @@ type Queue struct {
 // Subscription represents a consumer subscription (consumer group + queue).
 type Subscription struct {
 	ID                string
+	Namespace         string
 	TopicName         string
 	QueueID           string
 	ConsumerGroup     string
 	DeliveryGuarantee DeliveryGuarantee
 	AckTimeout        time.Duration
-	Offset            int64 // next offset to read for this consumer
+	Offset            int64  // next offset to read for this consumer
+	CreatedBy         string // клиент, создавший подписку
 	CreatedAt         time.Time
+	LastConsumedAt    time.Time // время последнего Consume; нулевое, если не было
 }
 
 type Message struct {
@@ -56,9 +62,58 @@ type Message struct {
 	DeliveryID string // for at-least-once ack
 }
 
+// messageOverhead — приблизительный объём служебных полей хранимого
+// сообщения (структура, строки, элемент журнала и индексов).
+const messageOverhead = 128
+
+// Size — приблизительный объём сообщения в памяти брокера, по которому
+// считается бюджет памяти: данные, ключ, заголовки и накладные расходы.
+func (m *Message) Size() int64 {
+	n := messageOverhead + len(m.ID) + len(m.TopicName) + len(m.QueueID) + len(m.Key) + len(m.Payload)
+	for k, v := range m.Headers {
+		n += len(k) + len(v)
+	}
+	return int64(n)
+}
+
 // PendingDelivery — это сообщение, которое не было подтверждено как минимум один раз
 type PendingDelivery struct {
-	Message    Message
-	ExpiresAt  time.Time
-	DeliveryID string
+	Message     Message
+	ExpiresAt   time.Time
+	DeliveryID  string
+	DeliveredAt time.Time // первая выдача; повторные доставки его не меняют
+}
+
+// ACLOperation — действие, на которое правило ACL выдаёт право.
+type ACLOperation string
+
+const (
+	ACLPublish ACLOperation = "publish" // публикация в топик
+	ACLConsume ACLOperation = "consume" // подписка и чтение топика, работа от имени группы
+	ACLAdmin   ACLOperation = "admin"   // управление ресурсом; включает publish и consume
+)
+
+// ACLResourceType — вид ресурса, к которому относится правило.
+type ACLResourceType string
+
+const (
+	ACLTopic   ACLResourceType = "topic"
+	ACLGroup   ACLResourceType = "group"   // группа потребителей
+	ACLCluster ACLResourceType = "cluster" // брокер целиком: правила ACL, память
+)
+
+// ACLRule разрешает клиенту Principal операции Operations над ресурсами
+// вида ResourceType из пространства имён Namespace, имена которых подходят
+// под Pattern.
+type ACLRule struct {
+	ID           string
+	Principal    string // имя клиента; "*" — любой, включая анонимного
+	ResourceType ACLResourceType
+	// Namespace — пространство имён ресурсов; "*" — любое. У правил cluster
+	// пусто: кластер один на все пространства.
+	Namespace string
+	// Pattern — имя ресурса, префикс с "*" на конце ("orders.*") или "*".
+	Pattern    string
+	Operations []ACLOperation
+	CreatedAt  time.Time
 }
@@ -0,0 +1,30 @@
+package domain
+
+import "context"
+
+// DefaultNamespace — пространство имён запросов, для которых оно не задано:
+// брокер без разделения на арендаторов целиком работает в нём.
+const DefaultNamespace = "default"
+
+type namespaceKey struct{}
+
+// WithNamespace возвращает ctx, операции репозиториев с которым видят только
+// топики, очереди, сообщения и подписки пространства имён ns.
+func WithNamespace(ctx context.Context, ns string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, ns)
+}
+
+// NamespaceFromContext возвращает пространство имён, заданное в ctx;
+// false — не задано.
+func NamespaceFromContext(ctx context.Context) (string, bool) {
+	ns, ok := ctx.Value(namespaceKey{}).(string)
+	return ns, ok && ns != ""
+}
+
+// Namespace возвращает пространство имён запроса или DefaultNamespace.
+func Namespace(ctx context.Context) string {
+	if ns, ok := NamespaceFromContext(ctx); ok {
+		return ns
+	}
+	return DefaultNamespace
+}
@@ -5,12 +5,19 @@ import (
 	"time"
 )
 
+// Репозитории топиков, очередей, сообщений и подписок разделены по
+// пространствам имён: операция видит только данные пространства Namespace(ctx),
+// одинаковые имена в разных пространствах не пересекаются.
+
 // TopicRepository manages topics.
 type TopicRepository interface {
 	Create(ctx context.Context, topic *Topic) error
 	Get(ctx context.Context, name string) (*Topic, error)
 	Delete(ctx context.Context, name string) error
 	List(ctx context.Context) ([]*Topic, error)
+	// Namespaces возвращает пространства имён, в которых есть топики;
+	// не зависит от пространства ctx.
+	Namespaces(ctx context.Context) ([]string, error)
 }
 
 // QueueRepository manages queues (partitions) per topic.
@@ -18,16 +25,41 @@ type QueueRepository interface {
 	Create(ctx context.Context, queue *Queue) error
 	Get(ctx context.Context, topicName, queueID string) (*Queue, error)
 	ListByTopic(ctx context.Context, topicName string) ([]*Queue, error)
+	Delete(ctx context.Context, topicName, queueID string) error
 }
 
 // MessageRepository stores and retrieves messages.
+// Сообщения, которые возвращают Read и GetByID, общие для всех читателей:
+// их поля, тело и заголовки изменять нельзя — для изменений нужна копия.
 type MessageRepository interface {
 	Append(ctx context.Context, msg *Message) error
+	// AppendBatch записывает сообщения одной очереди подряд: другие записи
+	// между ними не вклиниваются, и смещения идут без пропусков.
+	AppendBatch(ctx context.Context, msgs []*Message) error
 	Read(ctx context.Context, topicName, queueID string, offset, limit int) ([]*Message, error)
 	GetByID(ctx context.Context, topicName, queueID, messageID string) (*Message, error)
+	// Offsets возвращает смещение первого хранимого сообщения и high-water mark (следующее смещение).
+	Offsets(ctx context.Context, topicName, queueID string) (start, end int64, err error)
+	// OffsetForTime возвращает смещение первого сообщения с CreatedAt >= ts либо end, если таких нет.
+	OffsetForTime(ctx context.Context, topicName, queueID string, ts time.Time) (int64, error)
+	// DeleteQueue удаляет все сообщения очереди вместе с её журналом.
+	DeleteQueue(ctx context.Context, topicName, queueID string) error
+	// Purge удаляет все сообщения очереди и переносит начало журнала на high-water mark;
+	// смещения новых сообщений продолжают прежнюю нумерацию. Возвращает новое начало.
+	Purge(ctx context.Context, topicName, queueID string) (start int64, err error)
+	// Truncate удаляет сообщения со смещениями меньше before (ограничение хранения).
+	// Возвращает новое начало журнала.
+	Truncate(ctx context.Context, topicName, queueID string, before int64) (start int64, err error)
+	// Usage возвращает суммарный Size хранимых сообщений топика или всех
+	// топиков пространства имён, если topicName пуст.
+	Usage(ctx context.Context, topicName string) (int64, error)
+	// TotalUsage возвращает суммарный Size сообщений всех пространств имён.
+	TotalUsage(ctx context.Context) (int64, error)
 }
 
 // SubscriptionRepository manages consumer subscriptions.
+// ID подписок уникальны во всём брокере, но подписка другого пространства
+// имён для операций по ID не существует.
 type SubscriptionRepository interface {
 	Create(ctx context.Context, sub *Subscription) error
 	Get(ctx context.Context, id string) (*Subscription, error)
@@ -35,12 +67,51 @@ type SubscriptionRepository interface {
 	ListByTopic(ctx context.Context, topicName string) ([]*Subscription, error)
 	List(ctx context.Context) ([]*Subscription, error)
 	AdvanceOffset(ctx context.Context, id string, offset int64) error
+	// RaiseOffset атомарно сдвигает смещение на offset, только если текущее
+	// не меньше from и меньше offset: смещение не откатывается назад
+	// параллельным подтверждением, а перемещение ниже from не затирается.
+	RaiseOffset(ctx context.Context, id string, from, offset int64) error
+	// MarkConsumed запоминает время последнего обращения потребителя.
+	MarkConsumed(ctx context.Context, id string, at time.Time) error
+	SetAckTimeout(ctx context.Context, id string, timeout time.Duration) error
+	Delete(ctx context.Context, id string) error
 }
 
 // PendingDeliveryRepository tracks unacknowledged deliveries (at-least-once).
+// Доставки привязаны к ID подписки и от пространства имён не зависят:
+// use case находят подписку в пространстве запроса до обращения к ним.
 type PendingDeliveryRepository interface {
 	Add(ctx context.Context, subID string, pd *PendingDelivery) error
-	Ack(ctx context.Context, subID, deliveryID string) (ackedOffset int64, err error)
-	Expired(ctx context.Context, subID string, before time.Time) ([]*PendingDelivery, error)
+	// Ack удаляет доставку и возвращает смещение, до которого подтверждены
+	// все выданные подписке сообщения: подтверждение не по порядку его не
+	// сдвигает за неподтверждённые.
+	Ack(ctx context.Context, subID, deliveryID string) (committed int64, err error)
+	// Expired возвращает до limit доставок со сроком подтверждения до before,
+	// начиная с самых давно просроченных.
+	Expired(ctx context.Context, subID string, before time.Time, limit int) ([]*PendingDelivery, error)
+	// ExpireDue помечает доставки со сроком до before как готовые к повторной
+	// доставке (их по-прежнему отдаёт Expired) и возвращает ID подписок, у
+	// которых такие появились. Вызывается планировщиком повторной доставки.
+	ExpireDue(ctx context.Context, before time.Time) (subIDs []string, err error)
+	// NextDeadline возвращает ближайший срок подтверждения среди ещё не
+	// просроченных доставок всех подписок; ok == false, если таких нет.
+	NextDeadline(ctx context.Context) (deadline time.Time, ok bool, err error)
 	Remove(ctx context.Context, subID, deliveryID string) error
+	// Touch переносит срок ожидания подтверждения (nack — expiresAt = now).
+	Touch(ctx context.Context, subID, deliveryID string, expiresAt time.Time) error
+	// LastOffset возвращает наибольшее смещение, выданное подписке, пока у неё
+	// есть неподтверждённые доставки: чтение продолжается после него, даже если
+	// само оно уже подтверждено.
+	LastOffset(ctx context.Context, subID string) (offset int64, ok bool, err error)
+	// RemoveAll удаляет все неподтверждённые доставки подписки.
+	RemoveAll(ctx context.Context, subID string) error
+	// Stats возвращает число неподтверждённых доставок и время первой выдачи самой старой из них.
+	Stats(ctx context.Context, subID string) (count int, oldest time.Time, err error)
+}
+
+// ACLRepository хранит правила доступа.
+type ACLRepository interface {
+	Create(ctx context.Context, rule *ACLRule) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context) ([]*ACLRule, error)
 }
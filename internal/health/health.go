@@ -0,0 +1,114 @@
+// Package health сообщает о состоянии брокера: стандартный сервис gRPC
+// grpc.health.v1.Health и HTTP-проверки /healthz (процесс жив) и /readyz
+// (брокер готов принимать запросы).
+package health
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// State — стадия жизни брокера.
+type State int
+
+const (
+	Starting State = iota // хранилище и слушатели ещё запускаются
+	Ready                 // запросы принимаются
+	Draining              // идёт остановка, новые запросы не направляются
+)
+
+func (s State) String() string {
+	switch s {
+	case Ready:
+		return "ready"
+	case Draining:
+		return "draining"
+	}
+	return "starting"
+}
+
+// Checker хранит состояние брокера и отражает его в сервисе Health. Сервисы
+// services (полные имена gRPC-сервисов) и общий статус "" получают SERVING
+// после MarkReady и NOT_SERVING после Shutdown.
+type Checker struct {
+	server   *grpchealth.Server
+	services []string
+
+	mu    sync.Mutex
+	state State
+}
+
+// New создаёт Checker в состоянии Starting: все сервисы NOT_SERVING.
+func New(services ...string) *Checker {
+	c := &Checker{server: grpchealth.NewServer(), services: append([]string{""}, services...)}
+	c.setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	return c
+}
+
+// Register добавляет сервис grpc.health.v1.Health на сервер srv.
+func (c *Checker) Register(srv *grpc.Server) {
+	healthpb.RegisterHealthServer(srv, c.server)
+}
+
+// MarkReady переводит брокер в Ready; после Shutdown не действует.
+func (c *Checker) MarkReady() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state != Starting {
+		return
+	}
+	c.state = Ready
+	c.setStatus(healthpb.HealthCheckResponse_SERVING)
+}
+
+// Shutdown переводит брокер в Draining: все сервисы NOT_SERVING, /readyz
+// отвечает 503. Вызывается до остановки слушателей, чтобы балансировщик
+// успел убрать брокер из ротации.
+func (c *Checker) Shutdown() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.state = Draining
+	// Shutdown сервера Health выставляет NOT_SERVING и игнорирует
+	// дальнейшие SetServingStatus.
+	c.server.Shutdown()
+}
+
+// State возвращает текущую стадию.
+func (c *Checker) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+func (c *Checker) setStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	for _, s := range c.services {
+		c.server.SetServingStatus(s, status)
+	}
+}
+
+// LivenessHandler отвечает 200, пока процесс обслуживает HTTP, в том числе
+// во время остановки: перезапуск не ускорил бы её.
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = fmt.Fprintln(w, "ok")
+	})
+}
+
+// ReadinessHandler отвечает 200 в состоянии Ready и 503 при запуске и
+// остановке; тело — название стадии.
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		state := c.State()
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if state != Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_, _ = fmt.Fprintln(w, state)
+	})
+}
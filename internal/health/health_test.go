@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func status(t *testing.T, c *Checker, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := c.server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("Check(%q): %v", service, err)
+	}
+	return resp.Status
+}
+
+func readyz(t *testing.T, c *Checker) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	c.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	return rec.Code, strings.TrimSpace(rec.Body.String())
+}
+
+func TestChecker_lifecycle(t *testing.T) {
+	c := New("broker.Broker")
+	for _, svc := range []string{"", "broker.Broker"} {
+		if st := status(t, c, svc); st != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("starting %q: %v", svc, st)
+		}
+	}
+	if code, body := readyz(t, c); code != http.StatusServiceUnavailable || body != "starting" {
+		t.Errorf("readyz while starting: %d %q", code, body)
+	}
+
+	c.MarkReady()
+	for _, svc := range []string{"", "broker.Broker"} {
+		if st := status(t, c, svc); st != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("ready %q: %v", svc, st)
+		}
+	}
+	if code, body := readyz(t, c); code != http.StatusOK || body != "ready" {
+		t.Errorf("readyz when ready: %d %q", code, body)
+	}
+
+	c.Shutdown()
+	c.MarkReady() // после остановки не возвращает готовность
+	for _, svc := range []string{"", "broker.Broker"} {
+		if st := status(t, c, svc); st != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("draining %q: %v", svc, st)
+		}
+	}
+	if code, body := readyz(t, c); code != http.StatusServiceUnavailable || body != "draining" {
+		t.Errorf("readyz while draining: %d %q", code, body)
+	}
+
+	rec := httptest.NewRecorder()
+	c.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("healthz while draining: %d", rec.Code)
+	}
+}
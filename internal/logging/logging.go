@@ -0,0 +1,109 @@
+// Package logging настраивает структурированные логи брокера на log/slog:
+// уровень и формат из конфигурации, request_id, клиент, пространство имён и
+// контекст трассы из ctx.
+//
+// Use case и слушатели пишут через slog.Default() с контекстом запроса
+// (slog.InfoContext и т. п.), поэтому New обычно передаётся в slog.SetDefault.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"queue-service/internal/auth"
+	"queue-service/internal/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config — параметры логов.
+type Config struct {
+	Level  string // debug, info (по умолчанию), warn или error
+	Format string // json (по умолчанию) или text
+	// LevelVar, если задан, получает Level, и через него уровень можно
+	// менять на ходу.
+	LevelVar *slog.LevelVar
+}
+
+// ParseLevel разбирает logging.level; пустая строка означает info.
+func ParseLevel(s string) (slog.Level, error) {
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", s)
+	}
+	return l, nil
+}
+
+// New создаёт логгер, который пишет в w записи не ниже cfg.Level и
+// добавляет к ним request_id, client, namespace, trace_id и span_id из
+// контекста.
+func New(w io.Writer, cfg Config) (*slog.Logger, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	if cfg.LevelVar != nil {
+		cfg.LevelVar.Set(level)
+		opts.Level = cfg.LevelVar
+	}
+	var h slog.Handler
+	switch cfg.Format {
+	case "", "json":
+		h = slog.NewJSONHandler(w, opts)
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+	return slog.New(contextHandler{h}), nil
+}
+
+type requestIDKey struct{}
+
+// WithRequestID возвращает ctx с идентификатором запроса для логов.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID возвращает идентификатор запроса из ctx или "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// contextHandler добавляет к записи атрибуты запроса из ctx.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id := RequestID(ctx); id != "" {
+			r.AddAttrs(slog.String("request_id", id))
+		}
+		if id, ok := auth.FromContext(ctx); ok {
+			r.AddAttrs(slog.String("client", id.Name))
+		}
+		if ns, ok := domain.NamespaceFromContext(ctx); ok {
+			r.AddAttrs(slog.String("namespace", ns))
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
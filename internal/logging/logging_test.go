@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"queue-service/internal/auth"
+	"queue-service/internal/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew_levelAndContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{Level: "warn"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := auth.WithIdentity(WithRequestID(context.Background(), "r1"), auth.Identity{Name: "orders-svc"})
+	ctx = domain.WithNamespace(ctx, "shop")
+	ctx = trace.ContextWithSpanContext(ctx,
+		trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+
+	logger.InfoContext(ctx, "skipped")
+	logger.WarnContext(ctx, "memory limit", "topic", "orders")
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("want one JSON record, got %q: %v", buf.String(), err)
+	}
+	if rec["msg"] != "memory limit" || rec["topic"] != "orders" || rec["request_id"] != "r1" || rec["client"] != "orders-svc" ||
+		rec["namespace"] != "shop" || rec["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || rec["span_id"] != "00f067aa0ba902b7" {
+		t.Errorf("record %v", rec)
+	}
+}
+
+func TestNew_text(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Config{Level: "debug", Format: "text"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	logger.With("component", "stomp").DebugContext(WithRequestID(context.Background(), "r2"), "session closed")
+	if out := buf.String(); !strings.Contains(out, "level=DEBUG") || !strings.Contains(out, "component=stomp") || !strings.Contains(out, "request_id=r2") {
+		t.Errorf("text record %q", out)
+	}
+}
+
+func TestNew_levelVar(t *testing.T) {
+	var buf bytes.Buffer
+	level := new(slog.LevelVar)
+	logger, err := New(&buf, Config{Level: "warn", LevelVar: level})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	logger.Info("skipped")
+	level.Set(slog.LevelInfo)
+	logger.Info("written")
+	if out := buf.String(); strings.Contains(out, "skipped") || !strings.Contains(out, "written") {
+		t.Errorf("records %q", out)
+	}
+}
+
+func TestNew_invalid(t *testing.T) {
+	if _, err := New(&bytes.Buffer{}, Config{Level: "verbose"}); err == nil {
+		t.Error("unknown level must fail")
+	}
+	if _, err := New(&bytes.Buffer{}, Config{Format: "xml"}); err == nil {
+		t.Error("unknown format must fail")
+	}
+}
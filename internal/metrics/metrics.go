@@ -0,0 +1,184 @@
+// Package metrics собирает метрики брокера в формате Prometheus: события
+// use case (usecase.Metrics), коды ответов gRPC и состояние очередей и
+// подписок, которое считывается при каждом опросе /metrics.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"queue-service/internal/domain"
+	"queue-service/internal/usecase"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc/codes"
+)
+
+const namespace = "broker"
+
+// collectTimeout ограничивает чтение состояния брокера при одном опросе.
+const collectTimeout = 5 * time.Second
+
+// Metrics реализует usecase.Metrics и счётчик RPC для перехватчика gRPC.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	published       *prometheus.CounterVec
+	publishDuration *prometheus.HistogramVec
+	consumed        *prometheus.CounterVec
+	consumeDuration *prometheus.HistogramVec
+	redelivered     *prometheus.CounterVec
+	acked           *prometheus.CounterVec
+	ackDuration     *prometheus.HistogramVec
+	grpcRequests    *prometheus.CounterVec
+}
+
+var _ usecase.Metrics = (*Metrics)(nil)
+
+// New создаёт метрики в собственном реестре вместе со стандартными метриками
+// Go-рантайма и процесса.
+func New() *Metrics {
+	queueLabels := []string{"namespace", "topic", "queue"}
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		published: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "messages_published_total",
+			Help: "Messages written to a queue.",
+		}, queueLabels),
+		publishDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "publish_duration_seconds",
+			Help:    "Time to publish one message.",
+			Buckets: prometheus.ExponentialBuckets(0.00001, 4, 10),
+		}, queueLabels),
+		consumed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "messages_consumed_total",
+			Help: "Messages handed out by Consume, including redeliveries.",
+		}, queueLabels),
+		consumeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "consume_duration_seconds",
+			Help:    "Time of a Consume call that returned messages.",
+			Buckets: prometheus.ExponentialBuckets(0.00001, 4, 10),
+		}, queueLabels),
+		redelivered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "messages_redelivered_total",
+			Help: "At-least-once messages delivered again after ack timeout or nack.",
+		}, queueLabels),
+		acked: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "messages_acked_total",
+			Help: "Acknowledged at-least-once deliveries.",
+		}, queueLabels),
+		ackDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "ack_duration_seconds",
+			Help:    "Time to process an Ack.",
+			Buckets: prometheus.ExponentialBuckets(0.00001, 4, 10),
+		}, queueLabels),
+		grpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "grpc_requests_total",
+			Help: "Completed gRPC requests by method and status code.",
+		}, []string{"method", "code"}),
+	}
+	m.registry.MustRegister(
+		m.published, m.publishDuration,
+		m.consumed, m.consumeDuration, m.redelivered,
+		m.acked, m.ackDuration,
+		m.grpcRequests,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler отдаёт метрики для Prometheus.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) Published(ns, topic, queue string, d time.Duration) {
+	m.published.WithLabelValues(ns, topic, queue).Inc()
+	m.publishDuration.WithLabelValues(ns, topic, queue).Observe(d.Seconds())
+}
+
+func (m *Metrics) Consumed(ns, topic, queue string, n int, d time.Duration) {
+	m.consumed.WithLabelValues(ns, topic, queue).Add(float64(n))
+	m.consumeDuration.WithLabelValues(ns, topic, queue).Observe(d.Seconds())
+}
+
+func (m *Metrics) Redelivered(ns, topic, queue string, n int) {
+	m.redelivered.WithLabelValues(ns, topic, queue).Add(float64(n))
+}
+
+func (m *Metrics) Acked(ns, topic, queue string, d time.Duration) {
+	m.acked.WithLabelValues(ns, topic, queue).Inc()
+	m.ackDuration.WithLabelValues(ns, topic, queue).Observe(d.Seconds())
+}
+
+// RPCCompleted учитывает завершённый вызов gRPC.
+func (m *Metrics) RPCCompleted(method string, code codes.Code) {
+	m.grpcRequests.WithLabelValues(method, code.String()).Inc()
+}
+
+// RegisterState добавляет метрики состояния всех пространств имён: глубину
+// очередей, отставание подписок и число неподтверждённых доставок. Они вычисляются при опросе,
+// поэтому удалённые очереди и подписки сразу пропадают из вывода.
+func (m *Metrics) RegisterState(topics *usecase.TopicUseCase, messages *usecase.MessageUseCase, subs *usecase.SubscriptionUseCase) {
+	m.registry.MustRegister(&stateCollector{topics: topics, messages: messages, subs: subs})
+}
+
+var (
+	queueDepthDesc = prometheus.NewDesc(namespace+"_queue_depth",
+		"Messages stored in a queue.", []string{"namespace", "topic", "queue"}, nil)
+	subscriptionLagDesc = prometheus.NewDesc(namespace+"_subscription_lag",
+		"Messages of the queue not yet acknowledged (or read, for at-most-once) by the consumer group.",
+		[]string{"namespace", "topic", "queue", "consumer_group"}, nil)
+	subscriptionPendingDesc = prometheus.NewDesc(namespace+"_subscription_pending",
+		"Unacknowledged deliveries of a subscription.",
+		[]string{"namespace", "topic", "queue", "consumer_group"}, nil)
+)
+
+type stateCollector struct {
+	topics   *usecase.TopicUseCase
+	messages *usecase.MessageUseCase
+	subs     *usecase.SubscriptionUseCase
+}
+
+func (c *stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueDepthDesc
+	ch <- subscriptionLagDesc
+	ch <- subscriptionPendingDesc
+}
+
+// Collect пропускает то, что не удалось прочитать (например, топик удалён
+// во время опроса): частичные данные полезнее ошибки всего опроса.
+func (c *stateCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	namespaces, _ := c.topics.Namespaces(ctx)
+	for _, ns := range namespaces {
+		c.collectNamespace(domain.WithNamespace(ctx, ns), ns, ch)
+	}
+}
+
+func (c *stateCollector) collectNamespace(ctx context.Context, ns string, ch chan<- prometheus.Metric) {
+	topics, _ := c.topics.ListTopics(ctx)
+	for _, t := range topics {
+		queues, _ := c.topics.ListQueues(ctx, t.Name)
+		for _, q := range queues {
+			start, end, err := c.messages.Offsets(ctx, t.Name, q.QueueID)
+			if err != nil {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(end-start), ns, t.Name, q.QueueID)
+		}
+	}
+
+	stats, _ := c.subs.ListSubscriptionStats(ctx, "")
+	for _, st := range stats {
+		s := st.Subscription
+		ch <- prometheus.MustNewConstMetric(subscriptionLagDesc, prometheus.GaugeValue, float64(st.Lag), ns, s.TopicName, s.QueueID, s.ConsumerGroup)
+		ch <- prometheus.MustNewConstMetric(subscriptionPendingDesc, prometheus.GaugeValue, float64(st.Pending), ns, s.TopicName, s.QueueID, s.ConsumerGroup)
+	}
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"queue-service/internal/domain"
+	"queue-service/internal/repository/memory"
+	"queue-service/internal/usecase"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+)
+
+func TestMetrics_usecasesAndState(t *testing.T) {
+	ctx := context.Background()
+	m := New()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	subs := memory.NewSubscriptionRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	topicUC := usecase.NewTopicUseCase(topics, queues, msgs, subs, pending)
+	pub := usecase.NewPublishUseCase(topics, queues, msgs, 1024, usecase.MemoryBudget{}, m)
+	subUC := usecase.NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := usecase.NewConsumeUseCase(subs, msgs, pending, m)
+	messageUC := usecase.NewMessageUseCase(topics, queues, msgs)
+	m.RegisterState(topicUC, messageUC, subUC)
+
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+	for i := 0; i < 3; i++ {
+		_, _ = pub.Publish(ctx, "orders", "0", []byte("m"), "", nil)
+	}
+	sub, _ := subUC.Subscribe(ctx, "orders", "0", "billing", domain.AtLeastOnce)
+	out, _ := consumeUC.Consume(ctx, sub.ID, 2)
+	_ = consumeUC.Nack(ctx, sub.ID, out[0].DeliveryID)
+	again, _ := consumeUC.Consume(ctx, sub.ID, 2)
+	_ = consumeUC.Ack(ctx, sub.ID, again[0].DeliveryID)
+	// Топик с тем же именем в другом пространстве имён — отдельные ряды.
+	shop := domain.WithNamespace(ctx, "shop")
+	_, _ = topicUC.CreateTopic(shop, "orders", 10000)
+	_, _ = pub.Publish(shop, "orders", "0", []byte("m"), "", nil)
+	m.RPCCompleted("/broker.Broker/Publish", codes.OK)
+	m.RPCCompleted("/broker.Broker/Publish", codes.NotFound)
+
+	for _, c := range []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"published", testutil.ToFloat64(m.published.WithLabelValues("default", "orders", "0")), 3},
+		{"consumed", testutil.ToFloat64(m.consumed.WithLabelValues("default", "orders", "0")), 3},
+		{"redelivered", testutil.ToFloat64(m.redelivered.WithLabelValues("default", "orders", "0")), 1},
+		{"acked", testutil.ToFloat64(m.acked.WithLabelValues("default", "orders", "0")), 1},
+	} {
+		if c.got != c.want {
+			t.Errorf("%s: want %v, got %v", c.name, c.want, c.got)
+		}
+	}
+
+	// Подтверждено сообщение 0: смещение 1, из трёх сообщений не подтверждены два.
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	for _, line := range []string{
+		`broker_queue_depth{namespace="default",queue="0",topic="orders"} 3`,
+		`broker_queue_depth{namespace="shop",queue="0",topic="orders"} 1`,
+		`broker_subscription_lag{consumer_group="billing",namespace="default",queue="0",topic="orders"} 2`,
+		`broker_subscription_pending{consumer_group="billing",namespace="default",queue="0",topic="orders"} 1`,
+		`broker_grpc_requests_total{code="NotFound",method="/broker.Broker/Publish"} 1`,
+		`broker_publish_duration_seconds_count{namespace="default",queue="0",topic="orders"} 3`,
+		`broker_ack_duration_seconds_count{namespace="default",queue="0",topic="orders"} 1`,
+	} {
+		if !strings.Contains(string(body), line) {
+			t.Errorf("/metrics has no %q", line)
+		}
+	}
+}
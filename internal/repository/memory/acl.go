@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"queue-service/internal/domain"
+)
+
+// aclRepo хранит правила в порядке добавления.
+type aclRepo struct {
+	mu    sync.RWMutex
+	rules []*domain.ACLRule
+}
+
+func NewACLRepository() domain.ACLRepository {
+	return &aclRepo{}
+}
+
+func (r *aclRepo) Create(ctx context.Context, rule *domain.ACLRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.rules {
+		if existing.ID == rule.ID {
+			return domain.ErrExists
+		}
+	}
+	r.rules = append(r.rules, copyRule(rule))
+	return nil
+}
+
+func (r *aclRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, rule := range r.rules {
+		if rule.ID == id {
+			r.rules = slices.Delete(r.rules, i, i+1)
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (r *aclRepo) List(ctx context.Context) ([]*domain.ACLRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]*domain.ACLRule, len(r.rules))
+	for i, rule := range r.rules {
+		out[i] = copyRule(rule)
+	}
+	return out, nil
+}
+
+func copyRule(rule *domain.ACLRule) *domain.ACLRule {
+	c := *rule
+	c.Operations = slices.Clone(rule.Operations)
+	return &c
+}
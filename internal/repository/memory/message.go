@@ -2,66 +2,341 @@ package memory
 
 import (
 	"context"
+	"math"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"queue-service/internal/domain"
 )
 
+// timeIndexInterval — сколько смещений покрывает одна запись индекса времени.
+const timeIndexInterval = 1024
+
+// queueLog — журнал одной очереди. msgs[i] имеет смещение start+i.
+// Поля защищены mu: очереди блокируются независимо друг от друга.
+type queueLog struct {
+	mu    sync.RWMutex
+	start int64
+	msgs  []*domain.Message
+	byID  map[string]int64
+	// bytes — суммарный Size сообщений журнала.
+	bytes int64
+	// deleted — журнал удалён DeleteQueue; запись в него нужно повторить в новом.
+	deleted bool
+
+	// times[i] — наибольший CreatedAt среди сообщений со смещениями меньше
+	// (timeBase+i+1)*timeIndexInterval. Значения не убывают даже при
+	// неупорядоченных CreatedAt, поэтому по ним работает бинарный поиск.
+	timeBase int64
+	times    []time.Time
+}
+
+func newQueueLog() *queueLog {
+	return &queueLog{byID: make(map[string]int64)}
+}
+
+func (l *queueLog) end() int64 { return l.start + int64(len(l.msgs)) }
+
+func (l *queueLog) append(m *domain.Message) {
+	m.Offset = l.end()
+	l.msgs = append(l.msgs, m)
+	l.byID[m.ID] = m.Offset
+	l.bytes += m.Size()
+
+	block := m.Offset / timeIndexInterval
+	if len(l.times) == 0 {
+		l.timeBase = block
+	}
+	for int64(len(l.times)) <= block-l.timeBase {
+		var prev time.Time
+		if n := len(l.times); n > 0 {
+			prev = l.times[n-1]
+		}
+		l.times = append(l.times, prev)
+	}
+	if i := block - l.timeBase; m.CreatedAt.After(l.times[i]) {
+		l.times[i] = m.CreatedAt
+	}
+}
+
+// truncate удаляет сообщения со смещениями меньше before вместе с их
+// записями в индексах и возвращает освобождённый объём.
+func (l *queueLog) truncate(before int64) (freed int64) {
+	if before > l.end() {
+		before = l.end()
+	}
+	n := before - l.start
+	if n <= 0 {
+		return 0
+	}
+	for i := int64(0); i < n; i++ {
+		m := l.msgs[i]
+		// ID может повторяться; удаляем запись, только если она указывает на это сообщение.
+		if off, ok := l.byID[m.ID]; ok && off == m.Offset {
+			delete(l.byID, m.ID)
+		}
+		freed += m.Size()
+		l.msgs[i] = nil
+	}
+	l.bytes -= freed
+	l.msgs = l.msgs[n:]
+	l.start = before
+
+	if drop := before/timeIndexInterval - l.timeBase; drop > 0 {
+		if drop > int64(len(l.times)) {
+			drop = int64(len(l.times))
+		}
+		l.times = l.times[drop:]
+		l.timeBase += drop
+	}
+	return freed
+}
+
+// messageRepo хранит журналы очередей. Карта logs (ключ msgKey) читается без
+// общей блокировки; операции над сообщениями берут блокировку своего журнала,
+// так что запись в одну очередь не мешает работе с другими.
+// Объём сообщений считается атомарными счётчиками: общим, по пространствам
+// имён и по топикам пространств (nsKey).
 type messageRepo struct {
-	mu       sync.RWMutex
-	messages map[string][]*domain.Message
+	logs       sync.Map
+	total      atomic.Int64
+	namespaces sync.Map // пространство имён → *atomic.Int64
+	topics     sync.Map // nsKey топика → *atomic.Int64
 }
 
 func NewMessageRepository() domain.MessageRepository {
-	return &messageRepo{messages: make(map[string][]*domain.Message)}
+	return &messageRepo{}
+}
+
+// msgKey — ключ журнала очереди в logs: пространство имён, топик и очередь.
+func msgKey(ctx context.Context, topicName, queueID string) string {
+	return domain.Namespace(ctx) + "/" + topicName + "|" + queueID
 }
 
-func msgKey(topic, queueID string) string { return topic + "|" + queueID }
+// counter возвращает счётчик объёма из m, создавая его при первом обращении.
+func counter(m *sync.Map, key string) *atomic.Int64 {
+	c, ok := m.Load(key)
+	if !ok {
+		c, _ = m.LoadOrStore(key, new(atomic.Int64))
+	}
+	return c.(*atomic.Int64)
+}
 
+func (r *messageRepo) log(ctx context.Context, topicName, queueID string) *queueLog {
+	if l, ok := r.logs.Load(msgKey(ctx, topicName, queueID)); ok {
+		return l.(*queueLog)
+	}
+	return nil
+}
+
+// logOrCreate возвращает журнал очереди, создавая его при первой записи.
+func (r *messageRepo) logOrCreate(ctx context.Context, topicName, queueID string) *queueLog {
+	if l := r.log(ctx, topicName, queueID); l != nil {
+		return l
+	}
+	l, _ := r.logs.LoadOrStore(msgKey(ctx, topicName, queueID), newQueueLog())
+	return l.(*queueLog)
+}
+
+// Append сохраняет собственную копию сообщения: тело и заголовки копируются,
+// чтобы отправитель не мог изменить уже записанное сообщение. Дальше копия
+// только читается, и Read/GetByID отдают её без копирования.
 func (r *messageRepo) Append(ctx context.Context, msg *domain.Message) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	k := msgKey(msg.TopicName, msg.QueueID)
-	slice := r.messages[k]
-	if slice == nil {
-		slice = make([]*domain.Message, 0)
-	}
-	msg.Offset = int64(len(slice))
-	m := *msg
-	slice = append(slice, &m)
-	r.messages[k] = slice
+	m := ownCopy(msg)
+	l := r.lockLog(ctx, msg.TopicName, msg.QueueID)
+	l.append(m)
+	l.mu.Unlock()
+	r.account(ctx, msg.TopicName, m.Size())
+	msg.Offset = m.Offset
+	return nil
+}
+
+// AppendBatch сохраняет копии сообщений, как Append, под одной блокировкой
+// журнала очереди первого сообщения.
+func (r *messageRepo) AppendBatch(ctx context.Context, msgs []*domain.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	own := make([]*domain.Message, len(msgs))
+	var size int64
+	for i, msg := range msgs {
+		own[i] = ownCopy(msg)
+		size += own[i].Size()
+	}
+	l := r.lockLog(ctx, msgs[0].TopicName, msgs[0].QueueID)
+	for _, m := range own {
+		l.append(m)
+	}
+	l.mu.Unlock()
+	r.account(ctx, msgs[0].TopicName, size)
+	for i, msg := range msgs {
+		msg.Offset = own[i].Offset
+	}
 	return nil
 }
 
+// ownCopy копирует сообщение вместе с телом и заголовками.
+func ownCopy(msg *domain.Message) *domain.Message {
+	m := *msg
+	m.Payload = append([]byte(nil), msg.Payload...)
+	if msg.Headers != nil {
+		m.Headers = make(map[string]string, len(msg.Headers))
+		for k, v := range msg.Headers {
+			m.Headers[k] = v
+		}
+	}
+	m.DeliveryID = ""
+	return &m
+}
+
+// lockLog возвращает журнал очереди, созданный при необходимости, под его
+// блокировкой записи.
+func (r *messageRepo) lockLog(ctx context.Context, topicName, queueID string) *queueLog {
+	l := r.logOrCreate(ctx, topicName, queueID)
+	for {
+		l.mu.Lock()
+		if !l.deleted {
+			return l
+		}
+		// Очередь удалили между поиском журнала и блокировкой.
+		l.mu.Unlock()
+		l = r.logOrCreate(ctx, topicName, queueID)
+	}
+}
+
+// account изменяет счётчики объёма на delta байт.
+func (r *messageRepo) account(ctx context.Context, topicName string, delta int64) {
+	if delta == 0 {
+		return
+	}
+	r.total.Add(delta)
+	counter(&r.namespaces, domain.Namespace(ctx)).Add(delta)
+	counter(&r.topics, nsKey(ctx, topicName)).Add(delta)
+}
+
+func (r *messageRepo) Usage(ctx context.Context, topicName string) (int64, error) {
+	c, ok := r.namespaces.Load(domain.Namespace(ctx))
+	if topicName != "" {
+		c, ok = r.topics.Load(nsKey(ctx, topicName))
+	}
+	if ok {
+		return c.(*atomic.Int64).Load(), nil
+	}
+	return 0, nil
+}
+
+func (r *messageRepo) TotalUsage(ctx context.Context) (int64, error) {
+	return r.total.Load(), nil
+}
+
+// Read читает с offset; смещения до начала журнала (после очистки) пропускаются.
 func (r *messageRepo) Read(ctx context.Context, topicName, queueID string, offset, limit int) ([]*domain.Message, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	k := msgKey(topicName, queueID)
-	slice := r.messages[k]
-	if slice == nil || offset >= len(slice) {
+	l := r.log(ctx, topicName, queueID)
+	if l == nil {
+		return nil, nil
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if int64(offset) >= l.end() {
 		return nil, nil
 	}
-	end := offset + limit
-	if end > len(slice) {
-		end = len(slice)
+	from := int64(offset) - l.start
+	if from < 0 {
+		from = 0
 	}
-	out := make([]*domain.Message, 0, end-offset)
-	for i := offset; i < end; i++ {
-		m := *slice[i]
-		out = append(out, &m)
+	to := from + int64(limit)
+	if to > int64(len(l.msgs)) {
+		to = int64(len(l.msgs))
 	}
-	return out, nil
+	return append([]*domain.Message(nil), l.msgs[from:to]...), nil
 }
 
 func (r *messageRepo) GetByID(ctx context.Context, topicName, queueID, messageID string) (*domain.Message, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	slice := r.messages[msgKey(topicName, queueID)]
-	for _, m := range slice {
-		if m.ID == messageID {
-			m2 := *m
-			return &m2, nil
+	if l := r.log(ctx, topicName, queueID); l != nil {
+		l.mu.RLock()
+		defer l.mu.RUnlock()
+		if off, ok := l.byID[messageID]; ok {
+			return l.msgs[off-l.start], nil
 		}
 	}
 	return nil, domain.ErrNotFound
 }
+
+func (r *messageRepo) Offsets(ctx context.Context, topicName, queueID string) (start, end int64, err error) {
+	if l := r.log(ctx, topicName, queueID); l != nil {
+		l.mu.RLock()
+		defer l.mu.RUnlock()
+		return l.start, l.end(), nil
+	}
+	return 0, 0, nil
+}
+
+// OffsetForTime находит по индексу времени первый блок, где мог появиться
+// CreatedAt >= ts, и просматривает сообщения начиная с него.
+func (r *messageRepo) OffsetForTime(ctx context.Context, topicName, queueID string, ts time.Time) (int64, error) {
+	l := r.log(ctx, topicName, queueID)
+	if l == nil {
+		return 0, nil
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	block := sort.Search(len(l.times), func(i int) bool { return !l.times[i].Before(ts) })
+	if block == len(l.times) {
+		return l.end(), nil
+	}
+	from := (l.timeBase+int64(block))*timeIndexInterval - l.start
+	if from < 0 {
+		from = 0
+	}
+	for _, m := range l.msgs[from:] {
+		if !m.CreatedAt.Before(ts) {
+			return m.Offset, nil
+		}
+	}
+	return l.end(), nil
+}
+
+func (r *messageRepo) DeleteQueue(ctx context.Context, topicName, queueID string) error {
+	v, ok := r.logs.LoadAndDelete(msgKey(ctx, topicName, queueID))
+	if !ok {
+		return nil
+	}
+	l := v.(*queueLog)
+	l.mu.Lock()
+	l.deleted = true
+	freed := l.bytes
+	l.mu.Unlock()
+	r.account(ctx, topicName, -freed)
+	return nil
+}
+
+// truncate очищает журнал до before и уменьшает счётчики объёма. Удалённый
+// журнал уже вычтен из счётчиков в DeleteQueue.
+func (r *messageRepo) truncate(ctx context.Context, topicName string, l *queueLog, before int64) int64 {
+	l.mu.Lock()
+	freed := l.truncate(before)
+	start, deleted := l.start, l.deleted
+	l.mu.Unlock()
+	if !deleted {
+		r.account(ctx, topicName, -freed)
+	}
+	return start
+}
+
+func (r *messageRepo) Purge(ctx context.Context, topicName, queueID string) (int64, error) {
+	l := r.log(ctx, topicName, queueID)
+	if l == nil {
+		return 0, nil
+	}
+	return r.truncate(ctx, topicName, l, math.MaxInt64), nil
+}
+
+func (r *messageRepo) Truncate(ctx context.Context, topicName, queueID string, before int64) (int64, error) {
+	l := r.log(ctx, topicName, queueID)
+	if l == nil {
+		return 0, nil
+	}
+	return r.truncate(ctx, topicName, l, before), nil
+}
@@ -2,6 +2,8 @@ package memory
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -76,3 +78,226 @@ func TestMessageRepository_GetByID(t *testing.T) {
 		t.Errorf("want ErrNotFound, got %v", err)
 	}
 }
+
+func TestMessageRepository_DeleteQueue(t *testing.T) {
+	ctx := context.Background()
+	r := NewMessageRepository()
+	_ = r.Append(ctx, &domain.Message{TopicName: "t", QueueID: "0", CreatedAt: time.Now()})
+	_ = r.Append(ctx, &domain.Message{TopicName: "t", QueueID: "1", CreatedAt: time.Now()})
+
+	if err := r.DeleteQueue(ctx, "t", "0"); err != nil {
+		t.Fatalf("DeleteQueue: %v", err)
+	}
+	if msgs, _ := r.Read(ctx, "t", "0", 0, 10); len(msgs) != 0 {
+		t.Errorf("queue 0: want empty, got %d", len(msgs))
+	}
+	if msgs, _ := r.Read(ctx, "t", "1", 0, 10); len(msgs) != 1 {
+		t.Errorf("queue 1 must be untouched, got %d", len(msgs))
+	}
+}
+
+func TestMessageRepository_Purge(t *testing.T) {
+	ctx := context.Background()
+	r := NewMessageRepository()
+	for i := 0; i < 3; i++ {
+		_ = r.Append(ctx, &domain.Message{TopicName: "t", QueueID: "0", CreatedAt: time.Now()})
+	}
+
+	start, err := r.Purge(ctx, "t", "0")
+	if err != nil || start != 3 {
+		t.Fatalf("Purge: start=%d err=%v", start, err)
+	}
+	if msgs, _ := r.Read(ctx, "t", "0", 0, 10); len(msgs) != 0 {
+		t.Errorf("want empty after purge, got %d", len(msgs))
+	}
+
+	msg := &domain.Message{TopicName: "t", QueueID: "0", CreatedAt: time.Now()}
+	_ = r.Append(ctx, msg)
+	if msg.Offset != 3 {
+		t.Errorf("offsets must continue after purge, got %d", msg.Offset)
+	}
+	// Чтение со смещения до начала журнала начинается с первого хранимого сообщения.
+	msgs, _ := r.Read(ctx, "t", "0", 1, 10)
+	if len(msgs) != 1 || msgs[0].Offset != 3 {
+		t.Errorf("read below start: %+v", msgs)
+	}
+	if start, end, _ := r.Offsets(ctx, "t", "0"); start != 3 || end != 4 {
+		t.Errorf("offsets: start=%d end=%d", start, end)
+	}
+}
+
+func TestMessageRepository_Truncate_indexes(t *testing.T) {
+	ctx := context.Background()
+	r := NewMessageRepository()
+	base := time.Now()
+	n := 3*timeIndexInterval + 10
+	for i := 0; i < n; i++ {
+		_ = r.Append(ctx, &domain.Message{ID: fmt.Sprint("m", i), TopicName: "t", QueueID: "0", CreatedAt: base.Add(time.Duration(i) * time.Second)})
+	}
+
+	if m, err := r.GetByID(ctx, "t", "0", "m2000"); err != nil || m.Offset != 2000 {
+		t.Fatalf("GetByID: %v %+v", err, m)
+	}
+	if off, _ := r.OffsetForTime(ctx, "t", "0", base.Add(1500*time.Second)); off != 1500 {
+		t.Errorf("OffsetForTime: want 1500, got %d", off)
+	}
+
+	start, err := r.Truncate(ctx, "t", "0", 2000)
+	if err != nil || start != 2000 {
+		t.Fatalf("Truncate: start=%d err=%v", start, err)
+	}
+	if _, err := r.GetByID(ctx, "t", "0", "m1999"); err != domain.ErrNotFound {
+		t.Errorf("truncated message must not be found, got %v", err)
+	}
+	if m, err := r.GetByID(ctx, "t", "0", "m2000"); err != nil || m.Offset != 2000 {
+		t.Errorf("GetByID after truncate: %v %+v", err, m)
+	}
+	if off, _ := r.OffsetForTime(ctx, "t", "0", base); off != 2000 {
+		t.Errorf("OffsetForTime before start: want 2000, got %d", off)
+	}
+	if off, _ := r.OffsetForTime(ctx, "t", "0", base.Add(3000*time.Second)); off != 3000 {
+		t.Errorf("OffsetForTime: want 3000, got %d", off)
+	}
+	if off, _ := r.OffsetForTime(ctx, "t", "0", base.Add(time.Hour*24)); off != int64(n) {
+		t.Errorf("OffsetForTime after last: want %d, got %d", n, off)
+	}
+	if start, _ := r.Truncate(ctx, "t", "0", 10); start != 2000 {
+		t.Errorf("truncate below start must be a no-op, got start %d", start)
+	}
+}
+
+func TestMessageRepository_OffsetForTime_unordered(t *testing.T) {
+	ctx := context.Background()
+	r := NewMessageRepository()
+	base := time.Now()
+	// Публикации из разных горутин могут попасть в журнал не по порядку CreatedAt.
+	for _, d := range []time.Duration{0, 5, 3, 10, 7} {
+		_ = r.Append(ctx, &domain.Message{TopicName: "t", QueueID: "0", CreatedAt: base.Add(d * time.Second)})
+	}
+	if off, _ := r.OffsetForTime(ctx, "t", "0", base.Add(4*time.Second)); off != 1 {
+		t.Errorf("want 1, got %d", off)
+	}
+	if off, _ := r.OffsetForTime(ctx, "t", "0", base.Add(8*time.Second)); off != 3 {
+		t.Errorf("want 3, got %d", off)
+	}
+}
+
+func TestMessageRepository_Append_concurrentQueues(t *testing.T) {
+	ctx := context.Background()
+	r := NewMessageRepository()
+	const queues, perQueue = 4, 500
+	var wg sync.WaitGroup
+	for q := 0; q < queues; q++ {
+		for w := 0; w < 2; w++ {
+			wg.Add(1)
+			go func(queueID string) {
+				defer wg.Done()
+				for i := 0; i < perQueue/2; i++ {
+					_ = r.Append(ctx, &domain.Message{ID: fmt.Sprint(queueID, "-", w, "-", i), TopicName: "t", QueueID: queueID, CreatedAt: time.Now()})
+				}
+			}(fmt.Sprint(q))
+		}
+	}
+	wg.Wait()
+
+	for q := 0; q < queues; q++ {
+		msgs, _ := r.Read(ctx, "t", fmt.Sprint(q), 0, 2*perQueue)
+		if len(msgs) != perQueue {
+			t.Fatalf("queue %d: want %d messages, got %d", q, perQueue, len(msgs))
+		}
+		for i, m := range msgs {
+			if m.Offset != int64(i) {
+				t.Fatalf("queue %d: offset %d at position %d", q, m.Offset, i)
+			}
+		}
+	}
+}
+
+func TestMessageRepository_Append_ownsBuffers(t *testing.T) {
+	ctx := context.Background()
+	r := NewMessageRepository()
+	payload := []byte("hello")
+	headers := map[string]string{"k": "v"}
+	_ = r.Append(ctx, &domain.Message{ID: "m1", TopicName: "t", QueueID: "0", Payload: payload, Headers: headers, CreatedAt: time.Now()})
+
+	// Отправитель может переиспользовать свои буферы после Append.
+	payload[0] = 'J'
+	headers["k"] = "changed"
+
+	msgs, _ := r.Read(ctx, "t", "0", 0, 1)
+	if string(msgs[0].Payload) != "hello" || msgs[0].Headers["k"] != "v" {
+		t.Errorf("stored message changed with sender buffers: %q %v", msgs[0].Payload, msgs[0].Headers)
+	}
+	// Читатели получают одно и то же хранимое сообщение без копирования.
+	if m, _ := r.GetByID(ctx, "t", "0", "m1"); m != msgs[0] {
+		t.Error("GetByID and Read must share the stored message")
+	}
+}
+
+func TestMessageRepository_Usage(t *testing.T) {
+	ctx := context.Background()
+	r := NewMessageRepository()
+	msg := func(topic, queue, payload string) *domain.Message {
+		return &domain.Message{ID: "id", TopicName: topic, QueueID: queue, Payload: []byte(payload), Headers: map[string]string{"k": "v"}}
+	}
+	a, b, c := msg("t", "0", "aaaa"), msg("t", "1", "bb"), msg("u", "0", "c")
+	for _, m := range []*domain.Message{a, b, c} {
+		_ = r.Append(ctx, m)
+	}
+	usage := func(topic string) int64 {
+		n, _ := r.Usage(ctx, topic)
+		return n
+	}
+	if got, want := usage("t"), a.Size()+b.Size(); got != want {
+		t.Errorf("Usage(t) want %d, got %d", want, got)
+	}
+	if got, want := usage(""), a.Size()+b.Size()+c.Size(); got != want {
+		t.Errorf("Usage() want %d, got %d", want, got)
+	}
+
+	_, _ = r.Truncate(ctx, "t", "0", 1)
+	if got := usage("t"); got != b.Size() {
+		t.Errorf("after Truncate want %d, got %d", b.Size(), got)
+	}
+	_ = r.DeleteQueue(ctx, "t", "1")
+	_, _ = r.Purge(ctx, "u", "0")
+	if usage("t") != 0 || usage("u") != 0 || usage("") != 0 {
+		t.Errorf("all messages removed, usage t=%d u=%d total=%d", usage("t"), usage("u"), usage(""))
+	}
+	if usage("missing") != 0 {
+		t.Error("unknown topic should use nothing")
+	}
+}
+
+func TestMessageRepository_namespaces(t *testing.T) {
+	shop := domain.WithNamespace(context.Background(), "shop")
+	blog := domain.WithNamespace(context.Background(), "blog")
+	r := NewMessageRepository()
+	a := &domain.Message{ID: "a", TopicName: "t", QueueID: "0", Payload: []byte("shop")}
+	b := &domain.Message{ID: "b", TopicName: "t", QueueID: "0", Payload: []byte("blog!")}
+	_ = r.Append(shop, a)
+	_ = r.Append(blog, b)
+	if a.Offset != 0 || b.Offset != 0 {
+		t.Fatalf("logs of one topic in two namespaces share offsets: %d, %d", a.Offset, b.Offset)
+	}
+	got, _ := r.Read(shop, "t", "0", 0, 10)
+	if len(got) != 1 || got[0].ID != "a" {
+		t.Fatalf("shop reads %v", got)
+	}
+	if _, err := r.GetByID(shop, "t", "0", "b"); err == nil {
+		t.Error("message of blog visible in shop")
+	}
+
+	usage := func(ctx context.Context) int64 {
+		n, _ := r.Usage(ctx, "")
+		return n
+	}
+	total, _ := r.TotalUsage(shop)
+	if usage(shop) != a.Size() || usage(blog) != b.Size() || total != a.Size()+b.Size() {
+		t.Errorf("usage shop=%d blog=%d total=%d", usage(shop), usage(blog), total)
+	}
+	_ = r.DeleteQueue(shop, "t", "0")
+	if got, _ := r.Read(blog, "t", "0", 0, 10); len(got) != 1 {
+		t.Error("DeleteQueue in shop removed blog messages")
+	}
+}
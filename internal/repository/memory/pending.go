@@ -1,80 +1,358 @@
 package memory
 
 import (
+	"container/heap"
 	"context"
-	"errors"
+	"fmt"
 	"sync"
 	"time"
 
 	"queue-service/internal/domain"
 )
 
-var errDeliveryNotFound = errors.New("delivery not found")
+var errDeliveryNotFound = fmt.Errorf("delivery %w", domain.ErrNotFound)
 
+// pendingEntry — неподтверждённая доставка и её позиции в кучах подписки.
+// Доставка либо ждёт подтверждения (deadlineIdx >= 0), либо уже просрочена
+// и стоит в очереди ready на повторную доставку.
+type pendingEntry struct {
+	pd          *domain.PendingDelivery
+	deadlineIdx int
+	offsetIdx   int
+	ready       bool
+	// readyGen отличает актуальную запись в ready от устаревших: доставка
+	// может попасть туда повторно после Touch и нового истечения срока.
+	readyGen uint64
+}
+
+type readyRef struct {
+	e   *pendingEntry
+	gen uint64
+}
+
+func (r readyRef) live() bool { return r.e.ready && r.e.readyGen == r.gen }
+
+// subPending — неподтверждённые доставки одной подписки.
+type subPending struct {
+	id        string
+	entries   map[string]*pendingEntry
+	deadlines *indexedHeap[*pendingEntry] // по ExpiresAt, ближайший срок сверху
+	offsets   *indexedHeap[*pendingEntry] // по смещению, наименьшее сверху
+	// high — смещение, следующее за наибольшим выданным, пока у подписки
+	// есть неподтверждённые доставки.
+	high int64
+	// ready — просроченные доставки в порядке истечения срока. Подтверждённые
+	// и продлённые удаляются лениво: их ссылки перестают быть live.
+	ready []readyRef
+	// idx — позиция в pendingRepo.subs; -1, если ждущих доставок нет.
+	idx int
+}
+
+func newSubPending(id string) *subPending {
+	return &subPending{
+		id:      id,
+		entries: make(map[string]*pendingEntry),
+		deadlines: &indexedHeap[*pendingEntry]{
+			less:     func(a, b *pendingEntry) bool { return a.pd.ExpiresAt.Before(b.pd.ExpiresAt) },
+			setIndex: func(e *pendingEntry, i int) { e.deadlineIdx = i },
+		},
+		offsets: &indexedHeap[*pendingEntry]{
+			less:     func(a, b *pendingEntry) bool { return a.pd.Message.Offset < b.pd.Message.Offset },
+			setIndex: func(e *pendingEntry, i int) { e.offsetIdx = i },
+		},
+		idx: -1,
+	}
+}
+
+func (sp *subPending) earliest() time.Time { return sp.deadlines.items[0].pd.ExpiresAt }
+
+// trimReady отбрасывает устаревшие ссылки в начале очереди ready.
+func (sp *subPending) trimReady() {
+	for len(sp.ready) > 0 && !sp.ready[0].live() {
+		sp.ready[0] = readyRef{}
+		sp.ready = sp.ready[1:]
+	}
+	if len(sp.ready) == 0 {
+		sp.ready = nil
+	}
+}
+
+// pendingRepo хранит доставки каждой подписки в куче по сроку подтверждения,
+// а подписки — в общей куче по ближайшему сроку. Поиск просроченных доставок
+// не перебирает все неподтверждённые, а NextDeadline отвечает за O(1).
 type pendingRepo struct {
 	mu    sync.RWMutex
-	bySub map[string]map[string]*domain.PendingDelivery
+	bySub map[string]*subPending
+	subs  *indexedHeap[*subPending]
 }
 
 func NewPendingDeliveryRepository() domain.PendingDeliveryRepository {
-	return &pendingRepo{bySub: make(map[string]map[string]*domain.PendingDelivery)}
+	return &pendingRepo{
+		bySub: make(map[string]*subPending),
+		subs: &indexedHeap[*subPending]{
+			less:     func(a, b *subPending) bool { return a.earliest().Before(b.earliest()) },
+			setIndex: func(sp *subPending, i int) { sp.idx = i },
+		},
+	}
+}
+
+// fix восстанавливает положение подписки в общей куче после изменения её
+// сроков и удаляет подписку без доставок.
+func (r *pendingRepo) fix(sp *subPending) {
+	switch {
+	case sp.deadlines.Len() == 0:
+		if sp.idx >= 0 {
+			heap.Remove(r.subs, sp.idx)
+		}
+	case sp.idx < 0:
+		heap.Push(r.subs, sp)
+	default:
+		heap.Fix(r.subs, sp.idx)
+	}
+	if len(sp.entries) == 0 {
+		delete(r.bySub, sp.id)
+	}
+}
+
+// remove удаляет доставку из всех структур подписки; fix вызывает вызывающий.
+func (sp *subPending) remove(e *pendingEntry) {
+	if e.ready {
+		e.ready = false
+		sp.trimReady()
+	} else {
+		heap.Remove(sp.deadlines, e.deadlineIdx)
+	}
+	heap.Remove(sp.offsets, e.offsetIdx)
+	delete(sp.entries, e.pd.DeliveryID)
 }
 
 func (r *pendingRepo) Add(ctx context.Context, subID string, pd *domain.PendingDelivery) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if r.bySub[subID] == nil {
-		r.bySub[subID] = make(map[string]*domain.PendingDelivery)
+	sp := r.bySub[subID]
+	if sp == nil {
+		sp = newSubPending(subID)
+		r.bySub[subID] = sp
+	}
+	if old, ok := sp.entries[pd.DeliveryID]; ok {
+		sp.remove(old)
 	}
 	p := *pd
-	r.bySub[subID][pd.DeliveryID] = &p
+	e := &pendingEntry{pd: &p}
+	sp.entries[p.DeliveryID] = e
+	heap.Push(sp.deadlines, e)
+	heap.Push(sp.offsets, e)
+	sp.high = max(sp.high, p.Message.Offset+1)
+	r.fix(sp)
 	return nil
 }
 
-func (r *pendingRepo) Ack(ctx context.Context, subID, deliveryID string) (ackedOffset int64, err error) {
+// Ack удаляет доставку и возвращает смещение, до которого подтверждены все
+// выданные сообщения: наименьшее среди оставшихся неподтверждёнными или,
+// если таких нет, следующее за наибольшим выданным.
+func (r *pendingRepo) Ack(ctx context.Context, subID, deliveryID string) (committed int64, err error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	m, ok := r.bySub[subID]
+	sp, ok := r.bySub[subID]
 	if !ok {
 		return 0, errDeliveryNotFound
 	}
-	pd, ok := m[deliveryID]
+	e, ok := sp.entries[deliveryID]
 	if !ok {
 		return 0, errDeliveryNotFound
 	}
-	offset := pd.Message.Offset
-	delete(m, deliveryID)
-	if len(m) == 0 {
-		delete(r.bySub, subID)
+	sp.remove(e)
+	r.fix(sp)
+	if sp.offsets.Len() > 0 {
+		return sp.offsets.items[0].pd.Message.Offset, nil
 	}
-	return offset, nil
+	return sp.high, nil
 }
 
-func (r *pendingRepo) Expired(ctx context.Context, subID string, before time.Time) ([]*domain.PendingDelivery, error) {
+// Expired сначала отдаёт доставки, уже перенесённые ExpireDue в очередь
+// повторной доставки, затем ждущие со сроком до before — в порядке
+// истечения срока. Просматривается O(limit·log limit) элементов кучи,
+// а не все неподтверждённые доставки.
+func (r *pendingRepo) Expired(ctx context.Context, subID string, before time.Time, limit int) ([]*domain.PendingDelivery, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	m, ok := r.bySub[subID]
-	if !ok {
+	sp, ok := r.bySub[subID]
+	if !ok || limit <= 0 {
 		return nil, nil
 	}
 	var out []*domain.PendingDelivery
-	for _, pd := range m {
-		if pd.ExpiresAt.Before(before) {
-			p2 := *pd
-			out = append(out, &p2)
+	for _, ref := range sp.ready {
+		if len(out) == limit {
+			return out, nil
+		}
+		if ref.live() {
+			p := *ref.e.pd
+			out = append(out, &p)
+		}
+	}
+
+	// Куча упорядочена только вдоль ветвей, поэтому k ближайших сроков
+	// ищем обходом от корня с кандидатами во вспомогательной куче индексов.
+	items := sp.deadlines.items
+	frontier := &indexedHeap[int]{
+		less:     func(a, b int) bool { return items[a].pd.ExpiresAt.Before(items[b].pd.ExpiresAt) },
+		setIndex: func(int, int) {},
+	}
+	if len(items) > 0 {
+		heap.Push(frontier, 0)
+	}
+	for frontier.Len() > 0 && len(out) < limit {
+		i := heap.Pop(frontier).(int)
+		if !items[i].pd.ExpiresAt.Before(before) {
+			break
+		}
+		p := *items[i].pd
+		out = append(out, &p)
+		for _, c := range []int{2*i + 1, 2*i + 2} {
+			if c < len(items) {
+				heap.Push(frontier, c)
+			}
 		}
 	}
 	return out, nil
 }
 
+// ExpireDue переносит доставки со сроком до before в очередь повторной
+// доставки и возвращает подписки, у которых такие появились.
+func (r *pendingRepo) ExpireDue(ctx context.Context, before time.Time) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ids []string
+	for r.subs.Len() > 0 {
+		sp := r.subs.items[0]
+		if !sp.earliest().Before(before) {
+			break
+		}
+		for sp.deadlines.Len() > 0 && sp.earliest().Before(before) {
+			e := heap.Pop(sp.deadlines).(*pendingEntry)
+			e.ready = true
+			e.readyGen++
+			sp.ready = append(sp.ready, readyRef{e: e, gen: e.readyGen})
+		}
+		ids = append(ids, sp.id)
+		r.fix(sp)
+	}
+	return ids, nil
+}
+
+func (r *pendingRepo) NextDeadline(ctx context.Context) (time.Time, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.subs.Len() == 0 {
+		return time.Time{}, false, nil
+	}
+	return r.subs.items[0].earliest(), true, nil
+}
+
 func (r *pendingRepo) Remove(ctx context.Context, subID, deliveryID string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if m, ok := r.bySub[subID]; ok {
-		delete(m, deliveryID)
-		if len(m) == 0 {
-			delete(r.bySub, subID)
+	if sp, ok := r.bySub[subID]; ok {
+		if e, ok := sp.entries[deliveryID]; ok {
+			sp.remove(e)
+			r.fix(sp)
 		}
 	}
 	return nil
 }
+
+// Touch возвращает доставку из очереди повторной доставки в ожидание
+// подтверждения с новым сроком.
+func (r *pendingRepo) Touch(ctx context.Context, subID, deliveryID string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sp, ok := r.bySub[subID]
+	if !ok {
+		return errDeliveryNotFound
+	}
+	e, ok := sp.entries[deliveryID]
+	if !ok {
+		return errDeliveryNotFound
+	}
+	e.pd.ExpiresAt = expiresAt
+	if e.ready {
+		e.ready = false
+		sp.trimReady()
+		heap.Push(sp.deadlines, e)
+	} else {
+		heap.Fix(sp.deadlines, e.deadlineIdx)
+	}
+	r.fix(sp)
+	return nil
+}
+
+func (r *pendingRepo) LastOffset(ctx context.Context, subID string) (int64, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sp, ok := r.bySub[subID]
+	if !ok {
+		return 0, false, nil
+	}
+	return sp.high - 1, true, nil
+}
+
+func (r *pendingRepo) RemoveAll(ctx context.Context, subID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if sp, ok := r.bySub[subID]; ok {
+		if sp.idx >= 0 {
+			heap.Remove(r.subs, sp.idx)
+		}
+		delete(r.bySub, subID)
+	}
+	return nil
+}
+
+func (r *pendingRepo) Stats(ctx context.Context, subID string) (int, time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sp, ok := r.bySub[subID]
+	if !ok {
+		return 0, time.Time{}, nil
+	}
+	var oldest time.Time
+	for _, e := range sp.entries {
+		if oldest.IsZero() || e.pd.DeliveredAt.Before(oldest) {
+			oldest = e.pd.DeliveredAt
+		}
+	}
+	return len(sp.entries), oldest, nil
+}
+
+// indexedHeap — двоичная куча для container/heap, сообщающая элементам их
+// позиции через setIndex, чтобы heap.Fix и heap.Remove работали за O(log n).
+type indexedHeap[T any] struct {
+	items    []T
+	less     func(a, b T) bool
+	setIndex func(T, int)
+}
+
+func (h *indexedHeap[T]) Len() int           { return len(h.items) }
+func (h *indexedHeap[T]) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+
+func (h *indexedHeap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.setIndex(h.items[i], i)
+	h.setIndex(h.items[j], j)
+}
+
+func (h *indexedHeap[T]) Push(x any) {
+	v := x.(T)
+	h.setIndex(v, len(h.items))
+	h.items = append(h.items, v)
+}
+
+func (h *indexedHeap[T]) Pop() any {
+	n := len(h.items) - 1
+	v := h.items[n]
+	var zero T
+	h.items[n] = zero
+	h.items = h.items[:n]
+	h.setIndex(v, -1)
+	return v
+}
@@ -2,6 +2,8 @@ package memory
 
 import (
 	"context"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -26,8 +28,8 @@ func TestPendingDeliveryRepository_Add_Ack_Expired(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Ack: %v", err)
 	}
-	if offset != 0 {
-		t.Errorf("acked offset want 0, got %d", offset)
+	if offset != 1 {
+		t.Errorf("committed offset want 1, got %d", offset)
 	}
 
 	_, err = r.Ack(ctx, "sub-1", "del-1")
@@ -56,7 +58,7 @@ func TestPendingDeliveryRepository_Expired(t *testing.T) {
 		t.Error(err)
 	}
 
-	expired, _ := r.Expired(ctx, "sub-1", now)
+	expired, _ := r.Expired(ctx, "sub-1", now, 10)
 	if len(expired) != 1 {
 		t.Fatalf("want 1 expired, got %d", len(expired))
 	}
@@ -64,3 +66,154 @@ func TestPendingDeliveryRepository_Expired(t *testing.T) {
 		t.Errorf("got %s", expired[0].DeliveryID)
 	}
 }
+
+func TestPendingDeliveryRepository_Touch_LastOffset(t *testing.T) {
+	ctx := context.Background()
+	r := NewPendingDeliveryRepository()
+	now := time.Now()
+
+	if _, ok, _ := r.LastOffset(ctx, "sub-1"); ok {
+		t.Error("empty subscription should report no pending offset")
+	}
+	for i, id := range []string{"del-a", "del-b"} {
+		_ = r.Add(ctx, "sub-1", &domain.PendingDelivery{
+			Message:    domain.Message{Offset: int64(i + 3)},
+			ExpiresAt:  now.Add(time.Hour),
+			DeliveryID: id,
+		})
+	}
+	if last, ok, _ := r.LastOffset(ctx, "sub-1"); !ok || last != 4 {
+		t.Errorf("LastOffset want 4, got %d (ok=%v)", last, ok)
+	}
+
+	if err := r.Touch(ctx, "sub-1", "del-a", now.Add(-time.Second)); err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+	expired, _ := r.Expired(ctx, "sub-1", now, 10)
+	if len(expired) != 1 || expired[0].DeliveryID != "del-a" {
+		t.Errorf("touched delivery should expire, got %+v", expired)
+	}
+	if err := r.Touch(ctx, "sub-1", "missing", now); err == nil {
+		t.Error("Touch of unknown delivery should error")
+	}
+}
+
+func TestPendingDeliveryRepository_RemoveAll(t *testing.T) {
+	ctx := context.Background()
+	r := NewPendingDeliveryRepository()
+	_ = r.Add(ctx, "sub-1", &domain.PendingDelivery{DeliveryID: "d1", ExpiresAt: time.Now()})
+	_ = r.Add(ctx, "sub-1", &domain.PendingDelivery{DeliveryID: "d2", ExpiresAt: time.Now()})
+	_ = r.Add(ctx, "sub-2", &domain.PendingDelivery{DeliveryID: "d3", ExpiresAt: time.Now()})
+
+	if err := r.RemoveAll(ctx, "sub-1"); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+	if _, err := r.Ack(ctx, "sub-1", "d1"); err == nil {
+		t.Error("sub-1 deliveries should be gone")
+	}
+	if _, err := r.Ack(ctx, "sub-2", "d3"); err != nil {
+		t.Errorf("sub-2 delivery must remain: %v", err)
+	}
+}
+
+func TestPendingDeliveryRepository_Stats(t *testing.T) {
+	ctx := context.Background()
+	r := NewPendingDeliveryRepository()
+	first := time.Now().Add(-time.Minute)
+	_ = r.Add(ctx, "sub-1", &domain.PendingDelivery{DeliveryID: "d1", DeliveredAt: time.Now()})
+	_ = r.Add(ctx, "sub-1", &domain.PendingDelivery{DeliveryID: "d2", DeliveredAt: first})
+
+	count, oldest, err := r.Stats(ctx, "sub-1")
+	if err != nil || count != 2 || !oldest.Equal(first) {
+		t.Errorf("Stats: count=%d oldest=%v err=%v", count, oldest, err)
+	}
+	count, oldest, _ = r.Stats(ctx, "sub-2")
+	if count != 0 || !oldest.IsZero() {
+		t.Errorf("empty Stats: count=%d oldest=%v", count, oldest)
+	}
+}
+
+func TestPendingDeliveryRepository_Expired_orderAndLimit(t *testing.T) {
+	ctx := context.Background()
+	r := NewPendingDeliveryRepository()
+	now := time.Now()
+	// Сроки вперемешку: Expired должен отдать самые давно просроченные.
+	for i, ago := range []int{3, 7, 1, 9, 5, -1} {
+		_ = r.Add(ctx, "sub-1", &domain.PendingDelivery{
+			Message:    domain.Message{Offset: int64(i)},
+			ExpiresAt:  now.Add(-time.Duration(ago) * time.Second),
+			DeliveryID: "d" + strconv.Itoa(ago),
+		})
+	}
+	expired, _ := r.Expired(ctx, "sub-1", now, 3)
+	var got []string
+	for _, pd := range expired {
+		got = append(got, pd.DeliveryID)
+	}
+	if want := "d9 d7 d5"; strings.Join(got, " ") != want {
+		t.Errorf("Expired: want %s, got %v", want, got)
+	}
+	if all, _ := r.Expired(ctx, "sub-1", now, 100); len(all) != 5 {
+		t.Errorf("future delivery must not be expired, got %d", len(all))
+	}
+}
+
+func TestPendingDeliveryRepository_ExpireDue_NextDeadline(t *testing.T) {
+	ctx := context.Background()
+	r := NewPendingDeliveryRepository()
+	now := time.Now()
+
+	if _, ok, _ := r.NextDeadline(ctx); ok {
+		t.Error("empty repository should have no deadline")
+	}
+	_ = r.Add(ctx, "sub-1", &domain.PendingDelivery{DeliveryID: "d1", ExpiresAt: now.Add(-time.Second)})
+	_ = r.Add(ctx, "sub-1", &domain.PendingDelivery{DeliveryID: "d2", ExpiresAt: now.Add(time.Hour)})
+	_ = r.Add(ctx, "sub-2", &domain.PendingDelivery{DeliveryID: "d3", ExpiresAt: now.Add(time.Minute)})
+
+	if next, ok, _ := r.NextDeadline(ctx); !ok || !next.Equal(now.Add(-time.Second)) {
+		t.Errorf("NextDeadline: %v (ok=%v)", next, ok)
+	}
+	ids, _ := r.ExpireDue(ctx, now)
+	if len(ids) != 1 || ids[0] != "sub-1" {
+		t.Fatalf("ExpireDue: %v", ids)
+	}
+	if next, ok, _ := r.NextDeadline(ctx); !ok || !next.Equal(now.Add(time.Minute)) {
+		t.Errorf("NextDeadline after ExpireDue: %v (ok=%v)", next, ok)
+	}
+	if ids, _ := r.ExpireDue(ctx, now); len(ids) != 0 {
+		t.Errorf("repeated ExpireDue should report nothing, got %v", ids)
+	}
+
+	// Просроченная доставка по-прежнему отдаётся Expired, пока её не продлят.
+	expired, _ := r.Expired(ctx, "sub-1", now, 10)
+	if len(expired) != 1 || expired[0].DeliveryID != "d1" {
+		t.Fatalf("Expired after ExpireDue: %+v", expired)
+	}
+	if err := r.Touch(ctx, "sub-1", "d1", now.Add(time.Second)); err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+	if expired, _ := r.Expired(ctx, "sub-1", now, 10); len(expired) != 0 {
+		t.Errorf("touched delivery must wait again, got %+v", expired)
+	}
+	if next, _, _ := r.NextDeadline(ctx); !next.Equal(now.Add(time.Second)) {
+		t.Errorf("NextDeadline after Touch: %v", next)
+	}
+
+	// Повторное истечение не должно дублировать доставку.
+	_, _ = r.ExpireDue(ctx, now.Add(2*time.Second))
+	if expired, _ := r.Expired(ctx, "sub-1", now.Add(2*time.Second), 10); len(expired) != 1 {
+		t.Errorf("want 1 expired after second ExpireDue, got %d", len(expired))
+	}
+	if _, err := r.Ack(ctx, "sub-1", "d1"); err != nil {
+		t.Fatalf("Ack of expired delivery: %v", err)
+	}
+	if expired, _ := r.Expired(ctx, "sub-1", now.Add(2*time.Second), 10); len(expired) != 0 {
+		t.Errorf("acked delivery must not be expired, got %+v", expired)
+	}
+
+	_ = r.RemoveAll(ctx, "sub-2")
+	_, _ = r.Ack(ctx, "sub-1", "d2")
+	if _, ok, _ := r.NextDeadline(ctx); ok {
+		t.Error("no deliveries left, NextDeadline should report none")
+	}
+}
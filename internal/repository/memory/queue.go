@@ -7,6 +7,8 @@ import (
 	"queue-service/internal/domain"
 )
 
+// queueRepo хранит очереди по топикам; ключ топика включает пространство
+// имён (nsKey).
 type queueRepo struct {
 	mu     sync.RWMutex
 	queues map[string]map[string]*domain.Queue
@@ -16,24 +18,32 @@ func NewQueueRepository() domain.QueueRepository {
 	return &queueRepo{queues: make(map[string]map[string]*domain.Queue)}
 }
 
+// nsKey — имя топика, уникальное во всём брокере. Имена пространств не
+// содержат "/", так что ключи разных пространств не совпадают.
+func nsKey(ctx context.Context, topic string) string {
+	return domain.Namespace(ctx) + "/" + topic
+}
+
 func (r *queueRepo) Create(ctx context.Context, queue *domain.Queue) error {
+	k := nsKey(ctx, queue.TopicName)
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if r.queues[queue.TopicName] == nil {
-		r.queues[queue.TopicName] = make(map[string]*domain.Queue)
+	if r.queues[k] == nil {
+		r.queues[k] = make(map[string]*domain.Queue)
 	}
-	if _, exists := r.queues[queue.TopicName][queue.QueueID]; exists {
+	if _, exists := r.queues[k][queue.QueueID]; exists {
 		return domain.ErrExists
 	}
 	q := *queue
-	r.queues[queue.TopicName][queue.QueueID] = &q
+	q.Namespace = domain.Namespace(ctx)
+	r.queues[k][queue.QueueID] = &q
 	return nil
 }
 
 func (r *queueRepo) Get(ctx context.Context, topicName, queueID string) (*domain.Queue, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	if m, ok := r.queues[topicName]; ok {
+	if m, ok := r.queues[nsKey(ctx, topicName)]; ok {
 		if q, ok := m[queueID]; ok {
 			q2 := *q
 			return &q2, nil
@@ -45,7 +55,7 @@ func (r *queueRepo) Get(ctx context.Context, topicName, queueID string) (*domain
 func (r *queueRepo) ListByTopic(ctx context.Context, topicName string) ([]*domain.Queue, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	m, ok := r.queues[topicName]
+	m, ok := r.queues[nsKey(ctx, topicName)]
 	if !ok {
 		return nil, nil
 	}
@@ -56,3 +66,16 @@ func (r *queueRepo) ListByTopic(ctx context.Context, topicName string) ([]*domai
 	}
 	return out, nil
 }
+
+func (r *queueRepo) Delete(ctx context.Context, topicName, queueID string) error {
+	k := nsKey(ctx, topicName)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m, ok := r.queues[k]; ok {
+		delete(m, queueID)
+		if len(m) == 0 {
+			delete(r.queues, k)
+		}
+	}
+	return nil
+}
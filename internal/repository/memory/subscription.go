@@ -3,10 +3,14 @@ package memory
 import (
 	"context"
 	"sync"
+	"time"
 
 	"queue-service/internal/domain"
 )
 
+// subscriptionRepo хранит подписки по ID и по паре топик–группа (ключ tgKey
+// с пространством имён). Подписка другого пространства для операций по ID
+// не видна.
 type subscriptionRepo struct {
 	mu   sync.RWMutex
 	byID map[string]*domain.Subscription
@@ -20,21 +24,31 @@ func NewSubscriptionRepository() domain.SubscriptionRepository {
 	}
 }
 
-func tgKey(topic, group string) string { return topic + "|" + group }
+func tgKey(ns, topic, group string) string { return ns + "/" + topic + "|" + group }
+
+// get возвращает подписку id пространства имён ctx. Вызывается под r.mu.
+func (r *subscriptionRepo) get(ctx context.Context, id string) (*domain.Subscription, bool) {
+	s, ok := r.byID[id]
+	if !ok || s.Namespace != domain.Namespace(ctx) {
+		return nil, false
+	}
+	return s, true
+}
 
 func (r *subscriptionRepo) Create(ctx context.Context, sub *domain.Subscription) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	s := *sub
+	s.Namespace = domain.Namespace(ctx)
 	r.byID[sub.ID] = &s
-	r.byTg[tgKey(sub.TopicName, sub.ConsumerGroup)] = &s
+	r.byTg[tgKey(s.Namespace, sub.TopicName, sub.ConsumerGroup)] = &s
 	return nil
 }
 
 func (r *subscriptionRepo) Get(ctx context.Context, id string) (*domain.Subscription, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	s, ok := r.byID[id]
+	s, ok := r.get(ctx, id)
 	if !ok {
 		return nil, domain.ErrNotFound
 	}
@@ -45,7 +59,7 @@ func (r *subscriptionRepo) Get(ctx context.Context, id string) (*domain.Subscrip
 func (r *subscriptionRepo) GetByTopicAndGroup(ctx context.Context, topicName, consumerGroup string) (*domain.Subscription, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	s, ok := r.byTg[tgKey(topicName, consumerGroup)]
+	s, ok := r.byTg[tgKey(domain.Namespace(ctx), topicName, consumerGroup)]
 	if !ok {
 		return nil, domain.ErrNotFound
 	}
@@ -56,9 +70,10 @@ func (r *subscriptionRepo) GetByTopicAndGroup(ctx context.Context, topicName, co
 func (r *subscriptionRepo) ListByTopic(ctx context.Context, topicName string) ([]*domain.Subscription, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	ns := domain.Namespace(ctx)
 	var out []*domain.Subscription
 	for _, s := range r.byID {
-		if s.TopicName == topicName {
+		if s.Namespace == ns && s.TopicName == topicName {
 			s2 := *s
 			out = append(out, &s2)
 		}
@@ -69,10 +84,13 @@ func (r *subscriptionRepo) ListByTopic(ctx context.Context, topicName string) ([
 func (r *subscriptionRepo) List(ctx context.Context) ([]*domain.Subscription, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	out := make([]*domain.Subscription, 0, len(r.byID))
+	ns := domain.Namespace(ctx)
+	out := make([]*domain.Subscription, 0)
 	for _, s := range r.byID {
-		s2 := *s
-		out = append(out, &s2)
+		if s.Namespace == ns {
+			s2 := *s
+			out = append(out, &s2)
+		}
 	}
 	return out, nil
 }
@@ -80,8 +98,50 @@ func (r *subscriptionRepo) List(ctx context.Context) ([]*domain.Subscription, er
 func (r *subscriptionRepo) AdvanceOffset(ctx context.Context, id string, offset int64) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if s, ok := r.byID[id]; ok {
+	if s, ok := r.get(ctx, id); ok {
+		s.Offset = offset
+	}
+	return nil
+}
+
+func (r *subscriptionRepo) RaiseOffset(ctx context.Context, id string, from, offset int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.get(ctx, id); ok && s.Offset >= from && s.Offset < offset {
 		s.Offset = offset
 	}
 	return nil
 }
+
+func (r *subscriptionRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.get(ctx, id)
+	if !ok {
+		return nil
+	}
+	delete(r.byID, id)
+	k := tgKey(s.Namespace, s.TopicName, s.ConsumerGroup)
+	if r.byTg[k] == s {
+		delete(r.byTg, k)
+	}
+	return nil
+}
+
+func (r *subscriptionRepo) MarkConsumed(ctx context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.get(ctx, id); ok {
+		s.LastConsumedAt = at
+	}
+	return nil
+}
+
+func (r *subscriptionRepo) SetAckTimeout(ctx context.Context, id string, timeout time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s, ok := r.get(ctx, id); ok {
+		s.AckTimeout = timeout
+	}
+	return nil
+}
@@ -2,35 +2,44 @@ package memory
 
 import (
 	"context"
+	"sort"
 	"sync"
 
 	"queue-service/internal/domain"
 )
 
+// topicRepo хранит топики по пространствам имён: namespace → имя → топик.
 type topicRepo struct {
 	mu     sync.RWMutex
-	topics map[string]*domain.Topic
+	topics map[string]map[string]*domain.Topic
 }
 
 func NewTopicRepository() domain.TopicRepository {
-	return &topicRepo{topics: make(map[string]*domain.Topic)}
+	return &topicRepo{topics: make(map[string]map[string]*domain.Topic)}
 }
 
 func (r *topicRepo) Create(ctx context.Context, topic *domain.Topic) error {
+	ns := domain.Namespace(ctx)
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if _, exists := r.topics[topic.Name]; exists {
+	m := r.topics[ns]
+	if m == nil {
+		m = make(map[string]*domain.Topic)
+		r.topics[ns] = m
+	}
+	if _, exists := m[topic.Name]; exists {
 		return domain.ErrExists
 	}
 	t := *topic
-	r.topics[topic.Name] = &t
+	t.Namespace = ns
+	m[topic.Name] = &t
 	return nil
 }
 
 func (r *topicRepo) Get(ctx context.Context, name string) (*domain.Topic, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	t, ok := r.topics[name]
+	t, ok := r.topics[domain.Namespace(ctx)][name]
 	if !ok {
 		return nil, domain.ErrNotFound
 	}
@@ -39,19 +48,37 @@ func (r *topicRepo) Get(ctx context.Context, name string) (*domain.Topic, error)
 }
 
 func (r *topicRepo) Delete(ctx context.Context, name string) error {
+	ns := domain.Namespace(ctx)
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	delete(r.topics, name)
+	if m, ok := r.topics[ns]; ok {
+		delete(m, name)
+		if len(m) == 0 {
+			delete(r.topics, ns)
+		}
+	}
 	return nil
 }
 
 func (r *topicRepo) List(ctx context.Context) ([]*domain.Topic, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	out := make([]*domain.Topic, 0, len(r.topics))
-	for _, t := range r.topics {
+	m := r.topics[domain.Namespace(ctx)]
+	out := make([]*domain.Topic, 0, len(m))
+	for _, t := range m {
 		t2 := *t
 		out = append(out, &t2)
 	}
 	return out, nil
 }
+
+func (r *topicRepo) Namespaces(ctx context.Context) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]string, 0, len(r.topics))
+	for ns := range r.topics {
+		out = append(out, ns)
+	}
+	sort.Strings(out)
+	return out, nil
+}
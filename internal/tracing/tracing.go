@@ -0,0 +1,106 @@
+// Package tracing настраивает экспорт трасс OpenTelemetry: OTLP/gRPC для
+// коллектора, stdout или файл для локальной отладки.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Config — параметры экспорта трасс.
+type Config struct {
+	// Exporter: none (по умолчанию), otlp, stdout или file.
+	Exporter string
+	// Endpoint — адрес OTLP/gRPC коллектора host:port; пустой — из
+	// OTEL_EXPORTER_OTLP_ENDPOINT или localhost:4317.
+	Endpoint string
+	// Insecure — подключаться к коллектору без TLS.
+	Insecure bool
+	// File — куда писать спаны для exporter: file.
+	File        string
+	ServiceName string
+	// SampleRatio — доля трасс, начатых брокером, которые записываются
+	// (0 — 1.0). Решение вызывающей стороны, пришедшее в traceparent, соблюдается.
+	SampleRatio float64
+}
+
+// Setup устанавливает глобальные TracerProvider и пропагатор W3C Trace
+// Context. Возвращаемая функция досылает накопленные спаны и закрывает
+// экспортёр; её нужно вызвать при остановке. С exporter: none спаны не
+// записываются, но контекст трассы по-прежнему передаётся через сообщения.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var (
+		exporter sdktrace.SpanExporter
+		closer   io.Closer
+	)
+	switch cfg.Exporter {
+	case "", "none":
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		opts := []otlptracegrpc.Option{}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "file":
+		if cfg.File == "" {
+			return nil, errors.New("tracing: exporter file requires file")
+		}
+		f, ferr := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if ferr != nil {
+			return nil, fmt.Errorf("tracing: %w", ferr)
+		}
+		closer = f
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return nil, fmt.Errorf("tracing: unknown exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		if closer != nil {
+			_ = closer.Close()
+		}
+		return nil, fmt.Errorf("tracing: %w", err)
+	}
+
+	name := cfg.ServiceName
+	if name == "" {
+		name = "queue-service"
+	}
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", name))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(tp)
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closer != nil {
+			err = errors.Join(err, closer.Close())
+		}
+		return err
+	}, nil
+}
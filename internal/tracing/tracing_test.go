@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestSetup_file(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.json")
+	shutdown, err := Setup(context.Background(), Config{Exporter: "file", File: path, ServiceName: "broker-test"})
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	_, span := otel.Tracer("test").Start(context.Background(), "publish orders")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"publish orders"`) || !strings.Contains(string(data), "broker-test") {
+		t.Errorf("span not exported: %s", data)
+	}
+}
+
+func TestSetup_errors(t *testing.T) {
+	for _, cfg := range []Config{{Exporter: "zipkin"}, {Exporter: "file"}} {
+		if _, err := Setup(context.Background(), cfg); err == nil {
+			t.Errorf("%+v: want error", cfg)
+		}
+	}
+	shutdown, err := Setup(context.Background(), Config{})
+	if err != nil {
+		t.Fatalf("none: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("none shutdown: %v", err)
+	}
+}
@@ -0,0 +1,293 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"queue-service/internal/auth"
+	"queue-service/internal/domain"
+)
+
+var (
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrInvalidACLRule   = errors.New("invalid acl rule")
+	ErrACLRuleNotFound  = errors.New("acl rule not found")
+)
+
+var aclOperations = []domain.ACLOperation{domain.ACLPublish, domain.ACLConsume, domain.ACLAdmin}
+
+// AnonymousPrincipal — имя клиента без учётных данных в правилах ACL.
+const AnonymousPrincipal = "anonymous"
+
+// ACLPolicy — включение проверки прав. При выключенной проверке разрешено
+// всё; суперпользователям разрешено всё всегда.
+type ACLPolicy struct {
+	Enabled    bool
+	SuperUsers []string
+}
+
+// ACLUseCase хранит правила доступа и проверяет по ним операции клиентов.
+// Проверка идёт по снимку правил в памяти и не обращается к репозиторию.
+type ACLUseCase struct {
+	rules domain.ACLRepository
+
+	mu    sync.Mutex // изменения правил и пересборка снимка
+	state atomic.Pointer[aclState]
+	// loaded — ID правил, загруженных LoadRules; под mu.
+	loaded []string
+}
+
+type aclState struct {
+	enabled    bool
+	superUsers map[string]bool
+	rules      []*domain.ACLRule
+}
+
+func NewACLUseCase(rules domain.ACLRepository, policy ACLPolicy) *ACLUseCase {
+	u := &ACLUseCase{rules: rules}
+	u.state.Store(&aclState{})
+	u.SetPolicy(policy)
+	return u
+}
+
+// SetPolicy включает или выключает проверку прав и меняет суперпользователей.
+func (u *ACLUseCase) SetPolicy(policy ACLPolicy) {
+	superUsers := make(map[string]bool, len(policy.SuperUsers))
+	for _, name := range policy.SuperUsers {
+		superUsers[name] = true
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	next := *u.state.Load()
+	next.enabled, next.superUsers = policy.Enabled, superUsers
+	u.state.Store(&next)
+}
+
+// Enabled сообщает, проверяются ли права.
+func (u *ACLUseCase) Enabled() bool {
+	return u.state.Load().enabled
+}
+
+// CreateRule проверяет и сохраняет правило; ID и CreatedAt заполняются.
+func (u *ACLUseCase) CreateRule(ctx context.Context, rule domain.ACLRule) (*domain.ACLRule, error) {
+	if err := validateRule(&rule); err != nil {
+		return nil, err
+	}
+	rule.ID = genACLRuleID()
+	rule.CreatedAt = time.Now()
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if err := u.rules.Create(ctx, &rule); err != nil {
+		return nil, err
+	}
+	if err := u.reload(ctx); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "acl rule created", "rule", rule.ID, "principal", rule.Principal,
+		"resource_type", rule.ResourceType, "pattern", rule.Pattern, "operations", rule.Operations)
+	return &rule, nil
+}
+
+// LoadRules заменяет правила, загруженные прошлым вызовом LoadRules
+// (из конфигурации), на rules; правила, созданные через API, остаются.
+// Если хоть одно правило неверно, ничего не меняется.
+func (u *ACLUseCase) LoadRules(ctx context.Context, rules []domain.ACLRule) error {
+	rules = slices.Clone(rules)
+	for i := range rules {
+		if err := validateRule(&rules[i]); err != nil {
+			return fmt.Errorf("rule #%d: %w", i+1, err)
+		}
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, id := range u.loaded {
+		if err := u.rules.Delete(ctx, id); err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+	}
+	u.loaded = u.loaded[:0]
+	now := time.Now()
+	for _, r := range rules {
+		r.ID = genACLRuleID()
+		r.CreatedAt = now
+		if err := u.rules.Create(ctx, &r); err != nil {
+			return err
+		}
+		u.loaded = append(u.loaded, r.ID)
+	}
+	return u.reload(ctx)
+}
+
+// ValidateACLRule проверяет правило, не сохраняя его.
+func ValidateACLRule(rule domain.ACLRule) error {
+	return validateRule(&rule)
+}
+
+// DeleteRule удаляет правило по ID.
+func (u *ACLUseCase) DeleteRule(ctx context.Context, id string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if err := u.rules.Delete(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrACLRuleNotFound
+		}
+		return err
+	}
+	if err := u.reload(ctx); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "acl rule deleted", "rule", id)
+	return nil
+}
+
+// ListRules возвращает правила клиента principal или все, если он пуст.
+func (u *ACLUseCase) ListRules(ctx context.Context, principal string) ([]*domain.ACLRule, error) {
+	rules, err := u.rules.List(ctx)
+	if err != nil || principal == "" {
+		return rules, err
+	}
+	out := rules[:0]
+	for _, r := range rules {
+		if r.Principal == principal {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// reload пересобирает снимок правил после изменения. Вызывается под u.mu.
+func (u *ACLUseCase) reload(ctx context.Context) error {
+	rules, err := u.rules.List(ctx)
+	if err != nil {
+		return err
+	}
+	next := *u.state.Load()
+	next.rules = rules
+	u.state.Store(&next)
+	return nil
+}
+
+// Authorize проверяет, может ли клиент из ctx выполнить op над ресурсом;
+// отказ — ErrPermissionDenied с описанием и запись в лог.
+func (u *ACLUseCase) Authorize(ctx context.Context, op domain.ACLOperation, resourceType domain.ACLResourceType, name string) error {
+	st := u.state.Load()
+	if !st.enabled {
+		return nil
+	}
+	principal, ns := Principal(ctx), domain.Namespace(ctx)
+	if st.allowed(principal, ns, resourceType, name, func(ops []domain.ACLOperation) bool {
+		return slices.Contains(ops, op) || slices.Contains(ops, domain.ACLAdmin)
+	}) {
+		return nil
+	}
+	slog.WarnContext(ctx, "permission denied", "principal", principal, "operation", op,
+		"resource_type", resourceType, "resource", name, "namespace", ns)
+	return fmt.Errorf("%w: %s may not %s %s %q", ErrPermissionDenied, principal, op, resourceType, name)
+}
+
+// CanAccess сообщает, есть ли у клиента хоть какое-то право на ресурс:
+// по нему списки топиков и подписок показывают только доступное клиенту.
+func (u *ACLUseCase) CanAccess(ctx context.Context, resourceType domain.ACLResourceType, name string) bool {
+	st := u.state.Load()
+	if !st.enabled {
+		return true
+	}
+	return st.allowed(Principal(ctx), domain.Namespace(ctx), resourceType, name, func(ops []domain.ACLOperation) bool { return len(ops) > 0 })
+}
+
+// allowed ищет правило для ресурса name пространства имён ns; у правил
+// cluster пространство не проверяется.
+func (st *aclState) allowed(principal, ns string, resourceType domain.ACLResourceType, name string, grants func([]domain.ACLOperation) bool) bool {
+	if st.superUsers[principal] {
+		return true
+	}
+	for _, r := range st.rules {
+		if r.ResourceType == resourceType && (r.Principal == "*" || r.Principal == principal) &&
+			(resourceType == domain.ACLCluster || r.Namespace == "*" || r.Namespace == ns) &&
+			matchPattern(r.Pattern, name) && grants(r.Operations) {
+			return true
+		}
+	}
+	return false
+}
+
+// Principal возвращает имя клиента из контекста запроса или
+// AnonymousPrincipal.
+func Principal(ctx context.Context) string {
+	if id, ok := auth.FromContext(ctx); ok && id.Name != "" {
+		return id.Name
+	}
+	return AnonymousPrincipal
+}
+
+// matchPattern сравнивает имя с шаблоном: точное имя, префикс с "*" на
+// конце или "*".
+func matchPattern(pattern, name string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(name, prefix)
+	}
+	return pattern == name
+}
+
+func validateRule(r *domain.ACLRule) error {
+	if r.Principal == "" {
+		return fmt.Errorf("%w: principal is required", ErrInvalidACLRule)
+	}
+	switch r.ResourceType {
+	case domain.ACLTopic, domain.ACLGroup:
+		switch r.Namespace {
+		case "":
+			r.Namespace = domain.DefaultNamespace
+		case "*":
+		default:
+			if err := ValidateNamespace(r.Namespace); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidACLRule, err)
+			}
+		}
+	case domain.ACLCluster:
+		if r.Pattern == "" {
+			r.Pattern = "*"
+		}
+		if r.Namespace != "" {
+			return fmt.Errorf("%w: cluster rules have no namespace", ErrInvalidACLRule)
+		}
+	default:
+		return fmt.Errorf("%w: resource type must be topic, group or cluster", ErrInvalidACLRule)
+	}
+	if r.Pattern == "" || strings.Contains(strings.TrimSuffix(r.Pattern, "*"), "*") {
+		return fmt.Errorf("%w: pattern must be a name, a prefix ending with * or *", ErrInvalidACLRule)
+	}
+	if len(r.Operations) == 0 {
+		return fmt.Errorf("%w: at least one operation is required", ErrInvalidACLRule)
+	}
+	for _, op := range r.Operations {
+		if !slices.Contains(aclOperations, op) {
+			return fmt.Errorf("%w: unknown operation %q", ErrInvalidACLRule, op)
+		}
+	}
+	// Операции без повторов в порядке aclOperations.
+	ops := make([]domain.ACLOperation, 0, len(aclOperations))
+	for _, op := range aclOperations {
+		if slices.Contains(r.Operations, op) {
+			ops = append(ops, op)
+		}
+	}
+	r.Operations = ops
+	return nil
+}
+
+func genACLRuleID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return "acl-" + hex.EncodeToString(b)
+}
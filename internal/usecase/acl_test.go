@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"queue-service/internal/auth"
+	"queue-service/internal/domain"
+	"queue-service/internal/repository/memory"
+)
+
+func asClient(name string) context.Context {
+	return auth.WithIdentity(context.Background(), auth.Identity{Name: name, Method: "api_key"})
+}
+
+func TestACLUseCase_Authorize(t *testing.T) {
+	ctx := context.Background()
+	acl := NewACLUseCase(memory.NewACLRepository(), ACLPolicy{Enabled: true, SuperUsers: []string{"root"}})
+	rules := []domain.ACLRule{
+		{Principal: "orders", ResourceType: domain.ACLTopic, Pattern: "orders.*", Operations: []domain.ACLOperation{domain.ACLPublish}},
+		{Principal: "orders", ResourceType: domain.ACLTopic, Pattern: "orders.admin", Operations: []domain.ACLOperation{domain.ACLAdmin}},
+		{Principal: "*", ResourceType: domain.ACLTopic, Pattern: "public", Operations: []domain.ACLOperation{domain.ACLConsume}},
+	}
+	for _, r := range rules {
+		if _, err := acl.CreateRule(ctx, r); err != nil {
+			t.Fatalf("CreateRule: %v", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		client string
+		op     domain.ACLOperation
+		topic  string
+		ok     bool
+	}{
+		{"orders", domain.ACLPublish, "orders.created", true},
+		{"orders", domain.ACLConsume, "orders.created", false},
+		{"orders", domain.ACLPublish, "billing", false},
+		{"orders", domain.ACLConsume, "orders.admin", true}, // admin включает consume
+		{"billing", domain.ACLConsume, "public", true},
+		{"", domain.ACLConsume, "public", true}, // "*" включает анонимного
+		{"", domain.ACLPublish, "public", false},
+		{"root", domain.ACLAdmin, "anything", true},
+	} {
+		ctx := context.Background()
+		if tc.client != "" {
+			ctx = asClient(tc.client)
+		}
+		err := acl.Authorize(ctx, tc.op, domain.ACLTopic, tc.topic)
+		if (err == nil) != tc.ok || (err != nil && !errors.Is(err, ErrPermissionDenied)) {
+			t.Errorf("%s %s %s: %v", tc.client, tc.op, tc.topic, err)
+		}
+	}
+	if acl.CanAccess(asClient("orders"), domain.ACLTopic, "billing") || !acl.CanAccess(asClient("orders"), domain.ACLTopic, "orders.x") {
+		t.Error("CanAccess does not follow rules")
+	}
+	// Правила на топики не дают прав на группы.
+	if err := acl.Authorize(asClient("orders"), domain.ACLConsume, domain.ACLGroup, "orders.created"); err == nil {
+		t.Error("topic rule granted group access")
+	}
+}
+
+func TestACLUseCase_namespace(t *testing.T) {
+	ctx := context.Background()
+	acl := NewACLUseCase(memory.NewACLRepository(), ACLPolicy{Enabled: true})
+	for _, r := range []domain.ACLRule{
+		{Principal: "orders", ResourceType: domain.ACLTopic, Pattern: "orders", Operations: []domain.ACLOperation{domain.ACLPublish}},
+		{Principal: "orders", ResourceType: domain.ACLTopic, Namespace: "team-a", Pattern: "billing", Operations: []domain.ACLOperation{domain.ACLPublish}},
+		{Principal: "orders", ResourceType: domain.ACLTopic, Namespace: "*", Pattern: "audit", Operations: []domain.ACLOperation{domain.ACLPublish}},
+	} {
+		if _, err := acl.CreateRule(ctx, r); err != nil {
+			t.Fatalf("CreateRule: %v", err)
+		}
+	}
+	if list, _ := acl.ListRules(ctx, "orders"); list[0].Namespace != domain.DefaultNamespace {
+		t.Errorf("rule without namespace: %q", list[0].Namespace)
+	}
+
+	teamA := domain.WithNamespace(asClient("orders"), "team-a")
+	for _, tc := range []struct {
+		ctx   context.Context
+		topic string
+		ok    bool
+	}{
+		{asClient("orders"), "orders", true},
+		{teamA, "orders", false}, // правило без пространства — только default
+		{teamA, "billing", true},
+		{asClient("orders"), "billing", false},
+		{asClient("orders"), "audit", true},
+		{teamA, "audit", true},
+	} {
+		err := acl.Authorize(tc.ctx, domain.ACLPublish, domain.ACLTopic, tc.topic)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s in %s: %v", tc.topic, domain.Namespace(tc.ctx), err)
+		}
+	}
+}
+
+func TestACLUseCase_disabled(t *testing.T) {
+	acl := NewACLUseCase(memory.NewACLRepository(), ACLPolicy{})
+	if err := acl.Authorize(context.Background(), domain.ACLAdmin, domain.ACLCluster, "*"); err != nil {
+		t.Errorf("disabled ACL denied: %v", err)
+	}
+}
+
+func TestACLUseCase_rules(t *testing.T) {
+	ctx := context.Background()
+	acl := NewACLUseCase(memory.NewACLRepository(), ACLPolicy{Enabled: true})
+	for name, r := range map[string]domain.ACLRule{
+		"no principal":  {ResourceType: domain.ACLTopic, Pattern: "a", Operations: []domain.ACLOperation{domain.ACLPublish}},
+		"bad type":      {Principal: "a", ResourceType: "queue", Pattern: "a", Operations: []domain.ACLOperation{domain.ACLPublish}},
+		"inner star":    {Principal: "a", ResourceType: domain.ACLTopic, Pattern: "a*b", Operations: []domain.ACLOperation{domain.ACLPublish}},
+		"no operations": {Principal: "a", ResourceType: domain.ACLTopic, Pattern: "a"},
+		"bad operation": {Principal: "a", ResourceType: domain.ACLTopic, Pattern: "a", Operations: []domain.ACLOperation{"delete"}},
+		"bad namespace": {Principal: "a", ResourceType: domain.ACLTopic, Namespace: "a/b", Pattern: "a", Operations: []domain.ACLOperation{domain.ACLPublish}},
+		"cluster namespace": {Principal: "a", ResourceType: domain.ACLCluster, Namespace: "team-a",
+			Operations: []domain.ACLOperation{domain.ACLAdmin}},
+	} {
+		if _, err := acl.CreateRule(ctx, r); !errors.Is(err, ErrInvalidACLRule) {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	rule, err := acl.CreateRule(ctx, domain.ACLRule{Principal: "ops", ResourceType: domain.ACLCluster,
+		Operations: []domain.ACLOperation{domain.ACLAdmin, domain.ACLAdmin}})
+	if err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+	if rule.ID == "" || rule.Pattern != "*" || len(rule.Operations) != 1 {
+		t.Errorf("rule not normalized: %+v", rule)
+	}
+	if list, _ := acl.ListRules(ctx, "ops"); len(list) != 1 {
+		t.Errorf("ListRules(ops) = %d rules", len(list))
+	}
+	if list, _ := acl.ListRules(ctx, "other"); len(list) != 0 {
+		t.Errorf("ListRules(other) = %d rules", len(list))
+	}
+	if err := acl.Authorize(asClient("ops"), domain.ACLAdmin, domain.ACLCluster, "*"); err != nil {
+		t.Errorf("cluster admin: %v", err)
+	}
+	if err := acl.DeleteRule(ctx, rule.ID); err != nil {
+		t.Fatalf("DeleteRule: %v", err)
+	}
+	if err := acl.Authorize(asClient("ops"), domain.ACLAdmin, domain.ACLCluster, "*"); err == nil {
+		t.Error("deleted rule still applies")
+	}
+	if err := acl.DeleteRule(ctx, rule.ID); !errors.Is(err, ErrACLRuleNotFound) {
+		t.Errorf("second DeleteRule: %v", err)
+	}
+}
+
+func TestACLUseCase_LoadRules(t *testing.T) {
+	ctx := context.Background()
+	acl := NewACLUseCase(memory.NewACLRepository(), ACLPolicy{Enabled: true})
+	publish := []domain.ACLOperation{domain.ACLPublish}
+	if err := acl.LoadRules(ctx, []domain.ACLRule{{Principal: "orders", ResourceType: domain.ACLTopic, Pattern: "orders", Operations: publish}}); err != nil {
+		t.Fatalf("LoadRules: %v", err)
+	}
+	if _, err := acl.CreateRule(ctx, domain.ACLRule{Principal: "billing", ResourceType: domain.ACLTopic, Pattern: "bills", Operations: publish}); err != nil {
+		t.Fatalf("CreateRule: %v", err)
+	}
+
+	// Неверное правило отклоняет весь набор.
+	err := acl.LoadRules(ctx, []domain.ACLRule{
+		{Principal: "audit", ResourceType: domain.ACLTopic, Pattern: "orders", Operations: publish},
+		{Principal: "audit", ResourceType: "queue", Pattern: "orders", Operations: publish},
+	})
+	if !errors.Is(err, ErrInvalidACLRule) {
+		t.Fatalf("invalid set: %v", err)
+	}
+	if err := acl.Authorize(asClient("orders"), domain.ACLPublish, domain.ACLTopic, "orders"); err != nil {
+		t.Fatalf("rules changed by a rejected set: %v", err)
+	}
+
+	if err := acl.LoadRules(ctx, []domain.ACLRule{{Principal: "audit", ResourceType: domain.ACLTopic, Pattern: "orders", Operations: publish}}); err != nil {
+		t.Fatalf("LoadRules: %v", err)
+	}
+	if err := acl.Authorize(asClient("orders"), domain.ACLPublish, domain.ACLTopic, "orders"); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("replaced rule still applies: %v", err)
+	}
+	if err := acl.Authorize(asClient("audit"), domain.ACLPublish, domain.ACLTopic, "orders"); err != nil {
+		t.Errorf("loaded rule: %v", err)
+	}
+	if err := acl.Authorize(asClient("billing"), domain.ACLPublish, domain.ACLTopic, "bills"); err != nil {
+		t.Errorf("rule created through the API: %v", err)
+	}
+
+	acl.SetPolicy(ACLPolicy{})
+	if err := acl.Authorize(asClient("orders"), domain.ACLPublish, domain.ACLTopic, "orders"); err != nil {
+		t.Errorf("disabled by SetPolicy: %v", err)
+	}
+}
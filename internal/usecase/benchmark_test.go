@@ -23,10 +23,10 @@ func setupBench(b *testing.B) (
 	subs := memory.NewSubscriptionRepository()
 	pending := memory.NewPendingDeliveryRepository()
 
-	topicUC := NewTopicUseCase(topics, queues)
-	pub := NewPublishUseCase(topics, queues, msgs, 10*1024*1024)
-	subUC := NewSubscriptionUseCase(subs, topics, queues, 30)
-	consumeUC := NewConsumeUseCase(subs, msgs, pending)
+	topicUC := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	pub := NewPublishUseCase(topics, queues, msgs, 10*1024*1024, MemoryBudget{}, nil)
+	subUC := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := NewConsumeUseCase(subs, msgs, pending, nil)
 
 	_, _ = topicUC.CreateTopic(ctx, "bench", 1000000)
 	sub, _ := subUC.Subscribe(ctx, "bench", "0", "g1", domain.AtLeastOnce)
@@ -98,3 +98,36 @@ func BenchmarkFullFlow_PublishConsumeAck(b *testing.B) {
 		}
 	}
 }
+
+// BenchmarkConsume_batch100 — одна операция читает пакет из 100 сообщений с
+// начала очереди (подписка перематывается перед каждым Consume), так что
+// allocs/op делённое на 100 — число аллокаций на одно полученное сообщение.
+func BenchmarkConsume_batch100(b *testing.B) {
+	for _, tc := range []struct {
+		name      string
+		guarantee domain.DeliveryGuarantee
+	}{
+		{"atMostOnce", domain.AtMostOnce},
+		{"atLeastOnce", domain.AtLeastOnce},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			ctx, _, pub, subUC, consumeUC, _ := setupBench(b)
+			sub, _ := subUC.Subscribe(ctx, "bench", "0", "batch", tc.guarantee)
+			payload := make([]byte, 1024)
+			headers := map[string]string{"content-type": "application/json"}
+			for i := 0; i < 100; i++ {
+				_, _ = pub.Publish(ctx, "bench", "0", payload, "key", headers)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = subUC.SeekSubscription(ctx, sub.ID, Seek{Position: SeekEarliest})
+				msgs, _ := consumeUC.Consume(ctx, sub.ID, 100)
+				if len(msgs) != 100 {
+					b.Fatalf("got %d messages", len(msgs))
+				}
+			}
+		})
+	}
+}
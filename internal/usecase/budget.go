@@ -0,0 +1,398 @@
+package usecase
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"time"
+
+	"queue-service/internal/domain"
+)
+
+var ErrMemoryLimit = errors.New("memory limit exceeded")
+
+// OverflowPolicy — что делает Publish, когда сообщение не помещается в бюджет памяти.
+type OverflowPolicy int
+
+const (
+	OverflowReject OverflowPolicy = iota // сразу вернуть ErrMemoryLimit
+	OverflowBlock                        // ждать освобождения памяти не дольше BlockTimeout
+	OverflowEvict                        // удалить самые старые сообщения в пределах превышенного лимита
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowEvict:
+		return "evict"
+	}
+	return "reject"
+}
+
+// ParseOverflowPolicy разбирает значение broker.memory.overflow_policy;
+// пустая строка означает reject.
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch s {
+	case "", "reject":
+		return OverflowReject, nil
+	case "block":
+		return OverflowBlock, nil
+	case "evict":
+		return OverflowEvict, nil
+	}
+	return 0, fmt.Errorf("unknown overflow policy %q", s)
+}
+
+// memoryPollInterval — как часто политика block проверяет, освободилась ли память.
+const memoryPollInterval = 10 * time.Millisecond
+
+// MemoryBudget ограничивает объём сообщений (domain.Message.Size) в памяти.
+// Нулевые лимиты — без ограничения. Лимиты мягкие: параллельные Publish
+// могут превысить их на размер одновременно записываемых сообщений.
+type MemoryBudget struct {
+	Limit       int64            // на все топики всех пространств имён
+	TopicLimit  int64            // на каждый топик, если его нет в TopicLimits
+	TopicLimits map[string]int64 // лимиты отдельных топиков
+	// NamespaceLimit — на все топики пространства имён, если его нет в
+	// NamespaceLimits; квота памяти арендатора.
+	NamespaceLimit  int64
+	NamespaceLimits map[string]int64
+	Policy          OverflowPolicy
+	// BlockTimeout — сколько политика block ждёт памяти; 0 — до отмены
+	// контекста запроса.
+	BlockTimeout time.Duration
+}
+
+func (b MemoryBudget) topicLimit(topicName string) int64 {
+	if l, ok := b.TopicLimits[topicName]; ok {
+		return l
+	}
+	return b.TopicLimit
+}
+
+func (b MemoryBudget) namespaceLimit(ns string) int64 {
+	if l, ok := b.NamespaceLimits[ns]; ok {
+		return l
+	}
+	return b.NamespaceLimit
+}
+
+// MemoryUsage — занятая память и лимиты брокера и пространства имён запроса.
+type MemoryUsage struct {
+	Used           int64
+	Limit          int64
+	Policy         OverflowPolicy
+	Namespace      string
+	NamespaceUsed  int64
+	NamespaceLimit int64
+	Topics         []TopicMemoryUsage // топики пространства по имени
+}
+
+type TopicMemoryUsage struct {
+	TopicName string
+	Used      int64
+	Limit     int64
+}
+
+// MemoryUsage возвращает занятую сообщениями память: всего, в пространстве
+// имён запроса и по его топикам.
+func (u *PublishUseCase) MemoryUsage(ctx context.Context) (*MemoryUsage, error) {
+	used, err := u.messages.TotalUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nsUsed, err := u.messages.Usage(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	topics, err := u.topics.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ns := domain.Namespace(ctx)
+	budget := u.budget.Load()
+	out := &MemoryUsage{
+		Used:           used,
+		Limit:          budget.Limit,
+		Policy:         budget.Policy,
+		Namespace:      ns,
+		NamespaceUsed:  nsUsed,
+		NamespaceLimit: budget.namespaceLimit(ns),
+	}
+	for _, t := range topics {
+		n, err := u.messages.Usage(ctx, t.Name)
+		if err != nil {
+			return nil, err
+		}
+		out.Topics = append(out.Topics, TopicMemoryUsage{TopicName: t.Name, Used: n, Limit: budget.topicLimit(t.Name)})
+	}
+	sort.Slice(out.Topics, func(i, j int) bool { return out.Topics[i].TopicName < out.Topics[j].TopicName })
+	return out, nil
+}
+
+// reserve проверяет, что size байт сообщений для очереди queueID топика
+// помещаются в бюджет, и при необходимости применяет политику переполнения.
+func (u *PublishUseCase) reserve(ctx context.Context, topicName, queueID string, size int64) error {
+	budget := u.budget.Load()
+	topicLimit := budget.topicLimit(topicName)
+	nsLimit := budget.namespaceLimit(domain.Namespace(ctx))
+	if budget.Limit <= 0 && topicLimit <= 0 && nsLimit <= 0 {
+		return nil
+	}
+	if budget.Policy == OverflowEvict {
+		// Сообщение больше лимита не поместится, даже если вытеснить всё.
+		for _, l := range []int64{budget.Limit, topicLimit, nsLimit} {
+			if l > 0 && size > l {
+				return u.memoryLimit(ctx, topicName, queueID, size, budget.Policy)
+			}
+		}
+	}
+	var (
+		deadline <-chan time.Time
+		evicted  int
+	)
+	for {
+		excess, scope, limit, err := u.excess(ctx, budget.Limit, topicName, topicLimit, nsLimit, size)
+		if err != nil {
+			return err
+		}
+		if excess <= 0 {
+			if evicted > 0 {
+				slog.DebugContext(ctx, "evicted messages to fit memory budget",
+					"topic", topicName, "queue", queueID, "scope", scope.String(), "evicted", evicted)
+			}
+			return nil
+		}
+		switch budget.Policy {
+		case OverflowEvict:
+			n, err := u.evict(ctx, scope, topicName, excess+limit/evictHeadroom)
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				return u.memoryLimit(ctx, topicName, queueID, size, budget.Policy)
+			}
+			evicted += n
+			continue
+		case OverflowBlock:
+			if deadline == nil && budget.BlockTimeout > 0 {
+				t := time.NewTimer(budget.BlockTimeout)
+				defer t.Stop()
+				deadline = t.C
+			}
+			select {
+			case <-ctx.Done():
+				return u.memoryLimit(ctx, topicName, queueID, size, budget.Policy)
+			case <-deadline:
+				return u.memoryLimit(ctx, topicName, queueID, size, budget.Policy)
+			case <-time.After(memoryPollInterval):
+			}
+		default:
+			return u.memoryLimit(ctx, topicName, queueID, size, budget.Policy)
+		}
+	}
+}
+
+// memoryLimit отмечает в логе отклонённое сообщение и возвращает ErrMemoryLimit.
+func (u *PublishUseCase) memoryLimit(ctx context.Context, topicName, queueID string, size int64, policy OverflowPolicy) error {
+	slog.WarnContext(ctx, "message rejected: memory limit exceeded",
+		"topic", topicName, "queue", queueID, "size", size, "policy", policy.String())
+	return ErrMemoryLimit
+}
+
+// memoryScope — область, на которую действует лимит памяти.
+type memoryScope int
+
+const (
+	scopeBroker memoryScope = iota
+	scopeNamespace
+	scopeTopic
+)
+
+func (s memoryScope) String() string {
+	switch s {
+	case scopeNamespace:
+		return "namespace"
+	case scopeTopic:
+		return "topic"
+	}
+	return "broker"
+}
+
+// excess возвращает, на сколько байт превысится самый строгий из лимитов
+// после записи size байт в топик, самую узкую из превышенных областей и её
+// лимит: вытеснение из неё уменьшает занятую память и во всех объемлющих.
+func (u *PublishUseCase) excess(ctx context.Context, limit int64, topicName string, topicLimit, nsLimit, size int64) (int64, memoryScope, int64, error) {
+	var (
+		excess     int64
+		scope      memoryScope
+		scopeLimit = limit
+	)
+	if limit > 0 {
+		used, err := u.messages.TotalUsage(ctx)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		excess = used + size - limit
+	}
+	if nsLimit > 0 {
+		used, err := u.messages.Usage(ctx, "")
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if e := used + size - nsLimit; e > 0 {
+			excess, scope, scopeLimit = max(excess, e), scopeNamespace, nsLimit
+		}
+	}
+	if topicLimit > 0 {
+		used, err := u.messages.Usage(ctx, topicName)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if e := used + size - topicLimit; e > 0 {
+			excess, scope, scopeLimit = max(excess, e), scopeTopic, topicLimit
+		}
+	}
+	return excess, scope, scopeLimit, nil
+}
+
+// evictHeadroom: вытеснение освобождает сверх нужного 1/evictHeadroom
+// превышенного лимита, чтобы следующие Publish не просматривали область снова.
+const evictHeadroom = 20
+
+// evictChunk — сколько сообщений очереди читается за раз при вытеснении.
+const evictChunk = 64
+
+// evict удаляет самые старые по времени записи сообщения в области scope
+// (в очередях топика, во всех топиках пространства имён запроса или во всём
+// брокере), пока не освободит need байт, и возвращает их число. Очереди
+// области перечисляются один раз, а их начала упорядочиваются кучей.
+func (u *PublishUseCase) evict(ctx context.Context, scope memoryScope, topicName string, need int64) (int, error) {
+	cursors, err := u.evictCursors(ctx, scope, topicName)
+	if err != nil {
+		return 0, err
+	}
+	h := evictHeap(slices.Clone(cursors))
+	heap.Init(&h)
+	var (
+		freed int64
+		n     int
+	)
+	for freed < need && h.Len() > 0 {
+		c := h[0]
+		m := c.buf[0]
+		c.buf, c.cut = c.buf[1:], m.Offset+1
+		freed += m.Size()
+		n++
+		ok, err := c.fill(u.messages)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+	for _, c := range cursors {
+		if c.cut < 0 {
+			continue
+		}
+		if _, err := u.messages.Truncate(c.ctx, c.topic, c.queue, c.cut); err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// evictCursors возвращает непустые очереди области scope.
+func (u *PublishUseCase) evictCursors(ctx context.Context, scope memoryScope, topicName string) ([]*evictCursor, error) {
+	namespaces := []string{domain.Namespace(ctx)}
+	if scope == scopeBroker {
+		var err error
+		if namespaces, err = u.topics.Namespaces(ctx); err != nil {
+			return nil, err
+		}
+	}
+	var out []*evictCursor
+	for _, ns := range namespaces {
+		nsCtx := domain.WithNamespace(ctx, ns)
+		topicNames := []string{topicName}
+		if scope != scopeTopic {
+			topics, err := u.topics.List(nsCtx)
+			if err != nil {
+				return nil, err
+			}
+			topicNames = topicNames[:0]
+			for _, t := range topics {
+				topicNames = append(topicNames, t.Name)
+			}
+		}
+		for _, name := range topicNames {
+			queues, err := u.queues.ListByTopic(nsCtx, name)
+			if errors.Is(err, domain.ErrNotFound) {
+				continue // топик удалён параллельно
+			}
+			if err != nil {
+				return nil, err
+			}
+			for _, q := range queues {
+				c := &evictCursor{ctx: nsCtx, topic: name, queue: q.QueueID, cut: -1}
+				ok, err := c.fill(u.messages)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					out = append(out, c)
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// evictCursor — очередь области вытеснения с прочитанным началом журнала.
+type evictCursor struct {
+	ctx          context.Context // с пространством имён очереди
+	topic, queue string
+	buf          []*domain.Message // прочитанные, но ещё не вытесненные
+	cut          int64             // очередь усекается до этого смещения; -1 — не трогать
+}
+
+// fill дочитывает сообщения, когда прочитанные кончились; false — больше нет.
+func (c *evictCursor) fill(messages domain.MessageRepository) (bool, error) {
+	if len(c.buf) > 0 {
+		return true, nil
+	}
+	msgs, err := messages.Read(c.ctx, c.topic, c.queue, int(max(c.cut, 0)), evictChunk)
+	if err != nil {
+		return false, err
+	}
+	c.buf = msgs
+	return len(msgs) > 0, nil
+}
+
+// evictHeap упорядочивает очереди по времени записи первого сообщения.
+type evictHeap []*evictCursor
+
+func (h evictHeap) Len() int { return len(h) }
+func (h evictHeap) Less(i, j int) bool {
+	a, b := h[i].buf[0], h[j].buf[0]
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.Offset < b.Offset
+}
+func (h evictHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *evictHeap) Push(x any)   { *h = append(*h, x.(*evictCursor)) }
+func (h *evictHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"queue-service/internal/domain"
+	"queue-service/internal/logging"
+	"queue-service/internal/repository/memory"
+)
+
+// newBudgetTest создаёт топики orders и audit и возвращает PublishUseCase с бюджетом b.
+func newBudgetTest(t *testing.T, b MemoryBudget) (*PublishUseCase, *TopicUseCase) {
+	t.Helper()
+	ctx := context.Background()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	topicUC := NewTopicUseCase(topics, queues, msgs, memory.NewSubscriptionRepository(), memory.NewPendingDeliveryRepository())
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+	_, _ = topicUC.CreateTopic(ctx, "audit", 10000)
+	return NewPublishUseCase(topics, queues, msgs, 1024, b, nil), topicUC
+}
+
+// msgSize — Size сообщения со 100-байтным телом в топике orders.
+func msgSize(t *testing.T) int64 {
+	t.Helper()
+	pub, _ := newBudgetTest(t, MemoryBudget{})
+	m, err := pub.Publish(context.Background(), "orders", "0", bytes.Repeat([]byte("x"), 100), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m.Size()
+}
+
+func TestPublishUseCase_memoryLimit_reject(t *testing.T) {
+	ctx := context.Background()
+	size := msgSize(t)
+	pub, _ := newBudgetTest(t, MemoryBudget{Limit: 3 * size})
+	payload := bytes.Repeat([]byte("x"), 100)
+
+	for i := 0; i < 3; i++ {
+		if _, err := pub.Publish(ctx, "orders", "0", payload, "", nil); err != nil {
+			t.Fatalf("publish %d: %v", i, err)
+		}
+	}
+	if _, err := pub.Publish(ctx, "audit", "0", payload, "", nil); !errors.Is(err, ErrMemoryLimit) {
+		t.Errorf("global limit: want ErrMemoryLimit, got %v", err)
+	}
+	usage, _ := pub.MemoryUsage(ctx)
+	if usage.Used != 3*size || usage.Limit != 3*size || usage.Policy != OverflowReject {
+		t.Errorf("MemoryUsage: %+v", usage)
+	}
+}
+
+func TestPublishUseCase_memoryLimit_logged(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := logging.New(&buf, logging.Config{Level: "info"})
+	prev := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	pub, _ := newBudgetTest(t, MemoryBudget{Limit: 1})
+	ctx := logging.WithRequestID(context.Background(), "req-7")
+	if _, err := pub.Publish(ctx, "orders", "0", []byte("x"), "", nil); !errors.Is(err, ErrMemoryLimit) {
+		t.Fatalf("want ErrMemoryLimit, got %v", err)
+	}
+	var rec map[string]any
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if err := json.Unmarshal(lines[len(lines)-1], &rec); err != nil {
+		t.Fatalf("decode %q: %v", buf.String(), err)
+	}
+	if rec["level"] != "WARN" || rec["topic"] != "orders" || rec["policy"] != "reject" || rec["request_id"] != "req-7" {
+		t.Errorf("record %v", rec)
+	}
+}
+
+func TestPublishUseCase_memoryLimit_topic(t *testing.T) {
+	ctx := context.Background()
+	size := msgSize(t)
+	pub, _ := newBudgetTest(t, MemoryBudget{TopicLimit: size, TopicLimits: map[string]int64{"audit": 2 * size}})
+	payload := bytes.Repeat([]byte("x"), 100)
+
+	_, _ = pub.Publish(ctx, "orders", "0", payload, "", nil)
+	if _, err := pub.Publish(ctx, "orders", "0", payload, "", nil); !errors.Is(err, ErrMemoryLimit) {
+		t.Errorf("default topic limit: want ErrMemoryLimit, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := pub.Publish(ctx, "audit", "0", payload, "", nil); err != nil {
+			t.Errorf("audit has its own limit: %v", err)
+		}
+	}
+
+	// Имя топика входит в Size: "audit" на байт короче "orders".
+	usage, _ := pub.MemoryUsage(ctx)
+	if len(usage.Topics) != 2 || usage.Topics[0].TopicName != "audit" || usage.Topics[0].Limit != 2*size ||
+		usage.Topics[0].Used != 2*(size-1) || usage.Topics[1].Used != size || usage.Topics[1].Limit != size {
+		t.Errorf("topic usage: %+v", usage.Topics)
+	}
+}
+
+func TestPublishUseCase_memoryLimit_evict(t *testing.T) {
+	ctx := context.Background()
+	size := msgSize(t)
+	// Полсообщения запаса: вытеснение одного сообщения освобождает и
+	// превышение, и запас сверх него (evictHeadroom).
+	pub, _ := newBudgetTest(t, MemoryBudget{Limit: 3*size + size/2, Policy: OverflowEvict})
+	payload := bytes.Repeat([]byte("x"), 100)
+
+	for i := 0; i < 5; i++ {
+		if _, err := pub.Publish(ctx, "orders", "0", payload, "", nil); err != nil {
+			t.Fatalf("publish %d: %v", i, err)
+		}
+	}
+	if start, end, _ := pub.messages.Offsets(ctx, "orders", "0"); start != 2 || end != 5 {
+		t.Errorf("evict should drop the oldest messages, offsets [%d, %d)", start, end)
+	}
+	// Общий лимит вытесняет самые старые сообщения брокера, а не только
+	// очереди, в которую идёт запись.
+	if _, err := pub.Publish(ctx, "audit", "0", payload, "", nil); err != nil {
+		t.Fatalf("publish to another topic: %v", err)
+	}
+	if start, _, _ := pub.messages.Offsets(ctx, "orders", "0"); start != 3 {
+		t.Errorf("global limit should evict from orders, start %d", start)
+	}
+	// Сообщение больше лимита не помещается, и вытеснять ради него нечего.
+	if _, err := pub.Publish(ctx, "audit", "0", bytes.Repeat([]byte("x"), int(3*size)), "", nil); !errors.Is(err, ErrMemoryLimit) {
+		t.Errorf("message over the limit: want ErrMemoryLimit, got %v", err)
+	}
+	if usage, _ := pub.MemoryUsage(ctx); usage.Used != 3*size-1 {
+		t.Errorf("nothing should be evicted for an oversized message, used %d", usage.Used)
+	}
+}
+
+func TestPublishUseCase_memoryLimit_evictScope(t *testing.T) {
+	size := msgSize(t)
+	pub, topicUC := newBudgetTest(t, MemoryBudget{TopicLimit: 2*size + size/2, NamespaceLimit: 3*size + size/2, Policy: OverflowEvict})
+	payload := bytes.Repeat([]byte("x"), 100)
+	teamA := domain.WithNamespace(context.Background(), "team-a")
+	teamB := domain.WithNamespace(context.Background(), "team-b")
+	for _, ctx := range []context.Context{teamA, teamB} {
+		_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+		_, _ = topicUC.CreateTopic(ctx, "events", 10000)
+		_, _ = topicUC.CreateQueue(ctx, "orders", "1")
+	}
+	publish := func(ctx context.Context, topic, queue string) {
+		t.Helper()
+		if _, err := pub.Publish(ctx, topic, queue, payload, "", nil); err != nil {
+			t.Fatalf("publish to %s/%s: %v", topic, queue, err)
+		}
+	}
+	start := func(ctx context.Context, topic, queue string) int64 {
+		s, _, _ := pub.messages.Offsets(ctx, topic, queue)
+		return s
+	}
+
+	// Лимит топика: вытесняется самое старое сообщение топика из другой очереди.
+	publish(teamA, "orders", "0")
+	publish(teamA, "orders", "1")
+	publish(teamA, "orders", "1")
+	if start(teamA, "orders", "0") != 1 || start(teamA, "orders", "1") != 0 {
+		t.Errorf("topic limit: starts %d, %d", start(teamA, "orders", "0"), start(teamA, "orders", "1"))
+	}
+	// Лимит пространства имён: вытесняется из другого топика того же
+	// пространства, соседнее не затрагивается.
+	publish(teamB, "orders", "0")
+	publish(teamA, "events", "0")
+	publish(teamA, "events", "0")
+	if start(teamA, "orders", "1") != 1 || start(teamA, "events", "0") != 0 || start(teamB, "orders", "0") != 0 {
+		t.Errorf("namespace limit: orders/1 %d, events/0 %d, team-b %d",
+			start(teamA, "orders", "1"), start(teamA, "events", "0"), start(teamB, "orders", "0"))
+	}
+}
+
+func TestPublishUseCase_memoryLimit_evictHeadroom(t *testing.T) {
+	ctx := context.Background()
+	size := msgSize(t)
+	pub, _ := newBudgetTest(t, MemoryBudget{Limit: 20 * size, Policy: OverflowEvict})
+	payload := bytes.Repeat([]byte("x"), 100)
+	for i := 0; i < 22; i++ {
+		if _, err := pub.Publish(ctx, "orders", "0", payload, "", nil); err != nil {
+			t.Fatalf("publish %d: %v", i, err)
+		}
+	}
+	// Первое переполнение освобождает сообщение и ещё 1/20 лимита, поэтому
+	// следующая запись помещается без вытеснения.
+	if start, end, _ := pub.messages.Offsets(ctx, "orders", "0"); start != 2 || end != 22 {
+		t.Errorf("offsets [%d, %d)", start, end)
+	}
+
+	// Большое сообщение вытесняет за один просмотр больше, чем читается за раз.
+	if err := pub.SetLimits(1<<20, MemoryBudget{Limit: 200 * size, Policy: OverflowEvict}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 180; i++ {
+		_, _ = pub.Publish(ctx, "orders", "0", payload, "", nil)
+	}
+	if _, err := pub.Publish(ctx, "orders", "0", bytes.Repeat(payload, 200), "", nil); err != nil {
+		t.Fatalf("large publish: %v", err)
+	}
+	start, end, _ := pub.messages.Offsets(ctx, "orders", "0")
+	if usage, _ := pub.MemoryUsage(ctx); start < 2+evictChunk || end != 203 || usage.Used > 200*size {
+		t.Errorf("offsets [%d, %d), used %d", start, end, usage.Used)
+	}
+}
+
+func TestPublishUseCase_memoryLimit_block(t *testing.T) {
+	ctx := context.Background()
+	size := msgSize(t)
+	pub, topicUC := newBudgetTest(t, MemoryBudget{Limit: size, Policy: OverflowBlock, BlockTimeout: 20 * time.Millisecond})
+	payload := bytes.Repeat([]byte("x"), 100)
+	_, _ = pub.Publish(ctx, "orders", "0", payload, "", nil)
+
+	begin := time.Now()
+	if _, err := pub.Publish(ctx, "orders", "0", payload, "", nil); !errors.Is(err, ErrMemoryLimit) {
+		t.Fatalf("want ErrMemoryLimit after timeout, got %v", err)
+	}
+	if waited := time.Since(begin); waited < 20*time.Millisecond {
+		t.Errorf("block policy returned after %v, before the timeout", waited)
+	}
+
+	// Освобождение памяти во время ожидания пропускает публикацию.
+	if err := pub.SetLimits(1024, MemoryBudget{Limit: size, Policy: OverflowBlock}); err != nil {
+		t.Fatalf("SetLimits: %v", err)
+	}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_, _ = topicUC.PurgeQueue(ctx, "orders", "0")
+	}()
+	wctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if _, err := pub.Publish(wctx, "orders", "0", payload, "", nil); err != nil {
+		t.Errorf("publish after purge: %v", err)
+	}
+}
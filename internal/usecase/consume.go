@@ -2,32 +2,72 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"queue-service/internal/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var ErrDeliveryNotFound = errors.New("delivery not found")
+
 type ConsumeUseCase struct {
 	subs     domain.SubscriptionRepository
 	messages domain.MessageRepository
 	pending  domain.PendingDeliveryRepository
+	metrics  Metrics
 }
 
 func NewConsumeUseCase(
 	subs domain.SubscriptionRepository,
 	messages domain.MessageRepository,
 	pending domain.PendingDeliveryRepository,
+	metrics Metrics,
 ) *ConsumeUseCase {
 	return &ConsumeUseCase{
 		subs:     subs,
 		messages: messages,
 		pending:  pending,
+		metrics:  orNop(metrics),
 	}
 }
 
 // Функция Consume получает сообщения для подписки.
 // Для случаев, когда сообщение получено хотя бы один раз, отслеживаются ожидающие сообщения, и возвращается delivery_id для подтверждения.
+// Span получения пишется, только если сообщения выданы или произошла ошибка:
+// пустые опросы трассу не засоряют. Span связан с трассами публикации
+// выданных сообщений.
 func (u *ConsumeUseCase) Consume(ctx context.Context, subscriptionID string, maxMessages int) ([]*domain.Message, error) {
+	begin := time.Now()
+	msgs, err := u.consume(ctx, subscriptionID, maxMessages)
+	if err == nil && len(msgs) == 0 {
+		return msgs, nil
+	}
+	name := "consume"
+	attrs := []attribute.KeyValue{attribute.String("queue_service.subscription.id", subscriptionID)}
+	if len(msgs) > 0 {
+		name += " " + msgs[0].TopicName
+		attrs = append(attrs, destinationAttrs(msgs[0].TopicName, msgs[0].QueueID)...)
+		attrs = append(attrs, attribute.Int("messaging.batch.message_count", len(msgs)))
+	}
+	_, span := tracer().Start(ctx, name,
+		consumerSpan,
+		trace.WithTimestamp(begin),
+		trace.WithAttributes(attrs...))
+	if span.IsRecording() {
+		for _, m := range msgs {
+			if link := trace.LinkFromContext(messageTraceContext(ctx, m.Headers)); link.SpanContext.IsValid() {
+				span.AddLink(link)
+			}
+		}
+	}
+	endSpan(span, err)
+	return msgs, err
+}
+
+func (u *ConsumeUseCase) consume(ctx context.Context, subscriptionID string, maxMessages int) ([]*domain.Message, error) {
 	if maxMessages <= 0 {
 		maxMessages = 1
 	}
@@ -35,23 +75,35 @@ func (u *ConsumeUseCase) Consume(ctx context.Context, subscriptionID string, max
 	if err != nil || sub == nil {
 		return nil, ErrSubscriptionNotFound
 	}
+	now := time.Now()
+	_ = u.subs.MarkConsumed(ctx, subscriptionID, now)
 
 	// Сначала повторно доставить сообщения, срок действия которых истек хотя бы один раз.
 	if sub.DeliveryGuarantee == domain.AtLeastOnce {
-		expired, _ := u.pending.Expired(ctx, subscriptionID, time.Now())
+		expired, _ := u.pending.Expired(ctx, subscriptionID, now, maxMessages)
 		if len(expired) > 0 {
-			out := make([]*domain.Message, 0, len(expired))
-			for _, pd := range expired {
-				msg := pd.Message
-				msg.DeliveryID = pd.DeliveryID
-				out = append(out, &msg)
+			out := make([]domain.Message, len(expired))
+			for i, pd := range expired {
+				// Продлить срок, иначе сообщение отдавалось бы при каждом опросе.
+				_ = u.pending.Touch(ctx, subscriptionID, pd.DeliveryID, now.Add(sub.AckTimeout))
+				out[i] = pd.Message
+				out[i].DeliveryID = pd.DeliveryID
 			}
-			return out, nil
+			u.metrics.Redelivered(sub.Namespace, sub.TopicName, sub.QueueID, len(out))
+			u.metrics.Consumed(sub.Namespace, sub.TopicName, sub.QueueID, len(out), time.Since(now))
+			return pointers(out), nil
 		}
 	}
 
-	// Чтение из очереди по смещению подписки
-	msgs, err := u.messages.Read(ctx, sub.TopicName, sub.QueueID, int(sub.Offset), maxMessages)
+	// Чтение из очереди по смещению подписки. Для at-least-once пропускаем
+	// уже выданные, но ещё не подтверждённые сообщения.
+	offset := sub.Offset
+	if sub.DeliveryGuarantee == domain.AtLeastOnce {
+		if last, ok, _ := u.pending.LastOffset(ctx, subscriptionID); ok && last+1 > offset {
+			offset = last + 1
+		}
+	}
+	msgs, err := u.messages.Read(ctx, sub.TopicName, sub.QueueID, int(offset), maxMessages)
 	if err != nil {
 		return nil, err
 	}
@@ -62,31 +114,83 @@ func (u *ConsumeUseCase) Consume(ctx context.Context, subscriptionID string, max
 	if sub.DeliveryGuarantee == domain.AtMostOnce {
 		// Немедленно выполнить смещение
 		lastOffset := msgs[len(msgs)-1].Offset
-		_ = u.subs.AdvanceOffset(ctx, subscriptionID, lastOffset+1)
+		_ = u.subs.RaiseOffset(ctx, subscriptionID, sub.Offset, lastOffset+1)
+		u.metrics.Consumed(sub.Namespace, sub.TopicName, sub.QueueID, len(msgs), time.Since(now))
 		return msgs, nil
 	}
 
-	// AtLeastOnce: добавить в список ожидающих и установить DeliveryID
+	// AtLeastOnce: добавить в список ожидающих и установить DeliveryID.
+	// Сообщения из репозитория общие и неизменяемые, поэтому DeliveryID
+	// ставится в копиях; тело и заголовки не копируются.
 	deliveryID := genID()
-	for i := range msgs {
-		msgs[i].DeliveryID = deliveryID + "-" + msgs[i].ID
+	out := make([]domain.Message, len(msgs))
+	for i, m := range msgs {
+		out[i] = *m
+		out[i].DeliveryID = deliveryID + "-" + m.ID
 		pd := &domain.PendingDelivery{
-			Message:    *msgs[i],
-			ExpiresAt:  time.Now().Add(sub.AckTimeout),
-			DeliveryID: msgs[i].DeliveryID,
+			Message:     out[i],
+			ExpiresAt:   now.Add(sub.AckTimeout),
+			DeliveryID:  out[i].DeliveryID,
+			DeliveredAt: now,
 		}
 		_ = u.pending.Add(ctx, sub.ID, pd)
 	}
-	return msgs, nil
+	u.metrics.Consumed(sub.Namespace, sub.TopicName, sub.QueueID, len(out), time.Since(now))
+	return pointers(out), nil
 }
 
-func (u *ConsumeUseCase) Ack(ctx context.Context, subscriptionID, deliveryID string) error {
-	if _, err := u.subs.Get(ctx, subscriptionID); err != nil {
+// pointers возвращает указатели на элементы msgs: одна аллокация на пакет
+// вместо отдельной на каждое сообщение.
+func pointers(msgs []domain.Message) []*domain.Message {
+	out := make([]*domain.Message, len(msgs))
+	for i := range msgs {
+		out[i] = &msgs[i]
+	}
+	return out
+}
+
+func (u *ConsumeUseCase) Ack(ctx context.Context, subscriptionID, deliveryID string) (err error) {
+	begin := time.Now()
+	sub, err := u.subs.Get(ctx, subscriptionID)
+	if err != nil {
 		return ErrSubscriptionNotFound
 	}
-	ackedOffset, err := u.pending.Ack(ctx, subscriptionID, deliveryID)
+	ctx, span := tracer().Start(ctx, "ack "+sub.TopicName,
+		consumerSpan,
+		trace.WithAttributes(destinationAttrs(sub.TopicName, sub.QueueID)...))
+	defer func() { endSpan(span, err) }()
+	committed, err := u.pending.Ack(ctx, subscriptionID, deliveryID)
 	if err != nil {
-		return err
+		return deliveryError(err)
+	}
+	u.metrics.Acked(sub.Namespace, sub.TopicName, sub.QueueID, time.Since(begin))
+	// Смещение подписки — начало неподтверждённого хвоста: оно не
+	// откатывается назад и не перескакивает через неподтверждённые.
+	// Параллельные Ack и SeekSubscription разрешает репозиторий.
+	return u.subs.RaiseOffset(ctx, subscriptionID, sub.Offset, committed)
+}
+
+// Nack возвращает неподтверждённое сообщение в очередь: оно будет доставлено
+// повторно при следующем Consume, не дожидаясь истечения ack timeout.
+func (u *ConsumeUseCase) Nack(ctx context.Context, subscriptionID, deliveryID string) error {
+	if _, err := u.subs.Get(ctx, subscriptionID); err != nil {
+		return ErrSubscriptionNotFound
+	}
+	return deliveryError(u.pending.Touch(ctx, subscriptionID, deliveryID, time.Now()))
+}
+
+// ExtendAckDeadline продлевает срок подтверждения доставки на extension от текущего момента,
+// чтобы долгая обработка не приводила к повторной доставке.
+func (u *ConsumeUseCase) ExtendAckDeadline(ctx context.Context, subscriptionID, deliveryID string, extension time.Duration) error {
+	if _, err := u.subs.Get(ctx, subscriptionID); err != nil {
+		return ErrSubscriptionNotFound
+	}
+	return deliveryError(u.pending.Touch(ctx, subscriptionID, deliveryID, time.Now().Add(extension)))
+}
+
+func deliveryError(err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return ErrDeliveryNotFound
 	}
-	return u.subs.AdvanceOffset(ctx, subscriptionID, ackedOffset+1)
+	return err
 }
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"queue-service/internal/domain"
@@ -16,10 +17,10 @@ func TestConsumeUseCase_Consume_atMostOnce(t *testing.T) {
 	topics := memory.NewTopicRepository()
 	queues := memory.NewQueueRepository()
 
-	topicUC := NewTopicUseCase(topics, queues)
-	pub := NewPublishUseCase(topics, queues, msgs, 1024)
-	subUC := NewSubscriptionUseCase(subs, topics, queues, 30)
-	consumeUC := NewConsumeUseCase(subs, msgs, pending)
+	topicUC := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	subUC := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := NewConsumeUseCase(subs, msgs, pending, nil)
 
 	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
 	_, _ = pub.Publish(ctx, "orders", "0", []byte("m1"), "", nil)
@@ -51,10 +52,10 @@ func TestConsumeUseCase_Consume_atLeastOnce_and_Ack(t *testing.T) {
 	topics := memory.NewTopicRepository()
 	queues := memory.NewQueueRepository()
 
-	topicUC := NewTopicUseCase(topics, queues)
-	pub := NewPublishUseCase(topics, queues, msgs, 1024)
-	subUC := NewSubscriptionUseCase(subs, topics, queues, 30)
-	consumeUC := NewConsumeUseCase(subs, msgs, pending)
+	topicUC := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	subUC := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := NewConsumeUseCase(subs, msgs, pending, nil)
 
 	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
 	_, _ = pub.Publish(ctx, "orders", "0", []byte("m1"), "", nil)
@@ -89,6 +90,7 @@ func TestConsumeUseCase_Consume_subscriptionNotFound(t *testing.T) {
 		memory.NewSubscriptionRepository(),
 		memory.NewMessageRepository(),
 		memory.NewPendingDeliveryRepository(),
+		nil,
 	)
 	_, err := consumeUC.Consume(ctx, "sub-nonexistent", 10)
 	if err != ErrSubscriptionNotFound {
@@ -102,9 +104,165 @@ func TestConsumeUseCase_Ack_subscriptionNotFound(t *testing.T) {
 		memory.NewSubscriptionRepository(),
 		memory.NewMessageRepository(),
 		memory.NewPendingDeliveryRepository(),
+		nil,
 	)
 	err := consumeUC.Ack(ctx, "sub-nonexistent", "delivery-1")
 	if err != ErrSubscriptionNotFound {
 		t.Errorf("want ErrSubscriptionNotFound, got %v", err)
 	}
 }
+
+func TestConsumeUseCase_atLeastOnce_noDuplicateWhilePending(t *testing.T) {
+	ctx := context.Background()
+	subs := memory.NewSubscriptionRepository()
+	msgs := memory.NewMessageRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+
+	topicUC := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	subUC := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := NewConsumeUseCase(subs, msgs, pending, nil)
+
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+	_, _ = pub.Publish(ctx, "orders", "0", []byte("m1"), "", nil)
+	_, _ = pub.Publish(ctx, "orders", "0", []byte("m2"), "", nil)
+	sub, _ := subUC.Subscribe(ctx, "orders", "0", "g1", domain.AtLeastOnce)
+
+	first, _ := consumeUC.Consume(ctx, sub.ID, 1)
+	if len(first) != 1 || string(first[0].Payload) != "m1" {
+		t.Fatalf("first consume: %+v", first)
+	}
+	// m1 ещё не подтверждён, но повторно его выдавать нельзя
+	second, _ := consumeUC.Consume(ctx, sub.ID, 10)
+	if len(second) != 1 || string(second[0].Payload) != "m2" {
+		t.Fatalf("second consume should return only m2, got %+v", second)
+	}
+
+	// ack m2, затем m1: смещение не должно откатиться назад
+	_ = consumeUC.Ack(ctx, sub.ID, second[0].DeliveryID)
+	_ = consumeUC.Ack(ctx, sub.ID, first[0].DeliveryID)
+	got, _ := subUC.GetSubscription(ctx, sub.ID)
+	if got.Offset != 2 {
+		t.Errorf("offset want 2, got %d", got.Offset)
+	}
+}
+
+func TestConsumeUseCase_Nack_redeliversImmediately(t *testing.T) {
+	ctx := context.Background()
+	subs := memory.NewSubscriptionRepository()
+	msgs := memory.NewMessageRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+
+	topicUC := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	subUC := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := NewConsumeUseCase(subs, msgs, pending, nil)
+
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+	_, _ = pub.Publish(ctx, "orders", "0", []byte("m1"), "", nil)
+	sub, _ := subUC.Subscribe(ctx, "orders", "0", "g1", domain.AtLeastOnce)
+
+	out, _ := consumeUC.Consume(ctx, sub.ID, 10)
+	if err := consumeUC.Nack(ctx, sub.ID, out[0].DeliveryID); err != nil {
+		t.Fatalf("Nack: %v", err)
+	}
+	again, _ := consumeUC.Consume(ctx, sub.ID, 10)
+	if len(again) != 1 || again[0].DeliveryID != out[0].DeliveryID {
+		t.Fatalf("want redelivery of %s, got %+v", out[0].DeliveryID, again)
+	}
+	// срок продлён при повторной доставке — третий опрос пуст
+	third, _ := consumeUC.Consume(ctx, sub.ID, 10)
+	if len(third) != 0 {
+		t.Errorf("redelivered message must not be returned again before timeout, got %d", len(third))
+	}
+}
+
+func TestConsumeUseCase_Ack_outOfOrder(t *testing.T) {
+	ctx := context.Background()
+	subs := memory.NewSubscriptionRepository()
+	msgs := memory.NewMessageRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+
+	topicUC := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	subUC := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := NewConsumeUseCase(subs, msgs, pending, nil)
+
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+	for _, p := range []string{"m0", "m1", "m2", "m3"} {
+		_, _ = pub.Publish(ctx, "orders", "0", []byte(p), "", nil)
+	}
+	sub, _ := subUC.Subscribe(ctx, "orders", "0", "g1", domain.AtLeastOnce)
+	out, _ := consumeUC.Consume(ctx, sub.ID, 3)
+	offset := func() int64 {
+		s, _ := subUC.GetSubscription(ctx, sub.ID)
+		return s.Offset
+	}
+
+	// Подтверждение m2 при неподтверждённых m0 и m1 не сдвигает смещение.
+	if err := consumeUC.Ack(ctx, sub.ID, out[2].DeliveryID); err != nil {
+		t.Fatalf("Ack m2: %v", err)
+	}
+	if got := offset(); got != 0 {
+		t.Errorf("offset after out-of-order ack: want 0, got %d", got)
+	}
+	// Уже подтверждённое m2 не выдаётся снова: чтение продолжается с m3.
+	next, _ := consumeUC.Consume(ctx, sub.ID, 10)
+	if len(next) != 1 || string(next[0].Payload) != "m3" {
+		t.Fatalf("next consume: %v", next)
+	}
+	_ = consumeUC.Ack(ctx, sub.ID, out[0].DeliveryID)
+	if got := offset(); got != 1 {
+		t.Errorf("offset after ack of m0: want 1, got %d", got)
+	}
+	_ = consumeUC.Ack(ctx, sub.ID, out[1].DeliveryID)
+	if got := offset(); got != 3 {
+		t.Errorf("offset after ack of m1: want 3, got %d", got)
+	}
+	_ = consumeUC.Ack(ctx, sub.ID, next[0].DeliveryID)
+	if got := offset(); got != 4 {
+		t.Errorf("offset after all acks: want 4, got %d", got)
+	}
+}
+
+func TestConsumeUseCase_Ack_concurrent(t *testing.T) {
+	ctx := context.Background()
+	subs := memory.NewSubscriptionRepository()
+	msgs := memory.NewMessageRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+
+	topicUC := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	subUC := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := NewConsumeUseCase(subs, msgs, pending, nil)
+
+	const n = 200
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+	for range n {
+		_, _ = pub.Publish(ctx, "orders", "0", []byte("m"), "", nil)
+	}
+	sub, _ := subUC.Subscribe(ctx, "orders", "0", "g1", domain.AtLeastOnce)
+	out, _ := consumeUC.Consume(ctx, sub.ID, n)
+
+	// Ack с устаревшим смещением не должен откатить более позднее.
+	var wg sync.WaitGroup
+	for _, m := range out {
+		wg.Go(func() {
+			if err := consumeUC.Ack(ctx, sub.ID, m.DeliveryID); err != nil {
+				t.Errorf("Ack: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	if s, _ := subUC.GetSubscription(ctx, sub.ID); s.Offset != n {
+		t.Errorf("offset after all acks: want %d, got %d", n, s.Offset)
+	}
+}
@@ -17,10 +17,10 @@ func TestFullFlow(t *testing.T) {
 	subs := memory.NewSubscriptionRepository()
 	pending := memory.NewPendingDeliveryRepository()
 
-	topicUC := NewTopicUseCase(topics, queues)
-	pub := NewPublishUseCase(topics, queues, msgs, 1024*1024)
-	subUC := NewSubscriptionUseCase(subs, topics, queues, 30)
-	consumeUC := NewConsumeUseCase(subs, msgs, pending)
+	topicUC := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	pub := NewPublishUseCase(topics, queues, msgs, 1024*1024, MemoryBudget{}, nil)
+	subUC := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := NewConsumeUseCase(subs, msgs, pending, nil)
 
 	// Create topic
 	_, err := topicUC.CreateTopic(ctx, "orders", 10000)
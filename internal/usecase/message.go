@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"queue-service/internal/domain"
+)
+
+var ErrQueueNotFound = errors.New("queue not found")
+var ErrMessageNotFound = errors.New("message not found")
+
+const (
+	// maxBrowseLimit ограничивает число сообщений в одном ответе Browse.
+	maxBrowseLimit = 1000
+	// maxBrowseScan ограничивает число просмотренных сообщений за один вызов
+	// Browse с фильтром, чтобы поиск по большой очереди не занимал её надолго.
+	maxBrowseScan = 10000
+)
+
+// MessageFilter отбирает сообщения в Browse. Пустые поля не проверяются.
+type MessageFilter struct {
+	Key             string
+	Headers         map[string]string // все перечисленные заголовки должны совпадать
+	PayloadContains []byte
+}
+
+func (f MessageFilter) match(m *domain.Message) bool {
+	if f.Key != "" && m.Key != f.Key {
+		return false
+	}
+	for k, v := range f.Headers {
+		if got, ok := m.Headers[k]; !ok || got != v {
+			return false
+		}
+	}
+	return len(f.PayloadContains) == 0 || bytes.Contains(m.Payload, f.PayloadContains)
+}
+
+// MessageUseCase даёт доступ к журналу очереди по смещениям, без подписки.
+type MessageUseCase struct {
+	topics   domain.TopicRepository
+	queues   domain.QueueRepository
+	messages domain.MessageRepository
+}
+
+func NewMessageUseCase(
+	topics domain.TopicRepository,
+	queues domain.QueueRepository,
+	messages domain.MessageRepository,
+) *MessageUseCase {
+	return &MessageUseCase{
+		topics:   topics,
+		queues:   queues,
+		messages: messages,
+	}
+}
+
+// Read читает до limit сообщений очереди начиная со смещения offset.
+func (u *MessageUseCase) Read(ctx context.Context, topicName, queueID string, offset int64, limit int) ([]*domain.Message, error) {
+	if err := u.checkQueue(ctx, topicName, queueID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 1
+	}
+	return u.messages.Read(ctx, topicName, queueID, int(offset), limit)
+}
+
+// Browse возвращает до limit сообщений очереди начиная с from, подходящих под
+// filter, не затрагивая подписки. next — смещение, с которого продолжать
+// просмотр; равно high-water mark, если очередь просмотрена до конца.
+func (u *MessageUseCase) Browse(ctx context.Context, topicName, queueID string, from int64, limit int, filter MessageFilter) (msgs []*domain.Message, next int64, err error) {
+	if err := u.checkQueue(ctx, topicName, queueID); err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 || limit > maxBrowseLimit {
+		limit = maxBrowseLimit
+	}
+	start, end, err := u.messages.Offsets(ctx, topicName, queueID)
+	if err != nil {
+		return nil, 0, err
+	}
+	next = from
+	if next < start {
+		next = start
+	}
+	for scanned := 0; len(msgs) < limit && next < end && scanned < maxBrowseScan; {
+		batch, err := u.messages.Read(ctx, topicName, queueID, int(next), limit)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for _, m := range batch {
+			next = m.Offset + 1
+			scanned++
+			if filter.match(m) {
+				msgs = append(msgs, m)
+				if len(msgs) == limit {
+					break
+				}
+			}
+		}
+	}
+	return msgs, next, nil
+}
+
+// GetMessage ищет сообщение очереди по ID.
+func (u *MessageUseCase) GetMessage(ctx context.Context, topicName, queueID, messageID string) (*domain.Message, error) {
+	if err := u.checkQueue(ctx, topicName, queueID); err != nil {
+		return nil, err
+	}
+	m, err := u.messages.GetByID(ctx, topicName, queueID, messageID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, ErrMessageNotFound
+	}
+	return m, err
+}
+
+// Offsets возвращает первое доступное смещение и high-water mark очереди.
+func (u *MessageUseCase) Offsets(ctx context.Context, topicName, queueID string) (start, end int64, err error) {
+	if err := u.checkQueue(ctx, topicName, queueID); err != nil {
+		return 0, 0, err
+	}
+	return u.messages.Offsets(ctx, topicName, queueID)
+}
+
+// OffsetForTime возвращает смещение первого сообщения, опубликованного не раньше ts.
+// Если таких нет, возвращается high-water mark.
+func (u *MessageUseCase) OffsetForTime(ctx context.Context, topicName, queueID string, ts time.Time) (int64, error) {
+	if err := u.checkQueue(ctx, topicName, queueID); err != nil {
+		return 0, err
+	}
+	return u.messages.OffsetForTime(ctx, topicName, queueID, ts)
+}
+
+func (u *MessageUseCase) checkQueue(ctx context.Context, topicName, queueID string) error {
+	if _, err := u.topics.Get(ctx, topicName); err != nil {
+		return ErrTopicNotFound
+	}
+	if _, err := u.queues.Get(ctx, topicName, queueID); err != nil {
+		return ErrQueueNotFound
+	}
+	return nil
+}
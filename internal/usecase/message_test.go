@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"queue-service/internal/repository/memory"
+)
+
+func TestMessageUseCase_Read_Offsets(t *testing.T) {
+	ctx := context.Background()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	topicUC := NewTopicUseCase(topics, queues, msgs, memory.NewSubscriptionRepository(), memory.NewPendingDeliveryRepository())
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	uc := NewMessageUseCase(topics, queues, msgs)
+
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+	before := time.Now()
+	for _, p := range []string{"m1", "m2", "m3"} {
+		_, _ = pub.Publish(ctx, "orders", "0", []byte(p), "", nil)
+	}
+
+	start, end, err := uc.Offsets(ctx, "orders", "0")
+	if err != nil || start != 0 || end != 3 {
+		t.Fatalf("Offsets: start=%d end=%d err=%v", start, end, err)
+	}
+	out, err := uc.Read(ctx, "orders", "0", 1, 10)
+	if err != nil || len(out) != 2 || string(out[0].Payload) != "m2" {
+		t.Fatalf("Read: err=%v out=%+v", err, out)
+	}
+
+	if off, _ := uc.OffsetForTime(ctx, "orders", "0", before); off != 0 {
+		t.Errorf("OffsetForTime(before) want 0, got %d", off)
+	}
+	if off, _ := uc.OffsetForTime(ctx, "orders", "0", time.Now().Add(time.Hour)); off != 3 {
+		t.Errorf("OffsetForTime(future) want high-water mark 3, got %d", off)
+	}
+}
+
+func TestMessageUseCase_notFound(t *testing.T) {
+	ctx := context.Background()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	topicUC := NewTopicUseCase(topics, queues, memory.NewMessageRepository(), memory.NewSubscriptionRepository(), memory.NewPendingDeliveryRepository())
+	uc := NewMessageUseCase(topics, queues, memory.NewMessageRepository())
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+
+	if _, err := uc.Read(ctx, "missing", "0", 0, 1); err != ErrTopicNotFound {
+		t.Errorf("want ErrTopicNotFound, got %v", err)
+	}
+	if _, _, err := uc.Offsets(ctx, "orders", "9"); err != ErrQueueNotFound {
+		t.Errorf("want ErrQueueNotFound, got %v", err)
+	}
+}
+
+func TestMessageUseCase_Browse_GetMessage(t *testing.T) {
+	ctx := context.Background()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	topicUC := NewTopicUseCase(topics, queues, msgs, memory.NewSubscriptionRepository(), memory.NewPendingDeliveryRepository())
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	uc := NewMessageUseCase(topics, queues, msgs)
+
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+	var ids []string
+	for i, p := range []string{"paid 1", "new 2", "paid 3", "new 4", "paid 5"} {
+		key := "a"
+		if i%2 == 1 {
+			key = "b"
+		}
+		m, _ := pub.Publish(ctx, "orders", "0", []byte(p), key, map[string]string{"n": p[len(p)-1:]})
+		ids = append(ids, m.ID)
+	}
+
+	out, next, err := uc.Browse(ctx, "orders", "0", 0, 2, MessageFilter{})
+	if err != nil || len(out) != 2 || next != 2 {
+		t.Fatalf("Browse: err=%v next=%d out=%d", err, next, len(out))
+	}
+	out, next, _ = uc.Browse(ctx, "orders", "0", 1, 2, MessageFilter{PayloadContains: []byte("paid")})
+	if len(out) != 2 || out[0].Offset != 2 || out[1].Offset != 4 || next != 5 {
+		t.Errorf("payload filter: next=%d out=%+v", next, out)
+	}
+	out, _, _ = uc.Browse(ctx, "orders", "0", 0, 10, MessageFilter{Key: "b", Headers: map[string]string{"n": "4"}})
+	if len(out) != 1 || out[0].ID != ids[3] {
+		t.Errorf("key and header filter: %+v", out)
+	}
+	if _, _, err := uc.Browse(ctx, "orders", "9", 0, 10, MessageFilter{}); err != ErrQueueNotFound {
+		t.Errorf("want ErrQueueNotFound, got %v", err)
+	}
+
+	m, err := uc.GetMessage(ctx, "orders", "0", ids[1])
+	if err != nil || string(m.Payload) != "new 2" {
+		t.Errorf("GetMessage: %v %+v", err, m)
+	}
+	if _, err := uc.GetMessage(ctx, "orders", "0", "missing"); err != ErrMessageNotFound {
+		t.Errorf("want ErrMessageNotFound, got %v", err)
+	}
+
+	// Просмотр не меняет смещения: те же сообщения доступны повторно.
+	if out, _, _ := uc.Browse(ctx, "orders", "0", 0, 10, MessageFilter{}); len(out) != 5 {
+		t.Errorf("second Browse: %d messages", len(out))
+	}
+}
@@ -0,0 +1,32 @@
+package usecase
+
+import "time"
+
+// Metrics получает события use case для мониторинга (реализация с
+// Prometheus — internal/metrics). Вызовы не должны блокироваться. Очередь
+// определяют пространство имён, топик и ID очереди.
+type Metrics interface {
+	// Published — сообщение записано в очередь за d.
+	Published(namespace, topic, queue string, d time.Duration)
+	// Consumed — Consume выдал n сообщений за d, включая повторные доставки.
+	Consumed(namespace, topic, queue string, n int, d time.Duration)
+	// Redelivered — из выданных n сообщений доставлены повторно.
+	Redelivered(namespace, topic, queue string, n int)
+	// Acked — доставка подтверждена за d.
+	Acked(namespace, topic, queue string, d time.Duration)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) Published(string, string, string, time.Duration)     {}
+func (nopMetrics) Consumed(string, string, string, int, time.Duration) {}
+func (nopMetrics) Redelivered(string, string, string, int)             {}
+func (nopMetrics) Acked(string, string, string, time.Duration)         {}
+
+// orNop заменяет nil на Metrics, который ничего не делает.
+func orNop(m Metrics) Metrics {
+	if m == nil {
+		return nopMetrics{}
+	}
+	return m
+}
@@ -5,9 +5,14 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	"queue-service/internal/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var ErrMessageTooLarge = errors.New("message exceeds max size")
@@ -16,7 +21,9 @@ type PublishUseCase struct {
 	topics   domain.TopicRepository
 	queues   domain.QueueRepository
 	messages domain.MessageRepository
-	maxSize  int
+	maxSize  atomic.Int64
+	budget   atomic.Pointer[MemoryBudget]
+	metrics  Metrics
 }
 
 func NewPublishUseCase(
@@ -24,26 +31,63 @@ func NewPublishUseCase(
 	queues domain.QueueRepository,
 	messages domain.MessageRepository,
 	maxMessageSize int,
+	budget MemoryBudget,
+	metrics Metrics,
 ) *PublishUseCase {
-	return &PublishUseCase{
+	u := &PublishUseCase{
 		topics:   topics,
 		queues:   queues,
 		messages: messages,
-		maxSize:  maxMessageSize,
+		metrics:  orNop(metrics),
+	}
+	u.maxSize.Store(int64(maxMessageSize))
+	u.budget.Store(&budget)
+	return u
+}
+
+// SetLimits меняет максимальный размер сообщения и бюджет памяти на ходу.
+// Уже записанные сообщения не вытесняются, даже если новый бюджет меньше.
+func (u *PublishUseCase) SetLimits(maxMessageSize int, budget MemoryBudget) error {
+	if maxMessageSize <= 0 {
+		return fmt.Errorf("max message size must be positive: %d", maxMessageSize)
 	}
+	u.maxSize.Store(int64(maxMessageSize))
+	u.budget.Store(&budget)
+	return nil
 }
 
+// Publish записывает сообщение в очередь. Родительская трасса берётся из ctx
+// (метаданные gRPC), а если её там нет — из заголовка traceparent сообщения.
+// Контекст span публикации сохраняется в заголовках, и потребители
+// продолжают ту же трассу.
 func (u *PublishUseCase) Publish(ctx context.Context, topicName, queueID string, payload []byte, key string, headers map[string]string) (*domain.Message, error) {
-	if len(payload) > u.maxSize {
+	if queueID == "" {
+		queueID = "0"
+	}
+	parent := ctx
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		parent = messageTraceContext(ctx, headers)
+	}
+	ctx, span := tracer().Start(parent, "publish "+topicName,
+		producerSpan,
+		trace.WithAttributes(destinationAttrs(topicName, queueID)...))
+	msg, err := u.publish(ctx, topicName, queueID, payload, key, withTraceHeaders(ctx, headers))
+	if err == nil {
+		span.SetAttributes(attribute.String("messaging.message.id", msg.ID))
+	}
+	endSpan(span, err)
+	return msg, err
+}
+
+func (u *PublishUseCase) publish(ctx context.Context, topicName, queueID string, payload []byte, key string, headers map[string]string) (*domain.Message, error) {
+	begin := time.Now()
+	if int64(len(payload)) > u.maxSize.Load() {
 		return nil, ErrMessageTooLarge
 	}
-	_, err := u.topics.Get(ctx, topicName)
+	topic, err := u.topics.Get(ctx, topicName)
 	if err != nil {
 		return nil, ErrTopicNotFound
 	}
-	if queueID == "" {
-		queueID = "0"
-	}
 	_, err = u.queues.Get(ctx, topicName, queueID)
 	if err != nil {
 		return nil, err
@@ -57,12 +101,114 @@ func (u *PublishUseCase) Publish(ctx context.Context, topicName, queueID string,
 		Headers:   headers,
 		CreatedAt: time.Now(),
 	}
-	if err := u.messages.Append(ctx, msg); err != nil {
+	if err := u.reserve(ctx, topicName, queueID, msg.Size()); err != nil {
+		return nil, err
+	}
+	_, store := tracer().Start(ctx, "store "+topicName)
+	err = u.messages.Append(ctx, msg)
+	if err == nil {
+		store.SetAttributes(attribute.Int64("messaging.message.offset", msg.Offset))
+	}
+	endSpan(store, err)
+	if err != nil {
 		return nil, err
 	}
+	// Ограничение хранения: в очереди остаются последние RetentionMessages сообщений.
+	if keep := int64(topic.RetentionMessages); keep > 0 && msg.Offset >= keep {
+		_, _ = u.messages.Truncate(ctx, topicName, queueID, msg.Offset+1-keep)
+	}
+	u.metrics.Published(topic.Namespace, topicName, queueID, time.Since(begin))
 	return msg, nil
 }
 
+// Record — сообщение пакета PublishBatch.
+type Record struct {
+	Payload []byte
+	Key     string
+	Headers map[string]string
+}
+
+// PublishBatch записывает records в очередь одним куском: смещения сообщений
+// идут подряд, а при ошибке не записывается ни одно. Бюджет памяти
+// проверяется для всего пакета. Span публикации один на пакет; если в ctx
+// трассы нет, он связывается (links) с трассами из заголовков записей.
+func (u *PublishUseCase) PublishBatch(ctx context.Context, topicName, queueID string, records []Record) ([]*domain.Message, error) {
+	if queueID == "" {
+		queueID = "0"
+	}
+	opts := []trace.SpanStartOption{
+		producerSpan,
+		trace.WithAttributes(destinationAttrs(topicName, queueID)...),
+		trace.WithAttributes(attribute.Int("messaging.batch.message_count", len(records))),
+	}
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		for _, r := range records {
+			if link := trace.LinkFromContext(messageTraceContext(ctx, r.Headers)); link.SpanContext.IsValid() {
+				opts = append(opts, trace.WithLinks(link))
+			}
+		}
+	}
+	ctx, span := tracer().Start(ctx, "publish "+topicName, opts...)
+	msgs, err := u.publishBatch(ctx, topicName, queueID, records)
+	endSpan(span, err)
+	return msgs, err
+}
+
+func (u *PublishUseCase) publishBatch(ctx context.Context, topicName, queueID string, records []Record) ([]*domain.Message, error) {
+	begin := time.Now()
+	for _, r := range records {
+		if int64(len(r.Payload)) > u.maxSize.Load() {
+			return nil, ErrMessageTooLarge
+		}
+	}
+	topic, err := u.topics.Get(ctx, topicName)
+	if err != nil {
+		return nil, ErrTopicNotFound
+	}
+	if _, err := u.queues.Get(ctx, topicName, queueID); err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	now := time.Now()
+	msgs := make([]*domain.Message, len(records))
+	var size int64
+	for i, r := range records {
+		msgs[i] = &domain.Message{
+			ID:        genID(),
+			TopicName: topicName,
+			QueueID:   queueID,
+			Payload:   r.Payload,
+			Key:       r.Key,
+			Headers:   withTraceHeaders(ctx, r.Headers),
+			CreatedAt: now,
+		}
+		size += msgs[i].Size()
+	}
+	if err := u.reserve(ctx, topicName, queueID, size); err != nil {
+		return nil, err
+	}
+	_, store := tracer().Start(ctx, "store "+topicName)
+	err = u.messages.AppendBatch(ctx, msgs)
+	if err == nil {
+		store.SetAttributes(attribute.Int64("messaging.message.offset", msgs[0].Offset))
+	}
+	endSpan(store, err)
+	if err != nil {
+		return nil, err
+	}
+	last := msgs[len(msgs)-1].Offset
+	if keep := int64(topic.RetentionMessages); keep > 0 && last >= keep {
+		_, _ = u.messages.Truncate(ctx, topicName, queueID, last+1-keep)
+	}
+	d := time.Since(begin)
+	for range msgs {
+		u.metrics.Published(topic.Namespace, topicName, queueID, d)
+	}
+	return msgs, nil
+}
+
 func genID() string {
 	b := make([]byte, 12)
 	_, _ = rand.Read(b)
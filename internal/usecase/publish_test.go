@@ -12,9 +12,9 @@ func TestPublishUseCase_Publish(t *testing.T) {
 	topics := memory.NewTopicRepository()
 	queues := memory.NewQueueRepository()
 	msgs := memory.NewMessageRepository()
-	topicUC := NewTopicUseCase(topics, queues)
+	topicUC := NewTopicUseCase(topics, queues, msgs, memory.NewSubscriptionRepository(), memory.NewPendingDeliveryRepository())
 	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
-	pub := NewPublishUseCase(topics, queues, msgs, 1024)
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
 
 	msg, err := pub.Publish(ctx, "orders", "0", []byte("hello"), "key1", nil)
 	if err != nil {
@@ -37,7 +37,7 @@ func TestPublishUseCase_topicNotFound(t *testing.T) {
 	ctx := context.Background()
 	topics := memory.NewTopicRepository()
 	queues := memory.NewQueueRepository()
-	pub := NewPublishUseCase(topics, queues, memory.NewMessageRepository(), 1024)
+	pub := NewPublishUseCase(topics, queues, memory.NewMessageRepository(), 1024, MemoryBudget{}, nil)
 
 	_, err := pub.Publish(ctx, "nonexistent", "0", []byte("x"), "", nil)
 	if err != ErrTopicNotFound {
@@ -49,12 +49,70 @@ func TestPublishUseCase_messageTooLarge(t *testing.T) {
 	ctx := context.Background()
 	topics := memory.NewTopicRepository()
 	queues := memory.NewQueueRepository()
-	topicUC := NewTopicUseCase(topics, queues)
+	topicUC := NewTopicUseCase(topics, queues, memory.NewMessageRepository(), memory.NewSubscriptionRepository(), memory.NewPendingDeliveryRepository())
 	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
-	pub := NewPublishUseCase(topics, queues, memory.NewMessageRepository(), 5)
+	pub := NewPublishUseCase(topics, queues, memory.NewMessageRepository(), 5, MemoryBudget{}, nil)
 
 	_, err := pub.Publish(ctx, "orders", "0", []byte("hello world"), "", nil)
 	if err != ErrMessageTooLarge {
 		t.Errorf("want ErrMessageTooLarge, got %v", err)
 	}
 }
+
+func TestPublishUseCase_Publish_retention(t *testing.T) {
+	ctx := context.Background()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	topicUC := NewTopicUseCase(topics, queues, msgs, memory.NewSubscriptionRepository(), memory.NewPendingDeliveryRepository())
+	_, _ = topicUC.CreateTopic(ctx, "orders", 3)
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		m, _ := pub.Publish(ctx, "orders", "0", []byte("m"), "", nil)
+		ids = append(ids, m.ID)
+	}
+	if start, end, _ := msgs.Offsets(ctx, "orders", "0"); start != 2 || end != 5 {
+		t.Errorf("want offsets [2, 5), got [%d, %d)", start, end)
+	}
+	if _, err := msgs.GetByID(ctx, "orders", "0", ids[1]); err == nil {
+		t.Error("message beyond retention must be gone")
+	}
+	if m, err := msgs.GetByID(ctx, "orders", "0", ids[2]); err != nil || m.Offset != 2 {
+		t.Errorf("retained message: %v %+v", err, m)
+	}
+}
+
+func TestPublishUseCase_PublishBatch(t *testing.T) {
+	ctx := context.Background()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	topicUC := NewTopicUseCase(topics, queues, msgs, memory.NewSubscriptionRepository(), memory.NewPendingDeliveryRepository())
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+	pub := NewPublishUseCase(topics, queues, msgs, 16, MemoryBudget{Limit: 700}, nil)
+
+	_, _ = pub.Publish(ctx, "orders", "0", []byte("m0"), "", nil)
+	out, err := pub.PublishBatch(ctx, "orders", "0", []Record{{Payload: []byte("m1"), Key: "k"}, {Payload: []byte("m2")}})
+	if err != nil {
+		t.Fatalf("PublishBatch: %v", err)
+	}
+	if len(out) != 2 || out[0].Offset != 1 || out[1].Offset != 2 || out[0].Key != "k" {
+		t.Errorf("batch: %+v %+v", out[0], out[1])
+	}
+
+	// Пакет с отклонённым сообщением не записывается целиком: по размеру
+	// сообщения и по бюджету памяти для всего пакета.
+	for name, batch := range map[string][]Record{
+		"too large": {{Payload: []byte("ok")}, {Payload: make([]byte, 17)}},
+		"memory":    {{Payload: make([]byte, 16)}, {Payload: make([]byte, 16)}},
+	} {
+		if _, err := pub.PublishBatch(ctx, "orders", "0", batch); err == nil {
+			t.Errorf("%s: batch accepted", name)
+		}
+	}
+	if _, end, _ := msgs.Offsets(ctx, "orders", "0"); end != 3 {
+		t.Errorf("high watermark after rejected batches: %d", end)
+	}
+}
@@ -0,0 +1,440 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"queue-service/internal/domain"
+)
+
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// Quota — ограничения клиента или топика; 0 — без ограничения. MaxTopics и
+// MaxSubscriptions действуют только для клиентов.
+type Quota struct {
+	PublishMessagesPerSec float64
+	PublishBytesPerSec    float64 // по размеру тела сообщения
+	ConsumeMessagesPerSec float64
+	MaxTopics             int
+	MaxSubscriptions      int
+}
+
+// QuotaPolicy — квоты клиентов и топиков. Незаданные поля Principals и
+// Topics берутся из Principal и Topic.
+type QuotaPolicy struct {
+	Principal  Quota            // каждому клиенту
+	Topic      Quota            // каждому топику каждого пространства имён
+	Principals map[string]Quota // по имени клиента
+	Topics     map[string]Quota // по имени топика
+	// Burst — сколько секунд лимита можно израсходовать разом после простоя;
+	// 0 — одна секунда.
+	Burst time.Duration
+}
+
+// QuotaUseCase ограничивает частоту публикации и чтения корзинами токенов
+// (token bucket) на клиента и на топик, а также число созданных клиентом
+// топиков и подписок. Вызов проходит, только если токенов хватает во всех
+// его корзинах; иначе возвращается ErrRateLimited и время, через которое
+// стоит повторить.
+type QuotaUseCase struct {
+	topics domain.TopicRepository
+	subs   domain.SubscriptionRepository
+	state  atomic.Pointer[quotaState]
+
+	now       func() time.Time
+	mu        sync.Mutex
+	buckets   map[bucketKey]*tokenBucket
+	lastSweep time.Time
+}
+
+type quotaState struct {
+	policy QuotaPolicy
+	burst  float64 // секунды
+	// rates — есть ли хоть один лимит частоты; без них вызовы не блокируются.
+	rates bool
+}
+
+// bucketKind — что ограничивает корзина.
+type bucketKind uint8
+
+const (
+	publishMessages bucketKind = iota
+	publishBytes
+	consumeMessages
+)
+
+type bucketKey struct {
+	kind  bucketKind
+	topic bool
+	name  string // клиент или пространство/топик
+}
+
+// tokenBucket — корзина токенов; скорость и ёмкость берутся из текущих квот
+// при каждом обращении.
+type tokenBucket struct {
+	tokens float64
+	rate   float64
+	last   time.Time
+}
+
+// sweepInterval — как часто удаляются полные корзины: полная корзина ничем
+// не отличается от новой, поэтому удаление ничего не меняет.
+const sweepInterval = time.Minute
+
+func NewQuotaUseCase(topics domain.TopicRepository, subs domain.SubscriptionRepository, policy QuotaPolicy) (*QuotaUseCase, error) {
+	u := &QuotaUseCase{topics: topics, subs: subs, now: time.Now, buckets: make(map[bucketKey]*tokenBucket)}
+	if err := u.SetPolicy(policy); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// SetPolicy проверяет и применяет новые квоты. Накопленные токены
+// сохраняются, новые скорости действуют со следующего вызова.
+func (u *QuotaUseCase) SetPolicy(p QuotaPolicy) error {
+	st, err := newQuotaState(p)
+	if err != nil {
+		return err
+	}
+	u.state.Store(st)
+	return nil
+}
+
+// Validate проверяет квоты, не применяя их.
+func (p QuotaPolicy) Validate() error {
+	_, err := newQuotaState(p)
+	return err
+}
+
+func newQuotaState(p QuotaPolicy) (*quotaState, error) {
+	st := &quotaState{policy: p, burst: p.Burst.Seconds()}
+	if p.Burst < 0 {
+		return nil, fmt.Errorf("quota burst must not be negative: %s", p.Burst)
+	}
+	if st.burst == 0 {
+		st.burst = 1
+	}
+	check := func(who string, q Quota) error {
+		if q.PublishMessagesPerSec < 0 || q.PublishBytesPerSec < 0 || q.ConsumeMessagesPerSec < 0 || q.MaxTopics < 0 || q.MaxSubscriptions < 0 {
+			return fmt.Errorf("quota of %s must not be negative", who)
+		}
+		if q.PublishMessagesPerSec > 0 || q.PublishBytesPerSec > 0 || q.ConsumeMessagesPerSec > 0 {
+			st.rates = true
+		}
+		return nil
+	}
+	if err := check("principals", p.Principal); err != nil {
+		return nil, err
+	}
+	if err := check("topics", p.Topic); err != nil {
+		return nil, err
+	}
+	for name, q := range p.Principals {
+		if err := check("principal "+name, q); err != nil {
+			return nil, err
+		}
+	}
+	for name, q := range p.Topics {
+		if err := check("topic "+name, q); err != nil {
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+// Enabled сообщает, задан ли хоть один лимит частоты.
+func (u *QuotaUseCase) Enabled() bool {
+	return u.state.Load().rates
+}
+
+// AllowPublish списывает одно сообщение и size байт с корзин клиента из ctx
+// и топика. При нехватке токенов ничего не списывает и возвращает
+// ErrRateLimited и время ожидания.
+func (u *QuotaUseCase) AllowPublish(ctx context.Context, topicName string, size int) (time.Duration, error) {
+	return u.AllowPublishBatch(ctx, topicName, 1, size)
+}
+
+// AllowPublishBatch — то же для пакета из count сообщений общим размером
+// size байт: пакет списывается или отклоняется целиком.
+func (u *QuotaUseCase) AllowPublishBatch(ctx context.Context, topicName string, count, size int) (time.Duration, error) {
+	st := u.state.Load()
+	if !st.rates {
+		return 0, nil
+	}
+	principal, topic := Principal(ctx), topicKey(ctx, topicName)
+	pq, tq := st.principalQuota(principal), st.topicQuota(topicName)
+	wait := u.take(st, []take{
+		{bucketKey{publishMessages, false, principal}, pq.PublishMessagesPerSec, float64(count)},
+		{bucketKey{publishMessages, true, topic}, tq.PublishMessagesPerSec, float64(count)},
+		{bucketKey{publishBytes, false, principal}, pq.PublishBytesPerSec, float64(size)},
+		{bucketKey{publishBytes, true, topic}, tq.PublishBytesPerSec, float64(size)},
+	})
+	if wait > 0 {
+		return wait, rateLimited(ctx, "publish", topicName, wait)
+	}
+	return 0, nil
+}
+
+// ReserveConsume списывает до want сообщений с корзин чтения клиента и
+// топика и возвращает, сколько удалось. Неиспользованное возвращается
+// через ReleaseConsume. Если нет ни одного токена — ErrRateLimited и время
+// ожидания.
+func (u *QuotaUseCase) ReserveConsume(ctx context.Context, topicName string, want int) (int, time.Duration, error) {
+	st := u.state.Load()
+	if !st.rates || want <= 0 {
+		return want, 0, nil
+	}
+	principal, topic := Principal(ctx), topicKey(ctx, topicName)
+	pq, tq := st.principalQuota(principal), st.topicQuota(topicName)
+	takes := []take{
+		{bucketKey{consumeMessages, false, principal}, pq.ConsumeMessagesPerSec, 1},
+		{bucketKey{consumeMessages, true, topic}, tq.ConsumeMessagesPerSec, 1},
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	now := u.now()
+	n := float64(want)
+	for _, t := range takes {
+		if t.rate > 0 {
+			n = math.Min(n, math.Floor(u.bucket(st, t, now).tokens))
+		}
+	}
+	if n < 1 {
+		wait := u.waitLocked(st, takes, now)
+		return 0, wait, rateLimited(ctx, "consume", topicName, wait)
+	}
+	for _, t := range takes {
+		if t.rate > 0 {
+			u.bucket(st, t, now).tokens -= n
+		}
+	}
+	u.sweepLocked(st, now)
+	return int(n), 0, nil
+}
+
+// ReleaseConsume возвращает в корзины чтения n зарезервированных, но не
+// выданных сообщений.
+func (u *QuotaUseCase) ReleaseConsume(ctx context.Context, topicName string, n int) {
+	st := u.state.Load()
+	if !st.rates || n <= 0 {
+		return
+	}
+	principal, topic := Principal(ctx), topicKey(ctx, topicName)
+	pq, tq := st.principalQuota(principal), st.topicQuota(topicName)
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	now := u.now()
+	for _, t := range []take{
+		{bucketKey{consumeMessages, false, principal}, pq.ConsumeMessagesPerSec, 1},
+		{bucketKey{consumeMessages, true, topic}, tq.ConsumeMessagesPerSec, 1},
+	} {
+		if t.rate > 0 {
+			b := u.bucket(st, t, now)
+			b.tokens = math.Min(b.tokens+float64(n), st.capacity(t.rate))
+		}
+	}
+}
+
+// CheckTopicQuota возвращает ErrQuotaExceeded, если клиент из ctx уже
+// создал максимальное число топиков во всех пространствах имён.
+func (u *QuotaUseCase) CheckTopicQuota(ctx context.Context) error {
+	principal := Principal(ctx)
+	limit := u.state.Load().principalQuota(principal).MaxTopics
+	if limit <= 0 {
+		return nil
+	}
+	used, err := u.countOwned(ctx, principal, func(ctx context.Context) ([]string, error) {
+		topics, err := u.topics.List(ctx)
+		owners := make([]string, len(topics))
+		for i, t := range topics {
+			owners[i] = t.CreatedBy
+		}
+		return owners, err
+	})
+	if err != nil {
+		return err
+	}
+	return principalQuotaError(ctx, principal, "topics", used, limit)
+}
+
+// CheckSubscriptionQuota — то же для подписок.
+func (u *QuotaUseCase) CheckSubscriptionQuota(ctx context.Context) error {
+	principal := Principal(ctx)
+	limit := u.state.Load().principalQuota(principal).MaxSubscriptions
+	if limit <= 0 {
+		return nil
+	}
+	used, err := u.countOwned(ctx, principal, func(ctx context.Context) ([]string, error) {
+		subs, err := u.subs.List(ctx)
+		owners := make([]string, len(subs))
+		for i, s := range subs {
+			owners[i] = s.CreatedBy
+		}
+		return owners, err
+	})
+	if err != nil {
+		return err
+	}
+	return principalQuotaError(ctx, principal, "subscriptions", used, limit)
+}
+
+// countOwned считает ресурсы клиента во всех пространствах имён; owners
+// возвращает создателей ресурсов пространства из ctx.
+func (u *QuotaUseCase) countOwned(ctx context.Context, principal string, owners func(context.Context) ([]string, error)) (int, error) {
+	namespaces, err := u.topics.Namespaces(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, ns := range namespaces {
+		list, err := owners(domain.WithNamespace(ctx, ns))
+		if err != nil {
+			return 0, err
+		}
+		for _, owner := range list {
+			if owner == principal {
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+
+func principalQuotaError(ctx context.Context, principal, resource string, used, limit int) error {
+	if used < limit {
+		return nil
+	}
+	slog.WarnContext(ctx, "principal quota exceeded", "principal", principal, "resource", resource, "used", used, "limit", limit)
+	return fmt.Errorf("%w: %s has created %d of %d %s", ErrQuotaExceeded, principal, used, limit, resource)
+}
+
+func rateLimited(ctx context.Context, op, topicName string, wait time.Duration) error {
+	slog.DebugContext(ctx, "rate limited", "op", op, "topic", topicName, "retry_after", wait.String())
+	return fmt.Errorf("%w: %s to %s, retry after %s", ErrRateLimited, op, topicName, wait.Round(time.Millisecond))
+}
+
+// take — сколько токенов n списать из корзины key со скоростью rate.
+type take struct {
+	key  bucketKey
+	rate float64
+	n    float64
+}
+
+// take списывает токены сразу из всех корзин или, если хоть в одной их не
+// хватает, ни из одной и возвращает время ожидания.
+func (u *QuotaUseCase) take(st *quotaState, takes []take) time.Duration {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	now := u.now()
+	if wait := u.waitLocked(st, takes, now); wait > 0 {
+		return wait
+	}
+	for _, t := range takes {
+		if t.rate > 0 {
+			u.bucket(st, t, now).tokens -= t.n
+		}
+	}
+	u.sweepLocked(st, now)
+	return 0
+}
+
+// waitLocked возвращает, через сколько во всех корзинах наберётся нужное
+// число токенов. Запрос больше ёмкости корзины проходит, когда она полна,
+// и уводит её в минус.
+func (u *QuotaUseCase) waitLocked(st *quotaState, takes []take, now time.Time) time.Duration {
+	var wait float64
+	for _, t := range takes {
+		if t.rate <= 0 {
+			continue
+		}
+		need := math.Min(t.n, st.capacity(t.rate))
+		if b := u.bucket(st, t, now); b.tokens < need {
+			wait = math.Max(wait, (need-b.tokens)/t.rate)
+		}
+	}
+	if wait == 0 {
+		return 0
+	}
+	// Не меньше миллисекунды: иначе клиенту нечего ждать.
+	return max(time.Duration(wait*float64(time.Second)), time.Millisecond)
+}
+
+// bucket возвращает корзину t.key, пополненную к моменту now.
+func (u *QuotaUseCase) bucket(st *quotaState, t take, now time.Time) *tokenBucket {
+	capacity := st.capacity(t.rate)
+	b, ok := u.buckets[t.key]
+	if !ok {
+		b = &tokenBucket{tokens: capacity, rate: t.rate, last: now}
+		u.buckets[t.key] = b
+		return b
+	}
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.tokens+elapsed*t.rate, capacity)
+		b.last = now
+	}
+	b.rate = t.rate
+	// После уменьшения лимита в корзине не остаётся больше новой ёмкости.
+	b.tokens = math.Min(b.tokens, capacity)
+	return b
+}
+
+// sweepLocked удаляет корзины, которые успели бы заполниться: так карта не
+// растёт из-за удалённых топиков и ушедших клиентов.
+func (u *QuotaUseCase) sweepLocked(st *quotaState, now time.Time) {
+	if now.Sub(u.lastSweep) < sweepInterval {
+		return
+	}
+	u.lastSweep = now
+	for key, b := range u.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.rate >= st.capacity(b.rate) {
+			delete(u.buckets, key)
+		}
+	}
+}
+
+// capacity — ёмкость корзины: не меньше одного токена, чтобы при лимите
+// меньше одного сообщения за Burst вызовы всё же проходили.
+func (st *quotaState) capacity(rate float64) float64 {
+	return math.Max(rate*st.burst, 1)
+}
+
+func (st *quotaState) principalQuota(principal string) Quota {
+	return mergeQuota(st.policy.Principals[principal], st.policy.Principal)
+}
+
+func (st *quotaState) topicQuota(topicName string) Quota {
+	return mergeQuota(st.policy.Topics[topicName], st.policy.Topic)
+}
+
+// mergeQuota дополняет незаданные поля q значениями def.
+func mergeQuota(q, def Quota) Quota {
+	if q.PublishMessagesPerSec == 0 {
+		q.PublishMessagesPerSec = def.PublishMessagesPerSec
+	}
+	if q.PublishBytesPerSec == 0 {
+		q.PublishBytesPerSec = def.PublishBytesPerSec
+	}
+	if q.ConsumeMessagesPerSec == 0 {
+		q.ConsumeMessagesPerSec = def.ConsumeMessagesPerSec
+	}
+	if q.MaxTopics == 0 {
+		q.MaxTopics = def.MaxTopics
+	}
+	if q.MaxSubscriptions == 0 {
+		q.MaxSubscriptions = def.MaxSubscriptions
+	}
+	return q
+}
+
+// topicKey — ключ корзины топика: одноимённые топики разных пространств
+// имён ограничиваются отдельно.
+func topicKey(ctx context.Context, topicName string) string {
+	return domain.Namespace(ctx) + "/" + topicName
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"queue-service/internal/domain"
+	"queue-service/internal/repository/memory"
+)
+
+// newTestQuotas возвращает QuotaUseCase с управляемыми часами: advance
+// сдвигает время вперёд.
+func newTestQuotas(t *testing.T, policy QuotaPolicy) (u *QuotaUseCase, advance func(time.Duration)) {
+	t.Helper()
+	u, err := NewQuotaUseCase(memory.NewTopicRepository(), memory.NewSubscriptionRepository(), policy)
+	if err != nil {
+		t.Fatalf("NewQuotaUseCase: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	u.now = func() time.Time { return now }
+	return u, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestQuotaUseCase_AllowPublish(t *testing.T) {
+	quotas, advance := newTestQuotas(t, QuotaPolicy{
+		Principal:  Quota{PublishMessagesPerSec: 2},
+		Topic:      Quota{PublishMessagesPerSec: 3},
+		Principals: map[string]Quota{"bulk": {PublishMessagesPerSec: 100}},
+	})
+	orders, billing, bulk := asClient("orders"), asClient("billing"), asClient("bulk")
+	allow := func(ctx context.Context, topic string) (time.Duration, error) {
+		return quotas.AllowPublish(ctx, topic, 10)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := allow(orders, "events"); err != nil {
+			t.Fatalf("publish %d: %v", i, err)
+		}
+	}
+	// Лимит клиента исчерпан: 2 сообщения в секунду, следующее — через 0.5 с.
+	wait, err := allow(orders, "events")
+	if !errors.Is(err, ErrRateLimited) || wait != 500*time.Millisecond {
+		t.Fatalf("third publish: %v, retry after %s", err, wait)
+	}
+	// У другого клиента своя корзина, но топик общий: 3 сообщения в секунду.
+	if _, err := allow(billing, "events"); err != nil {
+		t.Fatalf("billing: %v", err)
+	}
+	if _, err := allow(bulk, "events"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("topic limit: %v", err)
+	}
+	// Отказ по лимиту топика не списывает токены клиента.
+	if _, err := allow(bulk, "other"); err != nil {
+		t.Fatalf("bulk to other topic: %v", err)
+	}
+	// В другом пространстве имён одноимённый топик ограничивается отдельно.
+	if _, err := allow(domain.WithNamespace(bulk, "shop"), "events"); err != nil {
+		t.Fatalf("events in shop: %v", err)
+	}
+
+	advance(500 * time.Millisecond)
+	if _, err := allow(orders, "events"); err != nil {
+		t.Fatalf("after refill: %v", err)
+	}
+}
+
+func TestQuotaUseCase_AllowPublishBatch(t *testing.T) {
+	quotas, advance := newTestQuotas(t, QuotaPolicy{Topic: Quota{PublishMessagesPerSec: 4}})
+	ctx := asClient("orders")
+	if _, err := quotas.AllowPublishBatch(ctx, "events", 3, 30); err != nil {
+		t.Fatalf("first batch: %v", err)
+	}
+	// Пакет не проходит частично: на 2 сообщения токенов не хватает, и
+	// оставшийся токен не списан.
+	if wait, err := quotas.AllowPublishBatch(ctx, "events", 2, 20); !errors.Is(err, ErrRateLimited) || wait != 250*time.Millisecond {
+		t.Fatalf("second batch: %v, retry after %s", err, wait)
+	}
+	if _, err := quotas.AllowPublish(ctx, "events", 10); err != nil {
+		t.Fatalf("single message: %v", err)
+	}
+	advance(500 * time.Millisecond)
+	if _, err := quotas.AllowPublishBatch(ctx, "events", 2, 20); err != nil {
+		t.Fatalf("after refill: %v", err)
+	}
+}
+
+func TestQuotaUseCase_AllowPublish_bytes(t *testing.T) {
+	quotas, advance := newTestQuotas(t, QuotaPolicy{Topic: Quota{PublishBytesPerSec: 100}})
+	ctx := asClient("orders")
+	// Сообщение больше ёмкости корзины проходит, когда она полна, и уводит её в минус.
+	if _, err := quotas.AllowPublish(ctx, "events", 250); err != nil {
+		t.Fatalf("large message: %v", err)
+	}
+	wait, err := quotas.AllowPublish(ctx, "events", 10)
+	if !errors.Is(err, ErrRateLimited) || wait != 1600*time.Millisecond {
+		t.Fatalf("after large message: %v, retry after %s", err, wait)
+	}
+	advance(wait)
+	if _, err := quotas.AllowPublish(ctx, "events", 10); err != nil {
+		t.Fatalf("after wait: %v", err)
+	}
+}
+
+func TestQuotaUseCase_ReserveConsume(t *testing.T) {
+	quotas, advance := newTestQuotas(t, QuotaPolicy{
+		Topic: Quota{ConsumeMessagesPerSec: 10},
+		Burst: 2 * time.Second,
+	})
+	ctx := asClient("orders")
+
+	// Ёмкость — 20 сообщений: запрос 50 получает 20.
+	n, _, err := quotas.ReserveConsume(ctx, "events", 50)
+	if err != nil || n != 20 {
+		t.Fatalf("ReserveConsume: %d, %v", n, err)
+	}
+	if _, wait, err := quotas.ReserveConsume(ctx, "events", 1); !errors.Is(err, ErrRateLimited) || wait != 100*time.Millisecond {
+		t.Fatalf("empty bucket: %v, retry after %s", err, wait)
+	}
+	// Выдано только 5: остальное возвращается.
+	quotas.ReleaseConsume(ctx, "events", 15)
+	if n, _, err := quotas.ReserveConsume(ctx, "events", 50); err != nil || n != 15 {
+		t.Fatalf("after release: %d, %v", n, err)
+	}
+	advance(300 * time.Millisecond)
+	if n, _, err := quotas.ReserveConsume(ctx, "events", 50); err != nil || n != 3 {
+		t.Fatalf("after refill: %d, %v", n, err)
+	}
+}
+
+func TestQuotaUseCase_principalResources(t *testing.T) {
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	subs := memory.NewSubscriptionRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	topicUC := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	subUC := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	quotas, err := NewQuotaUseCase(topics, subs, QuotaPolicy{
+		Principal:  Quota{MaxTopics: 2, MaxSubscriptions: 1},
+		Principals: map[string]Quota{"ops": {MaxTopics: 10}},
+	})
+	if err != nil {
+		t.Fatalf("NewQuotaUseCase: %v", err)
+	}
+	if quotas.Enabled() {
+		t.Error("no rate limits, but Enabled")
+	}
+	orders := asClient("orders")
+
+	// Топики клиента считаются во всех пространствах имён.
+	_, _ = topicUC.CreateTopic(orders, "a", 0)
+	_, _ = topicUC.CreateTopic(domain.WithNamespace(orders, "shop"), "b", 0)
+	_, _ = topicUC.CreateTopic(asClient("ops"), "c", 0)
+	if err := quotas.CheckTopicQuota(orders); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("third topic of orders: %v", err)
+	}
+	if err := quotas.CheckTopicQuota(asClient("billing")); err != nil {
+		t.Fatalf("first topic of billing: %v", err)
+	}
+	if err := quotas.CheckTopicQuota(asClient("ops")); err != nil {
+		t.Fatalf("ops: %v", err)
+	}
+
+	if err := quotas.CheckSubscriptionQuota(orders); err != nil {
+		t.Fatalf("first subscription: %v", err)
+	}
+	_, _ = subUC.Subscribe(orders, "a", "0", "g", domain.AtLeastOnce)
+	if err := quotas.CheckSubscriptionQuota(orders); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("second subscription: %v", err)
+	}
+	// ops наследует общий предел подписок и ещё не создавал их.
+	if err := quotas.CheckSubscriptionQuota(asClient("ops")); err != nil {
+		t.Fatalf("ops subscription: %v", err)
+	}
+}
+
+func TestQuotaUseCase_invalidPolicy(t *testing.T) {
+	for name, p := range map[string]QuotaPolicy{
+		"negative rate":  {Principal: Quota{PublishMessagesPerSec: -1}},
+		"negative topic": {Topics: map[string]Quota{"a": {ConsumeMessagesPerSec: -1}}},
+		"negative burst": {Burst: -time.Second},
+	} {
+		if _, err := NewQuotaUseCase(memory.NewTopicRepository(), memory.NewSubscriptionRepository(), p); err == nil {
+			t.Errorf("%s: policy accepted", name)
+		}
+	}
+}
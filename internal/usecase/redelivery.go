@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"queue-service/internal/domain"
+)
+
+// RedeliveryScheduler отслеживает сроки подтверждения доставок и будит
+// потребителей подписок, у которых истёк срок, не дожидаясь их очередного
+// опроса. Сами сообщения по-прежнему отдаёт Consume.
+type RedeliveryScheduler struct {
+	pending domain.PendingDeliveryRepository
+	// maxIdle ограничивает сон между проверками: срок доставки может
+	// приблизиться (Nack, ExtendAckDeadline) уже после того, как планировщик
+	// заснул до прежнего ближайшего срока.
+	maxIdle time.Duration
+
+	mu      sync.Mutex
+	waiters map[string]chan struct{}
+}
+
+func NewRedeliveryScheduler(pending domain.PendingDeliveryRepository, maxIdle time.Duration) *RedeliveryScheduler {
+	return &RedeliveryScheduler{
+		pending: pending,
+		maxIdle: maxIdle,
+		waiters: make(map[string]chan struct{}),
+	}
+}
+
+// Run работает до отмены ctx: спит до ближайшего срока подтверждения (но не
+// дольше maxIdle), переводит просроченные доставки в повторную доставку и
+// уведомляет ждущих потребителей.
+func (s *RedeliveryScheduler) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		timer.Reset(s.tick(ctx, time.Now()))
+	}
+}
+
+// tick обрабатывает сроки, истёкшие к now, и возвращает время до следующей проверки.
+func (s *RedeliveryScheduler) tick(ctx context.Context, now time.Time) time.Duration {
+	ids, _ := s.pending.ExpireDue(ctx, now)
+	for _, id := range ids {
+		s.notify(id)
+	}
+	wait := s.maxIdle
+	if next, ok, _ := s.pending.NextDeadline(ctx); ok {
+		if d := next.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+// Ready возвращает канал, который закроется, когда у подписки появятся
+// доставки с истёкшим сроком. Канал нужно получить до Consume, иначе
+// уведомление между Consume и ожиданием будет пропущено.
+func (s *RedeliveryScheduler) Ready(subscriptionID string) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch, ok := s.waiters[subscriptionID]
+	if !ok {
+		ch = make(chan struct{})
+		s.waiters[subscriptionID] = ch
+	}
+	return ch
+}
+
+// Forget удаляет канал ожидания удалённой подписки, закрывая его: ждущий
+// потребитель проснётся и узнает, что подписки нет. Nil-планировщик ничего
+// не делает.
+func (s *RedeliveryScheduler) Forget(subscriptionID string) {
+	if s == nil {
+		return
+	}
+	s.notify(subscriptionID)
+}
+
+func (s *RedeliveryScheduler) notify(subscriptionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ch, ok := s.waiters[subscriptionID]; ok {
+		close(ch)
+		delete(s.waiters, subscriptionID)
+	}
+}
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"queue-service/internal/domain"
+	"queue-service/internal/repository/memory"
+)
+
+func TestRedeliveryScheduler_tick(t *testing.T) {
+	ctx := context.Background()
+	pending := memory.NewPendingDeliveryRepository()
+	s := NewRedeliveryScheduler(pending, time.Minute)
+	now := time.Now()
+
+	if wait := s.tick(ctx, now); wait != time.Minute {
+		t.Errorf("idle wait want maxIdle, got %v", wait)
+	}
+	_ = pending.Add(ctx, "sub-1", &domain.PendingDelivery{DeliveryID: "d1", ExpiresAt: now.Add(time.Second)})
+	_ = pending.Add(ctx, "sub-2", &domain.PendingDelivery{DeliveryID: "d2", ExpiresAt: now.Add(time.Hour)})
+	ready1, ready2 := s.Ready("sub-1"), s.Ready("sub-2")
+
+	if wait := s.tick(ctx, now); wait != time.Second {
+		t.Errorf("wait want time to next deadline, got %v", wait)
+	}
+	select {
+	case <-ready1:
+		t.Fatal("sub-1 notified before its deadline")
+	default:
+	}
+
+	s.tick(ctx, now.Add(2*time.Second))
+	select {
+	case <-ready1:
+	default:
+		t.Fatal("sub-1 should be notified after its deadline")
+	}
+	select {
+	case <-ready2:
+		t.Error("sub-2 must not be notified")
+	default:
+	}
+	if s.Ready("sub-1") == ready1 {
+		t.Error("Ready should return a fresh channel after notification")
+	}
+}
+
+func TestRedeliveryScheduler_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pending := memory.NewPendingDeliveryRepository()
+	s := NewRedeliveryScheduler(pending, time.Hour)
+
+	_ = pending.Add(ctx, "sub-1", &domain.PendingDelivery{DeliveryID: "d1", ExpiresAt: time.Now().Add(20 * time.Millisecond)})
+	ready := s.Ready("sub-1")
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	// maxIdle — час: уведомление должно прийти по сроку доставки.
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduler did not wake the subscription at its deadline")
+	}
+	if expired, _ := pending.Expired(ctx, "sub-1", time.Now(), 10); len(expired) != 1 {
+		t.Errorf("expired delivery must stay available to Consume, got %d", len(expired))
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+func TestRedeliveryScheduler_Forget(t *testing.T) {
+	ctx := context.Background()
+	subs := memory.NewSubscriptionRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	s := NewRedeliveryScheduler(pending, time.Hour)
+
+	topicUC := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	subUC := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	topicUC.SetRedelivery(s)
+	subUC.SetRedelivery(s)
+
+	_, _ = topicUC.CreateTopic(ctx, "orders", 100)
+	sub1, _ := subUC.Subscribe(ctx, "orders", "0", "g1", domain.AtLeastOnce)
+	sub2, _ := subUC.Subscribe(ctx, "orders", "0", "g2", domain.AtLeastOnce)
+	ready1, ready2 := s.Ready(sub1.ID), s.Ready(sub2.ID)
+
+	if err := subUC.Unsubscribe(ctx, sub1.ID); err != nil {
+		t.Fatalf("Unsubscribe: %v", err)
+	}
+	select {
+	case <-ready1:
+	default:
+		t.Fatal("waiter of an unsubscribed subscription should be woken")
+	}
+	if err := topicUC.DeleteTopic(ctx, "orders", true); err != nil {
+		t.Fatalf("DeleteTopic: %v", err)
+	}
+	select {
+	case <-ready2:
+	default:
+		t.Fatal("waiter of a deleted topic's subscription should be woken")
+	}
+	s.mu.Lock()
+	n := len(s.waiters)
+	s.mu.Unlock()
+	if n != 0 {
+		t.Errorf("want no waiters left, got %d", n)
+	}
+	var nilScheduler *RedeliveryScheduler
+	nilScheduler.Forget("sub-x")
+}
@@ -5,6 +5,10 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync/atomic"
 	"time"
 
 	"queue-service/internal/domain"
@@ -12,29 +16,93 @@ import (
 
 var ErrSubscriptionExists = errors.New("subscription already exists for topic and consumer group")
 var ErrSubscriptionNotFound = errors.New("subscription not found")
+var ErrInvalidSeek = errors.New("invalid seek position")
+var ErrOffsetOutOfRange = errors.New("offset out of range")
+
+// SeekPosition — куда переместить смещение подписки.
+type SeekPosition int
+
+const (
+	SeekEarliest  SeekPosition = iota // начало журнала очереди
+	SeekLatest                        // конец журнала: только новые сообщения
+	SeekOffset                        // явное смещение Seek.Offset
+	SeekTimestamp                     // первое сообщение с CreatedAt >= Seek.Timestamp
+)
+
+// Seek — целевая позиция для SeekSubscription.
+type Seek struct {
+	Position  SeekPosition
+	Offset    int64
+	Timestamp time.Time
+}
 
 type SubscriptionUseCase struct {
 	subs       domain.SubscriptionRepository
 	topics     domain.TopicRepository
 	queues     domain.QueueRepository
-	ackTimeout time.Duration
+	messages   domain.MessageRepository
+	pending    domain.PendingDeliveryRepository
+	ackTimeout atomic.Int64 // time.Duration
+	// redelivery забывает ожидающих удалённых подписок; nil — не подключён.
+	redelivery *RedeliveryScheduler
 }
 
 func NewSubscriptionUseCase(
 	subs domain.SubscriptionRepository,
 	topics domain.TopicRepository,
 	queues domain.QueueRepository,
+	messages domain.MessageRepository,
+	pending domain.PendingDeliveryRepository,
 	ackTimeoutSeconds int,
 ) *SubscriptionUseCase {
-	return &SubscriptionUseCase{
-		subs:       subs,
-		topics:     topics,
-		queues:     queues,
-		ackTimeout: time.Duration(ackTimeoutSeconds) * time.Second,
+	u := &SubscriptionUseCase{
+		subs:     subs,
+		topics:   topics,
+		queues:   queues,
+		messages: messages,
+		pending:  pending,
 	}
+	u.ackTimeout.Store(int64(time.Duration(ackTimeoutSeconds) * time.Second))
+	return u
+}
+
+// SetRedelivery подключает планировщик повторной доставки; вызывается до
+// начала работы.
+func (u *SubscriptionUseCase) SetRedelivery(r *RedeliveryScheduler) { u.redelivery = r }
+
+// SetAckTimeout меняет ack timeout новых и существующих подписок во всех
+// пространствах имён. Сроки уже выданных сообщений не пересчитываются.
+func (u *SubscriptionUseCase) SetAckTimeout(ctx context.Context, timeout time.Duration) error {
+	if timeout <= 0 {
+		return fmt.Errorf("ack timeout must be positive: %s", timeout)
+	}
+	u.ackTimeout.Store(int64(timeout))
+	namespaces, err := u.topics.Namespaces(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ns := range namespaces {
+		nsCtx := domain.WithNamespace(ctx, ns)
+		subs, err := u.subs.List(nsCtx)
+		if err != nil {
+			return err
+		}
+		for _, s := range subs {
+			if err := u.subs.SetAckTimeout(nsCtx, s.ID, timeout); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func (u *SubscriptionUseCase) Subscribe(ctx context.Context, topicName, queueID, consumerGroup string, guarantee domain.DeliveryGuarantee) (*domain.Subscription, error) {
+	return u.SubscribeFrom(ctx, topicName, queueID, consumerGroup, guarantee, Seek{Position: SeekEarliest})
+}
+
+// SubscribeFrom создаёт подписку, чтение которой начнётся с позиции start,
+// а не с начала очереди, как у Subscribe.
+func (u *SubscriptionUseCase) SubscribeFrom(ctx context.Context, topicName, queueID, consumerGroup string, guarantee domain.DeliveryGuarantee, start Seek) (*domain.Subscription, error) {
 	_, err := u.topics.Get(ctx, topicName)
 	if err != nil {
 		return nil, ErrTopicNotFound
@@ -50,18 +118,27 @@ func (u *SubscriptionUseCase) Subscribe(ctx context.Context, topicName, queueID,
 	if existing != nil {
 		return nil, ErrSubscriptionExists
 	}
+	offset, err := resolveSeek(ctx, u.messages, topicName, queueID, start)
+	if err != nil {
+		return nil, err
+	}
 	sub := &domain.Subscription{
 		ID:                genSubID(),
+		Namespace:         domain.Namespace(ctx),
 		TopicName:         topicName,
 		QueueID:           queueID,
 		ConsumerGroup:     consumerGroup,
 		DeliveryGuarantee: guarantee,
-		AckTimeout:        u.ackTimeout,
+		AckTimeout:        time.Duration(u.ackTimeout.Load()),
+		Offset:            offset,
+		CreatedBy:         Principal(ctx),
 		CreatedAt:         time.Now(),
 	}
 	if err := u.subs.Create(ctx, sub); err != nil {
 		return nil, err
 	}
+	slog.InfoContext(ctx, "subscription created", "subscription", sub.ID, "topic", topicName, "queue", queueID,
+		"consumer_group", consumerGroup, "at_least_once", guarantee == domain.AtLeastOnce, "offset", offset)
 	return sub, nil
 }
 
@@ -69,6 +146,15 @@ func (u *SubscriptionUseCase) GetSubscription(ctx context.Context, id string) (*
 	return u.subs.Get(ctx, id)
 }
 
+// FindSubscription ищет подписку группы потребителей на топик.
+func (u *SubscriptionUseCase) FindSubscription(ctx context.Context, topicName, consumerGroup string) (*domain.Subscription, error) {
+	sub, err := u.subs.GetByTopicAndGroup(ctx, topicName, consumerGroup)
+	if err != nil {
+		return nil, ErrSubscriptionNotFound
+	}
+	return sub, nil
+}
+
 func (u *SubscriptionUseCase) ListSubscriptions(ctx context.Context, topicName string) ([]*domain.Subscription, error) {
 	if topicName != "" {
 		return u.subs.ListByTopic(ctx, topicName)
@@ -76,6 +162,162 @@ func (u *SubscriptionUseCase) ListSubscriptions(ctx context.Context, topicName s
 	return u.subs.List(ctx)
 }
 
+// SubscriptionStats — состояние подписки для мониторинга отставания потребителей.
+type SubscriptionStats struct {
+	Subscription *domain.Subscription
+	// CommittedOffset — смещение, с которого продолжится чтение; не меньше
+	// начала журнала очереди.
+	CommittedOffset int64
+	// HighWatermark — смещение следующего сообщения очереди.
+	HighWatermark int64
+	// Lag — сколько сообщений очереди ещё не подтверждено (или не прочитано
+	// для at-most-once), включая выданные, но не подтверждённые.
+	Lag int64
+	// Pending — число неподтверждённых доставок.
+	Pending int
+	// OldestPending — время первой выдачи самой старой неподтверждённой
+	// доставки; нулевое, если таких нет.
+	OldestPending time.Time
+}
+
+// DescribeSubscription возвращает подписку вместе со смещениями очереди и
+// статистикой неподтверждённых доставок.
+func (u *SubscriptionUseCase) DescribeSubscription(ctx context.Context, id string) (*SubscriptionStats, error) {
+	sub, err := u.subs.Get(ctx, id)
+	if err != nil {
+		return nil, ErrSubscriptionNotFound
+	}
+	return u.stats(ctx, sub)
+}
+
+// ListSubscriptionStats — то же, что DescribeSubscription, для всех подписок
+// топика (или всех подписок, если topicName пуст), упорядоченных по топику и группе.
+func (u *SubscriptionUseCase) ListSubscriptionStats(ctx context.Context, topicName string) ([]*SubscriptionStats, error) {
+	subs, err := u.ListSubscriptions(ctx, topicName)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		if subs[i].TopicName != subs[j].TopicName {
+			return subs[i].TopicName < subs[j].TopicName
+		}
+		return subs[i].ConsumerGroup < subs[j].ConsumerGroup
+	})
+	out := make([]*SubscriptionStats, 0, len(subs))
+	for _, sub := range subs {
+		st, err := u.stats(ctx, sub)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+func (u *SubscriptionUseCase) stats(ctx context.Context, sub *domain.Subscription) (*SubscriptionStats, error) {
+	start, end, err := u.messages.Offsets(ctx, sub.TopicName, sub.QueueID)
+	if err != nil {
+		return nil, err
+	}
+	count, oldest, err := u.pending.Stats(ctx, sub.ID)
+	if err != nil {
+		return nil, err
+	}
+	committed := sub.Offset
+	if committed < start {
+		committed = start
+	}
+	lag := end - committed
+	if lag < 0 {
+		lag = 0
+	}
+	return &SubscriptionStats{
+		Subscription:    sub,
+		CommittedOffset: committed,
+		HighWatermark:   end,
+		Lag:             lag,
+		Pending:         count,
+		OldestPending:   oldest,
+	}, nil
+}
+
+// SeekSubscription перемещает смещение подписки и возвращает новое значение.
+// Неподтверждённые доставки удаляются: сообщения до нового смещения
+// пропускаются, после — будут прочитаны заново.
+func (u *SubscriptionUseCase) SeekSubscription(ctx context.Context, id string, seek Seek) (int64, error) {
+	sub, err := u.subs.Get(ctx, id)
+	if err != nil {
+		return 0, ErrSubscriptionNotFound
+	}
+	offset, err := resolveSeek(ctx, u.messages, sub.TopicName, sub.QueueID, seek)
+	if err != nil {
+		return 0, err
+	}
+	if err := u.pending.RemoveAll(ctx, id); err != nil {
+		return 0, fmt.Errorf("remove pending deliveries of %s: %w", id, err)
+	}
+	if err := u.subs.AdvanceOffset(ctx, id, offset); err != nil {
+		return 0, err
+	}
+	slog.InfoContext(ctx, "subscription offset moved", "subscription", id, "topic", sub.TopicName, "queue", sub.QueueID,
+		"from", sub.Offset, "to", offset)
+	return offset, nil
+}
+
+// resolveSeek переводит Seek в смещение очереди. Явное смещение до начала
+// журнала (после очистки) сдвигается на начало; дальше конца — ошибка.
+func resolveSeek(ctx context.Context, messages domain.MessageRepository, topicName, queueID string, seek Seek) (int64, error) {
+	start, end, err := messages.Offsets(ctx, topicName, queueID)
+	if err != nil {
+		return 0, err
+	}
+	switch seek.Position {
+	case SeekEarliest:
+		return start, nil
+	case SeekLatest:
+		return end, nil
+	case SeekOffset:
+		if seek.Offset < 0 || seek.Offset > end {
+			return 0, ErrOffsetOutOfRange
+		}
+		if seek.Offset < start {
+			return start, nil
+		}
+		return seek.Offset, nil
+	case SeekTimestamp:
+		return messages.OffsetForTime(ctx, topicName, queueID, seek.Timestamp)
+	}
+	return 0, ErrInvalidSeek
+}
+
+// Unsubscribe удаляет подписку вместе с её неподтверждёнными доставками.
+// Группа потребителей после этого может подписаться заново.
+func (u *SubscriptionUseCase) Unsubscribe(ctx context.Context, id string) error {
+	sub, err := u.subs.Get(ctx, id)
+	if err != nil {
+		return ErrSubscriptionNotFound
+	}
+	if err := removeSubscription(ctx, u.subs, u.pending, u.redelivery, id); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "subscription deleted", "subscription", id, "topic", sub.TopicName, "consumer_group", sub.ConsumerGroup)
+	return nil
+}
+
+// removeSubscription удаляет подписку; доставки удаляются первыми, чтобы не
+// осталось состояния, ссылающегося на несуществующую подписку.
+func removeSubscription(ctx context.Context, subs domain.SubscriptionRepository, pending domain.PendingDeliveryRepository,
+	redelivery *RedeliveryScheduler, id string) error {
+	if err := pending.RemoveAll(ctx, id); err != nil {
+		return fmt.Errorf("remove pending deliveries of %s: %w", id, err)
+	}
+	if err := subs.Delete(ctx, id); err != nil {
+		return fmt.Errorf("delete subscription %s: %w", id, err)
+	}
+	redelivery.Forget(id)
+	return nil
+}
+
 func genSubID() string {
 	b := make([]byte, 8)
 	_, _ = rand.Read(b)
@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"testing"
+	"time"
 
 	"queue-service/internal/domain"
 	"queue-service/internal/repository/memory"
@@ -13,9 +14,9 @@ func TestSubscriptionUseCase_Subscribe(t *testing.T) {
 	subs := memory.NewSubscriptionRepository()
 	topics := memory.NewTopicRepository()
 	queues := memory.NewQueueRepository()
-	topicUC := NewTopicUseCase(topics, queues)
+	topicUC := NewTopicUseCase(topics, queues, memory.NewMessageRepository(), subs, memory.NewPendingDeliveryRepository())
 	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
-	uc := NewSubscriptionUseCase(subs, topics, queues, 30)
+	uc := NewSubscriptionUseCase(subs, topics, queues, memory.NewMessageRepository(), memory.NewPendingDeliveryRepository(), 30)
 
 	sub, err := uc.Subscribe(ctx, "orders", "0", "my-group", domain.AtLeastOnce)
 	if err != nil {
@@ -34,9 +35,9 @@ func TestSubscriptionUseCase_Subscribe_duplicateReturnsErr(t *testing.T) {
 	subs := memory.NewSubscriptionRepository()
 	topics := memory.NewTopicRepository()
 	queues := memory.NewQueueRepository()
-	topicUC := NewTopicUseCase(topics, queues)
+	topicUC := NewTopicUseCase(topics, queues, memory.NewMessageRepository(), subs, memory.NewPendingDeliveryRepository())
 	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
-	uc := NewSubscriptionUseCase(subs, topics, queues, 30)
+	uc := NewSubscriptionUseCase(subs, topics, queues, memory.NewMessageRepository(), memory.NewPendingDeliveryRepository(), 30)
 
 	_, _ = uc.Subscribe(ctx, "orders", "0", "my-group", domain.AtMostOnce)
 	_, err := uc.Subscribe(ctx, "orders", "0", "my-group", domain.AtMostOnce)
@@ -51,6 +52,8 @@ func TestSubscriptionUseCase_Subscribe_topicNotFound(t *testing.T) {
 		memory.NewSubscriptionRepository(),
 		memory.NewTopicRepository(),
 		memory.NewQueueRepository(),
+		memory.NewMessageRepository(),
+		memory.NewPendingDeliveryRepository(),
 		30,
 	)
 	_, err := uc.Subscribe(ctx, "nonexistent", "0", "g", domain.AtMostOnce)
@@ -64,9 +67,9 @@ func TestSubscriptionUseCase_GetSubscription_ListSubscriptions(t *testing.T) {
 	subs := memory.NewSubscriptionRepository()
 	topics := memory.NewTopicRepository()
 	queues := memory.NewQueueRepository()
-	topicUC := NewTopicUseCase(topics, queues)
+	topicUC := NewTopicUseCase(topics, queues, memory.NewMessageRepository(), subs, memory.NewPendingDeliveryRepository())
 	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
-	uc := NewSubscriptionUseCase(subs, topics, queues, 30)
+	uc := NewSubscriptionUseCase(subs, topics, queues, memory.NewMessageRepository(), memory.NewPendingDeliveryRepository(), 30)
 
 	created, _ := uc.Subscribe(ctx, "orders", "0", "g1", domain.AtMostOnce)
 	got, err := uc.GetSubscription(ctx, created.ID)
@@ -82,3 +85,172 @@ func TestSubscriptionUseCase_GetSubscription_ListSubscriptions(t *testing.T) {
 		t.Errorf("ListSubscriptions(all): want 1, got %d", len(all))
 	}
 }
+
+func TestSubscriptionUseCase_Unsubscribe(t *testing.T) {
+	ctx := context.Background()
+	subs := memory.NewSubscriptionRepository()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	topicUC := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	_, _ = pub.Publish(ctx, "orders", "0", []byte("m"), "", nil)
+	uc := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := NewConsumeUseCase(subs, msgs, pending, nil)
+
+	sub, _ := uc.Subscribe(ctx, "orders", "0", "g1", domain.AtLeastOnce)
+	_, _ = consumeUC.Consume(ctx, sub.ID, 10)
+
+	if err := uc.Unsubscribe(ctx, sub.ID); err != nil {
+		t.Fatalf("Unsubscribe: %v", err)
+	}
+	if _, ok, _ := pending.LastOffset(ctx, sub.ID); ok {
+		t.Error("pending deliveries left after unsubscribe")
+	}
+	if _, err := uc.FindSubscription(ctx, "orders", "g1"); err != ErrSubscriptionNotFound {
+		t.Errorf("group must be free after unsubscribe, got %v", err)
+	}
+	if _, err := uc.Subscribe(ctx, "orders", "0", "g1", domain.AtLeastOnce); err != nil {
+		t.Errorf("re-subscribe: %v", err)
+	}
+	if err := uc.Unsubscribe(ctx, sub.ID); err != ErrSubscriptionNotFound {
+		t.Errorf("want ErrSubscriptionNotFound, got %v", err)
+	}
+}
+
+func TestSubscriptionUseCase_DescribeSubscription_lag(t *testing.T) {
+	ctx := context.Background()
+	subs := memory.NewSubscriptionRepository()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	topicUC := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	for i := 0; i < 5; i++ {
+		_, _ = pub.Publish(ctx, "orders", "0", []byte("m"), "", nil)
+	}
+	uc := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := NewConsumeUseCase(subs, msgs, pending, nil)
+
+	sub, _ := uc.Subscribe(ctx, "orders", "0", "g1", domain.AtLeastOnce)
+	st, err := uc.DescribeSubscription(ctx, sub.ID)
+	if err != nil {
+		t.Fatalf("DescribeSubscription: %v", err)
+	}
+	if st.HighWatermark != 5 || st.Lag != 5 || st.Pending != 0 || !st.Subscription.LastConsumedAt.IsZero() {
+		t.Errorf("before consume: %+v", st)
+	}
+
+	got, _ := consumeUC.Consume(ctx, sub.ID, 2)
+	_ = consumeUC.Ack(ctx, sub.ID, got[0].DeliveryID)
+	st, _ = uc.DescribeSubscription(ctx, sub.ID)
+	if st.CommittedOffset != 1 || st.Lag != 4 || st.Pending != 1 {
+		t.Errorf("after consume: committed=%d lag=%d pending=%d", st.CommittedOffset, st.Lag, st.Pending)
+	}
+	if st.OldestPending.IsZero() || st.Subscription.LastConsumedAt.IsZero() {
+		t.Errorf("timestamps not set: %+v", st)
+	}
+
+	if _, err := uc.DescribeSubscription(ctx, "missing"); err != ErrSubscriptionNotFound {
+		t.Errorf("want ErrSubscriptionNotFound, got %v", err)
+	}
+}
+
+func TestSubscriptionUseCase_SeekSubscription(t *testing.T) {
+	ctx := context.Background()
+	subs := memory.NewSubscriptionRepository()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	topicUC := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	for i := 0; i < 3; i++ {
+		_, _ = pub.Publish(ctx, "orders", "0", []byte("old"), "", nil)
+	}
+	mark := time.Now()
+	time.Sleep(time.Millisecond)
+	_, _ = pub.Publish(ctx, "orders", "0", []byte("new"), "", nil)
+	uc := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := NewConsumeUseCase(subs, msgs, pending, nil)
+
+	sub, _ := uc.Subscribe(ctx, "orders", "0", "g1", domain.AtLeastOnce)
+	got, _ := consumeUC.Consume(ctx, sub.ID, 10)
+	if len(got) != 4 {
+		t.Fatalf("want 4 messages, got %d", len(got))
+	}
+
+	tests := []struct {
+		seek Seek
+		want int64
+	}{
+		{Seek{Position: SeekLatest}, 4},
+		{Seek{Position: SeekEarliest}, 0},
+		{Seek{Position: SeekOffset, Offset: 2}, 2},
+		{Seek{Position: SeekTimestamp, Timestamp: mark}, 3},
+		{Seek{Position: SeekTimestamp, Timestamp: time.Now().Add(time.Hour)}, 4},
+	}
+	for _, tt := range tests {
+		offset, err := uc.SeekSubscription(ctx, sub.ID, tt.seek)
+		if err != nil || offset != tt.want {
+			t.Errorf("seek %+v: got %d, %v; want %d", tt.seek, offset, err, tt.want)
+		}
+	}
+
+	if _, err := uc.SeekSubscription(ctx, sub.ID, Seek{Position: SeekOffset, Offset: 5}); err != ErrOffsetOutOfRange {
+		t.Errorf("want ErrOffsetOutOfRange, got %v", err)
+	}
+	if _, err := uc.SeekSubscription(ctx, "missing", Seek{}); err != ErrSubscriptionNotFound {
+		t.Errorf("want ErrSubscriptionNotFound, got %v", err)
+	}
+
+	// После перемотки старые доставки сброшены, чтение идёт с нового смещения.
+	_, _ = uc.SeekSubscription(ctx, sub.ID, Seek{Position: SeekOffset, Offset: 1})
+	if err := consumeUC.Ack(ctx, sub.ID, got[0].DeliveryID); err != ErrDeliveryNotFound {
+		t.Errorf("ack after seek: want ErrDeliveryNotFound, got %v", err)
+	}
+	replay, _ := consumeUC.Consume(ctx, sub.ID, 10)
+	if len(replay) != 3 || replay[0].Offset != 1 {
+		t.Errorf("replay: got %d messages, first %+v", len(replay), replay)
+	}
+}
+
+func TestSubscriptionUseCase_SubscribeFrom(t *testing.T) {
+	ctx := context.Background()
+	subs := memory.NewSubscriptionRepository()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	topicUC := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	_, _ = pub.Publish(ctx, "orders", "0", []byte("a"), "", nil)
+	_, _ = pub.Publish(ctx, "orders", "0", []byte("b"), "", nil)
+	uc := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := NewConsumeUseCase(subs, msgs, pending, nil)
+
+	sub, err := uc.SubscribeFrom(ctx, "orders", "0", "tail", domain.AtMostOnce, Seek{Position: SeekLatest})
+	if err != nil || sub.Offset != 2 {
+		t.Fatalf("SubscribeFrom latest: %v %+v", err, sub)
+	}
+	_, _ = pub.Publish(ctx, "orders", "0", []byte("c"), "", nil)
+	got, _ := consumeUC.Consume(ctx, sub.ID, 10)
+	if len(got) != 1 || string(got[0].Payload) != "c" {
+		t.Errorf("want only new message, got %+v", got)
+	}
+
+	sub, err = uc.SubscribeFrom(ctx, "orders", "0", "future", domain.AtMostOnce, Seek{Position: SeekTimestamp, Timestamp: time.Now().Add(time.Hour)})
+	if err != nil || sub.Offset != 3 {
+		t.Errorf("SubscribeFrom timestamp: %v %+v", err, sub)
+	}
+	sub, _ = uc.Subscribe(ctx, "orders", "0", "all", domain.AtMostOnce)
+	if sub.Offset != 0 {
+		t.Errorf("Subscribe must start at the beginning, got %d", sub.Offset)
+	}
+}
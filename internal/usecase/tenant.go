@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sync/atomic"
+
+	"queue-service/internal/domain"
+)
+
+var (
+	ErrInvalidNamespace = errors.New("invalid namespace")
+	ErrNamespaceDenied  = errors.New("namespace not allowed")
+	ErrQuotaExceeded    = errors.New("quota exceeded")
+)
+
+// namespaceRe — допустимое имя пространства: без "/", которым репозитории
+// отделяют пространство от имени топика.
+var namespaceRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// TenantQuota — ограничения пространства имён; 0 — без ограничения.
+// Квота памяти задаётся в MemoryBudget.
+type TenantQuota struct {
+	MaxTopics        int
+	MaxSubscriptions int
+}
+
+// Tenant — пространство имён арендатора: клиенты, которые в нём работают,
+// и его квоты.
+type Tenant struct {
+	Namespace  string
+	Principals []string
+	Quota      TenantQuota
+}
+
+// TenantPolicy — разделение брокера на пространства имён. При выключенном
+// разделении все клиенты работают в domain.DefaultNamespace.
+type TenantPolicy struct {
+	Enabled bool
+	// Default — пространство клиентов, не привязанных ни к одному арендатору;
+	// пустое — domain.DefaultNamespace.
+	Default string
+	// TrustHeader разрешает непривязанным клиентам выбирать пространство
+	// заголовком запроса.
+	TrustHeader bool
+	// Admins могут работать в любом пространстве, выбранном заголовком.
+	Admins []string
+	// Quota — квоты по умолчанию: действуют для пространств, которых нет в
+	// Tenants, и для не заданных в Tenant.Quota полей.
+	Quota   TenantQuota
+	Tenants []Tenant
+}
+
+// TenantUseCase определяет пространство имён запроса и проверяет квоты
+// арендаторов. Квоты мягкие: параллельные запросы могут превысить их на
+// число одновременно создаваемых ресурсов.
+type TenantUseCase struct {
+	topics domain.TopicRepository
+	subs   domain.SubscriptionRepository
+	state  atomic.Pointer[tenantState]
+}
+
+type tenantState struct {
+	enabled     bool
+	def         string
+	trustHeader bool
+	admins      map[string]bool
+	principals  map[string]string // клиент → пространство
+	quota       TenantQuota
+	quotas      map[string]TenantQuota
+}
+
+func NewTenantUseCase(topics domain.TopicRepository, subs domain.SubscriptionRepository, policy TenantPolicy) (*TenantUseCase, error) {
+	st, err := newTenantState(policy)
+	if err != nil {
+		return nil, err
+	}
+	u := &TenantUseCase{topics: topics, subs: subs}
+	u.state.Store(st)
+	return u, nil
+}
+
+// SetPolicy проверяет и применяет новую политику. Созданные топики и
+// подписки остаются в своих пространствах, даже если клиент перенесён.
+func (u *TenantUseCase) SetPolicy(p TenantPolicy) error {
+	st, err := newTenantState(p)
+	if err != nil {
+		return err
+	}
+	u.state.Store(st)
+	return nil
+}
+
+// Validate проверяет политику, не применяя её.
+func (p TenantPolicy) Validate() error {
+	_, err := newTenantState(p)
+	return err
+}
+
+func newTenantState(p TenantPolicy) (*tenantState, error) {
+	st := &tenantState{
+		enabled:     p.Enabled,
+		def:         p.Default,
+		trustHeader: p.TrustHeader,
+		admins:      make(map[string]bool, len(p.Admins)),
+		principals:  make(map[string]string),
+		quota:       p.Quota,
+		quotas:      make(map[string]TenantQuota, len(p.Tenants)),
+	}
+	if st.def == "" {
+		st.def = domain.DefaultNamespace
+	}
+	if err := ValidateNamespace(st.def); err != nil {
+		return nil, err
+	}
+	for _, name := range p.Admins {
+		st.admins[name] = true
+	}
+	for _, t := range p.Tenants {
+		if err := ValidateNamespace(t.Namespace); err != nil {
+			return nil, err
+		}
+		if _, dup := st.quotas[t.Namespace]; dup {
+			return nil, fmt.Errorf("%w: %q is listed twice", ErrInvalidNamespace, t.Namespace)
+		}
+		st.quotas[t.Namespace] = t.Quota
+		for _, principal := range t.Principals {
+			if other, dup := st.principals[principal]; dup {
+				return nil, fmt.Errorf("principal %q belongs to namespaces %q and %q", principal, other, t.Namespace)
+			}
+			st.principals[principal] = t.Namespace
+		}
+	}
+	return st, nil
+}
+
+// ValidateNamespace проверяет имя пространства: до 64 латинских букв, цифр,
+// ".", "_" и "-", начиная с буквы или цифры.
+func ValidateNamespace(ns string) error {
+	if !namespaceRe.MatchString(ns) {
+		return fmt.Errorf("%w: %q", ErrInvalidNamespace, ns)
+	}
+	return nil
+}
+
+// Enabled сообщает, разделён ли брокер на пространства имён.
+func (u *TenantUseCase) Enabled() bool {
+	return u.state.Load().enabled
+}
+
+// Resolve определяет пространство имён запроса клиента из ctx. requested —
+// пространство из заголовка запроса или "". Привязанный к арендатору клиент
+// работает только в его пространстве; непривязанный — в пространстве по
+// умолчанию или, если разрешено, в выбранном заголовком.
+func (u *TenantUseCase) Resolve(ctx context.Context, requested string) (string, error) {
+	st := u.state.Load()
+	if !st.enabled {
+		return domain.DefaultNamespace, nil
+	}
+	principal := Principal(ctx)
+	ns, bound := st.principals[principal]
+	switch {
+	case requested == "" && bound:
+		return ns, nil
+	case requested == "":
+		return st.def, nil
+	case requested == ns || !bound && requested == st.def:
+		return requested, nil
+	}
+	if err := ValidateNamespace(requested); err != nil {
+		return "", err
+	}
+	if st.admins[principal] || !bound && st.trustHeader {
+		return requested, nil
+	}
+	slog.WarnContext(ctx, "namespace denied", "principal", principal, "requested", requested)
+	return "", fmt.Errorf("%w: %s may not use namespace %q", ErrNamespaceDenied, principal, requested)
+}
+
+// CheckTopicQuota возвращает ErrQuotaExceeded, если в пространстве имён
+// запроса уже создано максимальное число топиков. Без разделения на
+// пространства квоты не действуют.
+func (u *TenantUseCase) CheckTopicQuota(ctx context.Context) error {
+	ns := domain.Namespace(ctx)
+	limit := u.state.Load().quotaFor(ns).MaxTopics
+	if limit <= 0 {
+		return nil
+	}
+	topics, err := u.topics.List(ctx)
+	if err != nil {
+		return err
+	}
+	return quotaError(ctx, ns, "topics", len(topics), limit)
+}
+
+// CheckSubscriptionQuota — то же для подписок.
+func (u *TenantUseCase) CheckSubscriptionQuota(ctx context.Context) error {
+	ns := domain.Namespace(ctx)
+	limit := u.state.Load().quotaFor(ns).MaxSubscriptions
+	if limit <= 0 {
+		return nil
+	}
+	subs, err := u.subs.List(ctx)
+	if err != nil {
+		return err
+	}
+	return quotaError(ctx, ns, "subscriptions", len(subs), limit)
+}
+
+func quotaError(ctx context.Context, ns, resource string, used, limit int) error {
+	if used < limit {
+		return nil
+	}
+	slog.WarnContext(ctx, "namespace quota exceeded", "resource", resource, "used", used, "limit", limit)
+	return fmt.Errorf("%w: namespace %q has %d of %d %s", ErrQuotaExceeded, ns, used, limit, resource)
+}
+
+func (st *tenantState) quotaFor(ns string) TenantQuota {
+	if !st.enabled {
+		return TenantQuota{}
+	}
+	q := st.quotas[ns]
+	if q.MaxTopics == 0 {
+		q.MaxTopics = st.quota.MaxTopics
+	}
+	if q.MaxSubscriptions == 0 {
+		q.MaxSubscriptions = st.quota.MaxSubscriptions
+	}
+	return q
+}
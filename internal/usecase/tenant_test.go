@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"queue-service/internal/domain"
+	"queue-service/internal/repository/memory"
+)
+
+func TestTenantUseCase_Resolve(t *testing.T) {
+	tenants, err := NewTenantUseCase(memory.NewTopicRepository(), memory.NewSubscriptionRepository(), TenantPolicy{
+		Enabled: true,
+		Admins:  []string{"root"},
+		Tenants: []Tenant{
+			{Namespace: "shop", Principals: []string{"orders", "billing"}},
+			{Namespace: "blog", Principals: []string{"posts"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewTenantUseCase: %v", err)
+	}
+	for _, tc := range []struct {
+		client, requested string
+		want              string
+		err               error
+	}{
+		{"orders", "", "shop", nil},
+		{"orders", "shop", "shop", nil},
+		{"orders", "blog", "", ErrNamespaceDenied},
+		{"orders", "default", "", ErrNamespaceDenied},
+		{"stranger", "", "default", nil},
+		{"stranger", "default", "default", nil},
+		{"stranger", "shop", "", ErrNamespaceDenied},
+		{"", "", "default", nil}, // анонимный
+		{"root", "blog", "blog", nil},
+		{"root", "new", "new", nil},
+		{"root", "a/b", "", ErrInvalidNamespace},
+	} {
+		ctx := context.Background()
+		if tc.client != "" {
+			ctx = asClient(tc.client)
+		}
+		got, err := tenants.Resolve(ctx, tc.requested)
+		if got != tc.want || !errors.Is(err, tc.err) {
+			t.Errorf("%s %q: got %q, %v; want %q, %v", tc.client, tc.requested, got, err, tc.want, tc.err)
+		}
+	}
+
+	// С TrustHeader непривязанный клиент выбирает пространство сам, а
+	// привязанный по-прежнему ограничен своим.
+	trusting, _ := NewTenantUseCase(memory.NewTopicRepository(), memory.NewSubscriptionRepository(), TenantPolicy{
+		Enabled:     true,
+		TrustHeader: true,
+		Tenants:     []Tenant{{Namespace: "shop", Principals: []string{"orders"}}},
+	})
+	if ns, err := trusting.Resolve(asClient("stranger"), "blog"); err != nil || ns != "blog" {
+		t.Errorf("trusted header: %q, %v", ns, err)
+	}
+	if _, err := trusting.Resolve(asClient("orders"), "blog"); !errors.Is(err, ErrNamespaceDenied) {
+		t.Errorf("bound client escaped its namespace: %v", err)
+	}
+
+	// Без разделения заголовок игнорируется.
+	off, _ := NewTenantUseCase(memory.NewTopicRepository(), memory.NewSubscriptionRepository(), TenantPolicy{})
+	if ns, err := off.Resolve(asClient("orders"), "shop"); err != nil || ns != domain.DefaultNamespace {
+		t.Errorf("disabled: %q, %v", ns, err)
+	}
+}
+
+func TestTenantUseCase_invalidPolicy(t *testing.T) {
+	for name, p := range map[string]TenantPolicy{
+		"bad default":      {Enabled: true, Default: "a/b"},
+		"bad namespace":    {Enabled: true, Tenants: []Tenant{{Namespace: ""}}},
+		"duplicate":        {Enabled: true, Tenants: []Tenant{{Namespace: "shop"}, {Namespace: "shop"}}},
+		"shared principal": {Enabled: true, Tenants: []Tenant{{Namespace: "shop", Principals: []string{"x"}}, {Namespace: "blog", Principals: []string{"x"}}}},
+	} {
+		if _, err := NewTenantUseCase(memory.NewTopicRepository(), memory.NewSubscriptionRepository(), p); err == nil {
+			t.Errorf("%s: policy accepted", name)
+		}
+	}
+}
+
+func TestTenantUseCase_quotas(t *testing.T) {
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	subs := memory.NewSubscriptionRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	topicUC := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	subUC := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	tenants, err := NewTenantUseCase(topics, subs, TenantPolicy{
+		Enabled: true,
+		Quota:   TenantQuota{MaxTopics: 1, MaxSubscriptions: 1},
+		Tenants: []Tenant{{Namespace: "shop", Quota: TenantQuota{MaxTopics: 2}}},
+	})
+	if err != nil {
+		t.Fatalf("NewTenantUseCase: %v", err)
+	}
+	blog, shop := domain.WithNamespace(context.Background(), "blog"), domain.WithNamespace(context.Background(), "shop")
+
+	// В blog действует общая квота: один топик и одна подписка.
+	if err := tenants.CheckTopicQuota(blog); err != nil {
+		t.Fatalf("first topic: %v", err)
+	}
+	_, _ = topicUC.CreateTopic(blog, "posts", 0)
+	if err := tenants.CheckTopicQuota(blog); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("second topic in blog: %v", err)
+	}
+	_, _ = subUC.Subscribe(blog, "posts", "0", "feed", domain.AtLeastOnce)
+	if err := tenants.CheckSubscriptionQuota(blog); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("second subscription in blog: %v", err)
+	}
+
+	// Ресурсы blog не расходуют квоту shop. У shop свой предел топиков, а
+	// предел подписок общий.
+	_, _ = topicUC.CreateTopic(shop, "orders", 0)
+	if err := tenants.CheckTopicQuota(shop); err != nil {
+		t.Fatalf("second topic in shop: %v", err)
+	}
+	_, _ = topicUC.CreateTopic(shop, "payments", 0)
+	if err := tenants.CheckTopicQuota(shop); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("third topic in shop: %v", err)
+	}
+	if err := tenants.CheckSubscriptionQuota(shop); err != nil {
+		t.Fatalf("first subscription in shop: %v", err)
+	}
+	_, _ = subUC.Subscribe(shop, "orders", "0", "a", domain.AtLeastOnce)
+	if err := tenants.CheckSubscriptionQuota(shop); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("second subscription in shop: %v", err)
+	}
+}
@@ -3,23 +3,70 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
 	"time"
 
 	"queue-service/internal/domain"
 )
 
 var (
-	ErrTopicExists   = errors.New("topic already exists")
-	ErrTopicNotFound = errors.New("topic not found")
+	ErrTopicExists           = errors.New("topic already exists")
+	ErrTopicNotFound         = errors.New("topic not found")
+	ErrTopicHasSubscriptions = errors.New("topic has subscriptions")
+	ErrQueueHasSubscriptions = errors.New("queue has subscriptions")
 )
 
 type TopicUseCase struct {
-	topics domain.TopicRepository
-	queues domain.QueueRepository
+	topics   domain.TopicRepository
+	queues   domain.QueueRepository
+	messages domain.MessageRepository
+	subs     domain.SubscriptionRepository
+	pending  domain.PendingDeliveryRepository
+	// redelivery забывает ожидающих удалённых подписок; nil — не подключён.
+	redelivery *RedeliveryScheduler
+
+	hooksMu  sync.Mutex
+	onDelete []func(ctx context.Context, topicName, queueID string)
 }
 
-func NewTopicUseCase(topics domain.TopicRepository, queues domain.QueueRepository) *TopicUseCase {
-	return &TopicUseCase{topics: topics, queues: queues}
+func NewTopicUseCase(
+	topics domain.TopicRepository,
+	queues domain.QueueRepository,
+	messages domain.MessageRepository,
+	subs domain.SubscriptionRepository,
+	pending domain.PendingDeliveryRepository,
+) *TopicUseCase {
+	return &TopicUseCase{
+		topics:   topics,
+		queues:   queues,
+		messages: messages,
+		subs:     subs,
+		pending:  pending,
+	}
+}
+
+// SetRedelivery подключает планировщик повторной доставки; вызывается до
+// начала работы.
+func (u *TopicUseCase) SetRedelivery(r *RedeliveryScheduler) { u.redelivery = r }
+
+// OnDelete регистрирует fn, вызываемую после удаления топика (queueID пуст)
+// или очереди: так протоколы сбрасывают собственное состояние, чтобы
+// пересозданный топик не унаследовал его.
+func (u *TopicUseCase) OnDelete(fn func(ctx context.Context, topicName, queueID string)) {
+	u.hooksMu.Lock()
+	defer u.hooksMu.Unlock()
+	u.onDelete = append(u.onDelete, fn)
+}
+
+func (u *TopicUseCase) deleted(ctx context.Context, topicName, queueID string) {
+	u.hooksMu.Lock()
+	hooks := u.onDelete
+	u.hooksMu.Unlock()
+	for _, fn := range hooks {
+		fn(ctx, topicName, queueID)
+	}
 }
 
 func (u *TopicUseCase) CreateTopic(ctx context.Context, name string, retentionMessages int) (*domain.Topic, error) {
@@ -28,8 +75,10 @@ func (u *TopicUseCase) CreateTopic(ctx context.Context, name string, retentionMe
 		return nil, ErrTopicExists
 	}
 	topic := &domain.Topic{
+		Namespace:         domain.Namespace(ctx),
 		Name:              name,
 		RetentionMessages: retentionMessages,
+		CreatedBy:         Principal(ctx),
 		CreatedAt:         time.Now(),
 	}
 	if err := u.topics.Create(ctx, topic); err != nil {
@@ -37,6 +86,7 @@ func (u *TopicUseCase) CreateTopic(ctx context.Context, name string, retentionMe
 	}
 	// Create default queue "0" for the topic
 	queue := &domain.Queue{
+		Namespace: topic.Namespace,
 		TopicName: name,
 		QueueID:   "0",
 		CreatedAt: time.Now(),
@@ -45,6 +95,7 @@ func (u *TopicUseCase) CreateTopic(ctx context.Context, name string, retentionMe
 		_ = u.topics.Delete(ctx, name)
 		return nil, err
 	}
+	slog.InfoContext(ctx, "topic created", "topic", name, "retention_messages", retentionMessages)
 	return topic, nil
 }
 
@@ -52,12 +103,59 @@ func (u *TopicUseCase) GetTopic(ctx context.Context, name string) (*domain.Topic
 	return u.topics.Get(ctx, name)
 }
 
+// ListTopics возвращает топики пространства имён запроса.
 func (u *TopicUseCase) ListTopics(ctx context.Context) ([]*domain.Topic, error) {
 	return u.topics.List(ctx)
 }
 
-func (u *TopicUseCase) DeleteTopic(ctx context.Context, name string) error {
-	return u.topics.Delete(ctx, name)
+// Namespaces возвращает пространства имён, в которых есть топики.
+func (u *TopicUseCase) Namespaces(ctx context.Context) ([]string, error) {
+	return u.topics.Namespaces(ctx)
+}
+
+// DeleteTopic удаляет топик вместе с очередями, сообщениями, подписками и их
+// неподтверждёнными доставками. Без force топик с подписками не удаляется.
+func (u *TopicUseCase) DeleteTopic(ctx context.Context, name string, force bool) error {
+	if _, err := u.topics.Get(ctx, name); err != nil {
+		return ErrTopicNotFound
+	}
+	subs, err := u.subs.ListByTopic(ctx, name)
+	if err != nil {
+		return err
+	}
+	if len(subs) > 0 && !force {
+		return ErrTopicHasSubscriptions
+	}
+
+	// Сначала сам топик: новые Publish и Subscribe после этого получают
+	// ErrTopicNotFound, и зависимое состояние больше не пополняется.
+	if err := u.topics.Delete(ctx, name); err != nil {
+		return err
+	}
+	// Подписки перечитываются: между проверкой и удалением могли появиться новые.
+	if subs, err = u.subs.ListByTopic(ctx, name); err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if err := removeSubscription(ctx, u.subs, u.pending, u.redelivery, sub.ID); err != nil {
+			return err
+		}
+	}
+	queues, err := u.queues.ListByTopic(ctx, name)
+	if err != nil {
+		return err
+	}
+	for _, q := range queues {
+		if err := u.queues.Delete(ctx, name, q.QueueID); err != nil {
+			return fmt.Errorf("delete queue %s: %w", q.QueueID, err)
+		}
+		if err := u.messages.DeleteQueue(ctx, name, q.QueueID); err != nil {
+			return fmt.Errorf("delete messages of queue %s: %w", q.QueueID, err)
+		}
+	}
+	u.deleted(ctx, name, "")
+	slog.InfoContext(ctx, "topic deleted", "topic", name, "subscriptions", len(subs), "queues", len(queues))
+	return nil
 }
 
 func (u *TopicUseCase) CreateQueue(ctx context.Context, topicName, queueID string) (*domain.Queue, error) {
@@ -66,6 +164,7 @@ func (u *TopicUseCase) CreateQueue(ctx context.Context, topicName, queueID strin
 		return nil, ErrTopicNotFound
 	}
 	queue := &domain.Queue{
+		Namespace: domain.Namespace(ctx),
 		TopicName: topicName,
 		QueueID:   queueID,
 		CreatedAt: time.Now(),
@@ -73,9 +172,96 @@ func (u *TopicUseCase) CreateQueue(ctx context.Context, topicName, queueID strin
 	if err := u.queues.Create(ctx, queue); err != nil {
 		return nil, err
 	}
+	slog.InfoContext(ctx, "queue created", "topic", topicName, "queue", queueID)
 	return queue, nil
 }
 
 func (u *TopicUseCase) ListQueues(ctx context.Context, topicName string) ([]*domain.Queue, error) {
 	return u.queues.ListByTopic(ctx, topicName)
 }
+
+// DeleteQueue удаляет очередь, её сообщения и подписки на неё. Без force
+// очередь с подписками не удаляется.
+func (u *TopicUseCase) DeleteQueue(ctx context.Context, topicName, queueID string, force bool) error {
+	if err := u.checkQueue(ctx, topicName, queueID); err != nil {
+		return err
+	}
+	subs, err := u.queueSubscriptions(ctx, topicName, queueID)
+	if err != nil {
+		return err
+	}
+	if len(subs) > 0 && !force {
+		return ErrQueueHasSubscriptions
+	}
+
+	if err := u.queues.Delete(ctx, topicName, queueID); err != nil {
+		return err
+	}
+	if subs, err = u.queueSubscriptions(ctx, topicName, queueID); err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if err := removeSubscription(ctx, u.subs, u.pending, u.redelivery, sub.ID); err != nil {
+			return err
+		}
+	}
+	if err := u.messages.DeleteQueue(ctx, topicName, queueID); err != nil {
+		return err
+	}
+	u.deleted(ctx, topicName, queueID)
+	slog.InfoContext(ctx, "queue deleted", "topic", topicName, "queue", queueID, "subscriptions", len(subs))
+	return nil
+}
+
+// PurgeQueue удаляет все сообщения очереди и переносит начало журнала на
+// high-water mark. Неподтверждённые доставки подписок очереди сбрасываются,
+// их смещения переносятся на новое начало. Возвращает новое начало журнала.
+func (u *TopicUseCase) PurgeQueue(ctx context.Context, topicName, queueID string) (int64, error) {
+	if err := u.checkQueue(ctx, topicName, queueID); err != nil {
+		return 0, err
+	}
+	subs, err := u.queueSubscriptions(ctx, topicName, queueID)
+	if err != nil {
+		return 0, err
+	}
+	start, err := u.messages.Purge(ctx, topicName, queueID)
+	if err != nil {
+		return 0, err
+	}
+	for _, sub := range subs {
+		if err := u.pending.RemoveAll(ctx, sub.ID); err != nil {
+			return 0, fmt.Errorf("remove pending deliveries of %s: %w", sub.ID, err)
+		}
+		if sub.Offset < start {
+			if err := u.subs.AdvanceOffset(ctx, sub.ID, start); err != nil {
+				return 0, err
+			}
+		}
+	}
+	slog.InfoContext(ctx, "queue purged", "topic", topicName, "queue", queueID, "start_offset", start)
+	return start, nil
+}
+
+func (u *TopicUseCase) checkQueue(ctx context.Context, topicName, queueID string) error {
+	if _, err := u.topics.Get(ctx, topicName); err != nil {
+		return ErrTopicNotFound
+	}
+	if _, err := u.queues.Get(ctx, topicName, queueID); err != nil {
+		return ErrQueueNotFound
+	}
+	return nil
+}
+
+func (u *TopicUseCase) queueSubscriptions(ctx context.Context, topicName, queueID string) ([]*domain.Subscription, error) {
+	all, err := u.subs.ListByTopic(ctx, topicName)
+	if err != nil {
+		return nil, err
+	}
+	var out []*domain.Subscription
+	for _, sub := range all {
+		if sub.QueueID == queueID {
+			out = append(out, sub)
+		}
+	}
+	return out, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"queue-service/internal/domain"
 	"queue-service/internal/repository/memory"
 )
 
@@ -11,7 +12,7 @@ func TestTopicUseCase_CreateTopic(t *testing.T) {
 	ctx := context.Background()
 	topics := memory.NewTopicRepository()
 	queues := memory.NewQueueRepository()
-	uc := NewTopicUseCase(topics, queues)
+	uc := NewTopicUseCase(topics, queues, memory.NewMessageRepository(), memory.NewSubscriptionRepository(), memory.NewPendingDeliveryRepository())
 
 	t.Run("creates topic and default queue", func(t *testing.T) {
 		topic, err := uc.CreateTopic(ctx, "orders", 5000)
@@ -39,7 +40,7 @@ func TestTopicUseCase_GetTopic_ListTopics(t *testing.T) {
 	ctx := context.Background()
 	topics := memory.NewTopicRepository()
 	queues := memory.NewQueueRepository()
-	uc := NewTopicUseCase(topics, queues)
+	uc := NewTopicUseCase(topics, queues, memory.NewMessageRepository(), memory.NewSubscriptionRepository(), memory.NewPendingDeliveryRepository())
 
 	_, _ = uc.CreateTopic(ctx, "orders", 1000)
 	_, _ = uc.CreateTopic(ctx, "events", 2000)
@@ -58,7 +59,7 @@ func TestTopicUseCase_CreateQueue_ListQueues(t *testing.T) {
 	ctx := context.Background()
 	topics := memory.NewTopicRepository()
 	queues := memory.NewQueueRepository()
-	uc := NewTopicUseCase(topics, queues)
+	uc := NewTopicUseCase(topics, queues, memory.NewMessageRepository(), memory.NewSubscriptionRepository(), memory.NewPendingDeliveryRepository())
 
 	_, _ = uc.CreateTopic(ctx, "orders", 1000)
 
@@ -80,10 +81,125 @@ func TestTopicUseCase_CreateQueue_topicNotFound(t *testing.T) {
 	ctx := context.Background()
 	topics := memory.NewTopicRepository()
 	queues := memory.NewQueueRepository()
-	uc := NewTopicUseCase(topics, queues)
+	uc := NewTopicUseCase(topics, queues, memory.NewMessageRepository(), memory.NewSubscriptionRepository(), memory.NewPendingDeliveryRepository())
 
 	_, err := uc.CreateQueue(ctx, "nonexistent", "0")
 	if err != ErrTopicNotFound {
 		t.Errorf("want ErrTopicNotFound, got %v", err)
 	}
 }
+
+func TestTopicUseCase_DeleteTopic(t *testing.T) {
+	ctx := context.Background()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	subs := memory.NewSubscriptionRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	uc := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	subUC := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := NewConsumeUseCase(subs, msgs, pending, nil)
+
+	_, _ = uc.CreateTopic(ctx, "orders", 1000)
+	_, _ = uc.CreateQueue(ctx, "orders", "1")
+	_, _ = pub.Publish(ctx, "orders", "0", []byte("a"), "", nil)
+	_, _ = pub.Publish(ctx, "orders", "1", []byte("b"), "", nil)
+	sub, err := subUC.Subscribe(ctx, "orders", "0", "g1", domain.AtLeastOnce)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if got, _ := consumeUC.Consume(ctx, sub.ID, 10); len(got) != 1 {
+		t.Fatalf("expected one pending delivery, got %d", len(got))
+	}
+
+	if err := uc.DeleteTopic(ctx, "orders", false); err != ErrTopicHasSubscriptions {
+		t.Fatalf("want ErrTopicHasSubscriptions, got %v", err)
+	}
+	if err := uc.DeleteTopic(ctx, "orders", true); err != nil {
+		t.Fatalf("DeleteTopic: %v", err)
+	}
+
+	if _, err := uc.GetTopic(ctx, "orders"); err == nil {
+		t.Error("topic still exists")
+	}
+	if list, _ := queues.ListByTopic(ctx, "orders"); len(list) != 0 {
+		t.Errorf("queues left: %d", len(list))
+	}
+	for _, q := range []string{"0", "1"} {
+		if _, end, _ := msgs.Offsets(ctx, "orders", q); end != 0 {
+			t.Errorf("queue %s: messages left (end=%d)", q, end)
+		}
+	}
+	if _, err := subs.Get(ctx, sub.ID); err == nil {
+		t.Error("subscription still exists")
+	}
+	if _, ok, _ := pending.LastOffset(ctx, sub.ID); ok {
+		t.Error("pending deliveries left")
+	}
+
+	// Топик с тем же именем создаётся заново с чистым состоянием.
+	_, _ = uc.CreateTopic(ctx, "orders", 1000)
+	if _, err := subUC.Subscribe(ctx, "orders", "0", "g1", domain.AtLeastOnce); err != nil {
+		t.Errorf("re-subscribe after delete: %v", err)
+	}
+	if err := uc.DeleteTopic(ctx, "missing", true); err != ErrTopicNotFound {
+		t.Errorf("want ErrTopicNotFound, got %v", err)
+	}
+}
+
+func TestTopicUseCase_DeleteQueue_PurgeQueue(t *testing.T) {
+	ctx := context.Background()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	subs := memory.NewSubscriptionRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	uc := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	subUC := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := NewConsumeUseCase(subs, msgs, pending, nil)
+
+	_, _ = uc.CreateTopic(ctx, "orders", 1000)
+	_, _ = uc.CreateQueue(ctx, "orders", "1")
+	for i := 0; i < 3; i++ {
+		_, _ = pub.Publish(ctx, "orders", "0", []byte("m"), "", nil)
+	}
+	sub0, _ := subUC.Subscribe(ctx, "orders", "0", "g0", domain.AtLeastOnce)
+	sub1, _ := subUC.Subscribe(ctx, "orders", "1", "g1", domain.AtLeastOnce)
+	_, _ = consumeUC.Consume(ctx, sub0.ID, 1)
+
+	start, err := uc.PurgeQueue(ctx, "orders", "0")
+	if err != nil || start != 3 {
+		t.Fatalf("PurgeQueue: start=%d err=%v", start, err)
+	}
+	if _, ok, _ := pending.LastOffset(ctx, sub0.ID); ok {
+		t.Error("pending deliveries must be dropped by purge")
+	}
+	if got, _ := subs.Get(ctx, sub0.ID); got.Offset != 3 {
+		t.Errorf("subscription offset want 3, got %d", got.Offset)
+	}
+	msg, _ := pub.Publish(ctx, "orders", "0", []byte("after"), "", nil)
+	if got, _ := consumeUC.Consume(ctx, sub0.ID, 10); len(got) != 1 || got[0].ID != msg.ID {
+		t.Errorf("consume after purge: %+v", got)
+	}
+
+	if err := uc.DeleteQueue(ctx, "orders", "1", false); err != ErrQueueHasSubscriptions {
+		t.Fatalf("want ErrQueueHasSubscriptions, got %v", err)
+	}
+	if err := uc.DeleteQueue(ctx, "orders", "1", true); err != nil {
+		t.Fatalf("DeleteQueue: %v", err)
+	}
+	if _, err := subs.Get(ctx, sub1.ID); err == nil {
+		t.Error("subscription on deleted queue still exists")
+	}
+	if _, err := subs.Get(ctx, sub0.ID); err != nil {
+		t.Error("subscription on another queue must remain")
+	}
+	if err := uc.DeleteQueue(ctx, "orders", "1", true); err != ErrQueueNotFound {
+		t.Errorf("want ErrQueueNotFound, got %v", err)
+	}
+	if _, err := uc.PurgeQueue(ctx, "missing", "0"); err != ErrTopicNotFound {
+		t.Errorf("want ErrTopicNotFound, got %v", err)
+	}
+}
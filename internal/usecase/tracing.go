@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "queue-service/internal/usecase"
+
+// traceContext — формат W3C Trace Context, в котором контекст трассы
+// хранится в заголовках сообщения (traceparent, tracestate).
+var traceContext = propagation.TraceContext{}
+
+// Постоянные параметры спанов: создаются один раз, а не при каждом вызове.
+var (
+	producerSpan = trace.WithSpanKind(trace.SpanKindProducer)
+	consumerSpan = trace.WithSpanKind(trace.SpanKindConsumer)
+)
+
+// tracer берётся при каждом вызове, чтобы учитывать TracerProvider,
+// установленный после создания use case.
+func tracer() trace.Tracer { return otel.Tracer(tracerName) }
+
+// messageTraceContext возвращает ctx с контекстом трассы из заголовков сообщения.
+func messageTraceContext(ctx context.Context, headers map[string]string) context.Context {
+	return traceContext.Extract(ctx, propagation.MapCarrier(headers))
+}
+
+// withTraceHeaders возвращает копию headers с контекстом трассы ctx либо
+// сами headers, если трассы нет. Исходную карту не изменяет: она принадлежит
+// отправителю.
+func withTraceHeaders(ctx context.Context, headers map[string]string) map[string]string {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return headers
+	}
+	out := make(map[string]string, len(headers)+2)
+	for k, v := range headers {
+		out[k] = v
+	}
+	traceContext.Inject(ctx, propagation.MapCarrier(out))
+	return out
+}
+
+func destinationAttrs(topicName, queueID string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("messaging.system", "queue-service"),
+		attribute.String("messaging.destination.name", topicName),
+		attribute.String("messaging.destination.partition.id", queueID),
+	}
+}
+
+// endSpan завершает span, отмечая ошибку err.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
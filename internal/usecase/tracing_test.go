@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"queue-service/internal/domain"
+	"queue-service/internal/repository/memory"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordSpans устанавливает глобальный TracerProvider, который сохраняет
+// завершённые спаны; после теста трассировка снова отключена.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+	return rec
+}
+
+func spanByName(t *testing.T, rec *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, s := range rec.Ended() {
+		if s.Name() == name {
+			return s
+		}
+	}
+	t.Fatalf("span %q not recorded", name)
+	return nil
+}
+
+func hasAttribute(s sdktrace.ReadOnlySpan, kv attribute.KeyValue) bool {
+	for _, a := range s.Attributes() {
+		if a == kv {
+			return true
+		}
+	}
+	return false
+}
+
+func TestTracing_publishConsumeAck(t *testing.T) {
+	rec := recordSpans(t)
+	ctx := context.Background()
+	subs := memory.NewSubscriptionRepository()
+	msgs := memory.NewMessageRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+
+	topicUC := NewTopicUseCase(topics, queues, msgs, subs, pending)
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	subUC := NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30)
+	consumeUC := NewConsumeUseCase(subs, msgs, pending, nil)
+
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+	sub, _ := subUC.Subscribe(ctx, "orders", "0", "g1", domain.AtLeastOnce)
+
+	// Пустой опрос спанов не создаёт.
+	if out, _ := consumeUC.Consume(ctx, sub.ID, 10); len(out) != 0 {
+		t.Fatalf("want empty queue, got %d", len(out))
+	}
+
+	// Контекст отправителя пришёл в заголовке сообщения.
+	headers := map[string]string{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"app":         "x",
+	}
+	msg, err := pub.Publish(ctx, "orders", "0", []byte("m1"), "", headers)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if headers["traceparent"] != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("caller headers modified: %v", headers)
+	}
+
+	publish := spanByName(t, rec, "publish orders")
+	if publish.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" ||
+		publish.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("publish span must continue the producer trace, parent %v", publish.Parent())
+	}
+	if publish.SpanKind() != trace.SpanKindProducer {
+		t.Errorf("publish span kind %v", publish.SpanKind())
+	}
+	store := spanByName(t, rec, "store orders")
+	if store.Parent().SpanID() != publish.SpanContext().SpanID() {
+		t.Errorf("store span must be a child of publish")
+	}
+	if !hasAttribute(store, attribute.Int64("messaging.message.offset", msg.Offset)) {
+		t.Errorf("store span attributes: %v", store.Attributes())
+	}
+	want := "00-4bf92f3577b34da6a3ce929d0e0e4736-" + publish.SpanContext().SpanID().String() + "-01"
+	if msg.Headers["traceparent"] != want || msg.Headers["app"] != "x" {
+		t.Errorf("stored headers %v, want traceparent %s", msg.Headers, want)
+	}
+
+	out, err := consumeUC.Consume(ctx, sub.ID, 10)
+	if err != nil || len(out) != 1 {
+		t.Fatalf("Consume: %d messages, %v", len(out), err)
+	}
+	consume := spanByName(t, rec, "consume orders")
+	if links := consume.Links(); len(links) != 1 || links[0].SpanContext.SpanID() != publish.SpanContext().SpanID() {
+		t.Errorf("consume span must link to publish, got %v", links)
+	}
+
+	if err := consumeUC.Ack(ctx, sub.ID, out[0].DeliveryID); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	spanByName(t, rec, "ack orders")
+
+	if n := len(rec.Ended()); n != 4 {
+		t.Errorf("want 4 spans (publish, store, consume, ack), got %d", n)
+	}
+}
+
+func TestTracing_contextOverridesHeaders(t *testing.T) {
+	rec := recordSpans(t)
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	topicUC := NewTopicUseCase(topics, queues, msgs, memory.NewSubscriptionRepository(), memory.NewPendingDeliveryRepository())
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	_, _ = topicUC.CreateTopic(context.Background(), "orders", 10000)
+
+	// Контекст из метаданных gRPC важнее заголовка сообщения.
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "client")
+	parent.End()
+	_, err := pub.Publish(ctx, "orders", "0", []byte("m1"), "", map[string]string{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	})
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	publish := spanByName(t, rec, "publish orders")
+	if publish.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("publish parent %v, want %v", publish.Parent().SpanID(), parent.SpanContext().SpanID())
+	}
+}
+
+func TestTracing_disabledKeepsHeaders(t *testing.T) {
+	ctx := context.Background()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	topicUC := NewTopicUseCase(topics, queues, msgs, memory.NewSubscriptionRepository(), memory.NewPendingDeliveryRepository())
+	pub := NewPublishUseCase(topics, queues, msgs, 1024, MemoryBudget{}, nil)
+	_, _ = topicUC.CreateTopic(ctx, "orders", 10000)
+
+	msg, err := pub.Publish(ctx, "orders", "0", []byte("m1"), "", map[string]string{"app": "x"})
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if len(msg.Headers) != 1 {
+		t.Errorf("without a trace headers must stay as sent, got %v", msg.Headers)
+	}
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"queue-service/internal/delivery/grpc/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Как долго кэшируется список очередей топика для партиционирования по ключу.
+const queuesCacheTTL = time.Minute
+
+// Client — подключение к брокеру, общее для Producer и Consumer.
+type Client struct {
+	conn   *grpc.ClientConn
+	broker pb.BrokerClient
+
+	mu     sync.Mutex
+	queues map[string]cachedQueues
+}
+
+type cachedQueues struct {
+	ids       []string
+	fetchedAt time.Time
+}
+
+type Option func(*options)
+
+type options struct {
+	dialOpts []grpc.DialOption
+}
+
+// WithDialOptions передаёт дополнительные параметры grpc.NewClient
+// (TLS, интерсепторы и т.п.). По умолчанию соединение без TLS.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) { o.dialOpts = append(o.dialOpts, opts...) }
+}
+
+// New подключается к брокеру по адресу target, например "localhost:50051".
+func New(target string, opts ...Option) (*Client, error) {
+	o := options{dialOpts: []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	conn, err := grpc.NewClient(target, o.dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(conn), nil
+}
+
+func newClient(conn *grpc.ClientConn) *Client {
+	return &Client{
+		conn:   conn,
+		broker: pb.NewBrokerClient(conn),
+		queues: make(map[string]cachedQueues),
+	}
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// queuesOf возвращает отсортированные ID очередей топика.
+func (c *Client) queuesOf(ctx context.Context, topic string) ([]string, error) {
+	c.mu.Lock()
+	cached, ok := c.queues[topic]
+	c.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < queuesCacheTTL {
+		return cached.ids, nil
+	}
+
+	resp, err := c.broker.ListQueues(ctx, &pb.ListQueuesRequest{TopicName: topic})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	ids := make([]string, 0, len(resp.Queues))
+	for _, q := range resp.Queues {
+		ids = append(ids, q.QueueId)
+	}
+	sort.Strings(ids)
+
+	c.mu.Lock()
+	c.queues[topic] = cachedQueues{ids: ids, fetchedAt: time.Now()}
+	c.mu.Unlock()
+	return ids, nil
+}
@@ -0,0 +1,498 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"queue-service/internal/auth"
+	grpcdelivery "queue-service/internal/delivery/grpc"
+	"queue-service/internal/delivery/grpc/pb"
+	"queue-service/internal/repository/memory"
+	"queue-service/internal/usecase"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient поднимает настоящий BrokerHandler поверх bufconn и создаёт
+// топик "orders" с очередями "0", "1" и "2".
+func newTestClient(t *testing.T, ackTimeoutSeconds int) *Client {
+	t.Helper()
+	return newTestClientWithQuotas(t, ackTimeoutSeconds, usecase.QuotaPolicy{})
+}
+
+// newTestClientWithQuotas — то же с лимитами частоты quotas.
+func newTestClientWithQuotas(t *testing.T, ackTimeoutSeconds int, policy usecase.QuotaPolicy) *Client {
+	t.Helper()
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	subs := memory.NewSubscriptionRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	topicUC := usecase.NewTopicUseCase(topics, queues, msgs, subs, pending)
+	pub := usecase.NewPublishUseCase(topics, queues, msgs, 1024*1024, usecase.MemoryBudget{}, nil)
+	subUC := usecase.NewSubscriptionUseCase(subs, topics, queues, msgs, pending, ackTimeoutSeconds)
+	consumeUC := usecase.NewConsumeUseCase(subs, msgs, pending, nil)
+	messageUC := usecase.NewMessageUseCase(topics, queues, msgs)
+	tenants, _ := usecase.NewTenantUseCase(topics, subs, usecase.TenantPolicy{})
+	quotas, err := usecase.NewQuotaUseCase(topics, subs, policy)
+	if err != nil {
+		t.Fatalf("NewQuotaUseCase: %v", err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterBrokerServer(srv, grpcdelivery.NewBrokerHandler(topicUC, pub, subUC, consumeUC, messageUC,
+		usecase.NewACLUseCase(memory.NewACLRepository(), usecase.ACLPolicy{}), tenants, quotas))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	c, err := New("passthrough:///bufnet", WithDialOptions(
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	ctx := context.Background()
+	if _, err := topicUC.CreateTopic(ctx, "orders", 10000); err != nil {
+		t.Fatalf("CreateTopic: %v", err)
+	}
+	for _, id := range []string{"1", "2"} {
+		if _, err := topicUC.CreateQueue(ctx, "orders", id); err != nil {
+			t.Fatalf("CreateQueue: %v", err)
+		}
+	}
+	return c
+}
+
+func TestProducer_batchingAndCallbacks(t *testing.T) {
+	c := newTestClient(t, 30)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	delivered := map[string]*Record{}
+	var failed []error
+	p := c.NewProducer(WithBatchSize(4), WithLinger(time.Millisecond), WithDeliveryCallback(func(rec *Record, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			failed = append(failed, err)
+			return
+		}
+		delivered[string(rec.Value)] = rec
+	}))
+
+	for _, v := range []string{"a", "b", "c", "d", "e"} {
+		if err := p.Produce(ctx, &Record{Topic: "orders", Queue: "0", Value: []byte(v)}, nil); err != nil {
+			t.Fatalf("Produce: %v", err)
+		}
+	}
+	if err := p.Produce(ctx, &Record{Topic: "missing", Value: []byte("x")}, nil); err != nil {
+		t.Fatalf("Produce: %v", err)
+	}
+	if err := p.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	mu.Lock()
+	if len(delivered) != 5 {
+		t.Errorf("want 5 delivered, got %d", len(delivered))
+	}
+	if rec := delivered["e"]; rec == nil || rec.Offset != 4 || rec.MessageID == "" {
+		t.Errorf("record e: %+v", rec)
+	}
+	if len(failed) != 1 || !errors.Is(failed[0], ErrNotFound) {
+		t.Errorf("want one ErrNotFound, got %v", failed)
+	}
+	mu.Unlock()
+
+	if err := p.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := p.Produce(ctx, &Record{Topic: "orders"}, nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("want ErrClosed after Close, got %v", err)
+	}
+}
+
+func TestProducer_rateLimited(t *testing.T) {
+	c := newTestClientWithQuotas(t, 30, usecase.QuotaPolicy{Topic: usecase.Quota{PublishMessagesPerSec: 50}})
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var delivered int
+	var failed []error
+	p := c.NewProducer(WithBatchSize(60), WithLinger(10*time.Millisecond), WithRetries(20, time.Millisecond),
+		WithDeliveryCallback(func(rec *Record, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed = append(failed, err)
+				return
+			}
+			delivered++
+		}))
+	// 50 записей проходят сразу, остальные — после пауз из retry-after-ms.
+	for i := 0; i < 55; i++ {
+		if err := p.Produce(ctx, &Record{Topic: "orders", Queue: "0", Value: []byte("m")}, nil); err != nil {
+			t.Fatalf("Produce: %v", err)
+		}
+	}
+	if err := p.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if delivered != 55 || len(failed) != 0 {
+		t.Errorf("delivered %d, failed %v", delivered, failed)
+	}
+}
+
+func TestProducer_retryKeepsOrder(t *testing.T) {
+	c := newTestClientWithQuotas(t, 30, usecase.QuotaPolicy{
+		Topics: map[string]usecase.Quota{"orders": {PublishMessagesPerSec: 2}},
+	})
+	ctx := context.Background()
+	if _, err := c.broker.CreateTopic(ctx, &pb.CreateTopicRequest{Name: "events"}); err != nil {
+		t.Fatalf("CreateTopic: %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	p := c.NewProducer(WithBatchSize(4), WithLinger(time.Second), WithRetries(20, time.Millisecond),
+		WithDeliveryCallback(func(rec *Record, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				t.Errorf("%s: %v", rec.Value, err)
+			}
+			order = append(order, string(rec.Value))
+		}))
+	// Третья запись упирается в лимит, четвёртая в другой топик проходит,
+	// но подтверждается только после третьей.
+	for _, r := range []*Record{
+		{Topic: "orders", Queue: "0", Value: []byte("o1")},
+		{Topic: "orders", Queue: "0", Value: []byte("o2")},
+		{Topic: "orders", Queue: "0", Value: []byte("o3")},
+		{Topic: "events", Value: []byte("e1")},
+	} {
+		if err := p.Produce(ctx, r, nil); err != nil {
+			t.Fatalf("Produce: %v", err)
+		}
+	}
+	if err := p.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"o1", "o2", "o3", "e1"}; !slices.Equal(order, want) {
+		t.Errorf("delivery order %v, want %v", order, want)
+	}
+}
+
+func TestProducer_keyPartitioning(t *testing.T) {
+	c := newTestClient(t, 30)
+	ctx := context.Background()
+	p := c.NewProducer()
+	defer p.Close(ctx)
+
+	queueOf := map[string]string{}
+	for i := 0; i < 3; i++ {
+		for _, key := range []string{"user-1", "user-2", "user-3", "user-4"} {
+			rec := &Record{Topic: "orders", Key: key, Value: []byte("v")}
+			if err := p.ProduceSync(ctx, rec); err != nil {
+				t.Fatalf("ProduceSync: %v", err)
+			}
+			if prev, ok := queueOf[key]; ok && prev != rec.Queue {
+				t.Errorf("key %s moved from queue %s to %s", key, prev, rec.Queue)
+			}
+			queueOf[key] = rec.Queue
+		}
+	}
+	for key, q := range queueOf {
+		if q != "0" && q != "1" && q != "2" {
+			t.Errorf("key %s: unexpected queue %q", key, q)
+		}
+	}
+}
+
+func TestConsumer_autoAck(t *testing.T) {
+	c := newTestClient(t, 30)
+	ctx := context.Background()
+	p := c.NewProducer()
+	defer p.Close(ctx)
+	for _, v := range []string{"ok", "fail", "ok2"} {
+		if err := p.ProduceSync(ctx, &Record{Topic: "orders", Queue: "0", Value: []byte(v)}); err != nil {
+			t.Fatalf("ProduceSync: %v", err)
+		}
+	}
+
+	cons, err := c.Subscribe(ctx, "orders", "0", "g1", AtLeastOnce, WithPollInterval(5*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	var mu sync.Mutex
+	var seen []string
+	failOnce := true
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- cons.Run(ctx, func(_ context.Context, m *Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+			seen = append(seen, string(m.Value))
+			if string(m.Value) == "fail" && failOnce {
+				failOnce = false
+				return errors.New("boom")
+			}
+			return nil
+		})
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		n := len(seen)
+		mu.Unlock()
+		if n >= 4 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := cons.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := <-runErr; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// "fail" отклонён через Nack и доставлен повторно.
+	want := map[string]int{"ok": 1, "fail": 2, "ok2": 1}
+	got := map[string]int{}
+	for _, v := range seen {
+		got[v]++
+	}
+	for v, n := range want {
+		if got[v] != n {
+			t.Errorf("%s: want %d deliveries, got %d (%v)", v, n, got[v], seen)
+		}
+	}
+
+	msgs, err := c.NewConsumer(cons.SubscriptionID()).Poll(ctx)
+	if err != nil || len(msgs) != 0 {
+		t.Errorf("want nothing left after acks, got %d (%v)", len(msgs), err)
+	}
+}
+
+func TestConsumer_explicitAckAndExtension(t *testing.T) {
+	c := newTestClient(t, 1)
+	ctx := context.Background()
+	p := c.NewProducer()
+	defer p.Close(ctx)
+	if err := p.ProduceSync(ctx, &Record{Topic: "orders", Queue: "0", Value: []byte("slow")}); err != nil {
+		t.Fatalf("ProduceSync: %v", err)
+	}
+
+	cons, err := c.Subscribe(ctx, "orders", "0", "g1", AtLeastOnce,
+		WithAckMode(AckExplicit), WithAckDeadlineExtension(time.Second))
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	other := c.NewConsumer(cons.SubscriptionID())
+
+	msgs, err := cons.Poll(ctx)
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("Poll: %d %v", len(msgs), err)
+	}
+	// Обработка дольше ack timeout: без продления сообщение вернулось бы в очередь.
+	stop := cons.keepAlive(ctx, msgs[0])
+	time.Sleep(1500 * time.Millisecond)
+	if redelivered, _ := other.Poll(ctx); len(redelivered) != 0 {
+		t.Errorf("message redelivered while deadline was extended")
+	}
+	stop()
+
+	if err := msgs[0].Ack(ctx); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	if err := msgs[0].Ack(ctx); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Ack: want ErrNotFound, got %v", err)
+	}
+}
+
+func TestTraceContextPropagation(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator()) })
+
+	c := newTestClient(t, 30)
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled,
+	}))
+	p := c.NewProducer()
+	defer p.Close(ctx)
+	headers := map[string]string{"app": "x"}
+	if err := p.ProduceSync(ctx, &Record{Topic: "orders", Queue: "0", Value: []byte("m"), Headers: headers}); err != nil {
+		t.Fatalf("ProduceSync: %v", err)
+	}
+	if len(headers) != 1 {
+		t.Errorf("caller headers modified: %v", headers)
+	}
+
+	cons, err := c.Subscribe(context.Background(), "orders", "0", "g1", AtLeastOnce, WithPollInterval(5*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	got := make(chan trace.SpanContext, 1)
+	runCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = cons.Run(runCtx, func(ctx context.Context, _ *Message) error {
+			got <- trace.SpanContextFromContext(ctx)
+			cancel()
+			return nil
+		})
+	}()
+	select {
+	case sc := <-got:
+		if sc.TraceID() != traceID {
+			t.Errorf("handler trace %v, want %v", sc.TraceID(), traceID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("message not delivered")
+	}
+}
+
+func TestConsumer_startPosition(t *testing.T) {
+	c := newTestClient(t, 30)
+	ctx := context.Background()
+	p := c.NewProducer()
+	defer p.Close(ctx)
+	if err := p.ProduceSync(ctx, &Record{Topic: "orders", Queue: "0", Value: []byte("old")}); err != nil {
+		t.Fatalf("ProduceSync: %v", err)
+	}
+
+	latest, err := c.Subscribe(ctx, "orders", "0", "tail", AtMostOnce, WithStartLatest())
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	since, err := c.Subscribe(ctx, "orders", "0", "since", AtMostOnce, WithStartTime(time.Now().Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if err := p.ProduceSync(ctx, &Record{Topic: "orders", Queue: "0", Value: []byte("new")}); err != nil {
+		t.Fatalf("ProduceSync: %v", err)
+	}
+
+	for _, cons := range []*Consumer{latest, since} {
+		msgs, err := cons.Poll(ctx)
+		if err != nil || len(msgs) != 1 || string(msgs[0].Value) != "new" {
+			t.Errorf("Poll: %v %+v", err, msgs)
+		}
+	}
+}
+
+func TestMapError(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want error
+	}{
+		{codes.NotFound, ErrNotFound},
+		{codes.AlreadyExists, ErrAlreadyExists},
+		{codes.InvalidArgument, ErrInvalidArgument},
+		{codes.FailedPrecondition, ErrFailedPrecondition},
+		{codes.Internal, ErrInternal},
+		{codes.Unavailable, ErrUnavailable},
+		{codes.Unauthenticated, ErrUnauthenticated},
+		{codes.PermissionDenied, ErrPermissionDenied},
+		{codes.ResourceExhausted, ErrResourceExhausted},
+	}
+	for _, tt := range tests {
+		err := mapError(status.Error(tt.code, "details"))
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%v: want %v, got %v", tt.code, tt.want, err)
+		}
+	}
+	if err := mapError(status.Error(codes.Aborted, "no")); status.Code(err) != codes.Aborted {
+		t.Errorf("unmapped code should keep status, got %v", err)
+	}
+}
+
+func TestWithAPIKey(t *testing.T) {
+	authn, err := auth.NewAuthenticator(auth.Config{APIKeys: []auth.APIKey{{Name: "orders", Key: "secret"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	topics := memory.NewTopicRepository()
+	queues := memory.NewQueueRepository()
+	msgs := memory.NewMessageRepository()
+	subs := memory.NewSubscriptionRepository()
+	pending := memory.NewPendingDeliveryRepository()
+	tenants, _ := usecase.NewTenantUseCase(topics, subs, usecase.TenantPolicy{Enabled: true, Admins: []string{"orders"}})
+	quotas, _ := usecase.NewQuotaUseCase(topics, subs, usecase.QuotaPolicy{})
+	handler := grpcdelivery.NewBrokerHandler(
+		usecase.NewTopicUseCase(topics, queues, msgs, subs, pending),
+		usecase.NewPublishUseCase(topics, queues, msgs, 1024, usecase.MemoryBudget{}, nil),
+		usecase.NewSubscriptionUseCase(subs, topics, queues, msgs, pending, 30),
+		usecase.NewConsumeUseCase(subs, msgs, pending, nil),
+		usecase.NewMessageUseCase(topics, queues, msgs),
+		usecase.NewACLUseCase(memory.NewACLRepository(), usecase.ACLPolicy{}),
+		tenants,
+		quotas,
+	)
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpcdelivery.AuthUnaryInterceptor(authn), grpcdelivery.NamespaceUnaryInterceptor(tenants))
+	pb.RegisterBrokerServer(srv, handler)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	dial := func(opts ...Option) *Client {
+		c, err := New("passthrough:///bufnet", append(opts, WithDialOptions(
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+		))...)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		t.Cleanup(func() { _ = c.Close() })
+		return c
+	}
+	ctx := context.Background()
+	if _, err := dial().broker.ListTopics(ctx, &pb.ListTopicsRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("without key: %v", err)
+	}
+	if _, err := dial(WithAPIKey("secret")).broker.ListTopics(ctx, &pb.ListTopicsRequest{}); err != nil {
+		t.Errorf("with key: %v", err)
+	}
+
+	// Топик, созданный в пространстве shop, не виден в пространстве по умолчанию.
+	shop := dial(WithAPIKey("secret"), WithNamespace("shop"))
+	if _, err := shop.broker.CreateTopic(ctx, &pb.CreateTopicRequest{Name: "orders"}); err != nil {
+		t.Fatalf("CreateTopic in shop: %v", err)
+	}
+	if resp, _ := shop.broker.ListTopics(ctx, &pb.ListTopicsRequest{}); len(resp.GetTopics()) != 1 || resp.Topics[0].Namespace != "shop" {
+		t.Errorf("shop topics: %v", resp.GetTopics())
+	}
+	if resp, _ := dial(WithAPIKey("secret")).broker.ListTopics(ctx, &pb.ListTopicsRequest{}); len(resp.GetTopics()) != 0 {
+		t.Errorf("default namespace sees %v", resp.GetTopics())
+	}
+}
@@ -0,0 +1,366 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"queue-service/internal/delivery/grpc/pb"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+type DeliveryGuarantee int
+
+const (
+	AtMostOnce DeliveryGuarantee = iota
+	AtLeastOnce
+)
+
+type AckMode int
+
+const (
+	// AckAuto — Run подтверждает сообщение, если обработчик вернул nil,
+	// и возвращает его в очередь (Nack) при ошибке.
+	AckAuto AckMode = iota
+	// AckExplicit — обработчик сам вызывает Message.Ack или Message.Nack.
+	AckExplicit
+)
+
+// Message — полученное сообщение. DeliveryID пуст для подписок at-most-once:
+// такие сообщения подтверждать не нужно.
+type Message struct {
+	ID         string
+	Topic      string
+	Queue      string
+	Key        string
+	Value      []byte
+	Headers    map[string]string
+	Offset     int64
+	DeliveryID string
+
+	consumer *Consumer
+}
+
+func (m *Message) Ack(ctx context.Context) error {
+	if m.DeliveryID == "" {
+		return nil
+	}
+	_, err := m.consumer.client.broker.Ack(ctx, &pb.AckRequest{
+		SubscriptionId: m.consumer.subID,
+		DeliveryId:     m.DeliveryID,
+	})
+	return mapError(err)
+}
+
+// Nack возвращает сообщение в очередь для немедленной повторной доставки.
+func (m *Message) Nack(ctx context.Context) error {
+	if m.DeliveryID == "" {
+		return nil
+	}
+	_, err := m.consumer.client.broker.Nack(ctx, &pb.NackRequest{
+		SubscriptionId: m.consumer.subID,
+		DeliveryId:     m.DeliveryID,
+	})
+	return mapError(err)
+}
+
+// ExtendAckDeadline переносит срок подтверждения на d от текущего момента.
+// Сервер работает с точностью до секунды.
+func (m *Message) ExtendAckDeadline(ctx context.Context, d time.Duration) error {
+	if m.DeliveryID == "" {
+		return nil
+	}
+	seconds := int32((d + time.Second - 1) / time.Second)
+	_, err := m.consumer.client.broker.ExtendAckDeadline(ctx, &pb.ExtendAckDeadlineRequest{
+		SubscriptionId:   m.consumer.subID,
+		DeliveryId:       m.DeliveryID,
+		ExtensionSeconds: seconds,
+	})
+	return mapError(err)
+}
+
+// Handler обрабатывает одно сообщение в Consumer.Run.
+type Handler func(ctx context.Context, msg *Message) error
+
+type ConsumerOption func(*consumerConfig)
+
+type consumerConfig struct {
+	maxMessages  int
+	pollInterval time.Duration
+	ackMode      AckMode
+	ackExtension time.Duration
+
+	// Учитываются только в Client.Subscribe.
+	startLatest bool
+	startTime   time.Time
+}
+
+// WithMaxMessages — сколько сообщений запрашивать за один Consume (по умолчанию 10).
+func WithMaxMessages(n int) ConsumerOption {
+	return func(c *consumerConfig) { c.maxMessages = n }
+}
+
+// WithPollInterval — пауза перед следующим Consume, если сообщений не было
+// (по умолчанию 200ms).
+func WithPollInterval(d time.Duration) ConsumerOption {
+	return func(c *consumerConfig) { c.pollInterval = d }
+}
+
+func WithAckMode(mode AckMode) ConsumerOption {
+	return func(c *consumerConfig) { c.ackMode = mode }
+}
+
+// WithAckDeadlineExtension включает продление срока подтверждения, пока
+// работает обработчик: каждые d/2 срок переносится на d вперёд.
+func WithAckDeadlineExtension(d time.Duration) ConsumerOption {
+	return func(c *consumerConfig) { c.ackExtension = d }
+}
+
+// WithStartLatest — новая подписка читает только сообщения, опубликованные
+// после Subscribe. По умолчанию чтение идёт с начала очереди.
+func WithStartLatest() ConsumerOption {
+	return func(c *consumerConfig) { c.startLatest = true }
+}
+
+// WithStartTime — новая подписка читает с первого сообщения, опубликованного
+// не раньше t.
+func WithStartTime(t time.Time) ConsumerOption {
+	return func(c *consumerConfig) { c.startTime = t }
+}
+
+// Consumer читает сообщения одной подписки.
+type Consumer struct {
+	client *Client
+	subID  string
+	cfg    consumerConfig
+
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	mu      sync.Mutex
+	running chan struct{} // закрывается, когда Run завершился
+}
+
+// Subscribe создаёт подписку и возвращает Consumer для неё.
+func (c *Client) Subscribe(
+	ctx context.Context,
+	topic, queue, group string,
+	guarantee DeliveryGuarantee,
+	opts ...ConsumerOption,
+) (*Consumer, error) {
+	dg := pb.DeliveryGuarantee_AT_MOST_ONCE
+	if guarantee == AtLeastOnce {
+		dg = pb.DeliveryGuarantee_AT_LEAST_ONCE
+	}
+	var cfg consumerConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	req := &pb.SubscribeRequest{
+		TopicName:         topic,
+		QueueId:           queue,
+		ConsumerGroup:     group,
+		DeliveryGuarantee: dg,
+	}
+	switch {
+	case cfg.startLatest:
+		req.StartPosition = pb.SeekPosition_LATEST
+	case !cfg.startTime.IsZero():
+		req.StartPosition = pb.SeekPosition_TIMESTAMP
+		req.StartTimestampUnixMs = cfg.startTime.UnixMilli()
+	}
+	resp, err := c.broker.Subscribe(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return c.NewConsumer(resp.SubscriptionId, opts...), nil
+}
+
+// NewConsumer создаёт Consumer для уже существующей подписки.
+func (c *Client) NewConsumer(subscriptionID string, opts ...ConsumerOption) *Consumer {
+	cfg := consumerConfig{
+		maxMessages:  10,
+		pollInterval: 200 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Consumer{
+		client:  c,
+		subID:   subscriptionID,
+		cfg:     cfg,
+		closing: make(chan struct{}),
+	}
+}
+
+func (c *Consumer) SubscriptionID() string {
+	return c.subID
+}
+
+// Poll выполняет один запрос Consume. Пустой результат не является ошибкой.
+func (c *Consumer) Poll(ctx context.Context) ([]*Message, error) {
+	select {
+	case <-c.closing:
+		return nil, ErrClosed
+	default:
+	}
+
+	resp, err := c.client.broker.Consume(ctx, &pb.ConsumeRequest{
+		SubscriptionId: c.subID,
+		MaxMessages:    int32(c.cfg.maxMessages),
+	})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	msgs := make([]*Message, len(resp.Messages))
+	for i, m := range resp.Messages {
+		msgs[i] = &Message{
+			ID:         m.Id,
+			Topic:      m.TopicName,
+			Queue:      m.QueueId,
+			Key:        m.Key,
+			Value:      m.Payload,
+			Headers:    m.Headers,
+			Offset:     m.Offset,
+			DeliveryID: m.DeliveryId,
+			consumer:   c,
+		}
+	}
+	return msgs, nil
+}
+
+// Run опрашивает подписку и передаёт сообщения handler по одному, пока не
+// отменён ctx или не вызван Close. После Close возвращает nil; временные
+// ошибки сервера переживает, повторяя опрос.
+func (c *Consumer) Run(ctx context.Context, handler Handler) error {
+	c.mu.Lock()
+	if c.running != nil {
+		c.mu.Unlock()
+		return errors.New("client: consumer is already running")
+	}
+	done := make(chan struct{})
+	c.running = done
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.running = nil
+		c.mu.Unlock()
+		close(done)
+	}()
+
+	for {
+		msgs, err := c.Poll(ctx)
+		switch {
+		case errors.Is(err, ErrClosed):
+			return nil
+		case err != nil && ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, ErrUnavailable):
+			msgs = nil
+		case err != nil:
+			return err
+		}
+
+		for _, m := range msgs {
+			if err := c.handle(ctx, handler, m); err != nil {
+				return err
+			}
+			select {
+			case <-c.closing:
+				return nil
+			default:
+			}
+		}
+
+		if len(msgs) == 0 {
+			select {
+			case <-time.After(c.cfg.pollInterval):
+			case <-c.closing:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// handle вызывает обработчик, продлевая срок подтверждения, и в режиме
+// AckAuto подтверждает результат. Ошибкой Run считаются только сбои Ack/Nack.
+// Обработчик получает ctx с контекстом трассы из заголовков сообщения и
+// продолжает трассу производителя.
+func (c *Consumer) handle(ctx context.Context, handler Handler, m *Message) error {
+	stop := c.keepAlive(ctx, m)
+	herr := handler(otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(m.Headers)), m)
+	stop()
+
+	if c.cfg.ackMode != AckAuto {
+		return nil
+	}
+	var err error
+	if herr == nil {
+		err = m.Ack(ctx)
+	} else {
+		err = m.Nack(ctx)
+	}
+	if errors.Is(err, ErrNotFound) {
+		// Срок подтверждения истёк, сообщение уже вернулось в очередь.
+		return nil
+	}
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// keepAlive продлевает срок подтверждения m в фоне, пока не вызвана
+// возвращённая функция.
+func (c *Consumer) keepAlive(ctx context.Context, m *Message) (stop func()) {
+	if c.cfg.ackExtension <= 0 || m.DeliveryID == "" {
+		return func() {}
+	}
+	quit := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(c.cfg.ackExtension / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = m.ExtendAckDeadline(ctx, c.cfg.ackExtension)
+			case <-quit:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() {
+		close(quit)
+		wg.Wait()
+	}
+}
+
+// Close останавливает Run после обработки текущего сообщения и ждёт его
+// завершения. Неподтверждённые сообщения at-least-once будут доставлены
+// повторно по истечении срока.
+func (c *Consumer) Close(ctx context.Context) error {
+	c.closeOnce.Do(func() { close(c.closing) })
+
+	c.mu.Lock()
+	done := c.running
+	c.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// staticCredentials добавляет к каждому вызову одни и те же метаданные.
+type staticCredentials map[string]string
+
+func (c staticCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return c, nil
+}
+
+// RequireTransportSecurity разрешает передачу без TLS: брокер в разработке
+// часто слушает без TLS. В проде соединение должно быть защищено.
+func (staticCredentials) RequireTransportSecurity() bool { return false }
+
+// WithAPIKey передаёт с каждым вызовом API-ключ (метаданные x-api-key).
+func WithAPIKey(key string) Option {
+	return WithDialOptions(grpc.WithPerRPCCredentials(staticCredentials{"x-api-key": key}))
+}
+
+// WithBearerToken передаёт с каждым вызовом JWT (authorization: Bearer).
+func WithBearerToken(token string) Option {
+	return WithDialOptions(grpc.WithPerRPCCredentials(staticCredentials{"authorization": "Bearer " + token}))
+}
+
+// WithNamespace выбирает пространство имён брокера (метаданные x-namespace).
+// Без него брокер определяет пространство по клиенту.
+func WithNamespace(ns string) Option {
+	return WithDialOptions(grpc.WithPerRPCCredentials(staticCredentials{"x-namespace": ns}))
+}
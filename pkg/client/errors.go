@@ -0,0 +1,88 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Ошибки брокера, восстановленные из кодов gRPC (см. internal/delivery/grpc/errors.go).
+// Проверяются через errors.Is; текст исходной ошибки сохраняется.
+var (
+	ErrNotFound           = errors.New("broker: not found")
+	ErrAlreadyExists      = errors.New("broker: already exists")
+	ErrInvalidArgument    = errors.New("broker: invalid argument")
+	ErrFailedPrecondition = errors.New("broker: failed precondition")
+	ErrInternal           = errors.New("broker: internal error")
+	ErrUnavailable        = errors.New("broker: unavailable")
+	ErrUnauthenticated    = errors.New("broker: unauthenticated")
+	ErrPermissionDenied   = errors.New("broker: permission denied")
+	ErrResourceExhausted  = errors.New("broker: resource exhausted")
+
+	// ErrClosed возвращается при использовании закрытого Producer или Consumer.
+	ErrClosed = errors.New("client: closed")
+)
+
+var codeErrors = map[codes.Code]error{
+	codes.NotFound:           ErrNotFound,
+	codes.AlreadyExists:      ErrAlreadyExists,
+	codes.InvalidArgument:    ErrInvalidArgument,
+	codes.FailedPrecondition: ErrFailedPrecondition,
+	codes.Internal:           ErrInternal,
+	codes.Unavailable:        ErrUnavailable,
+	codes.DeadlineExceeded:   ErrUnavailable,
+	codes.Unauthenticated:    ErrUnauthenticated,
+	codes.PermissionDenied:   ErrPermissionDenied,
+	codes.ResourceExhausted:  ErrResourceExhausted,
+}
+
+// mapError переводит ошибку gRPC в одну из ошибок пакета.
+func mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return errorFromStatus(st.Code(), st.Message())
+}
+
+func errorFromStatus(code codes.Code, msg string) error {
+	if sentinel, ok := codeErrors[code]; ok {
+		return fmt.Errorf("%w: %s", sentinel, msg)
+	}
+	return status.Error(code, msg)
+}
+
+// retryable — ошибки, после которых запрос имеет смысл повторить.
+func retryable(code codes.Code) bool {
+	switch code {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+// retryAfterHeader — метаданные ответа, отклонённого лимитом частоты: через
+// сколько миллисекунд повторить вызов.
+const retryAfterHeader = "retry-after-ms"
+
+// retryAfter возвращает паузу из заголовков ответа; 0 — вызов не упирался
+// в лимит частоты.
+func retryAfter(header metadata.MD) time.Duration {
+	v := header.Get(retryAfterHeader)
+	if len(v) == 0 {
+		return 0
+	}
+	ms, err := strconv.ParseInt(v[0], 10, 64)
+	if err != nil || ms <= 0 {
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
@@ -0,0 +1,390 @@
+package client
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"queue-service/internal/delivery/grpc/pb"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Record — сообщение для публикации. Если Queue пустая, а Key задан, очередь
+// выбирается хешем ключа среди очередей топика; без ключа сервер пишет в
+// очередь по умолчанию.
+type Record struct {
+	Topic   string
+	Queue   string
+	Key     string
+	Value   []byte
+	Headers map[string]string
+
+	// Заполняются после успешной доставки.
+	MessageID string
+	Offset    int64
+}
+
+// DeliveryFunc вызывается ровно один раз для каждой записи после её
+// подтверждения сервером или окончательной ошибки.
+type DeliveryFunc func(rec *Record, err error)
+
+type ProducerOption func(*producerConfig)
+
+type producerConfig struct {
+	batchSize      int
+	linger         time.Duration
+	bufferSize     int
+	maxRetries     int
+	retryBackoff   time.Duration
+	requestTimeout time.Duration
+	onDelivery     DeliveryFunc
+}
+
+// WithBatchSize — максимальное число записей в одном PublishBatch (по умолчанию 100).
+func WithBatchSize(n int) ProducerOption {
+	return func(c *producerConfig) { c.batchSize = n }
+}
+
+// WithLinger — сколько ждать добора пачки после первой записи (по умолчанию 5ms).
+func WithLinger(d time.Duration) ProducerOption {
+	return func(c *producerConfig) { c.linger = d }
+}
+
+// WithBufferSize — сколько записей может ждать отправки; Produce блокируется,
+// когда буфер полон (по умолчанию 10000).
+func WithBufferSize(n int) ProducerOption {
+	return func(c *producerConfig) { c.bufferSize = n }
+}
+
+// WithRetries задаёт число повторов при временных ошибках и начальную паузу,
+// которая удваивается с каждой попыткой (по умолчанию 3 и 100ms).
+func WithRetries(n int, backoff time.Duration) ProducerOption {
+	return func(c *producerConfig) {
+		c.maxRetries = n
+		c.retryBackoff = backoff
+	}
+}
+
+// WithRequestTimeout — таймаут одного запроса PublishBatch (по умолчанию 10s).
+func WithRequestTimeout(d time.Duration) ProducerOption {
+	return func(c *producerConfig) { c.requestTimeout = d }
+}
+
+// WithDeliveryCallback задаёт обработчик доставки для записей, переданных
+// в Produce без собственного.
+func WithDeliveryCallback(fn DeliveryFunc) ProducerOption {
+	return func(c *producerConfig) { c.onDelivery = fn }
+}
+
+type pendingRecord struct {
+	rec     *Record
+	promise DeliveryFunc
+}
+
+// Producer публикует записи пачками в фоне. Записи одного Producer
+// подтверждаются в порядке вызовов Produce, и смещения подтверждённых записей
+// одной очереди растут в том же порядке.
+//
+// Доставка — «хотя бы раз»: после временной ошибки пачка отправляется заново
+// начиная с первой неудавшейся записи, поэтому записи, которые брокер уже
+// принял (в том числе при потерянном ответе), могут оказаться в очереди
+// дважды. Подтверждение сообщает смещение последней копии.
+type Producer struct {
+	client *Client
+	cfg    producerConfig
+
+	records chan pendingRecord
+	done    chan struct{}
+
+	// closeMu защищает records от записи после закрытия канала.
+	closeMu sync.RWMutex
+	closed  bool
+
+	mu       sync.Mutex
+	inflight int
+	idle     chan struct{} // закрыт, когда inflight == 0
+}
+
+// NewProducer создаёт Producer и запускает фоновую отправку.
+func (c *Client) NewProducer(opts ...ProducerOption) *Producer {
+	cfg := producerConfig{
+		batchSize:      100,
+		linger:         5 * time.Millisecond,
+		bufferSize:     10000,
+		maxRetries:     3,
+		retryBackoff:   100 * time.Millisecond,
+		requestTimeout: 10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.batchSize <= 0 {
+		cfg.batchSize = 1
+	}
+
+	idle := make(chan struct{})
+	close(idle)
+	p := &Producer{
+		client:  c,
+		cfg:     cfg,
+		records: make(chan pendingRecord, cfg.bufferSize),
+		done:    make(chan struct{}),
+		idle:    idle,
+	}
+	go p.run()
+	return p
+}
+
+// Produce ставит запись в очередь на отправку. promise (если не nil)
+// вызывается вместо обработчика из WithDeliveryCallback. Блокируется, пока
+// в буфере нет места или не отменён ctx.
+//
+// Контекст трассы из ctx добавляется в заголовки записи глобальным
+// пропагатором OpenTelemetry (otel.SetTextMapPropagator), если заголовки
+// ещё не содержат traceparent.
+func (p *Producer) Produce(ctx context.Context, rec *Record, promise DeliveryFunc) error {
+	p.closeMu.RLock()
+	defer p.closeMu.RUnlock()
+	if p.closed {
+		return ErrClosed
+	}
+	injectTraceContext(ctx, rec)
+
+	p.mu.Lock()
+	if p.inflight == 0 {
+		p.idle = make(chan struct{})
+	}
+	p.inflight++
+	p.mu.Unlock()
+
+	select {
+	case p.records <- pendingRecord{rec: rec, promise: promise}:
+		return nil
+	case <-ctx.Done():
+		p.release()
+		return ctx.Err()
+	}
+}
+
+// ProduceSync публикует запись и ждёт подтверждения. После успеха у rec
+// заполнены MessageID и Offset.
+func (p *Producer) ProduceSync(ctx context.Context, rec *Record) error {
+	result := make(chan error, 1)
+	if err := p.Produce(ctx, rec, func(_ *Record, err error) { result <- err }); err != nil {
+		return err
+	}
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush ждёт доставки всех записей, поставленных до вызова.
+func (p *Producer) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	idle := p.idle
+	p.mu.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close перестаёт принимать записи, дожидается отправки уже поставленных
+// и останавливает фоновую горутину.
+func (p *Producer) Close(ctx context.Context) error {
+	p.closeMu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.records)
+	}
+	p.closeMu.Unlock()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Producer) release() {
+	p.mu.Lock()
+	p.inflight--
+	if p.inflight == 0 {
+		close(p.idle)
+	}
+	p.mu.Unlock()
+}
+
+func (p *Producer) run() {
+	defer close(p.done)
+
+	batch := make([]pendingRecord, 0, p.cfg.batchSize)
+	timer := time.NewTimer(p.cfg.linger)
+	timer.Stop()
+	var lingerC <-chan time.Time
+
+	flush := func() {
+		timer.Stop()
+		lingerC = nil
+		if len(batch) > 0 {
+			p.send(batch)
+			batch = batch[:0]
+		}
+	}
+
+	for {
+		select {
+		case pr, ok := <-p.records:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, pr)
+			if len(batch) >= p.cfg.batchSize {
+				flush()
+			} else if len(batch) == 1 {
+				timer.Reset(p.cfg.linger)
+				lingerC = timer.C
+			}
+		case <-lingerC:
+			flush()
+		}
+	}
+}
+
+// send отправляет пачку. После временной ошибки повторяется хвост пачки
+// начиная с первой неудавшейся записи, чтобы не нарушить порядок.
+func (p *Producer) send(batch []pendingRecord) {
+	todo := make([]pendingRecord, 0, len(batch))
+	for _, pr := range batch {
+		if err := p.resolveQueue(pr.rec); err != nil {
+			p.finish(pr, err)
+			continue
+		}
+		todo = append(todo, pr)
+	}
+
+	backoff := p.cfg.retryBackoff
+	var throttled time.Duration // пауза, которую запросил брокер
+	for attempt := 0; len(todo) > 0; attempt++ {
+		if attempt > 0 {
+			time.Sleep(max(backoff, throttled))
+			backoff *= 2
+		}
+		canRetry := attempt < p.cfg.maxRetries
+
+		req := &pb.PublishBatchRequest{Messages: make([]*pb.PublishRequest, len(todo))}
+		for i, pr := range todo {
+			req.Messages[i] = &pb.PublishRequest{
+				TopicName: pr.rec.Topic,
+				QueueId:   pr.rec.Queue,
+				Payload:   pr.rec.Value,
+				Key:       pr.rec.Key,
+				Headers:   pr.rec.Headers,
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), p.cfg.requestTimeout)
+		var header metadata.MD
+		resp, err := p.client.broker.PublishBatch(ctx, req, grpc.Header(&header))
+		cancel()
+		throttled = retryAfter(header)
+		if err != nil {
+			if canRetry && retryable(status.Code(err)) {
+				continue
+			}
+			err = mapError(err)
+			for _, pr := range todo {
+				p.finish(pr, err)
+			}
+			return
+		}
+
+		next := len(todo)
+	results:
+		for i, pr := range todo {
+			if i >= len(resp.Results) {
+				p.finish(pr, errorFromStatus(codes.Internal, "missing publish result"))
+				continue
+			}
+			res := resp.Results[i]
+			code := codes.Code(res.Code)
+			switch {
+			case code == codes.OK:
+				pr.rec.MessageID = res.MessageId
+				pr.rec.Offset = res.Offset
+				p.finish(pr, nil)
+			// Отказ по лимиту частоты повторяется после паузы из retry-after-ms;
+			// другие RESOURCE_EXHAUSTED (память, квоты) — нет.
+			case canRetry && (retryable(code) || code == codes.ResourceExhausted && throttled > 0):
+				next = i
+				break results
+			default:
+				p.finish(pr, errorFromStatus(code, res.Error))
+			}
+		}
+		todo = todo[next:]
+	}
+}
+
+// resolveQueue выбирает очередь по ключу: FNV-1a по отсортированному списку
+// очередей топика, чтобы записи с одним ключом попадали в одну очередь.
+func (p *Producer) resolveQueue(rec *Record) error {
+	if rec.Queue != "" || rec.Key == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.cfg.requestTimeout)
+	defer cancel()
+	ids, err := p.client.queuesOf(ctx, rec.Topic)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(rec.Key))
+	rec.Queue = ids[h.Sum32()%uint32(len(ids))]
+	return nil
+}
+
+func (p *Producer) finish(pr pendingRecord, err error) {
+	switch {
+	case pr.promise != nil:
+		pr.promise(pr.rec, err)
+	case p.cfg.onDelivery != nil:
+		p.cfg.onDelivery(pr.rec, err)
+	}
+	p.release()
+}
+
+// injectTraceContext записывает контекст трассы ctx в копию rec.Headers:
+// карта принадлежит вызывающему и может использоваться повторно.
+func injectTraceContext(ctx context.Context, rec *Record) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+	if _, ok := rec.Headers["traceparent"]; ok {
+		return
+	}
+	headers := make(map[string]string, len(rec.Headers)+2)
+	for k, v := range rec.Headers {
+		headers[k] = v
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+	rec.Headers = headers
+}
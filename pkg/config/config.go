@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
@@ -13,26 +14,177 @@ type Config struct {
 	Server  ServerConfig
 	Broker  BrokerConfig
 	Logging LoggingConfig
+	Tracing TracingConfig
+	Auth    AuthConfig
+	ACL     ACLConfig
+	Tenancy TenancyConfig
+	Quotas  QuotasConfig
 }
 
 type ServerConfig struct {
-	GRPCPort int
-	HTTPPort int
+	GRPCPort  int
+	HTTPPort  int
+	STOMPPort int // 0 — слушатель STOMP отключён
+	KafkaPort int // 0 — слушатель Kafka отключён
+	// Адрес, который клиенты Kafka получают в Metadata и FindCoordinator.
+	KafkaAdvertisedHost string
+	// DrainDelaySeconds — сколько брокер после сигнала остановки отвечает
+	// «не готов», прежде чем закрыть слушатели.
+	DrainDelaySeconds int
+	// ShutdownTimeoutSeconds — сколько ждать завершения начатых RPC; 0 — без ограничения.
+	ShutdownTimeoutSeconds int
+	TLS                    TLSConfig
+}
+
+// TLSConfig — TLS слушателя gRPC. Пустой CertFile — соединения без TLS.
+type TLSConfig struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string // CA клиентских сертификатов для mTLS
+	ClientAuth   string // require (по умолчанию) или optional
+	// ReloadIntervalSeconds — как часто проверять, изменились ли файлы.
+	ReloadIntervalSeconds int
 }
 
 type BrokerConfig struct {
 	DefaultRetentionMessages int
 	AckTimeoutSeconds        int
 	MaxMessageSize           int
+	Memory                   MemoryConfig
+}
+
+// MemoryConfig — бюджет памяти под сообщения. Нулевые лимиты — без ограничения.
+type MemoryConfig struct {
+	LimitBytes      int64
+	TopicLimitBytes int64            // лимит каждого топика по умолчанию
+	TopicLimits     map[string]int64 // лимиты отдельных топиков, байты
+	OverflowPolicy  string           // reject, block или evict
+	BlockTimeoutMs  int
+}
+
+// TracingConfig — экспорт трасс OpenTelemetry.
+type TracingConfig struct {
+	Exporter    string // none, otlp, stdout или file
+	Endpoint    string // адрес OTLP/gRPC коллектора host:port
+	Insecure    bool
+	File        string
+	ServiceName string
+	SampleRatio float64 // 0 или 1 — записывать все трассы
+}
+
+// AuthConfig — аутентификация клиентов gRPC и STOMP. Без ключей и JWT
+// брокер доступен всем.
+type AuthConfig struct {
+	APIKeys        []APIKeyConfig
+	JWT            JWTConfig
+	AllowAnonymous bool // пропускать запросы без учётных данных
+}
+
+// APIKeyConfig — статический ключ: сам ключ или его SHA-256 в hex.
+type APIKeyConfig struct {
+	Name      string `mapstructure:"name"`
+	Key       string `mapstructure:"key"`
+	KeySHA256 string `mapstructure:"key_sha256"`
+}
+
+// JWTConfig — проверка JWT. KeyFile — секрет HMAC или открытый ключ RSA в PEM.
+type JWTConfig struct {
+	KeyFile  string
+	Issuer   string
+	Audience string
+}
+
+// ACLConfig — проверка прав. Rules загружаются при старте вместе с
+// правилами, созданными через API.
+type ACLConfig struct {
+	Enabled    bool
+	SuperUsers []string // клиенты, которым разрешено всё
+	Rules      []ACLRuleConfig
+}
+
+// ACLRuleConfig — правило доступа из конфигурации.
+type ACLRuleConfig struct {
+	Principal    string   `mapstructure:"principal"`
+	ResourceType string   `mapstructure:"resource_type"` // topic, group или cluster
+	Namespace    string   `mapstructure:"namespace"`     // пусто — default, "*" — любое
+	Pattern      string   `mapstructure:"pattern"`
+	Operations   []string `mapstructure:"operations"` // publish, consume, admin
+}
+
+// TenancyConfig — разделение брокера на пространства имён арендаторов.
+type TenancyConfig struct {
+	Enabled          bool
+	DefaultNamespace string // пространство клиентов без привязки; пустое — default
+	TrustHeader      bool   // клиенты без привязки выбирают пространство заголовком
+	Admins           []string
+	Quota            TenantQuotaConfig // по умолчанию; поля Namespaces с 0 берутся отсюда
+	Namespaces       []NamespaceConfig
+}
+
+// TenantQuotaConfig — квоты пространства имён; 0 — без ограничения.
+type TenantQuotaConfig struct {
+	MaxTopics        int   `mapstructure:"max_topics"`
+	MaxSubscriptions int   `mapstructure:"max_subscriptions"`
+	MaxMemoryBytes   int64 `mapstructure:"max_memory_bytes"`
+}
+
+// NamespaceConfig — арендатор: его клиенты и квоты.
+type NamespaceConfig struct {
+	Name              string   `mapstructure:"name"`
+	Principals        []string `mapstructure:"principals"`
+	TenantQuotaConfig `mapstructure:",squash"`
+}
+
+// QuotasConfig — лимиты частоты и числа ресурсов на клиента и на топик.
+type QuotasConfig struct {
+	BurstSeconds float64     // сколько секунд лимита можно израсходовать разом; 0 — 1
+	Principal    QuotaConfig // каждому клиенту
+	Topic        QuotaConfig // каждому топику
+	Principals   []PrincipalQuotaConfig
+	Topics       []TopicQuotaConfig
+}
+
+// QuotaConfig — лимиты; 0 — без ограничения. max_topics и max_subscriptions
+// действуют только для клиентов.
+type QuotaConfig struct {
+	PublishMessagesPerSecond float64 `mapstructure:"publish_messages_per_second"`
+	PublishBytesPerSecond    float64 `mapstructure:"publish_bytes_per_second"`
+	ConsumeMessagesPerSecond float64 `mapstructure:"consume_messages_per_second"`
+	MaxTopics                int     `mapstructure:"max_topics"`
+	MaxSubscriptions         int     `mapstructure:"max_subscriptions"`
+}
+
+// PrincipalQuotaConfig — лимиты отдельного клиента; поля с 0 берутся из
+// QuotasConfig.Principal.
+type PrincipalQuotaConfig struct {
+	Name        string `mapstructure:"name"`
+	QuotaConfig `mapstructure:",squash"`
+}
+
+// TopicQuotaConfig — лимиты отдельного топика; поля с 0 берутся из
+// QuotasConfig.Topic.
+type TopicQuotaConfig struct {
+	Topic       string `mapstructure:"topic"`
+	QuotaConfig `mapstructure:",squash"`
 }
 
 type LoggingConfig struct {
-	Level string
+	Level    string // debug, info, warn или error
+	Format   string // json или text
+	Sampling LogSamplingConfig
 }
 
-func Load(path string) (*Config, error) {
-	v := viper.New()
+// LogSamplingConfig — из успешных вызовов методов Methods в лог попадает
+// каждый Every-й; 0 или 1 — все.
+type LogSamplingConfig struct {
+	Methods []string
+	Every   int
+}
 
+// newViper ищет файл конфигурации path или, если он пуст, config.yaml в
+// текущем каталоге и в ./config.
+func newViper(path string) *viper.Viper {
+	v := viper.New()
 	if path != "" {
 		v.SetConfigFile(path)
 	} else {
@@ -41,6 +193,12 @@ func Load(path string) (*Config, error) {
 		v.AddConfigPath(".")
 		v.AddConfigPath("./config")
 	}
+	return v
+}
+
+// Load читает и проверяет конфигурацию.
+func Load(path string) (*Config, error) {
+	v := newViper(path)
 
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
@@ -49,10 +207,25 @@ func Load(path string) (*Config, error) {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok || errors.Is(err, os.ErrNotExist) {
 			v.SetDefault("server.grpc_port", 50051)
 			v.SetDefault("server.http_port", 8080)
+			v.SetDefault("server.stomp_port", 61613)
+			v.SetDefault("server.kafka_port", 9092)
+			v.SetDefault("server.kafka_advertised_host", "localhost")
+			v.SetDefault("server.shutdown_timeout_seconds", 10)
+			v.SetDefault("server.tls.reload_interval_seconds", 30)
 			v.SetDefault("broker.default_retention_messages", 10000)
 			v.SetDefault("broker.ack_timeout_seconds", 30)
 			v.SetDefault("broker.max_message_size", 1048576)
+			v.SetDefault("broker.memory.overflow_policy", "reject")
+			v.SetDefault("broker.memory.block_timeout_ms", 5000)
 			v.SetDefault("logging.level", "info")
+			v.SetDefault("logging.format", "json")
+			v.SetDefault("logging.sampling.methods", []string{
+				"/broker.Broker/Publish", "/broker.Broker/Consume", "/broker.Broker/Ack",
+				"/grpc.health.v1.Health/Check",
+			})
+			v.SetDefault("logging.sampling.every", 100)
+			v.SetDefault("tracing.exporter", "none")
+			v.SetDefault("tracing.service_name", "queue-service")
 		} else {
 			return nil, fmt.Errorf("config: %w", err)
 		}
@@ -60,18 +233,173 @@ func Load(path string) (*Config, error) {
 
 	cfg := &Config{
 		Server: ServerConfig{
-			GRPCPort: v.GetInt("server.grpc_port"),
-			HTTPPort: v.GetInt("server.http_port"),
+			GRPCPort:               v.GetInt("server.grpc_port"),
+			HTTPPort:               v.GetInt("server.http_port"),
+			STOMPPort:              v.GetInt("server.stomp_port"),
+			KafkaPort:              v.GetInt("server.kafka_port"),
+			KafkaAdvertisedHost:    v.GetString("server.kafka_advertised_host"),
+			DrainDelaySeconds:      v.GetInt("server.drain_delay_seconds"),
+			ShutdownTimeoutSeconds: v.GetInt("server.shutdown_timeout_seconds"),
+			TLS: TLSConfig{
+				CertFile:              v.GetString("server.tls.cert_file"),
+				KeyFile:               v.GetString("server.tls.key_file"),
+				ClientCAFile:          v.GetString("server.tls.client_ca_file"),
+				ClientAuth:            v.GetString("server.tls.client_auth"),
+				ReloadIntervalSeconds: v.GetInt("server.tls.reload_interval_seconds"),
+			},
 		},
 		Broker: BrokerConfig{
 			DefaultRetentionMessages: v.GetInt("broker.default_retention_messages"),
 			AckTimeoutSeconds:        v.GetInt("broker.ack_timeout_seconds"),
 			MaxMessageSize:           v.GetInt("broker.max_message_size"),
+			Memory: MemoryConfig{
+				LimitBytes:      v.GetInt64("broker.memory.limit_bytes"),
+				TopicLimitBytes: v.GetInt64("broker.memory.topic_limit_bytes"),
+				OverflowPolicy:  v.GetString("broker.memory.overflow_policy"),
+				BlockTimeoutMs:  v.GetInt("broker.memory.block_timeout_ms"),
+			},
 		},
 		Logging: LoggingConfig{
-			Level: v.GetString("logging.level"),
+			Level:  v.GetString("logging.level"),
+			Format: v.GetString("logging.format"),
+			Sampling: LogSamplingConfig{
+				Methods: v.GetStringSlice("logging.sampling.methods"),
+				Every:   v.GetInt("logging.sampling.every"),
+			},
+		},
+		Tracing: TracingConfig{
+			Exporter:    v.GetString("tracing.exporter"),
+			Endpoint:    v.GetString("tracing.endpoint"),
+			Insecure:    v.GetBool("tracing.insecure"),
+			File:        v.GetString("tracing.file"),
+			ServiceName: v.GetString("tracing.service_name"),
+			SampleRatio: v.GetFloat64("tracing.sample_ratio"),
+		},
+		Auth: AuthConfig{
+			JWT: JWTConfig{
+				KeyFile:  v.GetString("auth.jwt.key_file"),
+				Issuer:   v.GetString("auth.jwt.issuer"),
+				Audience: v.GetString("auth.jwt.audience"),
+			},
+			AllowAnonymous: v.GetBool("auth.allow_anonymous"),
+		},
+		ACL: ACLConfig{
+			Enabled:    v.GetBool("acl.enabled"),
+			SuperUsers: v.GetStringSlice("acl.super_users"),
+		},
+		Tenancy: TenancyConfig{
+			Enabled:          v.GetBool("tenancy.enabled"),
+			DefaultNamespace: v.GetString("tenancy.default_namespace"),
+			TrustHeader:      v.GetBool("tenancy.trust_header"),
+			Admins:           v.GetStringSlice("tenancy.admins"),
 		},
+		Quotas: QuotasConfig{
+			BurstSeconds: v.GetFloat64("quotas.burst_seconds"),
+		},
+	}
+
+	// Лимиты топиков задаются списком: ключи карт viper приводит к нижнему
+	// регистру, а имена топиков регистрозависимы.
+	var limits []struct {
+		Topic      string `mapstructure:"topic"`
+		LimitBytes int64  `mapstructure:"limit_bytes"`
+	}
+	if err := v.UnmarshalKey("broker.memory.topic_limits", &limits); err != nil {
+		return nil, fmt.Errorf("config: broker.memory.topic_limits: %w", err)
+	}
+	for _, l := range limits {
+		if cfg.Broker.Memory.TopicLimits == nil {
+			cfg.Broker.Memory.TopicLimits = make(map[string]int64, len(limits))
+		}
+		cfg.Broker.Memory.TopicLimits[l.Topic] = l.LimitBytes
+	}
+
+	if err := v.UnmarshalKey("auth.api_keys", &cfg.Auth.APIKeys); err != nil {
+		return nil, fmt.Errorf("config: auth.api_keys: %w", err)
+	}
+	if err := v.UnmarshalKey("acl.rules", &cfg.ACL.Rules); err != nil {
+		return nil, fmt.Errorf("config: acl.rules: %w", err)
+	}
+	if err := v.UnmarshalKey("tenancy.quota", &cfg.Tenancy.Quota); err != nil {
+		return nil, fmt.Errorf("config: tenancy.quota: %w", err)
+	}
+	if err := v.UnmarshalKey("tenancy.namespaces", &cfg.Tenancy.Namespaces); err != nil {
+		return nil, fmt.Errorf("config: tenancy.namespaces: %w", err)
+	}
+	if err := v.UnmarshalKey("quotas.principal", &cfg.Quotas.Principal); err != nil {
+		return nil, fmt.Errorf("config: quotas.principal: %w", err)
+	}
+	if err := v.UnmarshalKey("quotas.topic", &cfg.Quotas.Topic); err != nil {
+		return nil, fmt.Errorf("config: quotas.topic: %w", err)
+	}
+	if err := v.UnmarshalKey("quotas.principals", &cfg.Quotas.Principals); err != nil {
+		return nil, fmt.Errorf("config: quotas.principals: %w", err)
+	}
+	if err := v.UnmarshalKey("quotas.topics", &cfg.Quotas.Topics); err != nil {
+		return nil, fmt.Errorf("config: quotas.topics: %w", err)
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
 	return cfg, nil
 }
+
+// Validate проверяет диапазоны числовых параметров. Значения из
+// перечислений (уровень логов, политика переполнения) проверяют те, кто их
+// разбирает.
+func (c *Config) Validate() error {
+	type field struct {
+		key string
+		n   int64
+	}
+	for _, f := range []field{
+		{"server.grpc_port", int64(c.Server.GRPCPort)},
+		{"server.http_port", int64(c.Server.HTTPPort)},
+		{"server.stomp_port", int64(c.Server.STOMPPort)},
+		{"server.kafka_port", int64(c.Server.KafkaPort)},
+	} {
+		if f.n < 0 || f.n > 65535 {
+			return fmt.Errorf("%s: %d is not a valid port", f.key, f.n)
+		}
+	}
+	for _, f := range []field{
+		{"broker.max_message_size", int64(c.Broker.MaxMessageSize)},
+		{"broker.ack_timeout_seconds", int64(c.Broker.AckTimeoutSeconds)},
+	} {
+		if f.n <= 0 {
+			return fmt.Errorf("%s must be positive, got %d", f.key, f.n)
+		}
+	}
+	for _, f := range []field{
+		{"server.drain_delay_seconds", int64(c.Server.DrainDelaySeconds)},
+		{"server.shutdown_timeout_seconds", int64(c.Server.ShutdownTimeoutSeconds)},
+		{"broker.memory.limit_bytes", c.Broker.Memory.LimitBytes},
+		{"broker.memory.topic_limit_bytes", c.Broker.Memory.TopicLimitBytes},
+		{"broker.memory.block_timeout_ms", int64(c.Broker.Memory.BlockTimeoutMs)},
+	} {
+		if f.n < 0 {
+			return fmt.Errorf("%s must not be negative, got %d", f.key, f.n)
+		}
+	}
+	for topic, n := range c.Broker.Memory.TopicLimits {
+		if n < 0 {
+			return fmt.Errorf("broker.memory.topic_limits: limit of %q must not be negative, got %d", topic, n)
+		}
+	}
+	return nil
+}
+
+// Watch следит за файлом конфигурации, который нашёл бы Load(path), и
+// вызывает onChange после каждой его записи или замены (в том числе
+// ConfigMap в Kubernetes). Новую конфигурацию onChange читает сам через
+// Load. Без файла конфигурации Watch возвращает ошибку.
+func Watch(path string, onChange func()) error {
+	v := newViper(path)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	v.OnConfigChange(func(fsnotify.Event) { onChange() })
+	v.WatchConfig()
+	return nil
+}
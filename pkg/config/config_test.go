@@ -1,9 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoad_defaults(t *testing.T) {
@@ -15,12 +17,24 @@ func TestLoad_defaults(t *testing.T) {
 	if cfg.Server.GRPCPort != 50051 {
 		t.Errorf("default grpc_port want 50051, got %d", cfg.Server.GRPCPort)
 	}
+	if cfg.Server.STOMPPort != 61613 {
+		t.Errorf("default stomp_port want 61613, got %d", cfg.Server.STOMPPort)
+	}
+	if cfg.Server.KafkaPort != 9092 || cfg.Server.KafkaAdvertisedHost != "localhost" {
+		t.Errorf("default kafka listener want localhost:9092, got %s:%d", cfg.Server.KafkaAdvertisedHost, cfg.Server.KafkaPort)
+	}
+	if cfg.Server.ShutdownTimeoutSeconds != 10 {
+		t.Errorf("default shutdown_timeout_seconds want 10, got %d", cfg.Server.ShutdownTimeoutSeconds)
+	}
 	if cfg.Broker.AckTimeoutSeconds != 30 {
 		t.Errorf("default ack_timeout_seconds want 30, got %d", cfg.Broker.AckTimeoutSeconds)
 	}
 	if cfg.Broker.MaxMessageSize != 1048576 {
 		t.Errorf("default max_message_size want 1048576, got %d", cfg.Broker.MaxMessageSize)
 	}
+	if cfg.Logging.Level != "info" || cfg.Logging.Format != "json" || cfg.Logging.Sampling.Every != 100 {
+		t.Errorf("default logging: %+v", cfg.Logging)
+	}
 }
 
 func TestLoad_fromFile(t *testing.T) {
@@ -30,12 +44,78 @@ func TestLoad_fromFile(t *testing.T) {
 server:
   grpc_port: 9000
   http_port: 9080
+  drain_delay_seconds: 5
+  shutdown_timeout_seconds: 30
+  tls:
+    cert_file: /etc/broker/tls.crt
+    key_file: /etc/broker/tls.key
+    client_ca_file: /etc/broker/ca.crt
+    client_auth: optional
 broker:
   default_retention_messages: 5000
   ack_timeout_seconds: 60
   max_message_size: 2048
+  memory:
+    limit_bytes: 1000000
+    overflow_policy: evict
+    topic_limits:
+      - topic: Orders
+        limit_bytes: 5000
 logging:
   level: debug
+  format: text
+  sampling:
+    methods: [/broker.Broker/Publish]
+    every: 50
+tracing:
+  exporter: otlp
+  endpoint: collector:4317
+  insecure: true
+  sample_ratio: 0.25
+auth:
+  api_keys:
+    - name: orders
+      key: orders-secret
+    - name: billing
+      key_sha256: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+  jwt:
+    key_file: /etc/broker/jwt.pem
+    issuer: https://auth.example.com
+    audience: broker
+  allow_anonymous: true
+acl:
+  enabled: true
+  super_users: [ops]
+  rules:
+    - principal: orders
+      resource_type: topic
+      namespace: team-a
+      pattern: orders.*
+      operations: [publish, consume]
+tenancy:
+  enabled: true
+  trust_header: true
+  admins: [ops]
+  quota:
+    max_topics: 10
+  namespaces:
+    - name: Shop
+      principals: [orders, billing]
+      max_topics: 50
+      max_memory_bytes: 1048576
+quotas:
+  burst_seconds: 2
+  principal:
+    publish_messages_per_second: 100
+    max_subscriptions: 20
+  topic:
+    publish_bytes_per_second: 1048576
+  principals:
+    - name: Orders
+      publish_messages_per_second: 1000
+  topics:
+    - topic: Audit
+      consume_messages_per_second: 0.5
 `), 0644)
 	if err != nil {
 		t.Fatalf("write config: %v", err)
@@ -48,13 +128,105 @@ logging:
 	if cfg.Server.GRPCPort != 9000 {
 		t.Errorf("grpc_port want 9000, got %d", cfg.Server.GRPCPort)
 	}
+	if tlsCfg := cfg.Server.TLS; tlsCfg.CertFile != "/etc/broker/tls.crt" || tlsCfg.KeyFile != "/etc/broker/tls.key" ||
+		tlsCfg.ClientCAFile != "/etc/broker/ca.crt" || tlsCfg.ClientAuth != "optional" {
+		t.Errorf("tls config: %+v", tlsCfg)
+	}
+	if cfg.Server.DrainDelaySeconds != 5 || cfg.Server.ShutdownTimeoutSeconds != 30 {
+		t.Errorf("shutdown settings: drain %d, timeout %d", cfg.Server.DrainDelaySeconds, cfg.Server.ShutdownTimeoutSeconds)
+	}
 	if cfg.Broker.AckTimeoutSeconds != 60 {
 		t.Errorf("ack_timeout want 60, got %d", cfg.Broker.AckTimeoutSeconds)
 	}
 	if cfg.Broker.MaxMessageSize != 2048 {
 		t.Errorf("max_message_size want 2048, got %d", cfg.Broker.MaxMessageSize)
 	}
+	mem := cfg.Broker.Memory
+	if mem.LimitBytes != 1000000 || mem.OverflowPolicy != "evict" || mem.TopicLimits["Orders"] != 5000 {
+		t.Errorf("memory config: %+v", mem)
+	}
 	if cfg.Logging.Level != "debug" {
 		t.Errorf("logging.level want debug, got %s", cfg.Logging.Level)
 	}
+	lg := cfg.Logging
+	if lg.Format != "text" || len(lg.Sampling.Methods) != 1 || lg.Sampling.Methods[0] != "/broker.Broker/Publish" || lg.Sampling.Every != 50 {
+		t.Errorf("logging config: %+v", lg)
+	}
+	tr := cfg.Tracing
+	if tr.Exporter != "otlp" || tr.Endpoint != "collector:4317" || !tr.Insecure || tr.SampleRatio != 0.25 {
+		t.Errorf("tracing config: %+v", tr)
+	}
+	au := cfg.Auth
+	if len(au.APIKeys) != 2 || au.APIKeys[0] != (APIKeyConfig{Name: "orders", Key: "orders-secret"}) ||
+		au.APIKeys[1].Name != "billing" || au.APIKeys[1].KeySHA256 == "" {
+		t.Errorf("auth.api_keys: %+v", au.APIKeys)
+	}
+	if au.JWT != (JWTConfig{KeyFile: "/etc/broker/jwt.pem", Issuer: "https://auth.example.com", Audience: "broker"}) || !au.AllowAnonymous {
+		t.Errorf("auth config: %+v", au)
+	}
+	acl := cfg.ACL
+	if !acl.Enabled || len(acl.SuperUsers) != 1 || acl.SuperUsers[0] != "ops" || len(acl.Rules) != 1 ||
+		acl.Rules[0].Pattern != "orders.*" || acl.Rules[0].Namespace != "team-a" || len(acl.Rules[0].Operations) != 2 {
+		t.Errorf("acl config: %+v", acl)
+	}
+	tn := cfg.Tenancy
+	if !tn.Enabled || !tn.TrustHeader || tn.DefaultNamespace != "" || len(tn.Admins) != 1 || tn.Quota.MaxTopics != 10 ||
+		len(tn.Namespaces) != 1 || tn.Namespaces[0].Name != "Shop" || len(tn.Namespaces[0].Principals) != 2 ||
+		tn.Namespaces[0].MaxTopics != 50 || tn.Namespaces[0].MaxMemoryBytes != 1048576 {
+		t.Errorf("tenancy config: %+v", tn)
+	}
+	q := cfg.Quotas
+	if q.BurstSeconds != 2 || q.Principal != (QuotaConfig{PublishMessagesPerSecond: 100, MaxSubscriptions: 20}) ||
+		q.Topic.PublishBytesPerSecond != 1048576 ||
+		len(q.Principals) != 1 || q.Principals[0].Name != "Orders" || q.Principals[0].PublishMessagesPerSecond != 1000 ||
+		len(q.Topics) != 1 || q.Topics[0].Topic != "Audit" || q.Topics[0].ConsumeMessagesPerSecond != 0.5 {
+		t.Errorf("quotas config: %+v", q)
+	}
+}
+
+func TestLoad_invalid(t *testing.T) {
+	for name, yaml := range map[string]string{
+		"max_message_size": "broker:\n  ack_timeout_seconds: 30\n",
+		"ack_timeout":      "broker:\n  ack_timeout_seconds: 0\n  max_message_size: 1024\n",
+		"port":             "server:\n  grpc_port: 70000\nbroker:\n  ack_timeout_seconds: 30\n  max_message_size: 1024\n",
+		"memory":           "broker:\n  ack_timeout_seconds: 30\n  max_message_size: 1024\n  memory:\n    limit_bytes: -1\n",
+	} {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("%s: invalid config loaded", name)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(size int) {
+		t.Helper()
+		yaml := fmt.Sprintf("broker:\n  ack_timeout_seconds: 30\n  max_message_size: %d\n", size)
+		if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(1024)
+	changed := make(chan struct{}, 10)
+	if err := Watch(path, func() { changed <- struct{}{} }); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	write(2048)
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change notification")
+	}
+	cfg, err := Load(path)
+	if err != nil || cfg.Broker.MaxMessageSize != 2048 {
+		t.Fatalf("Load after change: %+v, %v", cfg, err)
+	}
+
+	if err := Watch(filepath.Join(t.TempDir(), "missing.yaml"), func() {}); err == nil {
+		t.Error("Watch of a missing file must fail")
+	}
 }